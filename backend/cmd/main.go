@@ -3,6 +3,10 @@ package main
 import (
 	"context"
 	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -10,6 +14,9 @@ import (
 
 	"cpsu/internal/config"
 	"cpsu/internal/connectdb"
+	"cpsu/internal/i18n"
+	"cpsu/internal/imaging"
+	"cpsu/internal/storage"
 
 	authHandler "cpsu/internal/auth/handler"
 	authRepo "cpsu/internal/auth/repository"
@@ -27,11 +34,13 @@ import (
 
 	newsHandler "cpsu/internal/news/handler"
 	newsRepo "cpsu/internal/news/repository"
+	newsPublisher "cpsu/internal/news/service"
 	newsService "cpsu/internal/news/service"
 
 	courseHandler "cpsu/internal/course/handler"
 	courseRepo "cpsu/internal/course/repository"
 	courseService "cpsu/internal/course/service"
+	courseStatusScheduler "cpsu/internal/course/service"
 
 	structureHandler "cpsu/internal/course_structure/handler"
 	structureRepo "cpsu/internal/course_structure/repository"
@@ -45,6 +54,14 @@ import (
 	subjectRepo "cpsu/internal/subject/repository"
 	subjectService "cpsu/internal/subject/service"
 
+	outcomeHandler "cpsu/internal/outcome/handler"
+	outcomeRepo "cpsu/internal/outcome/repository"
+	outcomeService "cpsu/internal/outcome/service"
+
+	careerHandler "cpsu/internal/career/handler"
+	careerRepo "cpsu/internal/career/repository"
+	careerService "cpsu/internal/career/service"
+
 	personnelHandler "cpsu/internal/personnel/handler"
 	personnelRepo "cpsu/internal/personnel/repository"
 	"cpsu/internal/personnel/service"
@@ -61,6 +78,13 @@ import (
 	documentHandler "cpsu/internal/document/handler"
 	documentRepo "cpsu/internal/document/repository"
 	documentService "cpsu/internal/document/service"
+
+	searchHandler "cpsu/internal/search/handler"
+	searchRepo "cpsu/internal/search/repository"
+	searchService "cpsu/internal/search/service"
+
+	homeHandler "cpsu/internal/home/handler"
+	homeService "cpsu/internal/home/service"
 )
 
 func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
@@ -72,6 +96,32 @@ func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
 	}
 }
 
+// ServeSignedFile เสิร์ฟไฟล์จาก local storage เมื่อ URL มีลายเซ็นที่ถูกต้องและยังไม่หมดอายุ
+func ServeSignedFile(store *storage.LocalStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimPrefix(c.Param("key"), "/")
+		if !store.VerifySignature(key, c.Query("expires"), c.Query("signature")) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "invalid or expired link"})
+			return
+		}
+
+		reader, err := store.Get(c.Request.Context(), key)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "file not found"})
+			return
+		}
+		defer reader.Close()
+
+		contentType := mime.TypeByExtension(path.Ext(key))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		c.Header("Cache-Control", "no-store")
+		c.DataFromReader(http.StatusOK, -1, contentType, reader, nil)
+	}
+}
+
 func main() {
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -105,33 +155,56 @@ func main() {
 	userService := userService.NewUserService(userRepo, auditLogRepo)
 	userHandler := userHandler.NewUserHandler(userService)
 
+	store, err := storage.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create storage: %v", err)
+	}
+
+	privateStore, err := storage.NewPrivate(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create private storage: %v", err)
+	}
+
+	imagePipeline := imaging.NewPipeline(cfg.ImageMaxSize)
+
 	newsRepo := newsRepo.NewNewsRepository(db.GetDB())
-	newsService := newsService.NewNewsService(newsRepo, auditLogRepo, cfg.MinioEndpoint, cfg.MinioAccessKey, cfg.MinioSecretKey, cfg.MinioBucket, cfg.MinioUseSSL, cfg.MinioPublicBaseURL)
+	newsService := newsService.NewNewsService(newsRepo, auditLogRepo, store, imagePipeline)
+	newsFeedHandler := newsHandler.NewNewsFeedHandler(newsService, cfg.SiteBaseURL, cfg.MinioPublicBaseURL)
 	newsHandler := newsHandler.NewNewsHandler(newsService)
+	newsPublisher.PublishScheduledNews(newsService)
 
 	courseRepo := courseRepo.NewCourseRepository(db.GetDB())
 	courseService := courseService.NewCourseService(courseRepo, auditLogRepo)
 	courseHandler := courseHandler.NewCourseHandler(courseService)
+	courseStatusScheduler.ApplyScheduledCourseStatus(courseService)
 
 	structureRepo := structureRepo.NewCourseStructureRepository(db.GetDB())
-	structureService := structureService.NewCourseStructureService(structureRepo)
+	structureService := structureService.NewCourseStructureService(structureRepo, auditLogRepo)
 	structureHandler := structureHandler.NewCourseStructureHandler(structureService)
 
 	roadmapRepo := roadmapRepo.NewRoadmapRepository(db.GetDB())
-	roadmapService := roadmapService.NewRoadmapService(roadmapRepo, cfg.MinioEndpoint, cfg.MinioAccessKey, cfg.MinioSecretKey, cfg.MinioBucket, cfg.MinioUseSSL, cfg.MinioPublicBaseURL)
+	roadmapService := roadmapService.NewRoadmapService(roadmapRepo, auditLogRepo, store, imagePipeline, roadmapService.NewStudyPlanRenderer(cfg.RoadmapFontPath))
 	roadmapHandler := roadmapHandler.NewRoadmapHandler(roadmapService)
 
 	subjectRepo := subjectRepo.NewSubjectRepository(db.GetDB())
 	subjectService := subjectService.NewSubjectService(subjectRepo, auditLogRepo)
 	subjectHandler := subjectHandler.NewSubjectHandler(subjectService)
 
+	outcomeRepo := outcomeRepo.NewOutcomeRepository(db.GetDB())
+	outcomeService := outcomeService.NewOutcomeService(outcomeRepo, auditLogRepo)
+	outcomeHandler := outcomeHandler.NewOutcomeHandler(outcomeService)
+
+	careerRepo := careerRepo.NewCareerRepository(db.GetDB())
+	careerService := careerService.NewCareerService(careerRepo, auditLogRepo)
+	careerHandler := careerHandler.NewCareerHandler(careerService)
+
 	personnelRepo := personnelRepo.NewPersonnelRepository(db.GetDB())
-	personnelService := personnelService.NewPersonnelService(personnelRepo, auditLogRepo, cfg.MinioEndpoint, cfg.MinioAccessKey, cfg.MinioSecretKey, cfg.MinioBucket, cfg.MinioUseSSL, cfg.MinioPublicBaseURL)
+	personnelService := personnelService.NewPersonnelService(personnelRepo, auditLogRepo, store, imagePipeline)
 	personnelHandler := personnelHandler.NewPersonnelHandler(personnelService)
 	service.SyncScopus(personnelService)
 
 	admissionRepo := admissionRepo.NewAdmissionRepository(db.GetDB())
-	admissionService := admissionService.NewAdmissionService(admissionRepo, auditLogRepo, cfg.MinioEndpoint, cfg.MinioAccessKey, cfg.MinioSecretKey, cfg.MinioBucket, cfg.MinioUseSSL, cfg.MinioPublicBaseURL)
+	admissionService := admissionService.NewAdmissionService(admissionRepo, auditLogRepo, store, imagePipeline)
 	admissionHandler := admissionHandler.NewAdmissionHandler(admissionService)
 
 	calendarRepo := calendarRepo.NewCalendarRepository(db.GetDB())
@@ -139,9 +212,16 @@ func main() {
 	calendarHandler := calendarHandler.NewCalendarHandler(calendarService)
 
 	documentRepo := documentRepo.NewDocumentRepository(db.GetDB())
-	documentService := documentService.NewDocumentService(documentRepo, auditLogRepo, cfg.MinioEndpoint, cfg.MinioAccessKey, cfg.MinioSecretKey, cfg.MinioBucket, cfg.MinioUseSSL, cfg.MinioPublicBaseURL)
+	documentService := documentService.NewDocumentService(documentRepo, auditLogRepo, store, privateStore)
 	documentHandler := documentHandler.NewDocumentHandler(documentService)
 
+	searchRepo := searchRepo.NewSearchRepository(db.GetDB())
+	searchService := searchService.NewSearchService(searchRepo)
+	searchHandler := searchHandler.NewSearchHandler(searchService)
+
+	homeService := homeService.NewHomeService(newsService, calendarService, admissionService)
+	homeHandler := homeHandler.NewHomeHandler(homeService)
+
 	go func() {
 		for {
 			time.Sleep(10 * time.Second)
@@ -170,6 +250,13 @@ func main() {
 
 	r.Use(TimeoutMiddleware(5 * time.Second))
 
+	if localStore, ok := store.(*storage.LocalStorage); ok {
+		r.Static("/files", localStore.Root())
+	}
+	if localPrivateStore, ok := privateStore.(*storage.LocalStorage); ok {
+		r.GET("/private-files/*key", ServeSignedFile(localPrivateStore))
+	}
+
 	r.GET("/health", func(c *gin.Context) {
 		if err := connectdb.CheckDBConnection(db.GetDB()); err != nil {
 			c.JSON(503, gin.H{"detail": "Database connection failed"})
@@ -178,28 +265,59 @@ func main() {
 		c.JSON(200, gin.H{"status": "healthy", "database": "connected"})
 	})
 
-	public := r.Group("/api/v1")
+	// route สาธารณะเลือกภาษาจาก ?lang= หรือ Accept-Language ส่วน route admin ได้ข้อมูลทุกภาษา
+	feeds := r.Group("/feeds", i18n.Middleware())
+	{
+		feeds.GET("/news.rss", newsFeedHandler.RSS)
+		feeds.GET("/news.atom", newsFeedHandler.Atom)
+		feeds.GET("/news.json", newsFeedHandler.JSON)
+	}
+
+	public := r.Group("/api/v1", i18n.Middleware())
 	{
 		public.POST("/auth/login", authHandler.Login)
 		public.POST("/auth/refresh", authHandler.RefreshToken)
 
-		public.GET("/news", newsHandler.GetAllNews)
-		public.GET("/news/:id", newsHandler.GetNewsByID)
-
-		public.GET("/course", courseHandler.GetAllCourses)
-		public.GET("/course/:id", courseHandler.GetCourseByID)
-
-		public.GET("/structure", structureHandler.GetAllCourseStructure)
-		public.GET("/structure/:id", structureHandler.GetCourseStructureByID)
-
-		public.GET("/roadmap", roadmapHandler.GetAllRoadmap)
-		public.GET("/roadmap/:id", roadmapHandler.GetRoadmapByID)
-
-		public.GET("/subject", subjectHandler.GetAllSubjects)
-		public.GET("/subject/:id", subjectHandler.GetSubjectByID)
-
-		public.GET("/personnel", personnelHandler.GetAllPersonnels)
-		public.GET("/personnel/:id", personnelHandler.GetPersonnelByID)
+		public.GET("/news", newsHandler.GetPublicNews)
+		public.GET("/news/counts", newsHandler.GetNewsCounts)
+		public.GET("/news/:id", newsHandler.GetPublicNewsByID)
+		public.GET("/news-types", newsHandler.GetNewsTypes)
+		public.GET("/news-tags", newsHandler.GetNewsTags)
+
+		public.GET("/course", courseHandler.GetPublicCourses)
+		public.GET("/course/:id", courseHandler.GetPublicCourseByID)
+		public.GET("/course/:id/curriculum", courseHandler.RequirePublicCourse, structureHandler.GetCurriculum)
+		public.GET("/course/:id/lineage", courseHandler.RequirePublicCourse, courseHandler.GetPublicLineage)
+		public.GET("/course/:id/equivalences", courseHandler.RequirePublicCourse, courseHandler.GetPublicEquivalences)
+		public.GET("/course/:id/compare", courseHandler.RequirePublicCourse, courseHandler.ComparePublicCourses)
+		public.GET("/course/:id/prerequisites", courseHandler.RequirePublicCourse, subjectHandler.GetPrerequisiteGraph)
+		public.GET("/course/:id/credits", courseHandler.RequirePublicCourse, subjectHandler.GetCourseCredits)
+		public.GET("/course/:id/subjects/:code/unlocks", courseHandler.RequirePublicCourse, subjectHandler.GetSubjectUnlocks)
+		public.POST("/course/:id/graduation-audit", courseHandler.RequirePublicCourse, subjectHandler.GraduationAudit)
+		public.GET("/course/:id/study-plans", courseHandler.RequirePublicCourse, roadmapHandler.GetStudyPlans)
+		public.GET("/course/:id/study-plans/:plan", courseHandler.RequirePublicCourse, roadmapHandler.GetStudyPlan)
+		public.GET("/course/:id/study-plans/:plan/download", courseHandler.RequirePublicCourse, roadmapHandler.DownloadStudyPlan)
+		public.GET("/course/:id/plos", courseHandler.RequirePublicCourse, outcomeHandler.GetPLOs)
+		public.GET("/course/:id/curriculum-map", courseHandler.RequirePublicCourse, outcomeHandler.GetCurriculumMap)
+		public.GET("/course/:id/curriculum-map/export", courseHandler.RequirePublicCourse, outcomeHandler.ExportCurriculumMap)
+		public.GET("/course/:id/careers", courseHandler.RequirePublicCourse, careerHandler.GetCourseCareers)
+
+		public.GET("/careers", careerHandler.GetCareers)
+		public.GET("/careers/:id", careerHandler.GetCareerByID)
+		public.GET("/careers/:id/courses", careerHandler.GetCareerCourses)
+
+		public.GET("/structure", structureHandler.GetPublicCourseStructure)
+		public.GET("/structure/:id", structureHandler.GetPublicCourseStructureByID)
+
+		public.GET("/roadmap", roadmapHandler.GetPublicRoadmap)
+		public.GET("/roadmap/:id", roadmapHandler.GetPublicRoadmapByID)
+
+		public.GET("/subject", subjectHandler.GetPublicSubjects)
+		public.GET("/subject/:id", subjectHandler.GetPublicSubjectByID)
+
+		public.GET("/personnel", personnelHandler.GetPublicPersonnels)
+		public.GET("/personnel/:id", personnelHandler.GetPublicPersonnelByID)
+		public.GET("/personnel/alumni", personnelHandler.GetAlumniPersonnels)
 		public.GET("/personnel/research", personnelHandler.GetAllResearch)
 
 		public.GET("/admission", admissionHandler.GetAllAdmission)
@@ -208,8 +326,13 @@ func main() {
 		public.GET("/calendar", calendarHandler.GetAllCalendars)
 		public.GET("/calendar/:id", calendarHandler.GetCalendarByID)
 
-		public.GET("/document", documentHandler.GetAllDocument)
-		public.GET("/document/:id", documentHandler.GetDocumentByID)
+		public.GET("/document", documentHandler.GetPublicDocuments)
+		public.GET("/document/:id", documentHandler.GetPublicDocumentByID)
+		public.GET("/document/:id/download", documentHandler.DownloadDocument)
+
+		public.GET("/search", searchHandler.Search)
+
+		public.GET("/home", homeHandler.GetHome)
 	}
 
 	protected := r.Group("/api/v1")
@@ -240,6 +363,40 @@ func main() {
 			newsAdmin.POST("", permissionMiddleware.RequirePermission("news:create"), newsHandler.CreateNews)
 			newsAdmin.PUT("/:id", permissionMiddleware.RequirePermission("news:update"), newsHandler.UpdateNews)
 			newsAdmin.DELETE("/:id", permissionMiddleware.RequirePermission("news:delete"), newsHandler.DeleteNews)
+			newsAdmin.PUT("/:id/status", permissionMiddleware.RequirePermission("news:update"), newsHandler.ChangeNewsStatus)
+			newsAdmin.PUT("/:id/publish", permissionMiddleware.RequirePermission("news:publish"), newsHandler.PublishNews)
+			newsAdmin.GET("/:id/revisions", permissionMiddleware.RequirePermission("news:read_id"), newsHandler.GetRevisions)
+			newsAdmin.GET("/:id/revisions/diff", permissionMiddleware.RequirePermission("news:read_id"), newsHandler.DiffRevisions)
+			newsAdmin.GET("/:id/revisions/:revision", permissionMiddleware.RequirePermission("news:read_id"), newsHandler.GetRevision)
+			newsAdmin.POST("/:id/revisions/:revision/rollback", permissionMiddleware.RequirePermission("news:update"), newsHandler.RollbackNews)
+			newsAdmin.PUT("/:id/tags", permissionMiddleware.RequirePermission("news:update"), newsHandler.SetNewsTags)
+			newsAdmin.GET("/:id/images", permissionMiddleware.RequirePermission("news:read_id"), newsHandler.GetNewsImages)
+			newsAdmin.POST("/:id/images", permissionMiddleware.RequirePermission("news:update"), newsHandler.AddNewsImages)
+			newsAdmin.PUT("/:id/images/order", permissionMiddleware.RequirePermission("news:update"), newsHandler.ReorderNewsImages)
+			newsAdmin.PUT("/:id/images/:image", permissionMiddleware.RequirePermission("news:update"), newsHandler.UpdateNewsImage)
+			newsAdmin.DELETE("/:id/images/:image", permissionMiddleware.RequirePermission("news:update"), newsHandler.DeleteNewsImage)
+			newsAdmin.PUT("/highlights/order", permissionMiddleware.RequirePermission("news:feature"), newsHandler.ReorderHighlights)
+			newsAdmin.PUT("/:id/pin", permissionMiddleware.RequirePermission("news:feature"), newsHandler.PinNews)
+			newsAdmin.DELETE("/:id/pin", permissionMiddleware.RequirePermission("news:feature"), newsHandler.UnpinNews)
+			newsAdmin.PUT("/:id/feature", permissionMiddleware.RequirePermission("news:feature"), newsHandler.FeatureNews)
+			newsAdmin.DELETE("/:id/feature", permissionMiddleware.RequirePermission("news:feature"), newsHandler.UnfeatureNews)
+		}
+
+		newsTypeAdmin := admin.Group("/news-types")
+		{
+			newsTypeAdmin.GET("", permissionMiddleware.RequirePermission("news:read"), newsHandler.GetNewsTypes)
+			newsTypeAdmin.POST("", permissionMiddleware.RequirePermission("news_types:create"), newsHandler.CreateNewsType)
+			newsTypeAdmin.PUT("/order", permissionMiddleware.RequirePermission("news_types:update"), newsHandler.ReorderNewsTypes)
+			newsTypeAdmin.PUT("/:id", permissionMiddleware.RequirePermission("news_types:update"), newsHandler.UpdateNewsType)
+			newsTypeAdmin.DELETE("/:id", permissionMiddleware.RequirePermission("news_types:delete"), newsHandler.DeleteNewsType)
+		}
+
+		newsTagAdmin := admin.Group("/news-tags")
+		{
+			newsTagAdmin.GET("", permissionMiddleware.RequirePermission("news:read"), newsHandler.GetNewsTags)
+			newsTagAdmin.POST("", permissionMiddleware.RequirePermission("news_tags:create"), newsHandler.CreateNewsTag)
+			newsTagAdmin.PUT("/:id", permissionMiddleware.RequirePermission("news_tags:update"), newsHandler.UpdateNewsTag)
+			newsTagAdmin.DELETE("/:id", permissionMiddleware.RequirePermission("news_tags:delete"), newsHandler.DeleteNewsTag)
 		}
 
 		courseAdmin := admin.Group("/course")
@@ -249,6 +406,35 @@ func main() {
 			courseAdmin.POST("", permissionMiddleware.RequirePermission("courses:create"), courseHandler.CreateCourse)
 			courseAdmin.PUT("/:id", permissionMiddleware.RequirePermission("courses:update"), courseHandler.UpdateCourse)
 			courseAdmin.DELETE("/:id", permissionMiddleware.RequirePermission("courses:delete"), courseHandler.DeleteCourse)
+			courseAdmin.PUT("/:id/status", permissionMiddleware.RequirePermission("courses:update"), courseHandler.SetCourseStatus)
+			courseAdmin.DELETE("/:id/status/schedule", permissionMiddleware.RequirePermission("courses:update"), courseHandler.ClearCourseStatusSchedule)
+			courseAdmin.GET("/:id/lineage", permissionMiddleware.RequirePermission("courses:read_id"), courseHandler.GetLineage)
+			courseAdmin.PUT("/:id/lineage", permissionMiddleware.RequirePermission("courses:update"), courseHandler.SetLineage)
+			courseAdmin.GET("/:id/equivalences", permissionMiddleware.RequirePermission("courses:read_id"), courseHandler.GetEquivalences)
+			courseAdmin.PUT("/:id/equivalences/:from", permissionMiddleware.RequirePermission("courses:update"), courseHandler.SetEquivalences)
+			courseAdmin.GET("/:id/compare", permissionMiddleware.RequirePermission("courses:read_id"), courseHandler.CompareCourses)
+			courseAdmin.GET("/:id/curriculum", permissionMiddleware.RequirePermission("course_structure:read_id"), structureHandler.GetCurriculum)
+			courseAdmin.PUT("/:id/curriculum", permissionMiddleware.RequirePermission("course_structure:update"), structureHandler.ImportCurriculum)
+			courseAdmin.GET("/:id/prerequisites", permissionMiddleware.RequirePermission("subject:read"), subjectHandler.GetPrerequisiteGraph)
+			courseAdmin.GET("/:id/credits", permissionMiddleware.RequirePermission("subject:read"), subjectHandler.GetCourseCredits)
+			courseAdmin.POST("/:id/prerequisites/parse", permissionMiddleware.RequirePermission("subject:update"), subjectHandler.ParsePrerequisites)
+			courseAdmin.PUT("/:id/prerequisites/:code", permissionMiddleware.RequirePermission("subject:update"), subjectHandler.SetPrerequisites)
+			courseAdmin.GET("/:id/study-plans", permissionMiddleware.RequirePermission("roadmap:read"), roadmapHandler.GetStudyPlans)
+			courseAdmin.GET("/:id/study-plans/:plan/validation", permissionMiddleware.RequirePermission("roadmap:read_id"), roadmapHandler.ValidateStudyPlan)
+			courseAdmin.POST("/:id/study-plans", permissionMiddleware.RequirePermission("roadmap:create"), roadmapHandler.CreateStudyPlan)
+			courseAdmin.PUT("/:id/study-plans/:plan", permissionMiddleware.RequirePermission("roadmap:update"), roadmapHandler.UpdateStudyPlan)
+			courseAdmin.DELETE("/:id/study-plans/:plan", permissionMiddleware.RequirePermission("roadmap:delete"), roadmapHandler.DeleteStudyPlan)
+			courseAdmin.PUT("/:id/plos", permissionMiddleware.RequirePermission("courses:update"), outcomeHandler.SetPLOs)
+			courseAdmin.PUT("/:id/subjects/:code/clos", permissionMiddleware.RequirePermission("subject:update"), outcomeHandler.SetCLOs)
+			courseAdmin.PUT("/:id/curriculum-map", permissionMiddleware.RequirePermission("courses:update"), outcomeHandler.SetCurriculumMap)
+			courseAdmin.PUT("/:id/careers", permissionMiddleware.RequirePermission("courses:update"), careerHandler.SetCourseCareers)
+		}
+
+		careerAdmin := admin.Group("/careers")
+		{
+			careerAdmin.POST("", permissionMiddleware.RequirePermission("careers:create"), careerHandler.CreateCareer)
+			careerAdmin.PUT("/:id", permissionMiddleware.RequirePermission("careers:update"), careerHandler.UpdateCareer)
+			careerAdmin.DELETE("/:id", permissionMiddleware.RequirePermission("careers:delete"), careerHandler.DeleteCareer)
 		}
 
 		structureAdmin := admin.Group("/structure")
@@ -265,6 +451,7 @@ func main() {
 			roadmapAdmin.GET("", permissionMiddleware.RequirePermission("roadmap:read"), roadmapHandler.GetAllRoadmap)
 			roadmapAdmin.GET("/:id", permissionMiddleware.RequirePermission("roadmap:read_id"), roadmapHandler.GetRoadmapByID)
 			roadmapAdmin.POST("", permissionMiddleware.RequirePermission("roadmap:create"), roadmapHandler.CreateRoadmap)
+			roadmapAdmin.PUT("/:id", permissionMiddleware.RequirePermission("roadmap:update"), roadmapHandler.UpdateRoadmap)
 			roadmapAdmin.DELETE("/:id", permissionMiddleware.RequirePermission("roadmap:delete"), roadmapHandler.DeleteRoadmap)
 		}
 
@@ -273,6 +460,7 @@ func main() {
 			subjectAdmin.GET("", permissionMiddleware.RequirePermission("subject:read"), subjectHandler.GetAllSubjects)
 			subjectAdmin.GET("/:id", permissionMiddleware.RequirePermission("subject:read_id"), subjectHandler.GetSubjectByID)
 			subjectAdmin.POST("", permissionMiddleware.RequirePermission("subject:create"), subjectHandler.CreateSubject)
+			subjectAdmin.POST("/import", permissionMiddleware.RequirePermission("subject:create"), permissionMiddleware.RequirePermission("subject:update"), subjectHandler.ImportSubjects)
 			subjectAdmin.PUT("/:id", permissionMiddleware.RequirePermission("subject:update"), subjectHandler.UpdateSubject)
 			subjectAdmin.DELETE("/:id", permissionMiddleware.RequirePermission("subject:delete"), subjectHandler.DeleteSubject)
 		}
@@ -284,6 +472,8 @@ func main() {
 			personnelAdmin.POST("", permissionMiddleware.RequirePermission("personnel:create"), personnelHandler.CreatePersonnel)
 			personnelAdmin.PUT("/:id", permissionMiddleware.RequirePermission("personnel:update"), personnelHandler.UpdatePersonnel)
 			personnelAdmin.DELETE("/:id", permissionMiddleware.RequirePermission("personnel:delete"), personnelHandler.DeletePersonnel)
+			personnelAdmin.GET("/:id/status", permissionMiddleware.RequirePermission("personnel:read_id"), personnelHandler.GetPersonnelStatusHistory)
+			personnelAdmin.POST("/:id/status", permissionMiddleware.RequirePermission("personnel:update"), personnelHandler.ChangePersonnelStatus)
 			personnelAdmin.GET("/scopus", permissionMiddleware.RequirePermission("scopus:read"), personnelHandler.GetResearchfromScopus)
 			personnelAdmin.GET("/research", permissionMiddleware.RequirePermission("research:read"), personnelHandler.GetAllResearch)
 		}
@@ -310,6 +500,7 @@ func main() {
 		{
 			documentAdmin.GET("", permissionMiddleware.RequirePermission("document:read"), documentHandler.GetAllDocument)
 			documentAdmin.GET("/:id", permissionMiddleware.RequirePermission("document:read_id"), documentHandler.GetDocumentByID)
+			documentAdmin.GET("/:id/download", permissionMiddleware.RequirePermission("document:read_private"), documentHandler.DownloadPrivateDocument)
 			documentAdmin.POST("", permissionMiddleware.RequirePermission("document:create"), documentHandler.CreateDocument)
 			documentAdmin.PUT("/:id", permissionMiddleware.RequirePermission("document:update"), documentHandler.UpdateDocument)
 			documentAdmin.DELETE("/:id", permissionMiddleware.RequirePermission("document:delete"), documentHandler.DeleteDocument)
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"cpsu/internal/config"
+	"cpsu/internal/connectdb"
+	"cpsu/internal/imaging"
+	"cpsu/internal/storage"
+)
+
+// คำสั่งตรวจไฟล์ใน storage และ private storage ที่ไม่มีข้อมูลในฐานข้อมูลอ้างอิงแล้ว (orphan)
+// go run ./cmd/storage-reconcile          แสดงรายการ orphan
+// go run ./cmd/storage-reconcile -delete  ลบ orphan ทั้งหมด
+func main() {
+	deleteOrphans := flag.Bool("delete", false, "delete orphaned objects instead of only reporting them")
+	minAge := flag.Duration("min-age", 24*time.Hour, "skip objects newer than this, they may belong to an upload in progress")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := connectdb.NewPostgresDatabase(cfg.GetConnectionString())
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	store, err := storage.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create storage: %v", err)
+	}
+
+	privateStore, err := storage.NewPrivate(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create private storage: %v", err)
+	}
+
+	urls, err := storage.ReferencedURLs(db.GetDB())
+	if err != nil {
+		log.Fatalf("Failed to load references: %v", err)
+	}
+
+	privateURLs, err := storage.ReferencedPrivateURLs(db.GetDB())
+	if err != nil {
+		log.Fatalf("Failed to load private references: %v", err)
+	}
+
+	targets := []struct {
+		store   storage.Storage
+		folders []string
+		urls    []string
+	}{
+		{store, storage.Folders, urls},
+		{privateStore, storage.PrivateFolders, privateURLs},
+	}
+
+	ctx := context.Background()
+	cutoff := time.Now().Add(-*minAge)
+	orphans, failed := 0, 0
+
+	for _, target := range targets {
+		store := target.store
+		referenced := map[string]bool{}
+		for _, url := range target.urls {
+			key, ok := store.Key(url)
+			if !ok {
+				continue
+			}
+			for _, k := range imaging.ObjectKeys(key) {
+				referenced[k] = true
+			}
+		}
+
+		for _, folder := range target.folders {
+			objects, err := store.List(ctx, folder+"/")
+			if err != nil {
+				log.Fatalf("Failed to list %s: %v", folder, err)
+			}
+
+			for _, obj := range objects {
+				if referenced[obj.Key] || obj.LastModified.After(cutoff) {
+					continue
+				}
+				orphans++
+
+				if !*deleteOrphans {
+					log.Printf("orphan %s (%d bytes, %s)", store.URL(obj.Key), obj.Size, obj.LastModified.Format(time.RFC3339))
+					continue
+				}
+				if err := store.Delete(ctx, obj.Key); err != nil {
+					failed++
+					log.Printf("delete %s failed: %v", store.URL(obj.Key), err)
+					continue
+				}
+				log.Printf("deleted %s", store.URL(obj.Key))
+			}
+		}
+	}
+
+	log.Printf("found %d orphaned objects among %d references, %d failed to delete", orphans, len(urls)+len(privateURLs), failed)
+}
@@ -8,6 +8,9 @@ import (
 
 	"cpsu/internal/admission/models"
 	"cpsu/internal/admission/service"
+	"cpsu/internal/i18n"
+	"cpsu/internal/imaging"
+	"cpsu/internal/pagination"
 
 	"cpsu/internal/auth/repository"
 
@@ -32,10 +35,17 @@ func (h *AdmissionHandler) GetAllAdmission(c *gin.Context) {
 
 	admissions, err := h.admissionService.GetAllAdmission(param)
 	if err != nil {
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	for i := range admissions.Items {
+		i18n.Apply(c, &admissions.Items[i])
+	}
 	c.JSON(http.StatusOK, admissions)
 }
 
@@ -56,13 +66,16 @@ func (h *AdmissionHandler) GetAdmissionByID(c *gin.Context) {
 		return
 	}
 
+	i18n.Apply(c, admission)
 	c.JSON(http.StatusOK, admission)
 }
 
 func (h *AdmissionHandler) CreateAdmission(c *gin.Context) {
 	req := models.AdmissionRequest{
-		Round:  c.PostForm("round"),
-		Detail: c.PostForm("detail"),
+		Round:    c.PostForm("round"),
+		Detail:   c.PostForm("detail"),
+		RoundEn:  optionalPostForm(c, "round_en"),
+		DetailEn: optionalPostForm(c, "detail_en"),
 	}
 
 	fileImage, _ := c.FormFile("file_image")
@@ -73,10 +86,15 @@ func (h *AdmissionHandler) CreateAdmission(c *gin.Context) {
 
 	created, err := h.admissionService.CreateAdmission(req, fileImage, userID, ip, userAgent)
 	if err != nil {
+		if status := imaging.HTTPStatus(err); status != 0 {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	i18n.Apply(c, created)
 	c.JSON(http.StatusCreated, created)
 }
 
@@ -88,8 +106,10 @@ func (h *AdmissionHandler) UpdateAdmission(c *gin.Context) {
 	}
 
 	req := models.AdmissionRequest{
-		Round:  c.PostForm("round"),
-		Detail: c.PostForm("detail"),
+		Round:    c.PostForm("round"),
+		Detail:   c.PostForm("detail"),
+		RoundEn:  optionalPostForm(c, "round_en"),
+		DetailEn: optionalPostForm(c, "detail_en"),
 	}
 
 	fileImage, _ := c.FormFile("file_image")
@@ -100,6 +120,10 @@ func (h *AdmissionHandler) UpdateAdmission(c *gin.Context) {
 
 	updated, err := h.admissionService.UpdateAdmission(id, req, fileImage, userID, ip, userAgent)
 	if err != nil {
+		if status := imaging.HTTPStatus(err); status != 0 {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "admission not found"})
 		} else {
@@ -108,9 +132,19 @@ func (h *AdmissionHandler) UpdateAdmission(c *gin.Context) {
 		return
 	}
 
+	i18n.Apply(c, updated)
 	c.JSON(http.StatusOK, updated)
 }
 
+// optionalPostForm คืน nil เมื่อฟอร์มไม่มีฟิลด์นั้น เพื่อแยกจากการส่งค่าว่างมาเพื่อลบ
+func optionalPostForm(c *gin.Context, key string) *string {
+	value, ok := c.GetPostForm(key)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
 func (h *AdmissionHandler) DeleteAdmission(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
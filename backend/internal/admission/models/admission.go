@@ -1,22 +1,49 @@
 package models
 
+import (
+	"cpsu/internal/i18n"
+	"cpsu/internal/imaging"
+	"cpsu/internal/pagination"
+)
+
 type Admission struct {
-	AdmissionID int    `json:"admission_id"`
-	Round       string `json:"round"`
-	Detail      string `json:"detail"`
-	FileImage   string `json:"file_image"`
+	AdmissionID         int                  `json:"admission_id"`
+	Round               string               `json:"round"`
+	Detail              string               `json:"detail"`
+	RoundEn             string               `json:"round_en"`
+	DetailEn            string               `json:"detail_en"`
+	Lang                string               `json:"lang,omitempty"`
+	FileImage           string               `json:"file_image"`
+	FileImageVariants   []imaging.VariantURL `json:"file_image_variants,omitempty"`
+	MissingTranslations []string             `json:"missing_translations,omitempty"`
+}
+
+func (a *Admission) Localize(lang string) {
+	a.Round = i18n.Pick(lang, a.Round, a.RoundEn)
+	a.Detail = i18n.Pick(lang, a.Detail, a.DetailEn)
+	a.Lang = lang
+}
+
+func (a *Admission) CheckTranslations() {
+	a.MissingTranslations = i18n.Missing(
+		i18n.Field{Name: "round", TH: a.Round, EN: a.RoundEn},
+		i18n.Field{Name: "detail", TH: a.Detail, EN: a.DetailEn},
+	)
 }
 
 type AdmissionQueryParam struct {
+	pagination.Params
 	Search string `form:"search"`
-	Limit  int    `form:"limit"`
 	Round  string `form:"round"`
 	Sort   string `form:"sort"`
 	Order  string `form:"order"`
 }
 
+// AdmissionRequest ฟิลด์ภาษาอังกฤษที่เป็น nil จะคงค่าเดิมเมื่อแก้ไข
 type AdmissionRequest struct {
-	Round     string `json:"round"`
-	Detail    string `json:"detail"`
-	FileImage string `json:"file_image"`
+	Round     string  `json:"round"`
+	Detail    string  `json:"detail"`
+	RoundEn   *string `json:"round_en"`
+	DetailEn  *string `json:"detail_en"`
+	FileImage string  `json:"file_image"`
 }
@@ -3,13 +3,14 @@ package repository
 import (
 	"database/sql"
 	"strconv"
-	"strings"
 
 	"cpsu/internal/admission/models"
+	"cpsu/internal/pagination"
+	"cpsu/internal/textsearch"
 )
 
 type AdmissionRepository interface {
-	GetAllAdmission(param models.AdmissionQueryParam) ([]models.Admission, error)
+	GetAllAdmission(param models.AdmissionQueryParam) (*pagination.Page[models.Admission], error)
 	GetAdmissionByID(id int) (*models.Admission, error)
 	CreateAdmission(req models.AdmissionRequest) (*models.Admission, error)
 	UpdateAdmission(id int, req models.AdmissionRequest) (*models.Admission, error)
@@ -24,12 +25,16 @@ func NewAdmissionRepository(db *sql.DB) AdmissionRepository {
 	return &admissionRepository{db: db}
 }
 
-func (r *admissionRepository) GetAllAdmission(param models.AdmissionQueryParam) ([]models.Admission, error) {
-	query := `
-		SELECT admission_id, round, detail, file_image
-		FROM admission
-	`
+var admissionSort = pagination.Sorter{
+	Columns: map[string]string{
+		"admission_id": "admission_id",
+		"round":        "round",
+	},
+	Default: "admission_id",
+	Key:     "admission_id",
+}
 
+func (r *admissionRepository) GetAllAdmission(param models.AdmissionQueryParam) (*pagination.Page[models.Admission], error) {
 	conditions := []string{}
 	args := []interface{}{}
 	argIndex := 1
@@ -40,52 +45,43 @@ func (r *admissionRepository) GetAllAdmission(param models.AdmissionQueryParam)
 		argIndex++
 	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
-	}
-
-	sort := "admission_id"
-	if param.Sort != "" {
-		sort = param.Sort
-	}
-
-	order := "ASC"
-	if strings.ToUpper(param.Order) == "DESC" {
-		order = "DESC"
+	if terms := textsearch.Terms(param.Search); len(terms) > 0 {
+		condition, searchArgs, next := textsearch.Condition([]string{"round", "detail", "round_en", "detail_en"}, terms, argIndex)
+		conditions = append(conditions, condition)
+		args = append(args, searchArgs...)
+		argIndex = next
 	}
 
-	query += " ORDER BY " + sort + " " + order
-
-	if param.Limit > 0 {
-		query += " LIMIT " + strconv.Itoa(param.Limit)
-	}
-
-	rows, err := r.db.Query(query, args...)
+	orderBy, err := admissionSort.Parse(param.Sort, param.Order)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var admission []models.Admission
-	for rows.Next() {
+	stmt := pagination.Statement{
+		Columns:    "admission_id, round, detail, round_en, detail_en, file_image",
+		From:       "FROM admission",
+		Conditions: conditions,
+		Args:       args,
+		OrderBy:    orderBy,
+	}
+
+	return pagination.Fetch(r.db, stmt, param.Params, func(row pagination.Scanner) (models.Admission, error) {
 		var a models.Admission
-		if err := rows.Scan(
+		err := row.Scan(
 			&a.AdmissionID,
 			&a.Round,
 			&a.Detail,
+			&a.RoundEn,
+			&a.DetailEn,
 			&a.FileImage,
-		); err != nil {
-			return nil, err
-		}
-		admission = append(admission, a)
-	}
-
-	return admission, nil
+		)
+		return a, err
+	})
 }
 
 func (r *admissionRepository) GetAdmissionByID(id int) (*models.Admission, error) {
 	query := `
-		SELECT admission_id, round, detail, file_image
+		SELECT admission_id, round, detail, round_en, detail_en, file_image
 		FROM admission
 		WHERE admission_id = $1
 	`
@@ -97,6 +93,8 @@ func (r *admissionRepository) GetAdmissionByID(id int) (*models.Admission, error
 		&a.AdmissionID,
 		&a.Round,
 		&a.Detail,
+		&a.RoundEn,
+		&a.DetailEn,
 		&a.FileImage,
 	)
 	if err != nil {
@@ -113,12 +111,14 @@ func (r *admissionRepository) CreateAdmission(req models.AdmissionRequest) (*mod
 	var newID int
 
 	err := r.db.QueryRow(`
-		INSERT INTO admission (round, detail, file_image)
-		VALUES ($1, $2, $3)
+		INSERT INTO admission (round, detail, round_en, detail_en, file_image)
+		VALUES ($1, $2, COALESCE($3, ''), COALESCE($4, ''), $5)
 		RETURNING admission_id
 	`,
 		req.Round,
 		req.Detail,
+		req.RoundEn,
+		req.DetailEn,
 		req.FileImage,
 	).Scan(&newID)
 
@@ -136,12 +136,16 @@ func (r *admissionRepository) UpdateAdmission(id int, req models.AdmissionReques
 		UPDATE admission
 		SET round = $1,
 		    detail = $2,
-		    file_image = $3
-		WHERE admission_id = $4
+		    round_en = COALESCE($3, round_en),
+		    detail_en = COALESCE($4, detail_en),
+		    file_image = $5
+		WHERE admission_id = $6
 		RETURNING admission_id
 	`,
 		req.Round,
 		req.Detail,
+		req.RoundEn,
+		req.DetailEn,
 		req.FileImage,
 		id,
 	).Scan(&updatedID)
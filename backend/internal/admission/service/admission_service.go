@@ -2,23 +2,20 @@ package service
 
 import (
 	"context"
-	"fmt"
 	"mime/multipart"
-	"path/filepath"
 	"strconv"
 
 	"cpsu/internal/admission/models"
 	"cpsu/internal/admission/repository"
+	"cpsu/internal/pagination"
 
 	authrepo "cpsu/internal/auth/repository"
-
-	"github.com/google/uuid"
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
+	"cpsu/internal/imaging"
+	"cpsu/internal/storage"
 )
 
 type AdmissionService interface {
-	GetAllAdmission(param models.AdmissionQueryParam) ([]models.Admission, error)
+	GetAllAdmission(param models.AdmissionQueryParam) (*pagination.Page[models.Admission], error)
 	GetAdmissionByID(id int) (*models.Admission, error)
 	CreateAdmission(req models.AdmissionRequest, fileImage *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Admission, error)
 	UpdateAdmission(id int, req models.AdmissionRequest, fileImage *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Admission, error)
@@ -26,47 +23,45 @@ type AdmissionService interface {
 }
 
 type admissionService struct {
-	repo        repository.AdmissionRepository
-	auditRepo   *authrepo.AuditRepository
-	minioClient *minio.Client
-	bucket      string
-	publicBase  string
+	repo      repository.AdmissionRepository
+	auditRepo *authrepo.AuditRepository
+	store     storage.Storage
+	images    *imaging.Pipeline
 }
 
 func NewAdmissionService(
 	repo repository.AdmissionRepository,
 	auditRepo *authrepo.AuditRepository,
-	endpoint string,
-	accessKey string,
-	secretKey string,
-	bucket string,
-	useSSL bool,
-	publicBaseURL string,
+	store storage.Storage,
+	images *imaging.Pipeline,
 ) AdmissionService {
 
-	client, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
-		Secure: useSSL,
-	})
-	if err != nil {
-		panic(err)
-	}
-
 	return &admissionService{
-		repo:        repo,
-		auditRepo:   auditRepo,
-		minioClient: client,
-		bucket:      bucket,
-		publicBase:  publicBaseURL,
+		repo:      repo,
+		auditRepo: auditRepo,
+		store:     store,
+		images:    images,
 	}
 }
 
-func (s *admissionService) GetAllAdmission(param models.AdmissionQueryParam) ([]models.Admission, error) {
-	return s.repo.GetAllAdmission(param)
+func (s *admissionService) GetAllAdmission(param models.AdmissionQueryParam) (*pagination.Page[models.Admission], error) {
+	admissions, err := s.repo.GetAllAdmission(param)
+	if err != nil {
+		return nil, err
+	}
+	for i := range admissions.Items {
+		admissions.Items[i].FileImageVariants = imaging.VariantURLs(admissions.Items[i].FileImage)
+	}
+	return admissions, nil
 }
 
 func (s *admissionService) GetAdmissionByID(id int) (*models.Admission, error) {
-	return s.repo.GetAdmissionByID(id)
+	admission, err := s.repo.GetAdmissionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	admission.FileImageVariants = imaging.VariantURLs(admission.FileImage)
+	return admission, nil
 }
 
 func (s *admissionService) CreateAdmission(req models.AdmissionRequest, fileImage *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Admission, error) {
@@ -81,6 +76,9 @@ func (s *admissionService) CreateAdmission(req models.AdmissionRequest, fileImag
 
 	created, err := s.repo.CreateAdmission(req)
 	if err != nil {
+		if fileImage != nil {
+			imaging.RemoveUnused(s.store, req.FileImage)
+		}
 		return nil, err
 	}
 
@@ -93,11 +91,18 @@ func (s *admissionService) CreateAdmission(req models.AdmissionRequest, fileImag
 		ip, userAgent,
 	)
 
+	created.FileImageVariants = imaging.VariantURLs(created.FileImage)
 	return created, nil
 }
 
 func (s *admissionService) UpdateAdmission(id int, req models.AdmissionRequest, fileImage *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Admission, error) {
 
+	existing, err := s.repo.GetAdmissionByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req.FileImage = existing.FileImage
 	if fileImage != nil {
 		url, err := s.uploadFile(fileImage)
 		if err != nil {
@@ -108,9 +113,16 @@ func (s *admissionService) UpdateAdmission(id int, req models.AdmissionRequest,
 
 	updated, err := s.repo.UpdateAdmission(id, req)
 	if err != nil {
+		if fileImage != nil {
+			imaging.RemoveUnused(s.store, req.FileImage)
+		}
 		return nil, err
 	}
 
+	if existing.FileImage != updated.FileImage {
+		imaging.RemoveUnused(s.store, existing.FileImage)
+	}
+
 	err = s.auditRepo.LogAudit(
 		userID, "update", "admission", strconv.Itoa(id),
 		map[string]interface{}{
@@ -119,16 +131,24 @@ func (s *admissionService) UpdateAdmission(id int, req models.AdmissionRequest,
 		ip, userAgent,
 	)
 
+	updated.FileImageVariants = imaging.VariantURLs(updated.FileImage)
 	return updated, nil
 }
 
 func (s *admissionService) DeleteAdmission(id int, userID int, ip string, userAgent string) error {
 
-	err := s.repo.DeleteAdmission(id)
+	existing, err := s.repo.GetAdmissionByID(id)
 	if err != nil {
 		return err
 	}
 
+	err = s.repo.DeleteAdmission(id)
+	if err != nil {
+		return err
+	}
+
+	imaging.RemoveUnused(s.store, existing.FileImage)
+
 	err = s.auditRepo.LogAudit(
 		userID, "delete", "admission", strconv.Itoa(id),
 		map[string]interface{}{},
@@ -137,40 +157,7 @@ func (s *admissionService) DeleteAdmission(id int, userID int, ip string, userAg
 
 	return nil
 }
-func (s *admissionService) uploadFile(fileHeader *multipart.FileHeader) (string, error) {
-	file, err := fileHeader.Open()
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	ext := filepath.Ext(fileHeader.Filename)
-	objectName := fmt.Sprintf(
-		"admission/%s%s",
-		uuid.New().String(),
-		ext,
-	)
 
-	_, err = s.minioClient.PutObject(
-		context.Background(),
-		s.bucket,
-		objectName,
-		file,
-		fileHeader.Size,
-		minio.PutObjectOptions{
-			ContentType: fileHeader.Header.Get("Content-Type"),
-		},
-	)
-	if err != nil {
-		return "", err
-	}
-
-	imageURL := fmt.Sprintf(
-		"%s/%s/%s",
-		s.publicBase,
-		s.bucket,
-		objectName,
-	)
-
-	return imageURL, nil
+func (s *admissionService) uploadFile(fileHeader *multipart.FileHeader) (string, error) {
+	return s.images.Upload(context.Background(), s.store, "admission", fileHeader)
 }
@@ -6,7 +6,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"cpsu/internal/auth/models"
 	"cpsu/internal/auth/service"
+	"cpsu/internal/pagination"
 )
 
 type AuditHandler struct {
@@ -18,8 +20,20 @@ func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
 }
 
 func (h *AuditHandler) GetAllAuditLog(c *gin.Context) {
-	audits, err := h.AuditService.GetAllAuditLog(c.Request.Context())
+	var param models.AuditLogQueryParam
+	if err := c.ShouldBindQuery(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	audits, err := h.AuditService.GetAllAuditLog(c.Request.Context(), param)
 	if err != nil {
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "failed to get audit logs",
 			"error":   err.Error(),
@@ -27,7 +41,5 @@ func (h *AuditHandler) GetAllAuditLog(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data": audits,
-	})
+	c.JSON(http.StatusOK, audits)
 }
@@ -6,6 +6,7 @@ import (
 
 	"cpsu/internal/auth/models"
 	"cpsu/internal/auth/service"
+	"cpsu/internal/pagination"
 
 	"cpsu/internal/auth/repository"
 
@@ -32,6 +33,10 @@ func (h *UserHandler) GetAllUser(c *gin.Context) {
 
 	users, err := h.UserService.GetAllUser(param)
 	if err != nil {
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": err.Error(),
 		})
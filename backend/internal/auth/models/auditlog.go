@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"cpsu/internal/pagination"
+)
 
 type AuditLog struct {
 	ID         int                    `json:"id"`
@@ -25,3 +29,9 @@ type AuditLogResponse struct {
 	Description string    `json:"description"`
 	CreatedAt   time.Time `json:"created_at"`
 }
+
+type AuditLogQueryParam struct {
+	pagination.Params
+	Sort  string `form:"sort"`
+	Order string `form:"order"`
+}
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"cpsu/internal/pagination"
+)
 
 type User struct {
 	UserID       int        `json:"user_id"`
@@ -22,8 +26,8 @@ type UserResponse struct {
 }
 
 type UserQueryParam struct {
+	pagination.Params
 	Search string `form:"search"`
-	Limit  int    `form:"limit"`
 	UserID int    `form:"user_id"`
 	RoleID int    `json:"role_id"`
 	Sort   string `form:"sort"`
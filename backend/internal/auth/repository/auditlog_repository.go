@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"cpsu/internal/auth/models"
+	"cpsu/internal/pagination"
 	"database/sql"
 	"encoding/json"
 )
@@ -15,33 +16,49 @@ func NewAuditRepository(db *sql.DB) *AuditRepository {
 	return &AuditRepository{db: db}
 }
 
-func (r *AuditRepository) GetAllAuditLog(ctx context.Context) ([]models.AuditLog, error) {
+var auditLogSort = pagination.Sorter{
+	Columns: map[string]string{
+		"id":         "a.id",
+		"user_id":    "a.user_id",
+		"action":     "a.action",
+		"resource":   "a.resource",
+		"created_at": "a.created_at",
+	},
+	Default: "-created_at",
+	Key:     "a.id",
+}
 
-	rows, err := r.db.QueryContext(ctx, `
-		SELECT
-			a.id, u.user_id, u.username, u.email, a.action, 
-			a.resource, a.resource_id, a.details, a.created_at
-		FROM audit_logs a 
-		LEFT JOIN users u ON a.user_id = u.user_id
-		ORDER BY a.created_at DESC
-	`)
+func (r *AuditRepository) GetAllAuditLog(ctx context.Context, param models.AuditLogQueryParam) (*pagination.Page[models.AuditLog], error) {
+	orderBy, err := auditLogSort.Parse(param.Sort, param.Order)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var audits []models.AuditLog
-	var username, email sql.NullString
+	stmt := pagination.Statement{
+		Columns: `
+			a.id, u.user_id, u.username, u.email, a.action, 
+			a.resource, a.resource_id, a.details, a.created_at
+		`,
+		From: `
+			FROM audit_logs a 
+			LEFT JOIN users u ON a.user_id = u.user_id
+		`,
+		OrderBy: orderBy,
+	}
 
-	for rows.Next() {
+	return pagination.FetchContext(ctx, r.db, stmt, param.Params, func(row pagination.Scanner) (models.AuditLog, error) {
 		var audit models.AuditLog
+		var username, email sql.NullString
 		var detailsBytes []byte
 
-		err := rows.Scan(
+		err := row.Scan(
 			&audit.ID, &audit.UserID, &username, &email,
 			&audit.Action, &audit.Resource, &audit.ResourceID,
 			&detailsBytes, &audit.CreatedAt,
 		)
+		if err != nil {
+			return audit, err
+		}
 
 		if username.Valid {
 			audit.Username = username.String
@@ -50,24 +67,14 @@ func (r *AuditRepository) GetAllAuditLog(ctx context.Context) ([]models.AuditLog
 			audit.Email = email.String
 		}
 
-		if err != nil {
-			return nil, err
-		}
-
 		if len(detailsBytes) > 0 {
 			if err := json.Unmarshal(detailsBytes, &audit.Details); err != nil {
-				return nil, err
+				return audit, err
 			}
 		}
 
-		audits = append(audits, audit)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return audits, nil
+		return audit, nil
+	})
 }
 
 func (r *AuditRepository) LogAudit(userID int, action string, resource string, resourceID string, details map[string]interface{}, ipAddress string, userAgent string) error {
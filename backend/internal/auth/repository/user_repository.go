@@ -4,9 +4,9 @@ import (
 	"database/sql"
 	"errors"
 	"strconv"
-	"strings"
 
 	"cpsu/internal/auth/models"
+	"cpsu/internal/pagination"
 )
 
 type UserRepository struct {
@@ -17,16 +17,19 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-func (r *UserRepository) GetAllUser(param models.UserQueryParam) ([]models.UserResponse, error) {
-	query := `
-		SELECT u.user_id, u.username, u.email, r.role_id, r.name
-		FROM users u
-		LEFT JOIN user_roles ur ON u.user_id = ur.user_id
-		LEFT JOIN roles r ON ur.role_id = r.role_id
-		WHERE u.deleted_at IS NULL
-	`
+var userSort = pagination.Sorter{
+	Columns: map[string]string{
+		"user_id":  "u.user_id",
+		"username": "u.username",
+		"email":    "u.email",
+		"role":     "r.name",
+	},
+	Default: "user_id",
+	Key:     "u.user_id",
+}
 
-	conditions := []string{}
+func (r *UserRepository) GetAllUser(param models.UserQueryParam) (*pagination.Page[models.UserResponse], error) {
+	conditions := []string{"u.deleted_at IS NULL"}
 	args := []interface{}{}
 	argIndex := 1
 
@@ -48,46 +51,33 @@ func (r *UserRepository) GetAllUser(param models.UserQueryParam) ([]models.UserR
 		argIndex++
 	}
 
-	if len(conditions) > 0 {
-		query += " AND " + strings.Join(conditions, " AND ")
-	}
-
-	sortColumn := "u.user_id"
-	switch param.Sort {
-	case "username":
-		sortColumn = "u.username"
-	case "email":
-		sortColumn = "u.email"
-	case "role":
-		sortColumn = "r.name"
-	}
-
-	order := "ASC"
-	if strings.ToUpper(param.Order) == "DESC" {
-		order = "DESC"
-	}
-
-	query += " ORDER BY " + sortColumn + " " + order
-
-	if param.Limit > 0 {
-		query += " LIMIT " + strconv.Itoa(param.Limit)
-	}
-
-	rows, err := r.db.Query(query, args...)
+	orderBy, err := userSort.Parse(param.Sort, param.Order)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	// ผู้ใช้หนึ่งคนอาจมีหลาย role จึงต้องมี role_id ต่อท้ายให้ลำดับไม่ซ้ำ
+	orderBy = append(orderBy, pagination.SortKey{Column: "ur.role_id", Desc: orderBy[0].Desc})
 
-	var result []models.UserResponse
-	for rows.Next() {
+	stmt := pagination.Statement{
+		Columns: "u.user_id, u.username, u.email, r.role_id, r.name",
+		From: `
+			FROM users u
+			LEFT JOIN user_roles ur ON u.user_id = ur.user_id
+			LEFT JOIN roles r ON ur.role_id = r.role_id
+		`,
+		Conditions: conditions,
+		Args:       args,
+		OrderBy:    orderBy,
+	}
+
+	return pagination.Fetch(r.db, stmt, param.Params, func(row pagination.Scanner) (models.UserResponse, error) {
 		var u models.UserResponse
 
 		var roleID sql.NullInt64
 		var roleName sql.NullString
 
-		if err := rows.Scan(&u.UserID, &u.Username, &u.Email, &roleID, &roleName); err != nil {
-			return nil, err
+		if err := row.Scan(&u.UserID, &u.Username, &u.Email, &roleID, &roleName); err != nil {
+			return u, err
 		}
 
 		if roleID.Valid {
@@ -98,9 +88,8 @@ func (r *UserRepository) GetAllUser(param models.UserQueryParam) ([]models.UserR
 			u.Name = roleName.String
 		}
 
-		result = append(result, u)
-	}
-	return result, nil
+		return u, nil
+	})
 }
 
 func (r *UserRepository) CreateUser(username, email string) (int, error) {
@@ -6,6 +6,7 @@ import (
 
 	"cpsu/internal/auth/models"
 	"cpsu/internal/auth/repository"
+	"cpsu/internal/pagination"
 )
 
 type AuditService struct {
@@ -16,16 +17,14 @@ func NewAuditService(auditRepo *repository.AuditRepository) *AuditService {
 	return &AuditService{AuditRepo: auditRepo}
 }
 
-func (s *AuditService) GetAllAuditLog(ctx context.Context) ([]models.AuditLogResponse, error) {
-	audits, err := s.AuditRepo.GetAllAuditLog(ctx)
+func (s *AuditService) GetAllAuditLog(ctx context.Context, param models.AuditLogQueryParam) (*pagination.Page[models.AuditLogResponse], error) {
+	audits, err := s.AuditRepo.GetAllAuditLog(ctx, param)
 	if err != nil {
 		return nil, err
 	}
 
-	var res []models.AuditLogResponse
-
-	for _, a := range audits {
-		res = append(res, models.AuditLogResponse{
+	return pagination.Map(audits, func(a models.AuditLog) models.AuditLogResponse {
+		return models.AuditLogResponse{
 			ID:          a.ID,
 			UserID:      a.UserID,
 			Username:    a.Username,
@@ -35,10 +34,8 @@ func (s *AuditService) GetAllAuditLog(ctx context.Context) ([]models.AuditLogRes
 			ResourceID:  a.ResourceID,
 			Description: Description(a),
 			CreatedAt:   a.CreatedAt,
-		})
-	}
-
-	return res, nil
+		}
+	}), nil
 }
 
 func Description(a models.AuditLog) string {
@@ -55,6 +52,12 @@ func Description(a models.AuditLog) string {
 		return "ลบข้อมูล"
 	case "assign_role":
 		return "ให้สิทธิ์ผู้ใช้งาน"
+	case "update_status":
+		return "เปลี่ยนสถานะข้อมูล"
+	case "publish":
+		return "เผยแพร่ข้อมูล"
+	case "rollback":
+		return "ย้อนกลับข้อมูลเป็นฉบับก่อนหน้า"
 	default:
 		return "มีการดำเนินการในระบบ"
 	}
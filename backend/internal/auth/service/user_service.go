@@ -3,6 +3,7 @@ package service
 import (
 	"cpsu/internal/auth/models"
 	"cpsu/internal/auth/repository"
+	"cpsu/internal/pagination"
 	"strconv"
 )
 
@@ -21,7 +22,7 @@ func NewUserService(
 	}
 }
 
-func (s *UserService) GetAllUser(param models.UserQueryParam) ([]models.UserResponse, error) {
+func (s *UserService) GetAllUser(param models.UserQueryParam) (*pagination.Page[models.UserResponse], error) {
 	return s.UserRepo.GetAllUser(param)
 }
 
@@ -8,6 +8,8 @@ import (
 
 	"cpsu/internal/calendar/models"
 	"cpsu/internal/calendar/service"
+	"cpsu/internal/i18n"
+	"cpsu/internal/pagination"
 
 	"cpsu/internal/auth/repository"
 
@@ -32,10 +34,17 @@ func (h *CalendarHandler) GetAllCalendars(c *gin.Context) {
 
 	calendars, err := h.calendarService.GetAllCalendars(param)
 	if err != nil {
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	for i := range calendars.Items {
+		i18n.Apply(c, &calendars.Items[i])
+	}
 	c.JSON(http.StatusOK, calendars)
 }
 
@@ -57,6 +66,7 @@ func (h *CalendarHandler) GetCalendarByID(c *gin.Context) {
 		return
 	}
 
+	i18n.Apply(c, calendar)
 	c.JSON(http.StatusOK, calendar)
 }
 
@@ -77,6 +87,7 @@ func (h *CalendarHandler) CreateCalendar(c *gin.Context) {
 		return
 	}
 
+	i18n.Apply(c, createdCalendar)
 	c.JSON(http.StatusCreated, createdCalendar)
 }
 
@@ -108,6 +119,7 @@ func (h *CalendarHandler) UpdateCalendar(c *gin.Context) {
 		return
 	}
 
+	i18n.Apply(c, updatedCalendar)
 	c.JSON(http.StatusOK, updatedCalendar)
 }
 
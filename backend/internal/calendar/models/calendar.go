@@ -1,26 +1,53 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"cpsu/internal/i18n"
+	"cpsu/internal/pagination"
+)
 
 type Calendar struct {
-	CalenderID int       `json:"calendar_id"`
-	Title      string    `json:"title"`
-	Detail     string    `json:"detail"`
-	StartDate  time.Time `json:"start_date"`
-	EndDate    time.Time `json:"end_date"`
+	CalenderID          int       `json:"calendar_id"`
+	Title               string    `json:"title"`
+	Detail              string    `json:"detail"`
+	TitleEn             string    `json:"title_en"`
+	DetailEn            string    `json:"detail_en"`
+	Lang                string    `json:"lang,omitempty"`
+	StartDate           time.Time `json:"start_date"`
+	EndDate             time.Time `json:"end_date"`
+	MissingTranslations []string  `json:"missing_translations,omitempty"`
+}
+
+func (cal *Calendar) Localize(lang string) {
+	cal.Title = i18n.Pick(lang, cal.Title, cal.TitleEn)
+	cal.Detail = i18n.Pick(lang, cal.Detail, cal.DetailEn)
+	cal.Lang = lang
+}
+
+func (cal *Calendar) CheckTranslations() {
+	cal.MissingTranslations = i18n.Missing(
+		i18n.Field{Name: "title", TH: cal.Title, EN: cal.TitleEn},
+		i18n.Field{Name: "detail", TH: cal.Detail, EN: cal.DetailEn},
+	)
 }
 
 type CalendarQueryParam struct {
+	pagination.Params
 	Search string `form:"search"`
-	Limit  int    `form:"limit"`
-	Sort   string `form:"sort"`
-	Order  string `form:"order"`
+	// Upcoming แสดงเฉพาะกิจกรรมที่ยังไม่สิ้นสุด
+	Upcoming bool   `form:"upcoming"`
+	Sort     string `form:"sort"`
+	Order    string `form:"order"`
 }
 
+// CalendarRequest ฟิลด์ภาษาอังกฤษที่เป็น nil จะคงค่าเดิมเมื่อแก้ไข
 type CalendarRequest struct {
 	CalenderID int       `json:"calendar_id"`
 	Title      string    `json:"title"`
 	Detail     string    `json:"detail"`
+	TitleEn    *string   `json:"title_en"`
+	DetailEn   *string   `json:"detail_en"`
 	StartDate  time.Time `json:"start_date"`
 	EndDate    time.Time `json:"end_date"`
 }
@@ -2,14 +2,14 @@ package repository
 
 import (
 	"database/sql"
-	"strconv"
-	"strings"
 
 	"cpsu/internal/calendar/models"
+	"cpsu/internal/pagination"
+	"cpsu/internal/textsearch"
 )
 
 type CalendarRepository interface {
-	GetAllCalendars(param models.CalendarQueryParam) ([]models.Calendar, error)
+	GetAllCalendars(param models.CalendarQueryParam) (*pagination.Page[models.Calendar], error)
 	GetCalendarByID(id int) (*models.Calendar, error)
 	CreateCalendar(req *models.CalendarRequest) (*models.Calendar, error)
 	UpdateCalendar(req *models.CalendarRequest) (*models.Calendar, error)
@@ -24,57 +24,67 @@ func NewCalendarRepository(db *sql.DB) CalendarRepository {
 	return &calendarRepository{db: db}
 }
 
-func (r *calendarRepository) GetAllCalendars(param models.CalendarQueryParam) ([]models.Calendar, error) {
-	query := `
-		SELECT calendar_id, title, detail, start_date, end_date
-		FROM calendar
-	`
+var calendarSort = pagination.Sorter{
+	Columns: map[string]string{
+		"calendar_id": "calendar_id",
+		"title":       "title",
+		"start_date":  "start_date",
+		"end_date":    "end_date",
+	},
+	Default: "calendar_id",
+	Key:     "calendar_id",
+}
 
-	sort := "calendar_id"
-	if param.Sort != "" {
-		sort = param.Sort
-	}
+func (r *calendarRepository) GetAllCalendars(param models.CalendarQueryParam) (*pagination.Page[models.Calendar], error) {
+	conditions := []string{}
+	args := []interface{}{}
 
-	order := "ASC"
-	if strings.ToUpper(param.Order) == "DESC" {
-		order = "DESC"
+	if terms := textsearch.Terms(param.Search); len(terms) > 0 {
+		condition, searchArgs, _ := textsearch.Condition([]string{"title", "detail", "title_en", "detail_en"}, terms, 1)
+		conditions = append(conditions, condition)
+		args = append(args, searchArgs...)
 	}
 
-	query += " ORDER BY " + sort + " " + order
-
-	if param.Limit > 0 {
-		query += " LIMIT " + strconv.Itoa(param.Limit)
+	if param.Upcoming {
+		conditions = append(conditions, "end_date >= CURRENT_DATE")
 	}
 
-	rows, err := r.db.Query(query)
+	orderBy, err := calendarSort.Parse(param.Sort, param.Order)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var calendars []models.Calendar
-	for rows.Next() {
-		var calendar models.Calendar
-		err := rows.Scan(&calendar.CalenderID, &calendar.Title, &calendar.Detail, &calendar.StartDate, &calendar.EndDate)
-		if err != nil {
-			return nil, err
-		}
-		calendars = append(calendars, calendar)
+
+	stmt := pagination.Statement{
+		Columns:    calendarColumns,
+		From:       "FROM calendar",
+		Conditions: conditions,
+		Args:       args,
+		OrderBy:    orderBy,
 	}
 
-	return calendars, nil
+	return pagination.Fetch(r.db, stmt, param.Params, func(row pagination.Scanner) (models.Calendar, error) {
+		return scanCalendar(row)
+	})
+}
+
+const calendarColumns = "calendar_id, title, detail, title_en, detail_en, start_date, end_date"
+
+func scanCalendar(row pagination.Scanner) (models.Calendar, error) {
+	var calendar models.Calendar
+	err := row.Scan(
+		&calendar.CalenderID, &calendar.Title, &calendar.Detail, &calendar.TitleEn, &calendar.DetailEn,
+		&calendar.StartDate, &calendar.EndDate,
+	)
+	return calendar, err
 }
 
 func (r *calendarRepository) GetCalendarByID(id int) (*models.Calendar, error) {
 	query := `
-		SELECT calendar_id, title, detail, start_date, end_date
+		SELECT ` + calendarColumns + `
 		FROM calendar
 		WHERE calendar_id = $1
 	`
-	row := r.db.QueryRow(query, id)
-
-	var calendar models.Calendar
-	err := row.Scan(&calendar.CalenderID, &calendar.Title, &calendar.Detail, &calendar.StartDate, &calendar.EndDate)
+	calendar, err := scanCalendar(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, sql.ErrNoRows
@@ -87,35 +97,32 @@ func (r *calendarRepository) GetCalendarByID(id int) (*models.Calendar, error) {
 
 func (r *calendarRepository) CreateCalendar(req *models.CalendarRequest) (*models.Calendar, error) {
 	query := `
-		INSERT INTO calendar (title, detail, start_date, end_date)
-		VALUES ($1, $2, $3, $4)
-		RETURNING calendar_id
+		INSERT INTO calendar (title, detail, title_en, detail_en, start_date, end_date)
+		VALUES ($1, $2, COALESCE($3, ''), COALESCE($4, ''), $5, $6)
+		RETURNING ` + calendarColumns + `
 	`
 
-	var calendar models.Calendar
-	err := r.db.QueryRow(query, req.Title, req.Detail, req.StartDate, req.EndDate).Scan(&calendar.CalenderID)
+	calendar, err := scanCalendar(r.db.QueryRow(query, req.Title, req.Detail, req.TitleEn, req.DetailEn, req.StartDate, req.EndDate))
 	if err != nil {
 		return nil, err
 	}
 
-	calendar.Title = req.Title
-	calendar.Detail = req.Detail
-	calendar.StartDate = req.StartDate
-	calendar.EndDate = req.EndDate
-
 	return &calendar, nil
 }
 
 func (r *calendarRepository) UpdateCalendar(req *models.CalendarRequest) (*models.Calendar, error) {
 	query := `
 		UPDATE calendar
-		SET title = $1, detail = $2, start_date = $3, end_date = $4
-		WHERE calendar_id = $5
-		RETURNING calendar_id
+		SET title = $1, detail = $2,
+			title_en = COALESCE($3, title_en), detail_en = COALESCE($4, detail_en),
+			start_date = $5, end_date = $6
+		WHERE calendar_id = $7
+		RETURNING ` + calendarColumns + `
 	`
 
-	var calendar models.Calendar
-	err := r.db.QueryRow(query, req.Title, req.Detail, req.StartDate, req.EndDate, req.CalenderID).Scan(&calendar.CalenderID)
+	calendar, err := scanCalendar(r.db.QueryRow(
+		query, req.Title, req.Detail, req.TitleEn, req.DetailEn, req.StartDate, req.EndDate, req.CalenderID,
+	))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, sql.ErrNoRows
@@ -123,11 +130,6 @@ func (r *calendarRepository) UpdateCalendar(req *models.CalendarRequest) (*model
 		return nil, err
 	}
 
-	calendar.Title = req.Title
-	calendar.Detail = req.Detail
-	calendar.StartDate = req.StartDate
-	calendar.EndDate = req.EndDate
-
 	return &calendar, nil
 }
 
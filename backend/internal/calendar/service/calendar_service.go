@@ -3,13 +3,14 @@ package service
 import (
 	"cpsu/internal/calendar/models"
 	"cpsu/internal/calendar/repository"
+	"cpsu/internal/pagination"
 	"strconv"
 
 	authrepo "cpsu/internal/auth/repository"
 )
 
 type CalendarService interface {
-	GetAllCalendars(param models.CalendarQueryParam) ([]models.Calendar, error)
+	GetAllCalendars(param models.CalendarQueryParam) (*pagination.Page[models.Calendar], error)
 	GetCalendarByID(id int) (*models.Calendar, error)
 	CreateCalendar(req models.CalendarRequest, userID int, ip string, userAgent string) (*models.Calendar, error)
 	UpdateCalendar(id int, req models.CalendarRequest, userID int, ip string, userAgent string) (*models.Calendar, error)
@@ -28,7 +29,7 @@ func NewCalendarService(repo repository.CalendarRepository, auditRepo *authrepo.
 	}
 }
 
-func (s *calendarService) GetAllCalendars(param models.CalendarQueryParam) ([]models.Calendar, error) {
+func (s *calendarService) GetAllCalendars(param models.CalendarQueryParam) (*pagination.Page[models.Calendar], error) {
 	return s.repo.GetAllCalendars(param)
 }
 
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"cpsu/internal/career/models"
+	"cpsu/internal/career/repository"
+	"cpsu/internal/career/service"
+	"cpsu/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CareerHandler struct {
+	careerService service.CareerService
+}
+
+func NewCareerHandler(careerService service.CareerService) *CareerHandler {
+	return &CareerHandler{careerService: careerService}
+}
+
+func (h *CareerHandler) GetCareers(c *gin.Context) {
+	var param pagination.Params
+	if err := c.BindQuery(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameter"})
+		return
+	}
+
+	careers, err := h.careerService.GetCareers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get careers"})
+		return
+	}
+
+	page, err := pagination.Slice(careers, param)
+	if err != nil {
+		body, _ := pagination.BadRequest(err)
+		c.JSON(http.StatusBadRequest, body)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *CareerHandler) GetCareerByID(c *gin.Context) {
+	id, ok := careerID(c)
+	if !ok {
+		return
+	}
+
+	career, err := h.careerService.GetCareerByID(id)
+	if err != nil {
+		careerErrorResponse(c, err, "career not found")
+		return
+	}
+	c.JSON(http.StatusOK, career)
+}
+
+// GetCareerCourses คืนหลักสูตรที่นำไปสู่อาชีพ :id
+func (h *CareerHandler) GetCareerCourses(c *gin.Context) {
+	id, ok := careerID(c)
+	if !ok {
+		return
+	}
+
+	courses, err := h.careerService.GetCareerCourses(id)
+	if err != nil {
+		careerErrorResponse(c, err, "career not found")
+		return
+	}
+	c.JSON(http.StatusOK, courses)
+}
+
+func (h *CareerHandler) GetCourseCareers(c *gin.Context) {
+	careers, err := h.careerService.GetCourseCareers(c.Param("id"))
+	if err != nil {
+		careerErrorResponse(c, err, "course not found")
+		return
+	}
+	c.JSON(http.StatusOK, careers)
+}
+
+func (h *CareerHandler) CreateCareer(c *gin.Context) {
+	var req models.CareerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.careerService.CreateCareer(req, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		careerErrorResponse(c, err, "career not found")
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+func (h *CareerHandler) UpdateCareer(c *gin.Context) {
+	id, ok := careerID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CareerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.careerService.UpdateCareer(id, req, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		careerErrorResponse(c, err, "career not found")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *CareerHandler) DeleteCareer(c *gin.Context) {
+	id, ok := careerID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.careerService.DeleteCareer(id, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent")); err != nil {
+		careerErrorResponse(c, err, "career not found")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "career deleted successfully"})
+}
+
+func (h *CareerHandler) SetCourseCareers(c *gin.Context) {
+	var req models.CourseCareersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	careers, err := h.careerService.SetCourseCareers(
+		c.Param("id"),
+		req,
+		c.GetInt("user_id"),
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+	)
+	if err != nil {
+		careerErrorResponse(c, err, "course not found")
+		return
+	}
+	c.JSON(http.StatusOK, careers)
+}
+
+func careerID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid career ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func careerErrorResponse(c *gin.Context, err error, notFound string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	case errors.Is(err, repository.ErrDuplicateTitle):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidSalary),
+		errors.Is(err, service.ErrUnknownSubject),
+		errors.Is(err, repository.ErrUnknownCareer):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
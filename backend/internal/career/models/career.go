@@ -0,0 +1,42 @@
+package models
+
+// Career คืออาชีพหนึ่งอาชีพที่หลักสูตรนำไปสู่ได้ เงินเดือนเป็นบาทต่อเดือน ไม่ระบุได้
+type Career struct {
+	CareerID    int             `json:"career_id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	SalaryMin   *int            `json:"salary_min"`
+	SalaryMax   *int            `json:"salary_max"`
+	Subjects    []CareerSubject `json:"subjects"`
+}
+
+// CareerSubject ชื่อวิชามาจากรายวิชาแถวแรกที่ใช้รหัสนี้
+type CareerSubject struct {
+	SubjectID   string `json:"subject_id"`
+	ThaiSubject string `json:"thai_subject"`
+	EngSubject  string `json:"eng_subject"`
+}
+
+// CareerCourse คือหลักสูตรที่นำไปสู่อาชีพ
+type CareerCourse struct {
+	CourseID   string `json:"course_id"`
+	ThaiCourse string `json:"thai_course"`
+	EngCourse  string `json:"eng_course"`
+	Degree     string `json:"degree"`
+	Major      string `json:"major"`
+	Year       int    `json:"year"`
+	Status     string `json:"status"`
+}
+
+type CareerRequest struct {
+	Title       string   `json:"title" binding:"required,max=255"`
+	Description string   `json:"description"`
+	SalaryMin   *int     `json:"salary_min" binding:"omitempty,min=0"`
+	SalaryMax   *int     `json:"salary_max" binding:"omitempty,min=0"`
+	SubjectIDs  []string `json:"subject_ids"`
+}
+
+// CourseCareersRequest แทนที่อาชีพทั้งหมดของหลักสูตร ลำดับใน career_ids คือลำดับที่แสดง
+type CourseCareersRequest struct {
+	CareerIDs []int `json:"career_ids"`
+}
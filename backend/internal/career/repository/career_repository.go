@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"cpsu/internal/career/models"
+	coursemodels "cpsu/internal/course/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrDuplicateTitle = errors.New("career title already exists")
+	ErrUnknownCareer  = errors.New("career not found")
+)
+
+type CareerRepository interface {
+	GetCareers() ([]models.Career, error)
+	GetCareerByID(id int) (*models.Career, error)
+	GetCareerCourses(careerID int) ([]models.CareerCourse, error)
+	GetCourseCareers(courseID string) ([]models.Career, error)
+	CreateCareer(req models.CareerRequest) (int, error)
+	UpdateCareer(id int, req models.CareerRequest) error
+	DeleteCareer(id int) error
+	SetCourseCareers(courseID string, careerIDs []int) error
+	UnknownSubjects(subjectIDs []string) ([]string, error)
+	CourseExists(courseID string) error
+}
+
+type careerRepository struct {
+	db *sql.DB
+}
+
+func NewCareerRepository(db *sql.DB) CareerRepository {
+	return &careerRepository{db: db}
+}
+
+func mapCareerError(err error, foreignKeyErr error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505":
+			return ErrDuplicateTitle
+		case "23503":
+			if foreignKeyErr != nil {
+				return foreignKeyErr
+			}
+		}
+	}
+	return err
+}
+
+func (r *careerRepository) GetCareers() ([]models.Career, error) {
+	return r.queryCareers(`
+		SELECT career_id, title, description, salary_min, salary_max
+		FROM careers
+		ORDER BY title, career_id
+	`)
+}
+
+func (r *careerRepository) GetCareerByID(id int) (*models.Career, error) {
+	careers, err := r.queryCareers(`
+		SELECT career_id, title, description, salary_min, salary_max
+		FROM careers
+		WHERE career_id = $1
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(careers) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &careers[0], nil
+}
+
+// GetCourseCareers คืนอาชีพของหลักสูตรตามลำดับที่ตั้งไว้
+func (r *careerRepository) GetCourseCareers(courseID string) ([]models.Career, error) {
+	return r.queryCareers(`
+		SELECT k.career_id, k.title, k.description, k.salary_min, k.salary_max
+		FROM course_careers cc
+		JOIN careers k ON k.career_id = cc.career_id
+		WHERE cc.course_id = $1
+		ORDER BY cc.sort_order, k.title
+	`, courseID)
+}
+
+// GetCareerCourses คืนหลักสูตรที่เผยแพร่แล้วซึ่งนำไปสู่อาชีพ หลักสูตรใหม่ก่อน
+func (r *careerRepository) GetCareerCourses(careerID int) ([]models.CareerCourse, error) {
+	rows, err := r.db.Query(`
+		SELECT c.course_id, c.thai_course, c.eng_course, c.degree, c.major, c.year, c.status
+		FROM course_careers cc
+		JOIN courses c ON c.course_id = cc.course_id
+		WHERE cc.career_id = $1 AND c.status = ANY($2)
+		ORDER BY c.year DESC, c.course_id
+	`, careerID, pq.Array(coursemodels.PublicStatuses))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	courses := []models.CareerCourse{}
+	for rows.Next() {
+		var c models.CareerCourse
+		if err := rows.Scan(&c.CourseID, &c.ThaiCourse, &c.EngCourse, &c.Degree, &c.Major, &c.Year, &c.Status); err != nil {
+			return nil, err
+		}
+		courses = append(courses, c)
+	}
+	return courses, rows.Err()
+}
+
+func (r *careerRepository) CreateCareer(req models.CareerRequest) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow(`
+		INSERT INTO careers (title, description, salary_min, salary_max)
+		VALUES ($1, $2, $3, $4)
+		RETURNING career_id
+	`, req.Title, req.Description, req.SalaryMin, req.SalaryMax).Scan(&id)
+	if err != nil {
+		return 0, mapCareerError(err, nil)
+	}
+	if err := insertCareerSubjects(tx, id, req.SubjectIDs); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+func (r *careerRepository) UpdateCareer(id int, req models.CareerRequest) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE careers
+		SET title = $1, description = $2, salary_min = $3, salary_max = $4
+		WHERE career_id = $5
+	`, req.Title, req.Description, req.SalaryMin, req.SalaryMax, id)
+	if err != nil {
+		return mapCareerError(err, nil)
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec("DELETE FROM career_subjects WHERE career_id = $1", id); err != nil {
+		return err
+	}
+	if err := insertCareerSubjects(tx, id, req.SubjectIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *careerRepository) DeleteCareer(id int) error {
+	result, err := r.db.Exec("DELETE FROM careers WHERE career_id = $1", id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetCourseCareers แทนที่อาชีพทั้งหมดของหลักสูตร sort_order ตามลำดับของ careerIDs เริ่มที่ 1
+func (r *careerRepository) SetCourseCareers(courseID string, careerIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM course_careers WHERE course_id = $1", courseID); err != nil {
+		return err
+	}
+
+	if len(careerIDs) > 0 {
+		_, err := tx.Exec(`
+			INSERT INTO course_careers (course_id, career_id, sort_order)
+			SELECT $1, o.career_id, MIN(o.ord)
+			FROM unnest($2::int[]) WITH ORDINALITY AS o(career_id, ord)
+			GROUP BY o.career_id
+		`, courseID, pq.Array(careerIDs))
+		if err != nil {
+			return mapCareerError(err, ErrUnknownCareer)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UnknownSubjects คืนรหัสวิชาที่ไม่มีในหลักสูตรใดเลย
+func (r *careerRepository) UnknownSubjects(subjectIDs []string) ([]string, error) {
+	unknown := []string{}
+	if len(subjectIDs) == 0 {
+		return unknown, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT DISTINCT u.code FROM unnest($1::text[]) AS u(code)
+		WHERE NOT EXISTS (SELECT 1 FROM subjects s WHERE s.subject_id = u.code)
+		ORDER BY u.code
+	`, pq.Array(subjectIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		unknown = append(unknown, id)
+	}
+	return unknown, rows.Err()
+}
+
+func (r *careerRepository) CourseExists(courseID string) error {
+	var id string
+	return r.db.QueryRow("SELECT course_id FROM courses WHERE course_id = $1", courseID).Scan(&id)
+}
+
+func (r *careerRepository) queryCareers(query string, args ...interface{}) ([]models.Career, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	careers := []models.Career{}
+	for rows.Next() {
+		var k models.Career
+		if err := rows.Scan(&k.CareerID, &k.Title, &k.Description, &k.SalaryMin, &k.SalaryMax); err != nil {
+			return nil, err
+		}
+		k.Subjects = []models.CareerSubject{}
+		careers = append(careers, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return careers, r.loadSubjects(careers)
+}
+
+// loadSubjects ดึงรายวิชาของทุกอาชีพด้วย query เดียว
+func (r *careerRepository) loadSubjects(careers []models.Career) error {
+	if len(careers) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(careers))
+	index := make(map[int]int, len(careers))
+	for i, k := range careers {
+		ids[i] = int64(k.CareerID)
+		index[k.CareerID] = i
+	}
+
+	rows, err := r.db.Query(`
+		SELECT cs.career_id, cs.subject_id, COALESCE(s.thai_subject, ''), COALESCE(s.eng_subject, '')
+		FROM career_subjects cs
+		LEFT JOIN LATERAL (
+			SELECT thai_subject, eng_subject
+			FROM subjects
+			WHERE subject_id = cs.subject_id
+			ORDER BY id
+			LIMIT 1
+		) s ON true
+		WHERE cs.career_id = ANY($1)
+		ORDER BY cs.career_id, cs.sort_order
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			careerID int
+			subject  models.CareerSubject
+		)
+		if err := rows.Scan(&careerID, &subject.SubjectID, &subject.ThaiSubject, &subject.EngSubject); err != nil {
+			return err
+		}
+		i := index[careerID]
+		careers[i].Subjects = append(careers[i].Subjects, subject)
+	}
+	return rows.Err()
+}
+
+func insertCareerSubjects(tx *sql.Tx, careerID int, subjectIDs []string) error {
+	for i, subjectID := range subjectIDs {
+		_, err := tx.Exec(`
+			INSERT INTO career_subjects (career_id, subject_id, sort_order)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (career_id, subject_id) DO NOTHING
+		`, careerID, subjectID, i+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cpsu/internal/career/models"
+	"cpsu/internal/career/repository"
+
+	authrepo "cpsu/internal/auth/repository"
+)
+
+var (
+	ErrInvalidSalary  = errors.New("salary_min must not be greater than salary_max")
+	ErrUnknownSubject = errors.New("unknown subject")
+)
+
+type CareerService interface {
+	GetCareers() ([]models.Career, error)
+	GetCareerByID(id int) (*models.Career, error)
+	GetCareerCourses(careerID int) ([]models.CareerCourse, error)
+	GetCourseCareers(courseID string) ([]models.Career, error)
+	CreateCareer(req models.CareerRequest, userID int, ip string, userAgent string) (*models.Career, error)
+	UpdateCareer(id int, req models.CareerRequest, userID int, ip string, userAgent string) (*models.Career, error)
+	DeleteCareer(id int, userID int, ip string, userAgent string) error
+	SetCourseCareers(courseID string, req models.CourseCareersRequest, userID int, ip string, userAgent string) ([]models.Career, error)
+}
+
+type careerService struct {
+	repo      repository.CareerRepository
+	auditRepo *authrepo.AuditRepository
+}
+
+func NewCareerService(
+	repo repository.CareerRepository,
+	auditRepo *authrepo.AuditRepository,
+) CareerService {
+	return &careerService{
+		repo:      repo,
+		auditRepo: auditRepo,
+	}
+}
+
+func (s *careerService) GetCareers() ([]models.Career, error) {
+	return s.repo.GetCareers()
+}
+
+func (s *careerService) GetCareerByID(id int) (*models.Career, error) {
+	return s.repo.GetCareerByID(id)
+}
+
+// GetCareerCourses คืนหลักสูตรที่นำไปสู่อาชีพ และคืน sql.ErrNoRows เมื่อไม่มีอาชีพนี้
+func (s *careerService) GetCareerCourses(careerID int) ([]models.CareerCourse, error) {
+	if _, err := s.repo.GetCareerByID(careerID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCareerCourses(careerID)
+}
+
+func (s *careerService) GetCourseCareers(courseID string) ([]models.Career, error) {
+	if err := s.repo.CourseExists(courseID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCourseCareers(courseID)
+}
+
+func (s *careerService) CreateCareer(req models.CareerRequest, userID int, ip string, userAgent string) (*models.Career, error) {
+	if err := s.normalize(&req); err != nil {
+		return nil, err
+	}
+	id, err := s.repo.CreateCareer(req)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID,
+		"create",
+		"career",
+		strconv.Itoa(id),
+		map[string]interface{}{
+			"title": req.Title,
+		},
+		ip,
+		userAgent,
+	)
+
+	return s.repo.GetCareerByID(id)
+}
+
+func (s *careerService) UpdateCareer(id int, req models.CareerRequest, userID int, ip string, userAgent string) (*models.Career, error) {
+	if err := s.normalize(&req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateCareer(id, req); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID,
+		"update",
+		"career",
+		strconv.Itoa(id),
+		map[string]interface{}{
+			"title":    req.Title,
+			"subjects": len(req.SubjectIDs),
+		},
+		ip,
+		userAgent,
+	)
+
+	return s.repo.GetCareerByID(id)
+}
+
+func (s *careerService) DeleteCareer(id int, userID int, ip string, userAgent string) error {
+	if err := s.repo.DeleteCareer(id); err != nil {
+		return err
+	}
+
+	_ = s.auditRepo.LogAudit(userID, "delete", "career", strconv.Itoa(id), nil, ip, userAgent)
+	return nil
+}
+
+// SetCourseCareers แทนที่อาชีพทั้งหมดของหลักสูตร ข้อความ career_paths เดิมของหลักสูตรไม่ถูกแก้
+func (s *careerService) SetCourseCareers(courseID string, req models.CourseCareersRequest, userID int, ip string, userAgent string) ([]models.Career, error) {
+	if err := s.repo.CourseExists(courseID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetCourseCareers(courseID, req.CareerIDs); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID,
+		"update",
+		"course_careers",
+		courseID,
+		map[string]interface{}{
+			"career_ids": req.CareerIDs,
+		},
+		ip,
+		userAgent,
+	)
+
+	return s.repo.GetCourseCareers(courseID)
+}
+
+// normalize ตัดช่องว่าง ตรวจช่วงเงินเดือน และตรวจว่ารหัสวิชามีอยู่จริง
+func (s *careerService) normalize(req *models.CareerRequest) error {
+	req.Title = strings.TrimSpace(req.Title)
+	req.Description = strings.TrimSpace(req.Description)
+	if req.SalaryMin != nil && req.SalaryMax != nil && *req.SalaryMin > *req.SalaryMax {
+		return ErrInvalidSalary
+	}
+
+	subjectIDs := make([]string, 0, len(req.SubjectIDs))
+	for _, id := range req.SubjectIDs {
+		if id = strings.ToUpper(strings.TrimSpace(id)); id != "" {
+			subjectIDs = append(subjectIDs, id)
+		}
+	}
+	req.SubjectIDs = subjectIDs
+
+	unknown, err := s.repo.UnknownSubjects(req.SubjectIDs)
+	if err != nil {
+		return err
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownSubject, strings.Join(unknown, ", "))
+	}
+	return nil
+}
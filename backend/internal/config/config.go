@@ -24,8 +24,25 @@ type Config struct {
 	MinioBucket        string
 	MinioUseSSL        bool
 	MinioPublicBaseURL string
+	MinioPrivateBucket string
+
+	StorageDriver         string
+	StorageLocalDir       string
+	StorageLocalPublicURL string
+
+	StorageLocalPrivateDir string
+	StorageLocalPrivateURL string
+	StorageLocalSigningKey string
+
+	ImageMaxSize int64
 
 	CalendarID string
+
+	// SiteBaseURL คือ URL ของหน้าเว็บ ใช้สร้างลิงก์ใน feed ข่าว
+	SiteBaseURL string
+
+	// RoadmapFontPath คือฟอนต์ไทย (TTF/OTF) ที่ใช้สร้างไฟล์แผนการศึกษา
+	RoadmapFontPath string
 }
 
 func LoadConfig() (Config, error) {
@@ -52,27 +69,50 @@ func LoadConfig() (Config, error) {
 	viper.SetDefault("MINIO_BUCKET", "images")
 	viper.SetDefault("MINIO_USE_SSL", false)
 	viper.SetDefault("MINIO_PUBLIC_BASE_URL", "http://localhost:9000")
+	viper.SetDefault("MINIO_PRIVATE_BUCKET", "private")
+
+	viper.SetDefault("STORAGE_DRIVER", "minio")
+	viper.SetDefault("STORAGE_LOCAL_DIR", "./uploads")
+	viper.SetDefault("STORAGE_LOCAL_PUBLIC_URL", "http://localhost:8080/files")
+	viper.SetDefault("STORAGE_LOCAL_PRIVATE_DIR", "./uploads-private")
+	viper.SetDefault("STORAGE_LOCAL_PRIVATE_URL", "http://localhost:8080/private-files")
+
+	viper.SetDefault("IMAGE_MAX_SIZE_MB", 10)
 
 	viper.SetDefault("CALENDAR.ID", "")
 
+	viper.SetDefault("SITE_BASE_URL", "http://localhost:3000")
+
+	viper.SetDefault("ROADMAP_FONT_PATH", "/usr/share/fonts/noto/NotoSansThai-Regular.ttf")
+
 	useSSL := viper.GetBool("MINIO_USE_SSL")
 
 	// Set config values
 	config := Config{
-		AppPort:            viper.GetString("APP.PORT"),
-		DatabaseHost:       viper.GetString("POSTGRES.HOST"),
-		DatabasePort:       viper.GetInt("POSTGRES.PORT"),
-		DatabaseUser:       viper.GetString("POSTGRES.USER"),
-		DatabasePassword:   viper.GetString("POSTGRES.PASSWORD"),
-		DatabaseName:       viper.GetString("POSTGRES.DBNAME"),
-		DatabaseSSLMode:    viper.GetString("POSTGRES.SSLMODE"),
-		MinioEndpoint:      viper.GetString("MINIO_ENDPOINT"),
-		MinioAccessKey:     viper.GetString("MINIO_ACCESS_KEY"),
-		MinioSecretKey:     viper.GetString("MINIO_SECRET_KEY"),
-		MinioBucket:        viper.GetString("MINIO_BUCKET"),
-		MinioUseSSL:        useSSL,
-		MinioPublicBaseURL: viper.GetString("MINIO_PUBLIC_BASE_URL"),
-		CalendarID:         viper.GetString("CALENDAR.ID"),
+		AppPort:                viper.GetString("APP.PORT"),
+		DatabaseHost:           viper.GetString("POSTGRES.HOST"),
+		DatabasePort:           viper.GetInt("POSTGRES.PORT"),
+		DatabaseUser:           viper.GetString("POSTGRES.USER"),
+		DatabasePassword:       viper.GetString("POSTGRES.PASSWORD"),
+		DatabaseName:           viper.GetString("POSTGRES.DBNAME"),
+		DatabaseSSLMode:        viper.GetString("POSTGRES.SSLMODE"),
+		MinioEndpoint:          viper.GetString("MINIO_ENDPOINT"),
+		MinioAccessKey:         viper.GetString("MINIO_ACCESS_KEY"),
+		MinioSecretKey:         viper.GetString("MINIO_SECRET_KEY"),
+		MinioBucket:            viper.GetString("MINIO_BUCKET"),
+		MinioUseSSL:            useSSL,
+		MinioPublicBaseURL:     viper.GetString("MINIO_PUBLIC_BASE_URL"),
+		MinioPrivateBucket:     viper.GetString("MINIO_PRIVATE_BUCKET"),
+		StorageDriver:          viper.GetString("STORAGE_DRIVER"),
+		StorageLocalDir:        viper.GetString("STORAGE_LOCAL_DIR"),
+		StorageLocalPublicURL:  viper.GetString("STORAGE_LOCAL_PUBLIC_URL"),
+		StorageLocalPrivateDir: viper.GetString("STORAGE_LOCAL_PRIVATE_DIR"),
+		StorageLocalPrivateURL: viper.GetString("STORAGE_LOCAL_PRIVATE_URL"),
+		StorageLocalSigningKey: viper.GetString("STORAGE_LOCAL_SIGNING_KEY"),
+		ImageMaxSize:           viper.GetInt64("IMAGE_MAX_SIZE_MB") << 20,
+		CalendarID:             viper.GetString("CALENDAR.ID"),
+		SiteBaseURL:            viper.GetString("SITE_BASE_URL"),
+		RoadmapFontPath:        viper.GetString("ROADMAP_FONT_PATH"),
 	}
 
 	return config, nil
@@ -7,6 +7,7 @@ import (
 
 	"cpsu/internal/course/models"
 	"cpsu/internal/course/service"
+	"cpsu/internal/pagination"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,8 +27,16 @@ func (h *CourseHandler) GetAllCourses(c *gin.Context) {
 		return
 	}
 
+	h.getAllCourses(c, param)
+}
+
+func (h *CourseHandler) getAllCourses(c *gin.Context, param models.CoursesQueryParam) {
 	courses, err := h.courseService.GetAllCourses(param)
 	if err != nil {
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -65,7 +74,7 @@ func (h *CourseHandler) CreateCourse(c *gin.Context) {
 
 	createdCourse, err := h.courseService.CreateCourse(req, userID, ip, userAgent)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		courseStatusErrorResponse(c, err)
 		return
 	}
 
@@ -87,11 +96,7 @@ func (h *CourseHandler) UpdateCourse(c *gin.Context) {
 
 	updatedCourse, err := h.courseService.UpdateCourse(id, req, userID, ip, userAgent)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		courseStatusErrorResponse(c, err)
 		return
 	}
 
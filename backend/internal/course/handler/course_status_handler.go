@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"cpsu/internal/course/models"
+	"cpsu/internal/course/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *CourseHandler) GetPublicCourses(c *gin.Context) {
+	var param models.CoursesQueryParam
+	if err := c.BindQuery(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if param.Status != "" && !models.IsPublicStatus(param.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+	param.PublicOnly = true
+
+	h.getAllCourses(c, param)
+}
+
+func (h *CourseHandler) GetPublicCourseByID(c *gin.Context) {
+	course, err := h.courseService.GetCourseByID(c.Param("id"))
+	if err != nil {
+		courseStatusErrorResponse(c, err)
+		return
+	}
+
+	if !models.IsPublicStatus(course.Status) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+// RequirePublicCourse ซ่อนข้อมูลย่อยของหลักสูตรที่ยังไม่เผยแพร่ (รวมถึง ?from ของหน้าเปรียบเทียบ)
+func (h *CourseHandler) RequirePublicCourse(c *gin.Context) {
+	ids := []string{c.Param("id")}
+	if from := c.Query("from"); from != "" {
+		ids = append(ids, from)
+	}
+
+	for _, id := range ids {
+		status, err := h.courseService.GetCourseStatus(id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil || !models.IsPublicStatus(status) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "course not found"})
+			return
+		}
+	}
+
+	c.Next()
+}
+
+func (h *CourseHandler) SetCourseStatus(c *gin.Context) {
+	var req models.CourseStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	ip := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+
+	course, err := h.courseService.SetCourseStatus(c.Param("id"), req, userID, ip, userAgent)
+	if err != nil {
+		courseStatusErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+func (h *CourseHandler) ClearCourseStatusSchedule(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	ip := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+
+	course, err := h.courseService.ClearCourseStatusSchedule(c.Param("id"), userID, ip, userAgent)
+	if err != nil {
+		courseStatusErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+func courseStatusErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
+	case errors.Is(err, service.ErrInvalidStatus), errors.Is(err, service.ErrInvalidStatusDate):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"cpsu/internal/course/models"
+	"cpsu/internal/course/repository"
+	"cpsu/internal/course/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *CourseHandler) GetLineage(c *gin.Context) {
+	lineage, err := h.courseService.GetLineage(c.Param("id"))
+	if err != nil {
+		lineageErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lineage)
+}
+
+func (h *CourseHandler) GetPublicLineage(c *gin.Context) {
+	lineage, err := h.courseService.GetPublicLineage(c.Param("id"))
+	if err != nil {
+		lineageErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lineage)
+}
+
+func (h *CourseHandler) SetLineage(c *gin.Context) {
+	var req models.LineageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lineage, err := h.courseService.SetLineage(
+		c.Param("id"),
+		req,
+		c.GetInt("user_id"),
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+	)
+	if err != nil {
+		lineageErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lineage)
+}
+
+func (h *CourseHandler) GetEquivalences(c *gin.Context) {
+	h.getEquivalences(c, h.courseService.GetEquivalences)
+}
+
+func (h *CourseHandler) GetPublicEquivalences(c *gin.Context) {
+	h.getEquivalences(c, h.courseService.GetPublicEquivalences)
+}
+
+func (h *CourseHandler) getEquivalences(c *gin.Context, get func(courseID string) ([]models.SubjectEquivalence, error)) {
+	equivalences, err := get(c.Param("id"))
+	if err != nil {
+		lineageErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, equivalences)
+}
+
+// SetEquivalences แทนที่ตารางเทียบรายวิชาจากหลักสูตร :from ไปยังหลักสูตร :id
+func (h *CourseHandler) SetEquivalences(c *gin.Context) {
+	var req models.EquivalenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	equivalences, err := h.courseService.SetEquivalences(
+		c.Param("id"),
+		c.Param("from"),
+		req,
+		c.GetInt("user_id"),
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+	)
+	if err != nil {
+		lineageErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, equivalences)
+}
+
+// CompareCourses เทียบกับหลักสูตร ?from= ถ้าไม่ระบุจะใช้หลักสูตรเดิมตามสายรุ่น
+func (h *CourseHandler) CompareCourses(c *gin.Context) {
+	h.compareCourses(c, h.courseService.CompareCourses)
+}
+
+// ComparePublicCourses ตอบ 404 เมื่อหลักสูตร ?from หรือรุ่นเดิมตามสายรุ่นยังไม่เผยแพร่
+func (h *CourseHandler) ComparePublicCourses(c *gin.Context) {
+	h.compareCourses(c, h.courseService.ComparePublicCourses)
+}
+
+func (h *CourseHandler) compareCourses(c *gin.Context, compare func(courseID string, fromCourseID string) (*models.CourseComparison, error)) {
+	comparison, err := compare(c.Param("id"), c.Query("from"))
+	if err != nil {
+		lineageErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, comparison)
+}
+
+func lineageErrorResponse(c *gin.Context, err error) {
+	var cycleErr *service.LineageCycleError
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
+	case errors.Is(err, service.ErrSameCourse),
+		errors.Is(err, service.ErrUnknownSubject),
+		errors.Is(err, service.ErrCompareFromMissing),
+		errors.Is(err, repository.ErrUnknownCourse):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrAlreadyReplaced):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &cycleErr):
+		c.JSON(http.StatusConflict, gin.H{"error": "course lineage cycle", "chain": cycleErr.Chain})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
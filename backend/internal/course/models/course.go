@@ -1,5 +1,11 @@
 package models
 
+import (
+	"time"
+
+	"cpsu/internal/pagination"
+)
+
 type Courses struct {
 	CourseID      string `json:"course_id"`
 	Degree        string `json:"degree"`
@@ -21,17 +27,24 @@ type Courses struct {
 	PLO           string `json:"plo"`
 	DetailURL     string `json:"detail_url"`
 	Status        string `json:"status"`
+
+	// สถานะที่ตั้งเวลาไว้ จะมีผลเมื่อถึงวันที่ next_status_at
+	NextStatus   *string    `json:"next_status"`
+	NextStatusAt *time.Time `json:"next_status_at"`
 }
 
 type CoursesQueryParam struct {
+	pagination.Params
 	Search string `form:"search"`
-	Limit  int    `form:"limit"`
 	Degree string `form:"degree"`
 	Major  string `form:"major"`
 	Year   int    `form:"year"`
 	Sort   string `form:"sort"`
 	Status string `form:"status"`
 	Order  string `form:"order"`
+
+	// PublicOnly ตั้งโดย handler ของหน้าเว็บสาธารณะ
+	PublicOnly bool `form:"-"`
 }
 
 type CoursesRequest struct {
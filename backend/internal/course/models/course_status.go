@@ -0,0 +1,35 @@
+package models
+
+const (
+	StatusDraft   = "draft"
+	StatusActive  = "active"
+	StatusClosed  = "closed"
+	StatusRetired = "retired"
+)
+
+// PublicStatuses คือสถานะที่แสดงบนหน้าเว็บสาธารณะ หลักสูตรที่ปิดรับหรือเลิกใช้ยังแสดงให้นักศึกษาที่เรียนอยู่ดูได้
+var PublicStatuses = []string{StatusActive, StatusClosed, StatusRetired}
+
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusDraft, StatusActive, StatusClosed, StatusRetired:
+		return true
+	}
+	return false
+}
+
+func IsPublicStatus(status string) bool {
+	for _, s := range PublicStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// CourseStatusRequest รับวันที่เป็น YYYY-MM-DD ถ้าไม่ระบุ effective_from หรือเป็นวันนี้หรือวันที่ผ่านมาแล้วจะเปลี่ยนทันที
+// ถ้าเป็นวันในอนาคตจะตั้งเวลาไว้ เช่น เปิดหลักสูตรใหม่ในปีการศึกษาหน้า
+type CourseStatusRequest struct {
+	Status        string  `json:"status" binding:"required"`
+	EffectiveFrom *string `json:"effective_from"`
+}
@@ -0,0 +1,95 @@
+package models
+
+// CourseVersion คือข้อมูลย่อของหลักสูตรหนึ่งรุ่น ปีของหลักสูตรอยู่ใน year และท้ายรหัส เช่น BSCS65
+type CourseVersion struct {
+	CourseID   string `json:"course_id"`
+	ThaiCourse string `json:"thai_course"`
+	EngCourse  string `json:"eng_course"`
+	Year       int    `json:"year"`
+	Credits    string `json:"credits"`
+	Status     string `json:"status"`
+}
+
+// CourseLink คือหลักสูตร course_id ที่ใช้แทนหลักสูตร replaces_id
+type CourseLink struct {
+	CourseID   string `json:"course_id"`
+	ReplacesID string `json:"replaces_id"`
+	Note       string `json:"note"`
+}
+
+// CourseLineage เรียงจากรุ่นเก่าไปรุ่นใหม่ และมีหลักสูตรที่ขอรวมอยู่ด้วย
+type CourseLineage struct {
+	CourseID string          `json:"course_id"`
+	Versions []CourseVersion `json:"versions"`
+	Links    []CourseLink    `json:"links"`
+}
+
+// LineageRequest replaces_id เป็น null คือยกเลิกการเชื่อมกับหลักสูตรเดิม
+type LineageRequest struct {
+	ReplacesID *string `json:"replaces_id"`
+	Note       string  `json:"note"`
+}
+
+type SubjectVersion struct {
+	SubjectID   string `json:"subject_id"`
+	ThaiSubject string `json:"thai_subject"`
+	EngSubject  string `json:"eng_subject"`
+	Credits     string `json:"credits"`
+}
+
+type SubjectEquivalence struct {
+	EquivalenceID int            `json:"equivalence_id"`
+	FromCourseID  string         `json:"from_course_id"`
+	From          SubjectVersion `json:"from"`
+	ToCourseID    string         `json:"to_course_id"`
+	To            SubjectVersion `json:"to"`
+	Note          string         `json:"note"`
+}
+
+// EquivalenceRequest แทนที่ตารางเทียบรายวิชาทั้งหมดจากหลักสูตรเดิมไปยังหลักสูตรนี้
+type EquivalenceRequest struct {
+	Items []EquivalenceItem `json:"items" binding:"dive"`
+}
+
+type EquivalenceItem struct {
+	FromSubjectID string `json:"from_subject_id" binding:"required"`
+	ToSubjectID   string `json:"to_subject_id" binding:"required"`
+	Note          string `json:"note"`
+}
+
+// CategoryMinimum คือหน่วยกิตขั้นต่ำของหมวดระดับบนสุดในโครงสร้างหลักสูตร
+type CategoryMinimum struct {
+	Category   string
+	NameTH     string
+	MinCredits int
+}
+
+// CourseComparison เทียบรายวิชาของหลักสูตรรุ่นเดิม (from) กับรุ่นใหม่ (to)
+// วิชาที่รหัสตรงกันหรือมีในตารางเทียบถือเป็นวิชาเดียวกัน
+type CourseComparison struct {
+	From        CourseVersion    `json:"from"`
+	To          CourseVersion    `json:"to"`
+	FromCredits int              `json:"from_credits"`
+	ToCredits   int              `json:"to_credits"`
+	Categories  []CategoryChange `json:"categories"`
+	Added       []SubjectVersion `json:"added"`
+	Removed     []SubjectVersion `json:"removed"`
+	Changed     []SubjectChange  `json:"changed"`
+	Unchanged   int              `json:"unchanged"`
+}
+
+type CategoryChange struct {
+	Category       string `json:"category"`
+	NameTH         string `json:"name_th"`
+	FromMinCredits int    `json:"from_min_credits"`
+	ToMinCredits   int    `json:"to_min_credits"`
+}
+
+// SubjectChange via เป็น code เมื่อรหัสเดิม หรือ equivalence เมื่อจับคู่จากตารางเทียบ
+// fields คือส่วนที่เปลี่ยน ได้แก่ subject_id, thai_subject, eng_subject และ credits
+type SubjectChange struct {
+	From   SubjectVersion `json:"from"`
+	To     SubjectVersion `json:"to"`
+	Via    string         `json:"via"`
+	Fields []string       `json:"fields"`
+}
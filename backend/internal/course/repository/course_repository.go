@@ -3,17 +3,37 @@ package repository
 import (
 	"database/sql"
 	"strconv"
-	"strings"
+	"time"
 
 	"cpsu/internal/course/models"
+	"cpsu/internal/pagination"
+	"cpsu/internal/textsearch"
+
+	"github.com/lib/pq"
 )
 
 type CourseRepository interface {
-	GetAllCourses(param models.CoursesQueryParam) ([]models.Courses, error)
+	GetAllCourses(param models.CoursesQueryParam) (*pagination.Page[models.Courses], error)
 	GetCourseByID(id string) (*models.Courses, error)
 	CreateCourse(req models.CoursesRequest) (*models.Courses, error)
 	UpdateCourse(id string, req models.CoursesRequest) (*models.Courses, error)
 	DeleteCourse(id string) error
+
+	CourseExists(courseID string) error
+	GetCourseVersions(ids []string) ([]models.CourseVersion, error)
+	GetCourseLinks() ([]models.CourseLink, error)
+	SetCourseLink(courseID string, replacesID *string, note string) error
+	GetVersionSubjects(courseID string) ([]models.SubjectVersion, error)
+	GetCategoryMinimums(courseID string) ([]models.CategoryMinimum, error)
+	GetEquivalences(courseID string) ([]models.SubjectEquivalence, error)
+	GetPublicEquivalences(courseID string) ([]models.SubjectEquivalence, error)
+	ReplaceEquivalences(fromCourseID string, toCourseID string, items []models.EquivalenceItem) error
+
+	GetCourseStatus(courseID string) (string, error)
+	SetCourseStatus(courseID string, status string) error
+	ScheduleCourseStatus(courseID string, status string, effectiveFrom time.Time) error
+	ClearCourseStatusSchedule(courseID string) error
+	ApplyScheduledStatuses() (int64, error)
 }
 
 type courseRepository struct {
@@ -24,18 +44,21 @@ func NewCourseRepository(db *sql.DB) CourseRepository {
 	return &courseRepository{db: db}
 }
 
-func (r *courseRepository) GetAllCourses(param models.CoursesQueryParam) ([]models.Courses, error) {
-	query := `
-		SELECT 
-			c.course_id, c.degree, c.major, c.year, c.thai_course, 
-			c.eng_course, c.thai_degree, c.eng_degree, c.admission_req, 
-			c.graduation_req, c.philosophy, c.objective, c.tuition, c.credits, cp.career_paths_id, 
-			cp.career_paths, p.plo_id, p.plo, c.detail_url, c.status
-		FROM courses c
-		LEFT JOIN career_paths cp ON c.career_paths_id = cp.career_paths_id
-		LEFT JOIN plo p ON c.plo_id = p.plo_id
-	`
+var courseSort = pagination.Sorter{
+	Columns: map[string]string{
+		"course_id":   "c.course_id",
+		"degree":      "c.degree",
+		"major":       "c.major",
+		"year":        "c.year",
+		"thai_course": "c.thai_course",
+		"eng_course":  "c.eng_course",
+		"status":      "c.status",
+	},
+	Default: "year",
+	Key:     "c.course_id",
+}
 
+func (r *courseRepository) GetAllCourses(param models.CoursesQueryParam) (*pagination.Page[models.Courses], error) {
 	conditions := []string{}
 	args := []interface{}{}
 	argIndex := 1
@@ -62,51 +85,53 @@ func (r *courseRepository) GetAllCourses(param models.CoursesQueryParam) ([]mode
 		args = append(args, param.Status)
 		argIndex++
 	}
-
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
-	}
-
-	sort := "c.year"
-	if param.Sort != "" {
-		sort = "c." + param.Sort
-	}
-
-	order := "ASC"
-	if strings.ToUpper(param.Order) == "DESC" {
-		order = "DESC"
+	if param.PublicOnly {
+		conditions = append(conditions, "c.status = ANY($"+strconv.Itoa(argIndex)+")")
+		args = append(args, pq.Array(models.PublicStatuses))
+		argIndex++
 	}
 
-	query += " ORDER BY " + sort + " " + order
-
-	if param.Limit > 0 {
-		query += " LIMIT " + strconv.Itoa(param.Limit)
+	if terms := textsearch.Terms(param.Search); len(terms) > 0 {
+		condition, searchArgs, next := textsearch.Condition([]string{"c.course_id", "c.thai_course", "c.eng_course"}, terms, argIndex)
+		conditions = append(conditions, condition)
+		args = append(args, searchArgs...)
+		argIndex = next
 	}
 
-	rows, err := r.db.Query(query, args...)
+	orderBy, err := courseSort.Parse(param.Sort, param.Order)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var courses []models.Courses
-	for rows.Next() {
+	stmt := pagination.Statement{
+		Columns: `
+			c.course_id, c.degree, c.major, c.year, c.thai_course, 
+			c.eng_course, c.thai_degree, c.eng_degree, c.admission_req, 
+			c.graduation_req, c.philosophy, c.objective, c.tuition, c.credits, cp.career_paths_id, 
+			cp.career_paths, p.plo_id, p.plo, c.detail_url, c.status, c.next_status, c.next_status_at
+		`,
+		From: `
+			FROM courses c
+			LEFT JOIN career_paths cp ON c.career_paths_id = cp.career_paths_id
+			LEFT JOIN plo p ON c.plo_id = p.plo_id
+		`,
+		Conditions: conditions,
+		Args:       args,
+		OrderBy:    orderBy,
+	}
+
+	return pagination.Fetch(r.db, stmt, param.Params, func(row pagination.Scanner) (models.Courses, error) {
 		var course models.Courses
-		err := rows.Scan(
+		err := row.Scan(
 			&course.CourseID, &course.Degree, &course.Major, &course.Year,
 			&course.ThaiCourse, &course.EngCourse, &course.ThaiDegree,
 			&course.EngDegree, &course.AdmissionReq, &course.GraduationReq,
 			&course.Philosophy, &course.Objective, &course.Tuition, &course.Credits,
 			&course.CareerPathsID, &course.CareerPaths, &course.PloID,
-			&course.PLO, &course.DetailURL, &course.Status,
+			&course.PLO, &course.DetailURL, &course.Status, &course.NextStatus, &course.NextStatusAt,
 		)
-		if err != nil {
-			return nil, err
-		}
-		courses = append(courses, course)
-	}
-
-	return courses, nil
+		return course, err
+	})
 }
 
 func (r *courseRepository) GetCourseByID(id string) (*models.Courses, error) {
@@ -115,7 +140,7 @@ func (r *courseRepository) GetCourseByID(id string) (*models.Courses, error) {
 			c.course_id, c.degree, c.major, c.year, c.thai_course, 
 			c.eng_course, c.thai_degree, c.eng_degree, c.admission_req, 
 			c.graduation_req, c.philosophy, c.objective, c.tuition, c.credits, cp.career_paths_id, 
-			cp.career_paths, p.plo_id, p.plo, c.detail_url, c.status
+			cp.career_paths, p.plo_id, p.plo, c.detail_url, c.status, c.next_status, c.next_status_at
 		FROM courses c
 		LEFT JOIN career_paths cp ON c.career_paths_id = cp.career_paths_id
 		LEFT JOIN plo p ON c.plo_id = p.plo_id
@@ -131,7 +156,7 @@ func (r *courseRepository) GetCourseByID(id string) (*models.Courses, error) {
 		&course.EngDegree, &course.AdmissionReq, &course.GraduationReq,
 		&course.Philosophy, &course.Objective, &course.Tuition, &course.Credits,
 		&course.CareerPathsID, &course.CareerPaths, &course.PloID,
-		&course.PLO, &course.DetailURL, &course.Status,
+		&course.PLO, &course.DetailURL, &course.Status, &course.NextStatus, &course.NextStatusAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -224,11 +249,14 @@ func (r *courseRepository) UpdateCourse(id string, req models.CoursesRequest) (*
 		return nil, err
 	}
 
+	// เปลี่ยนสถานะตรงนี้ต้องยกเลิกสถานะที่ตั้งเวลาไว้เหมือน SetCourseStatus
 	_, err = tx.Exec(`
 		UPDATE courses
 		SET degree=$1, major=$2, year=$3, thai_course=$4, eng_course=$5, thai_degree=$6, eng_degree=$7,
 		    admission_req=$8, graduation_req=$9, philosophy=$10, objective=$11, tuition=$12,
-		    credits=$13, career_paths_id=$14, plo_id=$15, detail_url=$16, status=$17
+		    credits=$13, career_paths_id=$14, plo_id=$15, detail_url=$16, status=COALESCE(NULLIF($17, ''), status),
+		    next_status=CASE WHEN NULLIF($17, '') IS NULL THEN next_status END,
+		    next_status_at=CASE WHEN NULLIF($17, '') IS NULL THEN next_status_at END
 		WHERE course_id=$18
 	`,
 		req.Degree, req.Major, req.Year, req.ThaiCourse, req.EngCourse, req.ThaiDegree, req.EngDegree,
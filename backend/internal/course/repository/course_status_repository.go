@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// GetCourseStatus คืน sql.ErrNoRows เมื่อไม่มีหลักสูตรนี้
+func (r *courseRepository) GetCourseStatus(courseID string) (string, error) {
+	var status string
+	err := r.db.QueryRow("SELECT status FROM courses WHERE course_id = $1", courseID).Scan(&status)
+	return status, err
+}
+
+// SetCourseStatus เปลี่ยนสถานะทันทีและยกเลิกสถานะที่ตั้งเวลาไว้
+func (r *courseRepository) SetCourseStatus(courseID string, status string) error {
+	result, err := r.db.Exec(`
+		UPDATE courses SET status = $1, next_status = NULL, next_status_at = NULL
+		WHERE course_id = $2
+	`, status, courseID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// ScheduleCourseStatus ใช้เฉพาะวันที่ของ effectiveFrom ตามเขตเวลาของค่านั้น
+func (r *courseRepository) ScheduleCourseStatus(courseID string, status string, effectiveFrom time.Time) error {
+	result, err := r.db.Exec(`
+		UPDATE courses SET next_status = $1, next_status_at = $2
+		WHERE course_id = $3
+	`, status, effectiveFrom.Format("2006-01-02"), courseID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+func (r *courseRepository) ClearCourseStatusSchedule(courseID string) error {
+	result, err := r.db.Exec(`
+		UPDATE courses SET next_status = NULL, next_status_at = NULL
+		WHERE course_id = $1
+	`, courseID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// ApplyScheduledStatuses เปลี่ยนสถานะของหลักสูตรที่ถึงวันที่ตั้งไว้แล้ว
+func (r *courseRepository) ApplyScheduledStatuses() (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE courses SET status = next_status, next_status = NULL, next_status_at = NULL
+		WHERE next_status_at <= CURRENT_DATE
+	`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func requireAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
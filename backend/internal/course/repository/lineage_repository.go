@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"errors"
+
+	"cpsu/internal/course/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrUnknownCourse   = errors.New("course not found")
+	ErrAlreadyReplaced = errors.New("course is already replaced by another course")
+)
+
+// mapLineageError แปลง error จาก constraint ของตาราง course_lineage
+func mapLineageError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505":
+			return ErrAlreadyReplaced
+		case "23503":
+			return ErrUnknownCourse
+		}
+	}
+	return err
+}
+
+func (r *courseRepository) GetCourseVersions(ids []string) ([]models.CourseVersion, error) {
+	rows, err := r.db.Query(`
+		SELECT course_id, thai_course, eng_course, year, credits, status
+		FROM courses
+		WHERE course_id = ANY($1)
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []models.CourseVersion{}
+	for rows.Next() {
+		var v models.CourseVersion
+		if err := rows.Scan(&v.CourseID, &v.ThaiCourse, &v.EngCourse, &v.Year, &v.Credits, &v.Status); err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// GetCourseLinks คืนการเชื่อมรุ่นทั้งหมด ตารางมีไม่กี่แถวจึงโหลดทั้งหมดแล้วไล่สายใน service
+func (r *courseRepository) GetCourseLinks() ([]models.CourseLink, error) {
+	rows, err := r.db.Query(`
+		SELECT course_id, replaces_id, note
+		FROM course_lineage
+		ORDER BY course_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []models.CourseLink{}
+	for rows.Next() {
+		var l models.CourseLink
+		if err := rows.Scan(&l.CourseID, &l.ReplacesID, &l.Note); err != nil {
+			return nil, err
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
+
+// SetCourseLink แทนที่หลักสูตรเดิมของ courseID ถ้า replacesID เป็น nil จะลบการเชื่อม
+func (r *courseRepository) SetCourseLink(courseID string, replacesID *string, note string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM course_lineage WHERE course_id = $1", courseID); err != nil {
+		return err
+	}
+	if replacesID != nil {
+		_, err := tx.Exec(`
+			INSERT INTO course_lineage (course_id, replaces_id, note)
+			VALUES ($1, $2, $3)
+		`, courseID, *replacesID, note)
+		if err != nil {
+			return mapLineageError(err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetVersionSubjects คืนรายวิชาของหลักสูตรเรียงตามรหัส รหัสเดียวกันหลายแผนการเรียนจะใช้แถวแรก
+func (r *courseRepository) GetVersionSubjects(courseID string) ([]models.SubjectVersion, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT ON (subject_id) subject_id, thai_subject, COALESCE(eng_subject, ''), credits
+		FROM subjects
+		WHERE course_id = $1
+		ORDER BY subject_id, id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subjects := []models.SubjectVersion{}
+	for rows.Next() {
+		var s models.SubjectVersion
+		if err := rows.Scan(&s.SubjectID, &s.ThaiSubject, &s.EngSubject, &s.Credits); err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, s)
+	}
+	return subjects, rows.Err()
+}
+
+func (r *courseRepository) GetCategoryMinimums(courseID string) ([]models.CategoryMinimum, error) {
+	rows, err := r.db.Query(`
+		SELECT category, name_th, min_credits
+		FROM curriculum_nodes
+		WHERE course_id = $1 AND parent_id IS NULL
+		ORDER BY sort_order, node_id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []models.CategoryMinimum{}
+	for rows.Next() {
+		var c models.CategoryMinimum
+		if err := rows.Scan(&c.Category, &c.NameTH, &c.MinCredits); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// GetEquivalences คืนตารางเทียบที่หลักสูตรนี้เป็นต้นทางหรือปลายทาง พร้อมชื่อวิชาของแต่ละฝั่ง
+func (r *courseRepository) GetEquivalences(courseID string) ([]models.SubjectEquivalence, error) {
+	return r.getEquivalences(courseID, false)
+}
+
+// GetPublicEquivalences ตัดแถวที่หลักสูตรอีกฝั่งยังไม่เผยแพร่
+func (r *courseRepository) GetPublicEquivalences(courseID string) ([]models.SubjectEquivalence, error) {
+	return r.getEquivalences(courseID, true)
+}
+
+func (r *courseRepository) getEquivalences(courseID string, publicOnly bool) ([]models.SubjectEquivalence, error) {
+	query := `
+		SELECT e.equivalence_id, e.from_course_id, e.from_subject_id,
+			COALESCE(fs.thai_subject, ''), COALESCE(fs.eng_subject, ''), COALESCE(fs.credits, ''),
+			e.to_course_id, e.to_subject_id,
+			COALESCE(ts.thai_subject, ''), COALESCE(ts.eng_subject, ''), COALESCE(ts.credits, ''),
+			e.note
+		FROM subject_equivalences e
+		LEFT JOIN LATERAL (
+			SELECT thai_subject, eng_subject, credits FROM subjects
+			WHERE course_id = e.from_course_id AND subject_id = e.from_subject_id
+			ORDER BY id LIMIT 1
+		) fs ON true
+		LEFT JOIN LATERAL (
+			SELECT thai_subject, eng_subject, credits FROM subjects
+			WHERE course_id = e.to_course_id AND subject_id = e.to_subject_id
+			ORDER BY id LIMIT 1
+		) ts ON true
+		WHERE (e.from_course_id = $1 OR e.to_course_id = $1)
+	`
+	args := []interface{}{courseID}
+	if publicOnly {
+		query += `
+			AND EXISTS (SELECT 1 FROM courses fc WHERE fc.course_id = e.from_course_id AND fc.status = ANY($2))
+			AND EXISTS (SELECT 1 FROM courses tc WHERE tc.course_id = e.to_course_id AND tc.status = ANY($2))
+		`
+		args = append(args, pq.Array(models.PublicStatuses))
+	}
+	query += " ORDER BY e.from_course_id, e.to_course_id, e.from_subject_id, e.to_subject_id"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	equivalences := []models.SubjectEquivalence{}
+	for rows.Next() {
+		var e models.SubjectEquivalence
+		if err := rows.Scan(
+			&e.EquivalenceID, &e.FromCourseID, &e.From.SubjectID,
+			&e.From.ThaiSubject, &e.From.EngSubject, &e.From.Credits,
+			&e.ToCourseID, &e.To.SubjectID,
+			&e.To.ThaiSubject, &e.To.EngSubject, &e.To.Credits,
+			&e.Note,
+		); err != nil {
+			return nil, err
+		}
+		equivalences = append(equivalences, e)
+	}
+	return equivalences, rows.Err()
+}
+
+// ReplaceEquivalences แทนที่ตารางเทียบจาก fromCourseID ไปยัง toCourseID ทั้งหมดใน transaction เดียว
+func (r *courseRepository) ReplaceEquivalences(fromCourseID string, toCourseID string, items []models.EquivalenceItem) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		DELETE FROM subject_equivalences
+		WHERE from_course_id = $1 AND to_course_id = $2
+	`, fromCourseID, toCourseID)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		_, err := tx.Exec(`
+			INSERT INTO subject_equivalences (from_course_id, from_subject_id, to_course_id, to_subject_id, note)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (from_course_id, from_subject_id, to_course_id, to_subject_id) DO UPDATE SET note = EXCLUDED.note
+		`, fromCourseID, item.FromSubjectID, toCourseID, item.ToSubjectID, item.Note)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// CourseExists คืน sql.ErrNoRows เมื่อไม่มีหลักสูตรนี้
+func (r *courseRepository) CourseExists(courseID string) error {
+	var exists int
+	return r.db.QueryRow("SELECT 1 FROM courses WHERE course_id = $1", courseID).Scan(&exists)
+}
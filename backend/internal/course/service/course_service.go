@@ -3,16 +3,31 @@ package service
 import (
 	"cpsu/internal/course/models"
 	"cpsu/internal/course/repository"
+	"cpsu/internal/pagination"
 
 	authrepo "cpsu/internal/auth/repository"
 )
 
 type CourseService interface {
-	GetAllCourses(param models.CoursesQueryParam) ([]models.Courses, error)
+	GetAllCourses(param models.CoursesQueryParam) (*pagination.Page[models.Courses], error)
 	GetCourseByID(id string) (*models.Courses, error)
 	CreateCourse(course models.CoursesRequest, userID int, ip string, userAgent string) (*models.Courses, error)
 	UpdateCourse(id string, course models.CoursesRequest, userID int, ip string, userAgent string) (*models.Courses, error)
 	DeleteCourse(id string, userID int, ip string, userAgent string) error
+
+	GetLineage(courseID string) (*models.CourseLineage, error)
+	GetPublicLineage(courseID string) (*models.CourseLineage, error)
+	SetLineage(courseID string, req models.LineageRequest, userID int, ip string, userAgent string) (*models.CourseLineage, error)
+	GetEquivalences(courseID string) ([]models.SubjectEquivalence, error)
+	GetPublicEquivalences(courseID string) ([]models.SubjectEquivalence, error)
+	SetEquivalences(courseID string, fromCourseID string, req models.EquivalenceRequest, userID int, ip string, userAgent string) ([]models.SubjectEquivalence, error)
+	CompareCourses(courseID string, fromCourseID string) (*models.CourseComparison, error)
+	ComparePublicCourses(courseID string, fromCourseID string) (*models.CourseComparison, error)
+
+	GetCourseStatus(courseID string) (string, error)
+	SetCourseStatus(courseID string, req models.CourseStatusRequest, userID int, ip string, userAgent string) (*models.Courses, error)
+	ClearCourseStatusSchedule(courseID string, userID int, ip string, userAgent string) (*models.Courses, error)
+	ApplyScheduledStatuses() (int64, error)
 }
 
 type courseService struct {
@@ -30,7 +45,7 @@ func NewCourseService(
 	}
 }
 
-func (s *courseService) GetAllCourses(param models.CoursesQueryParam) ([]models.Courses, error) {
+func (s *courseService) GetAllCourses(param models.CoursesQueryParam) (*pagination.Page[models.Courses], error) {
 	return s.repo.GetAllCourses(param)
 }
 
@@ -39,6 +54,12 @@ func (s *courseService) GetCourseByID(id string) (*models.Courses, error) {
 }
 
 func (s *courseService) CreateCourse(course models.CoursesRequest, userID int, ip string, userAgent string) (*models.Courses, error) {
+	if course.Status == "" {
+		course.Status = models.StatusDraft
+	}
+	if !models.IsValidStatus(course.Status) {
+		return nil, ErrInvalidStatus
+	}
 
 	created, err := s.repo.CreateCourse(course)
 	if err != nil {
@@ -57,6 +78,10 @@ func (s *courseService) CreateCourse(course models.CoursesRequest, userID int, i
 }
 
 func (s *courseService) UpdateCourse(id string, course models.CoursesRequest, userID int, ip string, userAgent string) (*models.Courses, error) {
+	// status ว่างคือคงสถานะเดิม
+	if course.Status != "" && !models.IsValidStatus(course.Status) {
+		return nil, ErrInvalidStatus
+	}
 
 	updated, err := s.repo.UpdateCourse(id, course)
 	if err != nil {
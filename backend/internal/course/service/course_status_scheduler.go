@@ -0,0 +1,30 @@
+package service
+
+import (
+	"log"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ApplyScheduledCourseStatus เปลี่ยนสถานะหลักสูตรที่ถึงวันที่ตั้งไว้ ตรวจตอนเริ่มและทุกต้นชั่วโมง
+func ApplyScheduledCourseStatus(courseService CourseService) {
+	apply := func() {
+		changed, err := courseService.ApplyScheduledStatuses()
+		if err != nil {
+			log.Println("[CRON] Apply scheduled course status failed:", err)
+			return
+		}
+
+		if changed > 0 {
+			log.Printf("[CRON] Changed status of %d courses\n", changed)
+		}
+	}
+	apply()
+
+	c := cron.New(cron.WithSeconds())
+	if _, err := c.AddFunc("0 0 * * * *", apply); err != nil {
+		log.Fatal("cannot start course status cron:", err)
+	}
+
+	c.Start()
+}
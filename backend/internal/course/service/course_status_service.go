@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"cpsu/internal/course/models"
+)
+
+var (
+	ErrInvalidStatus     = errors.New("invalid course status, expected draft, active, closed or retired")
+	ErrInvalidStatusDate = errors.New("effective_from must be a date in YYYY-MM-DD format")
+)
+
+// bangkok ใช้ตัดสินว่า effective_from เป็นวันในอนาคตหรือไม่ ประเทศไทยไม่มี daylight saving จึงใช้ offset คงที่ได้
+var bangkok = time.FixedZone("Asia/Bangkok", 7*60*60)
+
+func (s *courseService) GetCourseStatus(courseID string) (string, error) {
+	return s.repo.GetCourseStatus(courseID)
+}
+
+// SetCourseStatus เปลี่ยนสถานะทันที หรือตั้งเวลาไว้เมื่อ effective_from เป็นวันในอนาคต
+func (s *courseService) SetCourseStatus(courseID string, req models.CourseStatusRequest, userID int, ip string, userAgent string) (*models.Courses, error) {
+	if !models.IsValidStatus(req.Status) {
+		return nil, ErrInvalidStatus
+	}
+
+	details := map[string]interface{}{
+		"status": req.Status,
+	}
+	effectiveFrom, err := parseStatusDate(req.EffectiveFrom)
+	if err != nil {
+		return nil, err
+	}
+	if effectiveFrom != nil && effectiveFrom.After(today()) {
+		if err := s.repo.ScheduleCourseStatus(courseID, req.Status, *effectiveFrom); err != nil {
+			return nil, err
+		}
+		details["effective_from"] = effectiveFrom.Format("2006-01-02")
+	} else if err := s.repo.SetCourseStatus(courseID, req.Status); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(userID, "update_status", "course", courseID, details, ip, userAgent)
+
+	return s.repo.GetCourseByID(courseID)
+}
+
+func (s *courseService) ClearCourseStatusSchedule(courseID string, userID int, ip string, userAgent string) (*models.Courses, error) {
+	if err := s.repo.ClearCourseStatusSchedule(courseID); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(userID, "cancel_status_schedule", "course", courseID, map[string]interface{}{}, ip, userAgent)
+
+	return s.repo.GetCourseByID(courseID)
+}
+
+func (s *courseService) ApplyScheduledStatuses() (int64, error) {
+	return s.repo.ApplyScheduledStatuses()
+}
+
+// parseStatusDate คืนเวลาเที่ยงคืนของวันนั้นตามเวลาไทย คืน nil เมื่อไม่ได้ระบุวันที่หรือส่งค่าว่างมา
+func parseStatusDate(value *string) (*time.Time, error) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil, nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(*value), bangkok)
+	if err != nil {
+		return nil, ErrInvalidStatusDate
+	}
+	return &date, nil
+}
+
+func today() time.Time {
+	now := time.Now().In(bangkok)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, bangkok)
+}
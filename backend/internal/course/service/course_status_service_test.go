@@ -0,0 +1,119 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"cpsu/internal/course/models"
+	"cpsu/internal/course/repository"
+
+	authrepo "cpsu/internal/auth/repository"
+
+	_ "github.com/lib/pq"
+)
+
+// statusRepo จำสถานะปัจจุบันกับสถานะที่ตั้งเวลาไว้ของหลักสูตรเดียว เมธอดอื่นของ CourseRepository ไม่ได้ใช้ในเทสต์นี้
+type statusRepo struct {
+	repository.CourseRepository
+
+	status     string
+	nextStatus string
+	nextAt     *time.Time
+}
+
+func (r *statusRepo) SetCourseStatus(courseID string, status string) error {
+	r.status = status
+	r.nextStatus = ""
+	r.nextAt = nil
+	return nil
+}
+
+func (r *statusRepo) ScheduleCourseStatus(courseID string, status string, effectiveFrom time.Time) error {
+	r.nextStatus = status
+	r.nextAt = &effectiveFrom
+	return nil
+}
+
+func (r *statusRepo) GetCourseByID(id string) (*models.Courses, error) {
+	return &models.Courses{CourseID: id}, nil
+}
+
+// newStatusTestService ใช้ audit repository ที่ต่อฐานข้อมูลไม่ได้ การบันทึก audit ล้มเหลวจะถูกข้ามไปเหมือนตอนใช้งานจริง
+func newStatusTestService(t *testing.T, repo repository.CourseRepository) CourseService {
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewCourseService(repo, authrepo.NewAuditRepository(db))
+}
+
+func TestSetCourseStatus(t *testing.T) {
+	date := func(days int) *string {
+		s := time.Now().In(bangkok).AddDate(0, 0, days).Format("2006-01-02")
+		return &s
+	}
+	blank := " "
+
+	tests := []struct {
+		name          string
+		effectiveFrom *string
+		wantStatus    string
+		wantScheduled bool
+	}{
+		{"no date", nil, models.StatusClosed, false},
+		{"blank date", &blank, models.StatusClosed, false},
+		{"today", date(0), models.StatusClosed, false},
+		{"past date", date(-30), models.StatusClosed, false},
+		{"future date", date(1), models.StatusActive, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pending := time.Now().AddDate(1, 0, 0)
+			repo := &statusRepo{status: models.StatusActive, nextStatus: models.StatusRetired, nextAt: &pending}
+			svc := newStatusTestService(t, repo)
+
+			req := models.CourseStatusRequest{Status: models.StatusClosed, EffectiveFrom: tt.effectiveFrom}
+			if _, err := svc.SetCourseStatus("BSCS65", req, 1, "", ""); err != nil {
+				t.Fatalf("SetCourseStatus error: %v", err)
+			}
+
+			if repo.status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", repo.status, tt.wantStatus)
+			}
+			if !tt.wantScheduled {
+				if repo.nextStatus != "" || repo.nextAt != nil {
+					t.Errorf("pending schedule = %q at %v, want cleared", repo.nextStatus, repo.nextAt)
+				}
+				return
+			}
+			if repo.nextStatus != models.StatusClosed || repo.nextAt == nil {
+				t.Fatalf("pending schedule = %q at %v, want %q", repo.nextStatus, repo.nextAt, models.StatusClosed)
+			}
+			if got := repo.nextAt.Format("2006-01-02"); got != *tt.effectiveFrom {
+				t.Errorf("scheduled date = %s, want %s", got, *tt.effectiveFrom)
+			}
+			if h, m, _ := repo.nextAt.In(bangkok).Clock(); h != 0 || m != 0 {
+				t.Errorf("scheduled time = %v, want Bangkok midnight", repo.nextAt)
+			}
+		})
+	}
+}
+
+func TestSetCourseStatusInvalidDate(t *testing.T) {
+	for _, value := range []string{"2026-11-01T00:00:00Z", "01/11/2026", "2026-13-01"} {
+		repo := &statusRepo{status: models.StatusActive}
+		svc := newStatusTestService(t, repo)
+
+		req := models.CourseStatusRequest{Status: models.StatusClosed, EffectiveFrom: &value}
+		if _, err := svc.SetCourseStatus("BSCS65", req, 1, "", ""); !errors.Is(err, ErrInvalidStatusDate) {
+			t.Errorf("SetCourseStatus(%q) error = %v, want ErrInvalidStatusDate", value, err)
+		}
+		if repo.status != models.StatusActive {
+			t.Errorf("SetCourseStatus(%q) changed status to %q", value, repo.status)
+		}
+	}
+}
@@ -0,0 +1,442 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cpsu/internal/course/models"
+	"cpsu/internal/credits"
+)
+
+var (
+	ErrSameCourse         = errors.New("from and to must be different courses")
+	ErrUnknownSubject     = errors.New("subject not found in course")
+	ErrCompareFromMissing = errors.New("from is required because this course does not replace another course")
+)
+
+// LineageCycleError คือการเชื่อมรุ่นที่ทำให้หลักสูตรแทนที่ตัวเองทางอ้อม
+type LineageCycleError struct {
+	Chain []string
+}
+
+func (e *LineageCycleError) Error() string {
+	return "course lineage cycle: " + strings.Join(e.Chain, " -> ")
+}
+
+// GetLineage คืนสายรุ่นของหลักสูตรตั้งแต่รุ่นแรกจนรุ่นล่าสุด
+func (s *courseService) GetLineage(courseID string) (*models.CourseLineage, error) {
+	if err := s.repo.CourseExists(courseID); err != nil {
+		return nil, err
+	}
+	links, err := s.repo.GetCourseLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	replaces := map[string]models.CourseLink{}
+	replacedBy := map[string]string{}
+	for _, l := range links {
+		replaces[l.CourseID] = l
+		replacedBy[l.ReplacesID] = l.CourseID
+	}
+
+	chain := []string{courseID}
+	seen := map[string]bool{courseID: true}
+	for cur := courseID; ; {
+		link, ok := replaces[cur]
+		if !ok || seen[link.ReplacesID] {
+			break
+		}
+		cur = link.ReplacesID
+		seen[cur] = true
+		chain = append([]string{cur}, chain...)
+	}
+	for cur := courseID; ; {
+		next, ok := replacedBy[cur]
+		if !ok || seen[next] {
+			break
+		}
+		cur = next
+		seen[cur] = true
+		chain = append(chain, cur)
+	}
+
+	versions, err := s.repo.GetCourseVersions(chain)
+	if err != nil {
+		return nil, err
+	}
+	byID := map[string]models.CourseVersion{}
+	for _, v := range versions {
+		byID[v.CourseID] = v
+	}
+
+	lineage := &models.CourseLineage{
+		CourseID: courseID,
+		Versions: []models.CourseVersion{},
+		Links:    []models.CourseLink{},
+	}
+	for i, id := range chain {
+		lineage.Versions = append(lineage.Versions, byID[id])
+		if i > 0 {
+			lineage.Links = append(lineage.Links, replaces[id])
+		}
+	}
+	return lineage, nil
+}
+
+// GetPublicLineage ตัดรุ่นที่ยังไม่เผยแพร่และการเชื่อมที่อ้างถึงรุ่นนั้นออกจากสายรุ่น
+func (s *courseService) GetPublicLineage(courseID string) (*models.CourseLineage, error) {
+	lineage, err := s.GetLineage(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	public := map[string]bool{}
+	versions := []models.CourseVersion{}
+	for _, v := range lineage.Versions {
+		if models.IsPublicStatus(v.Status) {
+			public[v.CourseID] = true
+			versions = append(versions, v)
+		}
+	}
+	links := []models.CourseLink{}
+	for _, l := range lineage.Links {
+		if public[l.CourseID] && public[l.ReplacesID] {
+			links = append(links, l)
+		}
+	}
+
+	lineage.Versions = versions
+	lineage.Links = links
+	return lineage, nil
+}
+
+// SetLineage กำหนดหลักสูตรเดิมที่ courseID ใช้แทน
+func (s *courseService) SetLineage(courseID string, req models.LineageRequest, userID int, ip string, userAgent string) (*models.CourseLineage, error) {
+	if err := s.repo.CourseExists(courseID); err != nil {
+		return nil, err
+	}
+
+	var replacesID *string
+	if req.ReplacesID != nil {
+		id := strings.ToUpper(strings.TrimSpace(*req.ReplacesID))
+		if id == courseID {
+			return nil, ErrSameCourse
+		}
+
+		links, err := s.repo.GetCourseLinks()
+		if err != nil {
+			return nil, err
+		}
+		replaces := map[string]string{}
+		for _, l := range links {
+			replaces[l.CourseID] = l.ReplacesID
+		}
+		// ถ้าไล่จากหลักสูตรเดิมย้อนไปแล้วเจอ courseID แปลว่าจะเกิดวง
+		chain := []string{courseID, id}
+		for cur, ok := replaces[id]; ok; cur, ok = replaces[cur] {
+			chain = append(chain, cur)
+			if cur == courseID {
+				return nil, &LineageCycleError{Chain: chain}
+			}
+			if len(chain) > len(links)+2 {
+				break
+			}
+		}
+		replacesID = &id
+	}
+
+	if err := s.repo.SetCourseLink(courseID, replacesID, strings.TrimSpace(req.Note)); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "update", "course_lineage", courseID,
+		map[string]interface{}{
+			"replaces_id": replacesID,
+		},
+		ip, userAgent,
+	)
+
+	return s.GetLineage(courseID)
+}
+
+func (s *courseService) GetEquivalences(courseID string) ([]models.SubjectEquivalence, error) {
+	if err := s.repo.CourseExists(courseID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetEquivalences(courseID)
+}
+
+func (s *courseService) GetPublicEquivalences(courseID string) ([]models.SubjectEquivalence, error) {
+	if err := s.repo.CourseExists(courseID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPublicEquivalences(courseID)
+}
+
+// SetEquivalences แทนที่ตารางเทียบรายวิชาจาก fromCourseID ไปยัง courseID ทุกรหัสต้องมีในหลักสูตรของตน
+func (s *courseService) SetEquivalences(courseID string, fromCourseID string, req models.EquivalenceRequest, userID int, ip string, userAgent string) ([]models.SubjectEquivalence, error) {
+	fromCourseID = strings.ToUpper(strings.TrimSpace(fromCourseID))
+	if fromCourseID == courseID {
+		return nil, ErrSameCourse
+	}
+
+	toSubjects, err := s.courseSubjects(courseID)
+	if err != nil {
+		return nil, err
+	}
+	fromSubjects, err := s.courseSubjects(fromCourseID)
+	if err != nil {
+		return nil, err
+	}
+
+	var unknown []string
+	items := make([]models.EquivalenceItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		item.FromSubjectID = strings.ToUpper(strings.TrimSpace(item.FromSubjectID))
+		item.ToSubjectID = strings.ToUpper(strings.TrimSpace(item.ToSubjectID))
+		item.Note = strings.TrimSpace(item.Note)
+		if _, ok := fromSubjects[item.FromSubjectID]; !ok {
+			unknown = append(unknown, item.FromSubjectID+" ("+fromCourseID+")")
+		}
+		if _, ok := toSubjects[item.ToSubjectID]; !ok {
+			unknown = append(unknown, item.ToSubjectID+" ("+courseID+")")
+		}
+		items = append(items, item)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSubject, strings.Join(unknown, ", "))
+	}
+
+	if err := s.repo.ReplaceEquivalences(fromCourseID, courseID, items); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "update", "subject_equivalence", courseID,
+		map[string]interface{}{
+			"from_course_id": fromCourseID,
+			"items":          len(items),
+		},
+		ip, userAgent,
+	)
+
+	return s.repo.GetEquivalences(courseID)
+}
+
+// CompareCourses เทียบหลักสูตรรุ่นเดิม fromCourseID กับ courseID
+// ถ้าไม่ระบุ fromCourseID จะใช้หลักสูตรที่ courseID แทนตามสายรุ่น
+func (s *courseService) CompareCourses(courseID string, fromCourseID string) (*models.CourseComparison, error) {
+	return s.compareCourses(courseID, fromCourseID, false)
+}
+
+// ComparePublicCourses คืน sql.ErrNoRows เมื่อหลักสูตรใดฝั่งหนึ่ง รวมถึงรุ่นเดิมตามสายรุ่น ยังไม่เผยแพร่
+// และใช้เฉพาะตารางเทียบที่ทั้งสองฝั่งเผยแพร่แล้ว
+func (s *courseService) ComparePublicCourses(courseID string, fromCourseID string) (*models.CourseComparison, error) {
+	return s.compareCourses(courseID, fromCourseID, true)
+}
+
+func (s *courseService) compareCourses(courseID string, fromCourseID string, publicOnly bool) (*models.CourseComparison, error) {
+	fromCourseID = strings.ToUpper(strings.TrimSpace(fromCourseID))
+	if fromCourseID == "" {
+		links, err := s.repo.GetCourseLinks()
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range links {
+			if l.CourseID == courseID {
+				fromCourseID = l.ReplacesID
+			}
+		}
+		if fromCourseID == "" {
+			if err := s.repo.CourseExists(courseID); err != nil {
+				return nil, err
+			}
+			return nil, ErrCompareFromMissing
+		}
+	}
+	if fromCourseID == courseID {
+		return nil, ErrSameCourse
+	}
+
+	versions, err := s.repo.GetCourseVersions([]string{fromCourseID, courseID})
+	if err != nil {
+		return nil, err
+	}
+	comparison := &models.CourseComparison{
+		Categories: []models.CategoryChange{},
+		Added:      []models.SubjectVersion{},
+		Removed:    []models.SubjectVersion{},
+		Changed:    []models.SubjectChange{},
+	}
+	found := 0
+	for _, v := range versions {
+		if v.CourseID == fromCourseID {
+			comparison.From = v
+			found++
+		} else if v.CourseID == courseID {
+			comparison.To = v
+			found++
+		}
+	}
+	if found < 2 {
+		return nil, sql.ErrNoRows
+	}
+	if publicOnly && (!models.IsPublicStatus(comparison.From.Status) || !models.IsPublicStatus(comparison.To.Status)) {
+		return nil, sql.ErrNoRows
+	}
+	comparison.FromCredits = credits.ParseTotal(comparison.From.Credits)
+	comparison.ToCredits = credits.ParseTotal(comparison.To.Credits)
+
+	if err := s.compareCategories(comparison, fromCourseID, courseID); err != nil {
+		return nil, err
+	}
+
+	oldSubjects, err := s.repo.GetVersionSubjects(fromCourseID)
+	if err != nil {
+		return nil, err
+	}
+	newSubjects, err := s.repo.GetVersionSubjects(courseID)
+	if err != nil {
+		return nil, err
+	}
+	getEquivalences := s.repo.GetEquivalences
+	if publicOnly {
+		getEquivalences = s.repo.GetPublicEquivalences
+	}
+	equivalences, err := getEquivalences(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	oldByCode := map[string]models.SubjectVersion{}
+	for _, subject := range oldSubjects {
+		oldByCode[subject.SubjectID] = subject
+	}
+	newByCode := map[string]models.SubjectVersion{}
+	for _, subject := range newSubjects {
+		newByCode[subject.SubjectID] = subject
+	}
+	matchedOld := map[string]bool{}
+	matchedNew := map[string]bool{}
+
+	addChange := func(from, to models.SubjectVersion, via string) {
+		matchedOld[from.SubjectID] = true
+		matchedNew[to.SubjectID] = true
+		fields := subjectChanges(from, to)
+		if len(fields) == 0 {
+			comparison.Unchanged++
+			return
+		}
+		comparison.Changed = append(comparison.Changed, models.SubjectChange{From: from, To: to, Via: via, Fields: fields})
+	}
+
+	for _, subject := range oldSubjects {
+		if to, ok := newByCode[subject.SubjectID]; ok {
+			addChange(subject, to, "code")
+		}
+	}
+	// ตารางเทียบอาจบันทึกไว้ทั้งสองทิศ จึงกลับทิศให้ from เป็นรุ่นเดิมเสมอ
+	for _, e := range equivalences {
+		fromCode, toCode := e.From.SubjectID, e.To.SubjectID
+		switch {
+		case e.FromCourseID == fromCourseID && e.ToCourseID == courseID:
+		case e.FromCourseID == courseID && e.ToCourseID == fromCourseID:
+			fromCode, toCode = toCode, fromCode
+		default:
+			continue
+		}
+		from, okFrom := oldByCode[fromCode]
+		to, okTo := newByCode[toCode]
+		if !okFrom || !okTo || fromCode == toCode {
+			continue
+		}
+		addChange(from, to, "equivalence")
+	}
+
+	for _, subject := range oldSubjects {
+		if !matchedOld[subject.SubjectID] {
+			comparison.Removed = append(comparison.Removed, subject)
+		}
+	}
+	for _, subject := range newSubjects {
+		if !matchedNew[subject.SubjectID] {
+			comparison.Added = append(comparison.Added, subject)
+		}
+	}
+	return comparison, nil
+}
+
+func (s *courseService) compareCategories(comparison *models.CourseComparison, fromCourseID string, toCourseID string) error {
+	oldCategories, err := s.repo.GetCategoryMinimums(fromCourseID)
+	if err != nil {
+		return err
+	}
+	newCategories, err := s.repo.GetCategoryMinimums(toCourseID)
+	if err != nil {
+		return err
+	}
+
+	index := map[string]int{}
+	for _, c := range newCategories {
+		index[c.Category] = len(comparison.Categories)
+		comparison.Categories = append(comparison.Categories, models.CategoryChange{
+			Category:     c.Category,
+			NameTH:       c.NameTH,
+			ToMinCredits: c.MinCredits,
+		})
+	}
+	for _, c := range oldCategories {
+		i, ok := index[c.Category]
+		if !ok {
+			i = len(comparison.Categories)
+			index[c.Category] = i
+			comparison.Categories = append(comparison.Categories, models.CategoryChange{Category: c.Category, NameTH: c.NameTH})
+		}
+		comparison.Categories[i].FromMinCredits = c.MinCredits
+	}
+	return nil
+}
+
+func (s *courseService) courseSubjects(courseID string) (map[string]bool, error) {
+	if err := s.repo.CourseExists(courseID); err != nil {
+		return nil, err
+	}
+	subjects, err := s.repo.GetVersionSubjects(courseID)
+	if err != nil {
+		return nil, err
+	}
+	codes := map[string]bool{}
+	for _, subject := range subjects {
+		codes[strings.ToUpper(subject.SubjectID)] = true
+	}
+	return codes, nil
+}
+
+// subjectChanges คืนชื่อฟิลด์ที่ต่างกัน หน่วยกิตเทียบในรูปแบบมาตรฐาน
+func subjectChanges(from, to models.SubjectVersion) []string {
+	var fields []string
+	if from.SubjectID != to.SubjectID {
+		fields = append(fields, "subject_id")
+	}
+	if strings.TrimSpace(from.ThaiSubject) != strings.TrimSpace(to.ThaiSubject) {
+		fields = append(fields, "thai_subject")
+	}
+	if !strings.EqualFold(strings.TrimSpace(from.EngSubject), strings.TrimSpace(to.EngSubject)) {
+		fields = append(fields, "eng_subject")
+	}
+	if normalizeCredits(from.Credits) != normalizeCredits(to.Credits) {
+		fields = append(fields, "credits")
+	}
+	return fields
+}
+
+func normalizeCredits(text string) string {
+	if parsed, err := credits.Parse(text); err == nil {
+		return parsed.String()
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
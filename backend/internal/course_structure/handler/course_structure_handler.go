@@ -8,6 +8,7 @@ import (
 
 	"cpsu/internal/course_structure/models"
 	"cpsu/internal/course_structure/service"
+	"cpsu/internal/pagination"
 
 	"github.com/gin-gonic/gin"
 )
@@ -27,8 +28,27 @@ func (h *CourseStructureHandler) GetAllCourseStructure(c *gin.Context) {
 		return
 	}
 
+	h.getAllCourseStructure(c, param)
+}
+
+func (h *CourseStructureHandler) GetPublicCourseStructure(c *gin.Context) {
+	var param models.CourseStructureQueryParam
+	if err := c.ShouldBindQuery(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	param.PublicOnly = true
+	h.getAllCourseStructure(c, param)
+}
+
+func (h *CourseStructureHandler) getAllCourseStructure(c *gin.Context, param models.CourseStructureQueryParam) {
 	courseStructures, err := h.courseStructureService.GetAllCourseStructure(param)
 	if err != nil {
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -37,6 +57,14 @@ func (h *CourseStructureHandler) GetAllCourseStructure(c *gin.Context) {
 }
 
 func (h *CourseStructureHandler) GetCourseStructureByID(c *gin.Context) {
+	h.getCourseStructureByID(c, h.courseStructureService.GetCourseStructureByID)
+}
+
+func (h *CourseStructureHandler) GetPublicCourseStructureByID(c *gin.Context) {
+	h.getCourseStructureByID(c, h.courseStructureService.GetPublicCourseStructureByID)
+}
+
+func (h *CourseStructureHandler) getCourseStructureByID(c *gin.Context, get func(id int) (*models.CourseStructure, error)) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
@@ -44,7 +72,7 @@ func (h *CourseStructureHandler) GetCourseStructureByID(c *gin.Context) {
 		return
 	}
 
-	courseStructures, err := h.courseStructureService.GetCourseStructureByID(id)
+	courseStructures, err := get(id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "course_structure not found"})
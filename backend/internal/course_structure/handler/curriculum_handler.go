@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"cpsu/internal/course_structure/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *CourseStructureHandler) GetCurriculum(c *gin.Context) {
+	curriculum, err := h.courseStructureService.GetCurriculum(c.Param("id"))
+	if err != nil {
+		curriculumErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, curriculum)
+}
+
+// ImportCurriculum รับไฟล์ XLSX ในฟิลด์ file ถ้า ?dry_run=true จะตรวจอย่างเดียวไม่บันทึก
+// ไฟล์ที่มีข้อผิดพลาดรายแถวจะได้ 422 พร้อมผลการตรวจ
+func (h *CourseStructureHandler) ImportCurriculum(c *gin.Context) {
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+			return
+		}
+		dryRun = parsed
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	openedFile, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer openedFile.Close()
+
+	result, err := h.courseStructureService.ImportCurriculum(
+		c.Param("id"),
+		openedFile,
+		dryRun,
+		c.GetInt("user_id"),
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+	)
+	if err != nil {
+		curriculumErrorResponse(c, err)
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func curriculumErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
+	case errors.Is(err, service.ErrInvalidCurriculumFile):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,5 +1,7 @@
 package models
 
+import "cpsu/internal/pagination"
+
 type CourseStructure struct {
 	CourseStructureID int    `json:"course_structure_id"`
 	CourseID          string `json:"course_id"`
@@ -8,11 +10,14 @@ type CourseStructure struct {
 }
 
 type CourseStructureQueryParam struct {
+	pagination.Params
 	Search   string `form:"search"`
-	Limit    int    `form:"limit"`
 	CourseID string `form:"course_id"`
 	Sort     string `form:"sort"`
 	Order    string `form:"order"`
+
+	// PublicOnly ตั้งโดย handler ของหน้าเว็บสาธารณะ
+	PublicOnly bool `form:"-"`
 }
 
 type CourseStructureRequest struct {
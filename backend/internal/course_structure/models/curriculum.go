@@ -0,0 +1,77 @@
+package models
+
+// หมวดวิชาระดับบนสุดของโครงสร้างหลักสูตร
+const (
+	CategoryGeneralEducation = "general_education"
+	CategoryCore             = "core"
+	CategoryMajorRequired    = "major_required"
+	CategoryMajorElective    = "major_elective"
+	CategoryFreeElective     = "free_elective"
+)
+
+// Categories เรียงตามลำดับที่แสดงในเล่มหลักสูตร
+var Categories = []string{
+	CategoryGeneralEducation,
+	CategoryCore,
+	CategoryMajorRequired,
+	CategoryMajorElective,
+	CategoryFreeElective,
+}
+
+// CategoryNames คือชื่อหมวดภาษาไทยและอังกฤษ ใช้เป็นชื่อเริ่มต้นและรับเป็นค่าในไฟล์ที่นำเข้าได้
+var CategoryNames = map[string][2]string{
+	CategoryGeneralEducation: {"หมวดวิชาศึกษาทั่วไป", "General Education"},
+	CategoryCore:             {"หมวดวิชาแกน", "Core Courses"},
+	CategoryMajorRequired:    {"หมวดวิชาเฉพาะด้านบังคับ", "Major Required Courses"},
+	CategoryMajorElective:    {"หมวดวิชาเฉพาะด้านเลือก", "Major Elective Courses"},
+	CategoryFreeElective:     {"หมวดวิชาเลือกเสรี", "Free Electives"},
+}
+
+// IsRequiredCategory คือหมวดที่ต้องเรียนทุกวิชา หน่วยกิตของวิชาจึงควรไม่น้อยกว่าหน่วยกิตขั้นต่ำ
+func IsRequiredCategory(category string) bool {
+	return category == CategoryCore || category == CategoryMajorRequired
+}
+
+// Curriculum คือโครงสร้างหลักสูตรแบบต้นไม้ หลักสูตร -> หมวดวิชา -> กลุ่มวิชา -> รายวิชา
+type Curriculum struct {
+	CourseID     string           `json:"course_id"`
+	ThaiCourse   string           `json:"thai_course"`
+	TotalCredits int              `json:"total_credits"`
+	Categories   []CurriculumNode `json:"categories"`
+}
+
+type CurriculumNode struct {
+	NodeID     int    `json:"node_id"`
+	Category   string `json:"category"`
+	NameTH     string `json:"name_th"`
+	NameEN     string `json:"name_en"`
+	MinCredits int    `json:"min_credits"`
+	// SubjectCredits คือผลรวมหน่วยกิตของรายวิชาในกลุ่มนี้และกลุ่มย่อย
+	SubjectCredits int                 `json:"subject_credits"`
+	Subjects       []CurriculumSubject `json:"subjects"`
+	Children       []CurriculumNode    `json:"children"`
+}
+
+type CurriculumSubject struct {
+	SubjectID   string `json:"subject_id"`
+	ThaiSubject string `json:"thai_subject"`
+	EngSubject  string `json:"eng_subject"`
+	Credits     string `json:"credits"`
+}
+
+// CurriculumRowError คือข้อผิดพลาดของแถวในไฟล์ row นับแบบ Excel (เริ่มที่ 1)
+type CurriculumRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// CurriculumImportResult ถ้ามี errors จะไม่บันทึกอะไรเลย
+// warnings คือข้อสังเกตของทั้งโครงสร้าง เช่น หน่วยกิตของกลุ่มย่อยเกินหน่วยกิตของหมวด
+type CurriculumImportResult struct {
+	DryRun     bool                 `json:"dry_run"`
+	Errors     []CurriculumRowError `json:"errors"`
+	Warnings   []string             `json:"warnings"`
+	Curriculum *Curriculum          `json:"curriculum,omitempty"`
+	Detail     string               `json:"detail,omitempty"`
+}
@@ -3,17 +3,26 @@ package repository
 import (
 	"database/sql"
 	"strconv"
-	"strings"
 
+	coursemodels "cpsu/internal/course/models"
 	"cpsu/internal/course_structure/models"
+	"cpsu/internal/pagination"
+	"cpsu/internal/textsearch"
+
+	"github.com/lib/pq"
 )
 
 type CourseStructureRepository interface {
-	GetAllCourseStructure(param models.CourseStructureQueryParam) ([]models.CourseStructure, error)
+	GetAllCourseStructure(param models.CourseStructureQueryParam) (*pagination.Page[models.CourseStructure], error)
 	GetCourseStructureByID(id int) (*models.CourseStructure, error)
+	GetPublicCourseStructureByID(id int) (*models.CourseStructure, error)
 	CreateCourseStructure(req *models.CourseStructureRequest) (*models.CourseStructure, error)
 	UpdateCourseStructure(id int, req models.CourseStructureRequest) (*models.CourseStructure, error)
 	DeleteCourseStructure(id int) error
+
+	GetCurriculum(courseID string) (*models.Curriculum, error)
+	GetCourseSubjects(courseID string) (map[string]models.CurriculumSubject, error)
+	ReplaceCurriculum(curriculum *models.Curriculum, detail string) error
 }
 
 type courseStructureRepository struct {
@@ -24,14 +33,17 @@ func NewCourseStructureRepository(db *sql.DB) CourseStructureRepository {
 	return &courseStructureRepository{db: db}
 }
 
-func (r *courseStructureRepository) GetAllCourseStructure(param models.CourseStructureQueryParam) ([]models.CourseStructure, error) {
-	query := `
-		SELECT 
-			cs.course_structure_id, c.course_id, c.thai_course, cs.detail
-		FROM course_structure cs
-		LEFT JOIN courses c ON cs.course_id = c.course_id
-	`
+var courseStructureSort = pagination.Sorter{
+	Columns: map[string]string{
+		"course_structure_id": "cs.course_structure_id",
+		"course_id":           "cs.course_id",
+		"detail":              "cs.detail",
+	},
+	Default: "course_structure_id",
+	Key:     "cs.course_structure_id",
+}
 
+func (r *courseStructureRepository) GetAllCourseStructure(param models.CourseStructureQueryParam) (*pagination.Page[models.CourseStructure], error) {
 	conditions := []string{}
 	args := []interface{}{}
 	argIndex := 1
@@ -42,54 +54,54 @@ func (r *courseStructureRepository) GetAllCourseStructure(param models.CourseStr
 		argIndex++
 	}
 
-	if param.Search != "" {
-		conditions = append(conditions, "cs.detail ILIKE $"+strconv.Itoa(argIndex))
-		args = append(args, "%"+param.Search+"%")
+	if param.PublicOnly {
+		conditions = append(conditions, "c.status = ANY($"+strconv.Itoa(argIndex)+")")
+		args = append(args, pq.Array(coursemodels.PublicStatuses))
 		argIndex++
 	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
-	}
-
-	sort := "cs.course_structure_id"
-	switch param.Sort {
-	case "course_id", "detail":
-		sort = "cs." + param.Sort
+	if terms := textsearch.Terms(param.Search); len(terms) > 0 {
+		condition, searchArgs, next := textsearch.Condition([]string{"cs.detail"}, terms, argIndex)
+		conditions = append(conditions, condition)
+		args = append(args, searchArgs...)
+		argIndex = next
 	}
 
-	order := "ASC"
-	if strings.ToUpper(param.Order) == "DESC" {
-		order = "DESC"
-	}
-
-	query += " ORDER BY " + sort + " " + order
-
-	if param.Limit > 0 {
-		query += " LIMIT " + strconv.Itoa(param.Limit)
-	}
-
-	rows, err := r.db.Query(query, args...)
+	orderBy, err := courseStructureSort.Parse(param.Sort, param.Order)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var result []models.CourseStructure
-	for rows.Next() {
-		var cs models.CourseStructure
-		if err := rows.Scan(
-			&cs.CourseStructureID, &cs.CourseID, &cs.ThaiCourse, &cs.Detail,
-		); err != nil {
-			return nil, err
-		}
-		result = append(result, cs)
+	stmt := pagination.Statement{
+		Columns: "cs.course_structure_id, c.course_id, c.thai_course, cs.detail",
+		From: `
+			FROM course_structure cs
+			LEFT JOIN courses c ON cs.course_id = c.course_id
+		`,
+		Conditions: conditions,
+		Args:       args,
+		OrderBy:    orderBy,
 	}
 
-	return result, nil
+	return pagination.Fetch(r.db, stmt, param.Params, func(row pagination.Scanner) (models.CourseStructure, error) {
+		var cs models.CourseStructure
+		err := row.Scan(
+			&cs.CourseStructureID, &cs.CourseID, &cs.ThaiCourse, &cs.Detail,
+		)
+		return cs, err
+	})
 }
 
 func (r *courseStructureRepository) GetCourseStructureByID(id int) (*models.CourseStructure, error) {
+	return r.getCourseStructureByID(id, false)
+}
+
+// GetPublicCourseStructureByID คืน sql.ErrNoRows เมื่อหลักสูตรยังไม่เผยแพร่
+func (r *courseStructureRepository) GetPublicCourseStructureByID(id int) (*models.CourseStructure, error) {
+	return r.getCourseStructureByID(id, true)
+}
+
+func (r *courseStructureRepository) getCourseStructureByID(id int, publicOnly bool) (*models.CourseStructure, error) {
 
 	query := `
 		SELECT 
@@ -99,9 +111,14 @@ func (r *courseStructureRepository) GetCourseStructureByID(id int) (*models.Cour
 		LEFT JOIN courses c ON cs.course_id = c.course_id
 		WHERE cs.course_structure_id = $1
 	`
+	args := []interface{}{id}
+	if publicOnly {
+		query += " AND c.status = ANY($2)"
+		args = append(args, pq.Array(coursemodels.PublicStatuses))
+	}
 
 	var cs models.CourseStructure
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRow(query, args...).Scan(
 		&cs.CourseStructureID, &cs.CourseID, &cs.ThaiCourse, &cs.Detail,
 	)
 
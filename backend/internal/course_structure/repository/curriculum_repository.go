@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"cpsu/internal/course_structure/models"
+)
+
+// GetCurriculum คืน sql.ErrNoRows เมื่อไม่มีหลักสูตรนี้ ถ้ายังไม่ได้นำเข้าโครงสร้างจะได้ categories ว่าง
+func (r *courseStructureRepository) GetCurriculum(courseID string) (*models.Curriculum, error) {
+	curriculum := &models.Curriculum{CourseID: courseID, Categories: []models.CurriculumNode{}}
+	err := r.db.QueryRow("SELECT thai_course FROM courses WHERE course_id = $1", courseID).Scan(&curriculum.ThaiCourse)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT node_id, parent_id, category, name_th, name_en, min_credits
+		FROM curriculum_nodes
+		WHERE course_id = $1
+		ORDER BY sort_order, node_id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type flatNode struct {
+		node     models.CurriculumNode
+		parentID sql.NullInt64
+	}
+	var flat []*flatNode
+	byID := map[int]*flatNode{}
+	for rows.Next() {
+		f := &flatNode{node: models.CurriculumNode{
+			Subjects: []models.CurriculumSubject{},
+			Children: []models.CurriculumNode{},
+		}}
+		if err := rows.Scan(&f.node.NodeID, &f.parentID, &f.node.Category, &f.node.NameTH, &f.node.NameEN, &f.node.MinCredits); err != nil {
+			return nil, err
+		}
+		flat = append(flat, f)
+		byID[f.node.NodeID] = f
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	subjectRows, err := r.db.Query(`
+		SELECT ns.node_id, ns.subject_id,
+			COALESCE(s.thai_subject, ''), COALESCE(s.eng_subject, ''), COALESCE(s.credits, '')
+		FROM curriculum_node_subjects ns
+		JOIN curriculum_nodes n ON ns.node_id = n.node_id
+		LEFT JOIN LATERAL (
+			SELECT thai_subject, eng_subject, credits
+			FROM subjects
+			WHERE course_id = n.course_id AND subject_id = ns.subject_id
+			ORDER BY id
+			LIMIT 1
+		) s ON true
+		WHERE n.course_id = $1
+		ORDER BY ns.node_id, ns.sort_order
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer subjectRows.Close()
+
+	for subjectRows.Next() {
+		var (
+			nodeID  int
+			subject models.CurriculumSubject
+		)
+		if err := subjectRows.Scan(&nodeID, &subject.SubjectID, &subject.ThaiSubject, &subject.EngSubject, &subject.Credits); err != nil {
+			return nil, err
+		}
+		if f, ok := byID[nodeID]; ok {
+			f.node.Subjects = append(f.node.Subjects, subject)
+		}
+	}
+	if err := subjectRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// ประกอบต้นไม้จากล่างขึ้นบน ลูกต้องต่อเข้ากับพ่อก่อนที่พ่อจะถูกคัดลอกไปไว้ในต้นไม้
+	var attach func(f *flatNode) models.CurriculumNode
+	children := map[int][]*flatNode{}
+	for _, f := range flat {
+		if f.parentID.Valid {
+			children[int(f.parentID.Int64)] = append(children[int(f.parentID.Int64)], f)
+		}
+	}
+	attach = func(f *flatNode) models.CurriculumNode {
+		node := f.node
+		for _, child := range children[node.NodeID] {
+			node.Children = append(node.Children, attach(child))
+		}
+		return node
+	}
+	for _, f := range flat {
+		if !f.parentID.Valid {
+			curriculum.Categories = append(curriculum.Categories, attach(f))
+		}
+	}
+
+	return curriculum, nil
+}
+
+// GetCourseSubjects คืนรายวิชาของหลักสูตรโดยใช้รหัสวิชาเป็น key รหัสเดียวกันหลายแผนการเรียนจะใช้แถวแรก
+func (r *courseStructureRepository) GetCourseSubjects(courseID string) (map[string]models.CurriculumSubject, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT ON (subject_id) subject_id, thai_subject, COALESCE(eng_subject, ''), credits
+		FROM subjects
+		WHERE course_id = $1
+		ORDER BY subject_id, id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subjects := map[string]models.CurriculumSubject{}
+	for rows.Next() {
+		var s models.CurriculumSubject
+		if err := rows.Scan(&s.SubjectID, &s.ThaiSubject, &s.EngSubject, &s.Credits); err != nil {
+			return nil, err
+		}
+		subjects[strings.ToUpper(s.SubjectID)] = s
+	}
+	return subjects, rows.Err()
+}
+
+// ReplaceCurriculum แทนที่โครงสร้างเดิมทั้งหมดของหลักสูตร และตั้ง detail ของ course_structure
+// ให้ตรงกับโครงสร้างใหม่ (สร้างแถวใหม่ถ้ายังไม่มี) ภายใน transaction เดียว
+func (r *courseStructureRepository) ReplaceCurriculum(curriculum *models.Curriculum, detail string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM curriculum_nodes WHERE course_id = $1", curriculum.CourseID); err != nil {
+		return err
+	}
+
+	var insert func(node *models.CurriculumNode, parentID *int, order int) error
+	insert = func(node *models.CurriculumNode, parentID *int, order int) error {
+		err := tx.QueryRow(`
+			INSERT INTO curriculum_nodes (course_id, parent_id, category, name_th, name_en, min_credits, sort_order)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING node_id
+		`, curriculum.CourseID, parentID, node.Category, node.NameTH, node.NameEN, node.MinCredits, order).Scan(&node.NodeID)
+		if err != nil {
+			return err
+		}
+
+		for i, subject := range node.Subjects {
+			_, err := tx.Exec(`
+				INSERT INTO curriculum_node_subjects (node_id, subject_id, sort_order)
+				VALUES ($1, $2, $3)
+			`, node.NodeID, subject.SubjectID, i+1)
+			if err != nil {
+				return err
+			}
+		}
+
+		for i := range node.Children {
+			if err := insert(&node.Children[i], &node.NodeID, i+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i := range curriculum.Categories {
+		if err := insert(&curriculum.Categories[i], nil, i+1); err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.Exec("UPDATE course_structure SET detail = $1 WHERE course_id = $2", detail, curriculum.CourseID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		if _, err := tx.Exec("INSERT INTO course_structure (course_id, detail) VALUES ($1, $2)", curriculum.CourseID, detail); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
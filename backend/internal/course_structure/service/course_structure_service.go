@@ -7,32 +7,42 @@ import (
 
 	"cpsu/internal/course_structure/models"
 	"cpsu/internal/course_structure/repository"
+	"cpsu/internal/pagination"
+
+	authrepo "cpsu/internal/auth/repository"
 
 	"github.com/xuri/excelize/v2"
 )
 
 type CourseStructureService interface {
-	GetAllCourseStructure(param models.CourseStructureQueryParam) ([]models.CourseStructure, error)
+	GetAllCourseStructure(param models.CourseStructureQueryParam) (*pagination.Page[models.CourseStructure], error)
 	GetCourseStructureByID(id int) (*models.CourseStructure, error)
+	GetPublicCourseStructureByID(id int) (*models.CourseStructure, error)
 	CreateCourseStructure(req models.CourseStructureRequest) (*models.CourseStructure, error)
 	UpdateCourseStructure(id int, req models.CourseStructureRequest) (*models.CourseStructure, error)
 	UploadExcel(file io.Reader) (string, error)
 	DeleteCourseStructure(id int) error
+
+	GetCurriculum(courseID string) (*models.Curriculum, error)
+	ImportCurriculum(courseID string, file io.Reader, dryRun bool, userID int, ip string, userAgent string) (*models.CurriculumImportResult, error)
 }
 
 type courseStructureService struct {
-	repo repository.CourseStructureRepository
+	repo      repository.CourseStructureRepository
+	auditRepo *authrepo.AuditRepository
 }
 
 func NewCourseStructureService(
 	repo repository.CourseStructureRepository,
+	auditRepo *authrepo.AuditRepository,
 ) CourseStructureService {
 	return &courseStructureService{
-		repo: repo,
+		repo:      repo,
+		auditRepo: auditRepo,
 	}
 }
 
-func (s *courseStructureService) GetAllCourseStructure(param models.CourseStructureQueryParam) ([]models.CourseStructure, error) {
+func (s *courseStructureService) GetAllCourseStructure(param models.CourseStructureQueryParam) (*pagination.Page[models.CourseStructure], error) {
 	return s.repo.GetAllCourseStructure(param)
 }
 
@@ -44,6 +54,14 @@ func (s *courseStructureService) GetCourseStructureByID(id int) (*models.CourseS
 	return s.repo.GetCourseStructureByID(id)
 }
 
+func (s *courseStructureService) GetPublicCourseStructureByID(id int) (*models.CourseStructure, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid course_structure_id")
+	}
+
+	return s.repo.GetPublicCourseStructureByID(id)
+}
+
 func (s *courseStructureService) CreateCourseStructure(req models.CourseStructureRequest) (*models.CourseStructure, error) {
 	if strings.TrimSpace(req.CourseID) == "" {
 		return nil, errors.New("course_id is required")
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cpsu/internal/course_structure/models"
+)
+
+const (
+	columnCategory   = "category"
+	columnGroup      = "group"
+	columnGroupEN    = "group_en"
+	columnMinCredits = "min_credits"
+	columnSubjectID  = "subject_id"
+)
+
+// หัวตารางที่ยอมรับ เทียบแบบไม่สนตัวพิมพ์และช่องว่างหัวท้าย
+var curriculumHeaders = map[string]string{
+	"category":    columnCategory,
+	"หมวด":        columnCategory,
+	"หมวดวิชา":    columnCategory,
+	"group":       columnGroup,
+	"subgroup":    columnGroup,
+	"กลุ่ม":       columnGroup,
+	"กลุ่มวิชา":   columnGroup,
+	"group_en":    columnGroupEN,
+	"group (en)":  columnGroupEN,
+	"min_credits": columnMinCredits,
+	"หน่วยกิตขั้นต่ำ": columnMinCredits,
+	"subject_id": columnSubjectID,
+	"subject":    columnSubjectID,
+	"รหัสวิชา":   columnSubjectID,
+}
+
+var requiredCurriculumColumns = []string{columnCategory, columnMinCredits, columnSubjectID}
+
+// importNode ใช้ระหว่างอ่านไฟล์ ก่อนแปลงเป็น models.CurriculumNode
+type importNode struct {
+	node     models.CurriculumNode
+	minRow   int
+	groups   []*importNode
+	byName   map[string]*importNode
+	subjects []models.CurriculumSubject
+}
+
+// parseCurriculumRows สร้างโครงสร้างหลักสูตรจากแถวของ sheet แถวแรกที่ไม่ว่างคือหัวตาราง
+//
+// แถวที่ไม่มีรหัสวิชาคือแถวกำหนดหมวดหรือกลุ่มพร้อมหน่วยกิตขั้นต่ำ
+// แถวที่มีรหัสวิชาคือรายวิชาในหมวดหรือกลุ่มนั้น ช่องหมวดและกลุ่มที่เว้นว่างจะใช้ค่าจากแถวก่อนหน้า
+func parseCurriculumRows(rows [][]string, subjects map[string]models.CurriculumSubject) ([]models.CurriculumNode, []models.CurriculumRowError) {
+	var errs []models.CurriculumRowError
+
+	headerRow := -1
+	for i, row := range rows {
+		if !blankRow(row) {
+			headerRow = i
+			break
+		}
+	}
+	if headerRow < 0 {
+		return nil, []models.CurriculumRowError{{Row: 1, Message: "file has no rows"}}
+	}
+
+	columns := map[string]int{}
+	for i, cell := range rows[headerRow] {
+		if name, ok := curriculumHeaders[strings.ToLower(strings.TrimSpace(cell))]; ok {
+			if _, seen := columns[name]; !seen {
+				columns[name] = i
+			}
+		}
+	}
+	for _, name := range requiredCurriculumColumns {
+		if _, ok := columns[name]; !ok {
+			errs = append(errs, models.CurriculumRowError{Row: headerRow + 1, Column: name, Message: "missing column " + name})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	cell := func(row []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	categories := map[string]*importNode{}
+	listed := map[string]int{}
+	var (
+		current *importNode
+		group   *importNode
+	)
+
+	for i := headerRow + 1; i < len(rows); i++ {
+		row, rowNum := rows[i], i+1
+		if blankRow(row) {
+			continue
+		}
+		rowErr := func(column, message string) {
+			errs = append(errs, models.CurriculumRowError{Row: rowNum, Column: column, Message: message})
+		}
+
+		subjectID := strings.ToUpper(cell(row, columnSubjectID))
+		groupName := cell(row, columnGroup)
+
+		if value := cell(row, columnCategory); value != "" {
+			key, ok := resolveCategory(value)
+			if !ok {
+				rowErr(columnCategory, fmt.Sprintf("unknown category %q", value))
+				continue
+			}
+			if current == nil || current.node.Category != key {
+				group = nil
+			}
+			current = categories[key]
+			if current == nil {
+				names := models.CategoryNames[key]
+				current = &importNode{
+					node:   models.CurriculumNode{Category: key, NameTH: names[0], NameEN: names[1]},
+					byName: map[string]*importNode{},
+				}
+				categories[key] = current
+			}
+		} else if current == nil {
+			rowErr(columnCategory, "category is required before the first group or subject")
+			continue
+		}
+
+		target := current
+		if groupName != "" {
+			group = current.byName[groupName]
+			if group == nil {
+				group = &importNode{node: models.CurriculumNode{
+					Category: current.node.Category,
+					NameTH:   groupName,
+					NameEN:   cell(row, columnGroupEN),
+				}}
+				current.byName[groupName] = group
+				current.groups = append(current.groups, group)
+			}
+			target = group
+		} else if subjectID != "" && group != nil {
+			target = group
+		} else if subjectID == "" {
+			// แถวกำหนดหมวดโดยไม่มีกลุ่ม รายวิชาที่ตามมาจะอยู่ในหมวดโดยตรง
+			group = nil
+		}
+
+		if subjectID == "" {
+			value := cell(row, columnMinCredits)
+			if value == "" {
+				rowErr(columnMinCredits, "min_credits is required on category and group rows")
+				continue
+			}
+			credits, err := strconv.Atoi(value)
+			if err != nil || credits < 0 {
+				rowErr(columnMinCredits, fmt.Sprintf("invalid min_credits %q", value))
+				continue
+			}
+			if target.minRow > 0 && target.node.MinCredits != credits {
+				rowErr(columnMinCredits, fmt.Sprintf("min_credits conflicts with row %d", target.minRow))
+				continue
+			}
+			target.node.MinCredits = credits
+			target.minRow = rowNum
+			continue
+		}
+
+		subject, ok := subjects[subjectID]
+		if !ok {
+			rowErr(columnSubjectID, fmt.Sprintf("unknown subject %s for this course", subjectID))
+			continue
+		}
+		if first, dup := listed[subjectID]; dup {
+			rowErr(columnSubjectID, fmt.Sprintf("subject %s already listed in row %d", subjectID, first))
+			continue
+		}
+		listed[subjectID] = rowNum
+		target.subjects = append(target.subjects, subject)
+	}
+
+	var tree []models.CurriculumNode
+	for _, key := range models.Categories {
+		if c, ok := categories[key]; ok {
+			tree = append(tree, c.build())
+		}
+	}
+	return tree, errs
+}
+
+func (n *importNode) build() models.CurriculumNode {
+	node := n.node
+	node.Subjects = n.subjects
+	if node.Subjects == nil {
+		node.Subjects = []models.CurriculumSubject{}
+	}
+	node.Children = []models.CurriculumNode{}
+	for _, g := range n.groups {
+		node.Children = append(node.Children, g.build())
+	}
+	return node
+}
+
+// resolveCategory รับได้ทั้ง key และชื่อหมวดภาษาไทยหรืออังกฤษ
+func resolveCategory(value string) (string, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for _, key := range models.Categories {
+		names := models.CategoryNames[key]
+		if value == key || value == strings.ToLower(names[0]) || value == strings.ToLower(names[1]) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func blankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
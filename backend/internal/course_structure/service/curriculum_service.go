@@ -0,0 +1,185 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"cpsu/internal/course_structure/models"
+	"cpsu/internal/credits"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var ErrInvalidCurriculumFile = errors.New("invalid curriculum file")
+
+func (s *courseStructureService) GetCurriculum(courseID string) (*models.Curriculum, error) {
+	curriculum, err := s.repo.GetCurriculum(courseID)
+	if err != nil {
+		return nil, err
+	}
+	summarizeCurriculum(curriculum)
+	return curriculum, nil
+}
+
+// ImportCurriculum อ่าน sheet แรกของไฟล์ XLSX แล้วแทนที่โครงสร้างเดิมพร้อมสร้าง detail ใหม่
+// ถ้ามีข้อผิดพลาดแม้แถวเดียวหรือเป็น dryRun จะคืนผลการตรวจโดยไม่บันทึก
+func (s *courseStructureService) ImportCurriculum(courseID string, file io.Reader, dryRun bool, userID int, ip string, userAgent string) (*models.CurriculumImportResult, error) {
+	existing, err := s.repo.GetCurriculum(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := excelize.OpenReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCurriculumFile, err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("%w: no sheets", ErrInvalidCurriculumFile)
+	}
+	rows, err := f.GetRows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCurriculumFile, err)
+	}
+
+	subjects, err := s.repo.GetCourseSubjects(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, rowErrors := parseCurriculumRows(rows, subjects)
+	result := &models.CurriculumImportResult{
+		DryRun:   dryRun,
+		Errors:   rowErrors,
+		Warnings: []string{},
+	}
+	if result.Errors == nil {
+		result.Errors = []models.CurriculumRowError{}
+	}
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	curriculum := &models.Curriculum{
+		CourseID:   courseID,
+		ThaiCourse: existing.ThaiCourse,
+		Categories: tree,
+	}
+	if curriculum.Categories == nil {
+		curriculum.Categories = []models.CurriculumNode{}
+	}
+	summarizeCurriculum(curriculum)
+	result.Warnings = curriculumWarnings(curriculum)
+	result.Curriculum = curriculum
+	result.Detail = CurriculumDetail(curriculum)
+
+	if dryRun {
+		return result, nil
+	}
+
+	if err := s.repo.ReplaceCurriculum(curriculum, result.Detail); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID,
+		"import",
+		"course_structure",
+		courseID,
+		map[string]interface{}{
+			"categories":    len(curriculum.Categories),
+			"total_credits": curriculum.TotalCredits,
+			"warnings":      len(result.Warnings),
+		},
+		ip,
+		userAgent,
+	)
+
+	return result, nil
+}
+
+// summarizeCurriculum คำนวณหน่วยกิตรวมของหลักสูตรและหน่วยกิตของรายวิชาในแต่ละกลุ่ม
+func summarizeCurriculum(curriculum *models.Curriculum) {
+	curriculum.TotalCredits = 0
+	for i := range curriculum.Categories {
+		sumSubjectCredits(&curriculum.Categories[i])
+		curriculum.TotalCredits += curriculum.Categories[i].MinCredits
+	}
+}
+
+func sumSubjectCredits(node *models.CurriculumNode) int {
+	total := 0
+	for _, subject := range node.Subjects {
+		total += subjectCredits(subject.Credits)
+	}
+	for i := range node.Children {
+		total += sumSubjectCredits(&node.Children[i])
+	}
+	node.SubjectCredits = total
+	return total
+}
+
+// subjectCredits คือหน่วยกิตที่นับรวม วิชาที่มี * หรือหน่วยกิตผิดรูปแบบนับเป็น 0
+func subjectCredits(text string) int {
+	parsed, err := credits.Parse(text)
+	if err != nil {
+		return 0
+	}
+	return parsed.Counted()
+}
+
+func curriculumWarnings(curriculum *models.Curriculum) []string {
+	warnings := []string{}
+	for _, category := range curriculum.Categories {
+		childMin := 0
+		for _, child := range category.Children {
+			childMin += child.MinCredits
+			if models.IsRequiredCategory(category.Category) && child.SubjectCredits < child.MinCredits {
+				warnings = append(warnings, fmt.Sprintf("%s / %s: subjects total %d credits, below minimum %d",
+					category.NameTH, child.NameTH, child.SubjectCredits, child.MinCredits))
+			}
+		}
+		if category.MinCredits > 0 && childMin > category.MinCredits {
+			warnings = append(warnings, fmt.Sprintf("%s: groups require %d credits, more than category minimum %d",
+				category.NameTH, childMin, category.MinCredits))
+		}
+		if models.IsRequiredCategory(category.Category) && category.SubjectCredits < category.MinCredits {
+			warnings = append(warnings, fmt.Sprintf("%s: subjects total %d credits, below minimum %d",
+				category.NameTH, category.SubjectCredits, category.MinCredits))
+		}
+	}
+	return warnings
+}
+
+// CurriculumDetail สร้างข้อความ detail ของ course_structure จากโครงสร้างหลักสูตร
+func CurriculumDetail(curriculum *models.Curriculum) string {
+	var b strings.Builder
+	b.WriteString("โครงสร้างหลักสูตร")
+	if curriculum.ThaiCourse != "" {
+		b.WriteString(" " + curriculum.ThaiCourse)
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "จำนวนหน่วยกิตรวมตลอดหลักสูตร ไม่น้อยกว่า %d หน่วยกิต\n", curriculum.TotalCredits)
+
+	var write func(node models.CurriculumNode, number string, depth int)
+	write = func(node models.CurriculumNode, number string, depth int) {
+		indent := strings.Repeat("    ", depth)
+		fmt.Fprintf(&b, "%s%s %s ไม่น้อยกว่า %d หน่วยกิต\n", indent, number, node.NameTH, node.MinCredits)
+		for _, subject := range node.Subjects {
+			fmt.Fprintf(&b, "%s    %s %s %s\n", indent, subject.SubjectID, subject.ThaiSubject, subject.Credits)
+		}
+		for i, child := range node.Children {
+			write(child, strings.TrimSuffix(number, ".")+"."+strconv.Itoa(i+1), depth+1)
+		}
+	}
+	for i, category := range curriculum.Categories {
+		write(category, strconv.Itoa(i+1)+".", 0)
+	}
+
+	return b.String()
+}
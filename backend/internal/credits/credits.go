@@ -0,0 +1,117 @@
+package credits
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxCredits และ MaxHours กันค่าที่พิมพ์เกินจนไม่น่าเป็นไปได้ เช่น 33(3-0-6)
+	MaxCredits = 30
+	MaxHours   = 60
+)
+
+var ErrInvalid = errors.New("invalid credits")
+
+// รูปแบบหน่วยกิตแบบไทย "หน่วยกิต(บรรยาย-ปฏิบัติ-ศึกษาด้วยตนเอง)" เช่น 3(2-2-5)
+// * หลังหน่วยกิตคือวิชาที่ไม่นับหน่วยกิตรวม ส่วนวงเล็บที่ไม่ใช่ชั่วโมงเรียนถือเป็นหมายเหตุ
+// เช่น "6 (ไม่น้อยกว่า 640 ชั่วโมง)" ของสหกิจศึกษา
+var (
+	creditsPattern = regexp.MustCompile(`^(\d+)\s*(\*?)\s*(?:\((.*)\))?$`)
+	hoursPattern   = regexp.MustCompile(`^\s*(\d+)\s*-\s*(\d+)\s*-\s*(\d+)\s*$`)
+	numericPattern = regexp.MustCompile(`^[\d\s-]+$`)
+)
+
+// Credits คือหน่วยกิตที่แยกเป็นตัวเลขแล้ว ชั่วโมงเป็น nil เมื่อข้อความไม่ได้ระบุ เช่น วิทยานิพนธ์ "12"
+type Credits struct {
+	Value          int    `json:"credit_value"`
+	LectureHours   *int   `json:"lecture_hours"`
+	LabHours       *int   `json:"lab_hours"`
+	SelfStudyHours *int   `json:"self_study_hours"`
+	NonCredit      bool   `json:"non_credit"`
+	Note           string `json:"credit_note,omitempty"`
+}
+
+// Parse อ่านและตรวจหน่วยกิต หน่วยกิตต้องเท่ากับชั่วโมงบรรยาย + ชั่วโมงปฏิบัติ/2
+// (หรือ /3 สำหรับวิชาฝึกปฏิบัติ) ตามเกณฑ์มาตรฐานหลักสูตร
+func Parse(s string) (Credits, error) {
+	var c Credits
+
+	text := strings.Join(strings.Fields(s), " ")
+	m := creditsPattern.FindStringSubmatch(text)
+	if m == nil {
+		return c, fmt.Errorf("%w: %q is not in the form 3(3-0-6)", ErrInvalid, s)
+	}
+
+	c.Value, _ = strconv.Atoi(m[1])
+	c.NonCredit = m[2] == "*"
+	if c.Value > MaxCredits {
+		return c, fmt.Errorf("%w: %d credits is more than %d", ErrInvalid, c.Value, MaxCredits)
+	}
+
+	if m[3] == "" {
+		return c, nil
+	}
+	hours := hoursPattern.FindStringSubmatch(m[3])
+	if hours == nil {
+		if numericPattern.MatchString(m[3]) {
+			return c, fmt.Errorf("%w: hours %q must be lecture-lab-self study", ErrInvalid, m[3])
+		}
+		c.Note = strings.TrimSpace(m[3])
+		return c, nil
+	}
+
+	lecture, _ := strconv.Atoi(hours[1])
+	lab, _ := strconv.Atoi(hours[2])
+	self, _ := strconv.Atoi(hours[3])
+	for _, h := range []int{lecture, lab, self} {
+		if h > MaxHours {
+			return c, fmt.Errorf("%w: %d hours is more than %d", ErrInvalid, h, MaxHours)
+		}
+	}
+	if lecture > c.Value || (2*(c.Value-lecture) != lab && 3*(c.Value-lecture) != lab) {
+		return c, fmt.Errorf("%w: %d(%d-%d-%d) lecture and lab hours do not add up to %d credits",
+			ErrInvalid, c.Value, lecture, lab, self, c.Value)
+	}
+
+	c.LectureHours, c.LabHours, c.SelfStudyHours = &lecture, &lab, &self
+	return c, nil
+}
+
+// String คืนข้อความรูปแบบมาตรฐาน เช่น "3(2-2-5)" หรือ "3*"
+func (c Credits) String() string {
+	s := strconv.Itoa(c.Value)
+	if c.NonCredit {
+		s += "*"
+	}
+	switch {
+	case c.LectureHours != nil && c.LabHours != nil && c.SelfStudyHours != nil:
+		s += fmt.Sprintf("(%d-%d-%d)", *c.LectureHours, *c.LabHours, *c.SelfStudyHours)
+	case c.Note != "":
+		s += " (" + c.Note + ")"
+	}
+	return s
+}
+
+// Counted คือหน่วยกิตที่นับรวมในหลักสูตร วิชาที่มี * ไม่นับ
+func (c Credits) Counted() int {
+	if c.NonCredit {
+		return 0
+	}
+	return c.Value
+}
+
+var totalPattern = regexp.MustCompile(`(\d+)\s*หน่วยกิต`)
+
+// ParseTotal อ่านหน่วยกิตรวมของหลักสูตรจากข้อความ เช่น "จำนวนไม่น้อยกว่า 126 หน่วยกิต" คืน 0 ถ้าไม่พบ
+func ParseTotal(s string) int {
+	m := totalPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0
+	}
+	value, _ := strconv.Atoi(m[1])
+	return value
+}
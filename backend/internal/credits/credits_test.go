@@ -0,0 +1,148 @@
+package credits
+
+import (
+	"errors"
+	"testing"
+
+	"cpsu/internal/seedtest"
+)
+
+func hours(lecture, lab, self int) *[3]int {
+	return &[3]int{lecture, lab, self}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text      string
+		value     int
+		hours     *[3]int
+		nonCredit bool
+		note      string
+		want      string
+	}{
+		{"3(3-0-6)", 3, hours(3, 0, 6), false, "", "3(3-0-6)"},
+		{"3(2-2-5)", 3, hours(2, 2, 5), false, "", "3(2-2-5)"},
+		{"4(2-4-6)", 4, hours(2, 4, 6), false, "", "4(2-4-6)"},
+		{"1(0-2-1)", 1, hours(0, 2, 1), false, "", "1(0-2-1)"},
+		{"2(0-4-2)", 2, hours(0, 4, 2), false, "", "2(0-4-2)"},
+		{"2(1-2-3)", 2, hours(1, 2, 3), false, "", "2(1-2-3)"},
+		{"2(2-0-4)", 2, hours(2, 0, 4), false, "", "2(2-0-4)"},
+		{"1(0-3-0)", 1, hours(0, 3, 0), false, "", "1(0-3-0)"},
+		{"3(2-3-4)", 3, hours(2, 3, 4), false, "", "3(2-3-4)"},
+		{"3*(3-0-6)", 3, hours(3, 0, 6), true, "", "3*(3-0-6)"},
+		{"1*(0-2-1)", 1, hours(0, 2, 1), true, "", "1*(0-2-1)"},
+		{"3*", 3, nil, true, "", "3*"},
+		{"3", 3, nil, false, "", "3"},
+		{"12", 12, nil, false, "", "12"},
+		{"3(3-0-6) ", 3, hours(3, 0, 6), false, "", "3(3-0-6)"},
+		{"\t3(2-2-5)", 3, hours(2, 2, 5), false, "", "3(2-2-5)"},
+		{"3 ( 2 - 2 - 5 )", 3, hours(2, 2, 5), false, "", "3(2-2-5)"},
+		{"6 (ไม่น้อยกว่า 640 ชั่วโมง)", 6, nil, false, "ไม่น้อยกว่า 640 ชั่วโมง", "6 (ไม่น้อยกว่า 640 ชั่วโมง)"},
+		{"6(ไม่น้อยกว่า 600 ชั่วโมง)", 6, nil, false, "ไม่น้อยกว่า 600 ชั่วโมง", "6 (ไม่น้อยกว่า 600 ชั่วโมง)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			c, err := Parse(tt.text)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.text, err)
+			}
+			if c.Value != tt.value || c.NonCredit != tt.nonCredit || c.Note != tt.note {
+				t.Errorf("Parse(%q) = %+v", tt.text, c)
+			}
+			if tt.hours == nil {
+				if c.LectureHours != nil || c.LabHours != nil || c.SelfStudyHours != nil {
+					t.Errorf("Parse(%q) has hours, want none", tt.text)
+				}
+			} else if c.LectureHours == nil || c.LabHours == nil || c.SelfStudyHours == nil ||
+				[3]int{*c.LectureHours, *c.LabHours, *c.SelfStudyHours} != *tt.hours {
+				t.Errorf("Parse(%q) hours do not match %v", tt.text, *tt.hours)
+			}
+			if got := c.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"empty", ""},
+		{"text only", "สามหน่วยกิต"},
+		{"trailing text", "3(3-0-6) บรรยาย"},
+		{"too many credits", "33(3-0-6)"},
+		{"too many hours", "3(3-0-70)"},
+		{"two hour fields", "3(3-0)"},
+		{"four hour fields", "3(3-0-6-1)"},
+		{"lecture over credits", "3(4-0-8)"},
+		{"lab does not add up", "3(2-1-5)"},
+		{"lab over three times", "1(0-4-1)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c, err := Parse(tt.text); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Parse(%q) = %+v, %v, want ErrInvalid", tt.text, c, err)
+			}
+		})
+	}
+}
+
+func TestCounted(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"3(3-0-6)", 3},
+		{"3*(3-0-6)", 0},
+		{"3*", 0},
+		{"6 (ไม่น้อยกว่า 640 ชั่วโมง)", 6},
+	}
+
+	for _, tt := range tests {
+		c, err := Parse(tt.text)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tt.text, err)
+		}
+		if got := c.Counted(); got != tt.want {
+			t.Errorf("Counted(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseTotal(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"จำนวนไม่น้อยกว่า 126 หน่วยกิต", 126},
+		{"จำนวนหน่วยกิตรวมตลอดหลักสูตร ไม่น้อยกว่า 36หน่วยกิต", 36},
+		{"ไม่น้อยกว่า 126", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := ParseTotal(tt.text); got != tt.want {
+			t.Errorf("ParseTotal(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+// หน่วยกิตทุกแถวในไฟล์ seed ต้องอ่านได้ และอ่านค่าที่ String() คืนได้ผลเท่าเดิม
+func TestParseSeedRows(t *testing.T) {
+	for _, row := range seedtest.SubjectRows(t) {
+		text := row["credits"]
+		c, err := Parse(text)
+		if err != nil {
+			t.Errorf("%s %s: %v", row["file"], row["subject_id"], err)
+			continue
+		}
+		again, err := Parse(c.String())
+		if err != nil || again.String() != c.String() {
+			t.Errorf("%s %s: %q does not round-trip: %q, %v", row["file"], row["subject_id"], text, again.String(), err)
+		}
+	}
+}
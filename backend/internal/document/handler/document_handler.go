@@ -8,6 +8,7 @@ import (
 
 	"cpsu/internal/document/models"
 	"cpsu/internal/document/service"
+	"cpsu/internal/pagination"
 
 	"github.com/gin-gonic/gin"
 )
@@ -31,7 +32,37 @@ func (h *DocumentHandler) GetAllDocument(c *gin.Context) {
 
 	documents, err := h.documentService.GetAllDocument(param)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get document"})
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+		} else if errors.Is(err, service.ErrInvalidVisibility) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get document"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, documents)
+}
+
+// GetPublicDocuments ใช้กับหน้าเว็บสาธารณะ แสดงเฉพาะเอกสาร public
+func (h *DocumentHandler) GetPublicDocuments(c *gin.Context) {
+
+	var param models.DocumentQueryParam
+
+	if err := c.BindQuery(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameter"})
+		return
+	}
+	param.Visibility = models.VisibilityPublic
+
+	documents, err := h.documentService.GetAllDocument(param)
+	if err != nil {
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get document"})
+		}
 		return
 	}
 
@@ -63,12 +94,76 @@ func (h *DocumentHandler) GetDocumentByID(c *gin.Context) {
 	c.JSON(http.StatusOK, document)
 }
 
+func (h *DocumentHandler) GetPublicDocumentByID(c *gin.Context) {
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid document ID"})
+		return
+	}
+
+	document, err := h.documentService.GetDocumentByID(id)
+	if err != nil {
+
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "document ID not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+
+		return
+	}
+
+	if document.Visibility != models.VisibilityPublic {
+		c.JSON(http.StatusNotFound, gin.H{"error": "document ID not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, document)
+}
+
+// DownloadDocument redirect ไปยังลิงก์ดาวน์โหลดอายุสั้น ใช้ได้เฉพาะเอกสาร public
+func (h *DocumentHandler) DownloadDocument(c *gin.Context) {
+	h.download(c, false)
+}
+
+// DownloadPrivateDocument ใช้กับผู้ที่มีสิทธิ์ document:read_private ดาวน์โหลดได้ทุกเอกสาร
+func (h *DocumentHandler) DownloadPrivateDocument(c *gin.Context) {
+	h.download(c, true)
+}
+
+func (h *DocumentHandler) download(c *gin.Context, allowPrivate bool) {
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid document ID"})
+		return
+	}
+
+	url, err := h.documentService.GetDownloadURL(id, allowPrivate)
+	if err != nil {
+
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, service.ErrDocumentPrivate) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "document ID not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, url)
+}
+
 func (h *DocumentHandler) CreateDocument(c *gin.Context) {
 
 	title := c.PostForm("title")
 	typeIDStr := c.PostForm("type_id")
 	typeName := c.PostForm("type_name")
 
+	visibility := c.PostForm("visibility")
+
 	var description *string
 	descriptionStr := c.PostForm("description")
 
@@ -99,12 +194,16 @@ func (h *DocumentHandler) CreateDocument(c *gin.Context) {
 	userAgent := c.GetHeader("User-Agent")
 
 	created, err := h.documentService.CreateDocument(
-		typeID, typeName, title, description,
+		typeID, typeName, title, description, visibility,
 		file, userID, ip, userAgent,
 	)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, service.ErrInvalidVisibility) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -125,6 +224,8 @@ func (h *DocumentHandler) UpdateDocument(c *gin.Context) {
 	typeIDStr := c.PostForm("type_id")
 	typeName := c.PostForm("type_name")
 
+	visibility := c.PostForm("visibility")
+
 	var description *string
 	descriptionStr := c.PostForm("description")
 
@@ -151,7 +252,7 @@ func (h *DocumentHandler) UpdateDocument(c *gin.Context) {
 	userAgent := c.GetHeader("User-Agent")
 
 	updated, err := h.documentService.UpdateDocument(
-		id, typeID, typeName, title, description,
+		id, typeID, typeName, title, description, visibility,
 		file, userID, ip, userAgent,
 	)
 
@@ -159,6 +260,8 @@ func (h *DocumentHandler) UpdateDocument(c *gin.Context) {
 
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "document ID not found"})
+		} else if errors.Is(err, service.ErrInvalidVisibility) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
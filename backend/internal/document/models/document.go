@@ -1,20 +1,24 @@
 package models
 
+import "cpsu/internal/pagination"
+
 type Document struct {
 	DocumentID  int     `json:"document_id"`
 	TypeID      int     `json:"type_id"`
 	TypeName    string  `json:"type_name"`
 	Title       string  `json:"title"`
 	Description *string `json:"description"`
-	File        string  `json:"file"`
+	File        string  `json:"file,omitempty"`
+	Visibility  string  `json:"visibility"`
 }
 
 type DocumentQueryParam struct {
-	Search string `form:"search"`
-	Limit  int    `form:"limit"`
-	TypeID int    `form:"type_id"`
-	Sort   string `form:"sort"`
-	Order  string `form:"order"`
+	pagination.Params
+	Search     string `form:"search"`
+	TypeID     int    `form:"type_id"`
+	Visibility string `form:"visibility"`
+	Sort       string `form:"sort"`
+	Order      string `form:"order"`
 }
 
 type DocumentRequest struct {
@@ -23,4 +27,14 @@ type DocumentRequest struct {
 	Title       string  `json:"title"`
 	Description *string `json:"description"`
 	File        string  `json:"file"`
+	Visibility  string  `json:"visibility"`
+}
+
+const (
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private"
+)
+
+func IsValidVisibility(visibility string) bool {
+	return visibility == VisibilityPublic || visibility == VisibilityPrivate
 }
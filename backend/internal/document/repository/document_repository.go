@@ -3,13 +3,14 @@ package repository
 import (
 	"database/sql"
 	"strconv"
-	"strings"
 
 	"cpsu/internal/document/models"
+	"cpsu/internal/pagination"
+	"cpsu/internal/textsearch"
 )
 
 type DocumentRepository interface {
-	GetAllDocument(param models.DocumentQueryParam) ([]models.Document, error)
+	GetAllDocument(param models.DocumentQueryParam) (*pagination.Page[models.Document], error)
 	GetDocumentByID(id int) (*models.Document, error)
 	CreateDocument(req models.DocumentRequest) (*models.Document, error)
 	UpdateDocument(id int, req models.DocumentRequest) (*models.Document, error)
@@ -24,14 +25,18 @@ func NewDocumentRepository(db *sql.DB) DocumentRepository {
 	return &documentRepository{db: db}
 }
 
-func (r *documentRepository) GetAllDocument(param models.DocumentQueryParam) ([]models.Document, error) {
-	query := `
-		SELECT 
-			d.document_id, d.type_id, dt.type_name, d.title, d.description, d.file
-		FROM document d
-		LEFT JOIN document_types dt ON d.type_id = dt.type_id
-	`
+var documentSort = pagination.Sorter{
+	Columns: map[string]string{
+		"document_id": "d.document_id",
+		"title":       "d.title",
+		"type_id":     "d.type_id",
+		"visibility":  "d.visibility",
+	},
+	Default: "-document_id",
+	Key:     "d.document_id",
+}
 
+func (r *documentRepository) GetAllDocument(param models.DocumentQueryParam) (*pagination.Page[models.Document], error) {
 	conditions := []string{}
 	args := []interface{}{}
 	argIndex := 1
@@ -42,55 +47,49 @@ func (r *documentRepository) GetAllDocument(param models.DocumentQueryParam) ([]
 		argIndex++
 	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
-	}
-
-	sort := "d.document_id"
-	if param.Sort != "" {
-		sort = "d." + param.Sort
-	}
-
-	order := "ASC"
-	if strings.ToUpper(param.Order) == "DESC" {
-		order = "DESC"
+	if param.Visibility != "" {
+		conditions = append(conditions, "d.visibility = $"+strconv.Itoa(argIndex))
+		args = append(args, param.Visibility)
+		argIndex++
 	}
 
-	query += " ORDER BY " + sort + " " + order
-
-	if param.Limit > 0 {
-		query += " LIMIT " + strconv.Itoa(param.Limit)
+	if terms := textsearch.Terms(param.Search); len(terms) > 0 {
+		condition, searchArgs, next := textsearch.Condition([]string{"d.title", "d.description"}, terms, argIndex)
+		conditions = append(conditions, condition)
+		args = append(args, searchArgs...)
+		argIndex = next
 	}
 
-	rows, err := r.db.Query(query, args...)
+	orderBy, err := documentSort.Parse(param.Sort, param.Order)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var documents []models.Document
+	stmt := pagination.Statement{
+		Columns: "d.document_id, d.type_id, dt.type_name, d.title, d.description, d.file, d.visibility",
+		From: `
+			FROM document d
+			LEFT JOIN document_types dt ON d.type_id = dt.type_id
+		`,
+		Conditions: conditions,
+		Args:       args,
+		OrderBy:    orderBy,
+	}
 
-	for rows.Next() {
+	return pagination.Fetch(r.db, stmt, param.Params, func(row pagination.Scanner) (models.Document, error) {
 		var document models.Document
-
-		err := rows.Scan(
+		err := row.Scan(
 			&document.DocumentID, &document.TypeID, &document.TypeName,
-			&document.Title, &document.Description, &document.File,
+			&document.Title, &document.Description, &document.File, &document.Visibility,
 		)
-		if err != nil {
-			return nil, err
-		}
-
-		documents = append(documents, document)
-	}
-
-	return documents, nil
+		return document, err
+	})
 }
 
 func (r *documentRepository) GetDocumentByID(id int) (*models.Document, error) {
 	query := `
 		SELECT 
-			d.document_id, d.type_id, dt.type_name, d.title, d.description, d.file
+			d.document_id, d.type_id, dt.type_name, d.title, d.description, d.file, d.visibility
 		FROM document d
 		LEFT JOIN document_types dt ON d.type_id = dt.type_id
 		WHERE d.document_id = $1
@@ -102,7 +101,7 @@ func (r *documentRepository) GetDocumentByID(id int) (*models.Document, error) {
 
 	err := row.Scan(
 		&document.DocumentID, &document.TypeID, &document.TypeName,
-		&document.Title, &document.Description, &document.File,
+		&document.Title, &document.Description, &document.File, &document.Visibility,
 	)
 
 	if err != nil {
@@ -150,11 +149,11 @@ func (r *documentRepository) CreateDocument(req models.DocumentRequest) (*models
 	var documentID int
 
 	err = tx.QueryRow(`
-		INSERT INTO document (type_id, title, description, file)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO document (type_id, title, description, file, visibility)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING document_id
 	`,
-		typeID, req.Title, req.Description, req.File,
+		typeID, req.Title, req.Description, req.File, req.Visibility,
 	).Scan(&documentID)
 
 	if err != nil {
@@ -204,11 +203,11 @@ func (r *documentRepository) UpdateDocument(id int, req models.DocumentRequest)
 
 	err = tx.QueryRow(`
 		UPDATE document
-		SET type_id = $1, title = $2, description = $3, file = $4
-		WHERE document_id = $5
+		SET type_id = $1, title = $2, description = $3, file = $4, visibility = $5
+		WHERE document_id = $6
 		RETURNING document_id
 	`,
-		typeID, req.Title, req.Description, req.File, id,
+		typeID, req.Title, req.Description, req.File, req.Visibility, id,
 	).Scan(&updatedID)
 
 	if err != nil {
@@ -3,64 +3,56 @@ package service
 import (
 	"context"
 	"errors"
-	"fmt"
 	"mime/multipart"
-	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	authrepo "cpsu/internal/auth/repository"
 	"cpsu/internal/document/models"
 	documentrepo "cpsu/internal/document/repository"
-
-	"github.com/google/uuid"
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
+	"cpsu/internal/imaging"
+	"cpsu/internal/pagination"
+	"cpsu/internal/storage"
 )
 
 type DocumentService interface {
-	GetAllDocument(param models.DocumentQueryParam) ([]models.Document, error)
+	GetAllDocument(param models.DocumentQueryParam) (*pagination.Page[models.Document], error)
 	GetDocumentByID(id int) (*models.Document, error)
-	CreateDocument(typeID int, typeName string, title string, description *string, file *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Document, error)
-	UpdateDocument(id int, typeID int, typeName string, title string, description *string, file *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Document, error)
+	CreateDocument(typeID int, typeName string, title string, description *string, visibility string, file *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Document, error)
+	UpdateDocument(id int, typeID int, typeName string, title string, description *string, visibility string, file *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Document, error)
 	DeleteDocument(id int, userID int, ip string, userAgent string) error
+	GetDownloadURL(id int, allowPrivate bool) (string, error)
 }
 
-type documentService struct {
-	repo        documentrepo.DocumentRepository
-	auditRepo   *authrepo.AuditRepository
-	minioClient *minio.Client
-	bucket      string
-	publicBase  string
-}
+var (
+	ErrInvalidVisibility = errors.New("visibility must be public or private")
+	ErrDocumentPrivate   = errors.New("document is private")
+)
 
-func NewDocumentService(repo documentrepo.DocumentRepository, auditRepo *authrepo.AuditRepository, endpoint string, accessKey string, secretKey string, bucket string, useSSL bool, publicBaseURL string) DocumentService {
+// อายุของลิงก์ดาวน์โหลดที่สร้างจาก GetDownloadURL
+const downloadURLExpiry = 5 * time.Minute
 
-	client, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
-		Secure: useSSL,
-	})
-	if err != nil {
-		panic(err)
-	}
+type documentService struct {
+	repo         documentrepo.DocumentRepository
+	auditRepo    *authrepo.AuditRepository
+	store        storage.Storage
+	privateStore storage.Storage
+}
 
+func NewDocumentService(repo documentrepo.DocumentRepository, auditRepo *authrepo.AuditRepository, store storage.Storage, privateStore storage.Storage) DocumentService {
 	return &documentService{
-		repo:        repo,
-		auditRepo:   auditRepo,
-		minioClient: client,
-		bucket:      bucket,
-		publicBase:  publicBaseURL,
+		repo:         repo,
+		auditRepo:    auditRepo,
+		store:        store,
+		privateStore: privateStore,
 	}
 }
 
-func (s *documentService) GetAllDocument(param models.DocumentQueryParam) ([]models.Document, error) {
-
-	if param.Sort == "" {
-		param.Sort = "document_id"
-	}
+func (s *documentService) GetAllDocument(param models.DocumentQueryParam) (*pagination.Page[models.Document], error) {
 
-	if param.Order == "" {
-		param.Order = "desc"
+	if param.Visibility != "" && !models.IsValidVisibility(param.Visibility) {
+		return nil, ErrInvalidVisibility
 	}
 
 	documents, err := s.repo.GetAllDocument(param)
@@ -68,14 +60,24 @@ func (s *documentService) GetAllDocument(param models.DocumentQueryParam) ([]mod
 		return nil, err
 	}
 
+	for i := range documents.Items {
+		hidePrivateFile(&documents.Items[i])
+	}
+
 	return documents, nil
 }
 
 func (s *documentService) GetDocumentByID(id int) (*models.Document, error) {
-	return s.repo.GetDocumentByID(id)
+	document, err := s.repo.GetDocumentByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	hidePrivateFile(document)
+	return document, nil
 }
 
-func (s *documentService) CreateDocument(typeID int, typeName string, title string, description *string, file *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Document, error) {
+func (s *documentService) CreateDocument(typeID int, typeName string, title string, description *string, visibility string, file *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Document, error) {
 
 	if strings.TrimSpace(title) == "" {
 		return nil, errors.New("title is required")
@@ -85,15 +87,23 @@ func (s *documentService) CreateDocument(typeID int, typeName string, title stri
 		return nil, errors.New("file is required")
 	}
 
-	fileURL, err := s.UploadFile(file)
+	if visibility == "" {
+		visibility = models.VisibilityPublic
+	}
+	if !models.IsValidVisibility(visibility) {
+		return nil, ErrInvalidVisibility
+	}
+
+	fileURL, err := s.UploadFile(file, visibility)
 	if err != nil {
 		return nil, err
 	}
 
-	req := models.DocumentRequest{TypeID: typeID, TypeName: typeName, Title: title, Description: description, File: fileURL}
+	req := models.DocumentRequest{TypeID: typeID, TypeName: typeName, Title: title, Description: description, File: fileURL, Visibility: visibility}
 
 	created, err := s.repo.CreateDocument(req)
 	if err != nil {
+		s.removeFile(fileURL)
 		return nil, err
 	}
 
@@ -103,16 +113,17 @@ func (s *documentService) CreateDocument(typeID int, typeName string, title stri
 		"document",
 		strconv.Itoa(created.DocumentID),
 		map[string]interface{}{
-			"title": created.Title,
+			"title":      created.Title,
+			"visibility": created.Visibility,
 		},
 		ip,
 		userAgent,
 	)
 
-	return s.repo.GetDocumentByID(created.DocumentID)
+	return s.GetDocumentByID(created.DocumentID)
 }
 
-func (s *documentService) UpdateDocument(id int, typeID int, typeName string, title string, description *string, file *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Document, error) {
+func (s *documentService) UpdateDocument(id int, typeID int, typeName string, title string, description *string, visibility string, file *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Document, error) {
 
 	if strings.TrimSpace(title) == "" {
 		return nil, errors.New("title is required")
@@ -123,46 +134,76 @@ func (s *documentService) UpdateDocument(id int, typeID int, typeName string, ti
 		return nil, err
 	}
 
+	if visibility == "" {
+		visibility = oldDocument.Visibility
+	}
+	if !models.IsValidVisibility(visibility) {
+		return nil, ErrInvalidVisibility
+	}
+
 	fileURL := oldDocument.File
 
 	if file != nil {
-		uploadedURL, err := s.UploadFile(file)
+		uploadedURL, err := s.UploadFile(file, visibility)
 		if err != nil {
 			return nil, err
 		}
 
 		fileURL = uploadedURL
+	} else if visibility != oldDocument.Visibility {
+		// เปลี่ยน visibility โดยไม่ได้อัปโหลดไฟล์ใหม่ ต้องย้ายไฟล์เดิมไปอีก storage
+		copiedURL, err := storage.Copy(context.Background(), s.storeFor(oldDocument.Visibility), s.storeFor(visibility), oldDocument.File)
+		if err != nil {
+			return nil, err
+		}
+
+		fileURL = copiedURL
 	}
 
-	req := models.DocumentRequest{TypeID: typeID, TypeName: typeName, Title: title, Description: description, File: fileURL}
+	req := models.DocumentRequest{TypeID: typeID, TypeName: typeName, Title: title, Description: description, File: fileURL, Visibility: visibility}
 
 	updated, err := s.repo.UpdateDocument(id, req)
 	if err != nil {
+		if fileURL != oldDocument.File {
+			s.removeFile(fileURL)
+		}
 		return nil, err
 	}
 
+	if oldDocument.File != fileURL || oldDocument.Visibility != visibility {
+		imaging.RemoveUnused(s.storeFor(oldDocument.Visibility), oldDocument.File)
+	}
+
 	_ = s.auditRepo.LogAudit(
 		userID,
 		"update",
 		"document",
 		strconv.Itoa(updated.DocumentID),
 		map[string]interface{}{
-			"title": updated.Title,
+			"title":      updated.Title,
+			"visibility": updated.Visibility,
 		},
 		ip,
 		userAgent,
 	)
 
-	return s.repo.GetDocumentByID(updated.DocumentID)
+	return s.GetDocumentByID(updated.DocumentID)
 }
 
 func (s *documentService) DeleteDocument(id int, userID int, ip string, userAgent string) error {
 
-	err := s.repo.DeleteDocument(id)
+	existing, err := s.repo.GetDocumentByID(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.DeleteDocument(id)
 	if err != nil {
 		return err
 	}
 
+	s.removeFile(existing.File)
+
 	_ = s.auditRepo.LogAudit(
 		userID,
 		"delete",
@@ -176,42 +217,47 @@ func (s *documentService) DeleteDocument(id int, userID int, ip string, userAgen
 	return nil
 }
 
-func (s *documentService) UploadFile(fileHeader *multipart.FileHeader) (string, error) {
-
-	file, err := fileHeader.Open()
+// GetDownloadURL คืนลิงก์ดาวน์โหลดอายุสั้น เอกสาร private จะได้เฉพาะเมื่อ allowPrivate เป็น true
+// ไฟล์ที่อยู่นอก storage (เช่น ลิงก์ภายนอกในข้อมูลเก่า) จะคืน URL เดิม
+func (s *documentService) GetDownloadURL(id int, allowPrivate bool) (string, error) {
+	document, err := s.repo.GetDocumentByID(id)
 	if err != nil {
 		return "", err
 	}
-	defer file.Close()
 
-	ext := filepath.Ext(fileHeader.Filename)
+	if document.Visibility == models.VisibilityPrivate && !allowPrivate {
+		return "", ErrDocumentPrivate
+	}
 
-	objectName := fmt.Sprintf(
-		"document/%s%s",
-		uuid.New().String(),
-		ext,
-	)
+	store := s.storeFor(document.Visibility)
+	key, ok := store.Key(document.File)
+	if !ok {
+		return document.File, nil
+	}
 
-	_, err = s.minioClient.PutObject(
-		context.Background(),
-		s.bucket,
-		objectName,
-		file,
-		fileHeader.Size,
-		minio.PutObjectOptions{
-			ContentType: fileHeader.Header.Get("Content-Type"),
-		},
-	)
-	if err != nil {
-		return "", err
+	return store.PresignedGet(context.Background(), key, downloadURLExpiry)
+}
+
+func (s *documentService) UploadFile(fileHeader *multipart.FileHeader, visibility string) (string, error) {
+	return storage.UploadFile(context.Background(), s.storeFor(visibility), "document", fileHeader)
+}
+
+func (s *documentService) storeFor(visibility string) storage.Storage {
+	if visibility == models.VisibilityPrivate {
+		return s.privateStore
 	}
+	return s.store
+}
 
-	fileURL := fmt.Sprintf(
-		"%s/%s/%s",
-		s.publicBase,
-		s.bucket,
-		objectName,
-	)
+// ไม่ส่ง URL ของไฟล์ private ออกไป ให้ดาวน์โหลดผ่าน endpoint download เท่านั้น
+func hidePrivateFile(document *models.Document) {
+	if document.Visibility == models.VisibilityPrivate {
+		document.File = ""
+	}
+}
 
-	return fileURL, nil
-}
\ No newline at end of file
+// removeFile ใช้เมื่อไม่แน่ใจว่าไฟล์อยู่ใน storage ใด จึงลบจากทั้งสองที่
+func (s *documentService) removeFile(url string) {
+	imaging.RemoveUnused(s.store, url)
+	imaging.RemoveUnused(s.privateStore, url)
+}
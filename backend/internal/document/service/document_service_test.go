@@ -0,0 +1,307 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"mime/multipart"
+	"net/url"
+	"strings"
+	"testing"
+
+	authrepo "cpsu/internal/auth/repository"
+	"cpsu/internal/document/models"
+	"cpsu/internal/document/repository"
+	"cpsu/internal/pagination"
+	"cpsu/internal/storage"
+
+	_ "github.com/lib/pq"
+)
+
+// documentRepo เก็บเอกสารไว้ใน map แทนฐานข้อมูล
+type documentRepo struct {
+	repository.DocumentRepository
+
+	documents map[int]models.Document
+	updateErr error
+}
+
+var errUpdateFailed = errors.New("update failed")
+
+func (r *documentRepo) GetAllDocument(param models.DocumentQueryParam) (*pagination.Page[models.Document], error) {
+	page := &pagination.Page[models.Document]{}
+	for id := 1; id <= len(r.documents); id++ {
+		page.Items = append(page.Items, r.documents[id])
+	}
+	return page, nil
+}
+
+func (r *documentRepo) GetDocumentByID(id int) (*models.Document, error) {
+	document, ok := r.documents[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &document, nil
+}
+
+func (r *documentRepo) CreateDocument(req models.DocumentRequest) (*models.Document, error) {
+	id := len(r.documents) + 1
+	r.documents[id] = models.Document{DocumentID: id, TypeID: req.TypeID, Title: req.Title, File: req.File, Visibility: req.Visibility}
+	return r.GetDocumentByID(id)
+}
+
+func (r *documentRepo) UpdateDocument(id int, req models.DocumentRequest) (*models.Document, error) {
+	if r.updateErr != nil {
+		return nil, r.updateErr
+	}
+	r.documents[id] = models.Document{DocumentID: id, TypeID: req.TypeID, Title: req.Title, File: req.File, Visibility: req.Visibility}
+	return r.GetDocumentByID(id)
+}
+
+type documentTest struct {
+	svc     DocumentService
+	repo    *documentRepo
+	public  *storage.LocalStorage
+	private *storage.LocalStorage
+}
+
+// newDocumentTest ใช้ local storage ใน t.TempDir() แทน MinIO และ audit repository ที่ต่อฐานข้อมูลไม่ได้
+func newDocumentTest(t *testing.T) *documentTest {
+	t.Helper()
+
+	public, err := storage.NewLocalStorage(t.TempDir(), "http://files.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	private, err := storage.NewSignedLocalStorage(t.TempDir(), "http://private.test", []byte("test-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repo := &documentRepo{documents: map[int]models.Document{}}
+	return &documentTest{
+		svc:     NewDocumentService(repo, authrepo.NewAuditRepository(db), public, private),
+		repo:    repo,
+		public:  public,
+		private: private,
+	}
+}
+
+// add เก็บไฟล์ลง storage ตาม visibility แล้วเพิ่มเอกสารที่อ้างอิงไฟล์นั้น
+func (d *documentTest) add(t *testing.T, visibility string) models.Document {
+	t.Helper()
+
+	store := d.public
+	if visibility == models.VisibilityPrivate {
+		store = d.private
+	}
+	key := "document/" + visibility + ".pdf"
+	if err := store.Put(context.Background(), key, strings.NewReader("pdf"), 3, "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := d.repo.CreateDocument(models.DocumentRequest{TypeID: 1, Title: visibility, File: store.URL(key), Visibility: visibility})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *created
+}
+
+func (d *documentTest) stored(t *testing.T, store storage.Storage) []string {
+	t.Helper()
+	objects, err := store.List(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []string{}
+	for _, obj := range objects {
+		keys = append(keys, obj.Key)
+	}
+	return keys
+}
+
+func pdfHeader(t *testing.T) *multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", "a.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte("%PDF-1.4"))
+	w.Close()
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["file"][0]
+}
+
+func TestPrivateDocumentHidesFileURL(t *testing.T) {
+	d := newDocumentTest(t)
+	public := d.add(t, models.VisibilityPublic)
+	private := d.add(t, models.VisibilityPrivate)
+
+	got, err := d.svc.GetDocumentByID(private.DocumentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.File != "" {
+		t.Errorf("GetDocumentByID(private).File = %q, want empty", got.File)
+	}
+
+	got, err = d.svc.GetDocumentByID(public.DocumentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.File != public.File {
+		t.Errorf("GetDocumentByID(public).File = %q, want %q", got.File, public.File)
+	}
+
+	page, err := d.svc.GetAllDocument(models.DocumentQueryParam{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range page.Items {
+		if doc.Visibility == models.VisibilityPrivate && doc.File != "" {
+			t.Errorf("GetAllDocument private File = %q, want empty", doc.File)
+		}
+	}
+
+	created, err := d.svc.CreateDocument(1, "", "new", nil, models.VisibilityPrivate, pdfHeader(t), 1, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.File != "" {
+		t.Errorf("CreateDocument(private).File = %q, want empty", created.File)
+	}
+	if stored := d.repo.documents[created.DocumentID].File; !strings.HasPrefix(stored, "http://private.test/document/") {
+		t.Errorf("stored file = %q, want in private storage", stored)
+	}
+
+	// เปลี่ยนจาก public เป็น private แล้วผลลัพธ์ต้องไม่มี URL เช่นกัน
+	updated, err := d.svc.UpdateDocument(public.DocumentID, 1, "", public.Title, nil, models.VisibilityPrivate, nil, 1, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.File != "" {
+		t.Errorf("UpdateDocument(private).File = %q, want empty", updated.File)
+	}
+}
+
+func TestUpdateDocumentVisibilityMovesFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+	}{
+		{"public to private", models.VisibilityPublic, models.VisibilityPrivate},
+		{"private to public", models.VisibilityPrivate, models.VisibilityPublic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDocumentTest(t)
+			doc := d.add(t, tt.from)
+
+			if _, err := d.svc.UpdateDocument(doc.DocumentID, 1, "", doc.Title, nil, tt.to, nil, 1, "", ""); err != nil {
+				t.Fatal(err)
+			}
+
+			fromStore, toStore := d.public, d.private
+			if tt.from == models.VisibilityPrivate {
+				fromStore, toStore = d.private, d.public
+			}
+			key := "document/" + tt.from + ".pdf"
+
+			if got := d.repo.documents[doc.DocumentID]; got.Visibility != tt.to || got.File != toStore.URL(key) {
+				t.Errorf("stored = %s %q, want %s %q", got.Visibility, got.File, tt.to, toStore.URL(key))
+			}
+			if keys := d.stored(t, toStore); len(keys) != 1 || keys[0] != key {
+				t.Errorf("%s objects = %v, want [%s]", tt.to, keys, key)
+			}
+			if keys := d.stored(t, fromStore); len(keys) != 0 {
+				t.Errorf("%s objects = %v, want none", tt.from, keys)
+			}
+		})
+	}
+}
+
+func TestUpdateDocumentVisibilityKeepsFileOnFailure(t *testing.T) {
+	d := newDocumentTest(t)
+	doc := d.add(t, models.VisibilityPublic)
+	d.repo.updateErr = errUpdateFailed
+
+	if _, err := d.svc.UpdateDocument(doc.DocumentID, 1, "", doc.Title, nil, models.VisibilityPrivate, nil, 1, "", ""); !errors.Is(err, errUpdateFailed) {
+		t.Fatalf("UpdateDocument error = %v, want errUpdateFailed", err)
+	}
+
+	if got := d.repo.documents[doc.DocumentID]; got.File != doc.File {
+		t.Errorf("stored file = %q, want %q", got.File, doc.File)
+	}
+	if keys := d.stored(t, d.public); len(keys) != 1 {
+		t.Errorf("public objects = %v, want the original file", keys)
+	}
+	if keys := d.stored(t, d.private); len(keys) != 0 {
+		t.Errorf("private objects = %v, want copy removed", keys)
+	}
+}
+
+func TestGetDownloadURL(t *testing.T) {
+	d := newDocumentTest(t)
+	public := d.add(t, models.VisibilityPublic)
+	private := d.add(t, models.VisibilityPrivate)
+	external, _ := d.repo.CreateDocument(models.DocumentRequest{Title: "external", File: "https://example.com/a.pdf", Visibility: models.VisibilityPublic})
+
+	tests := []struct {
+		name         string
+		id           int
+		allowPrivate bool
+		wantURL      string
+		wantSigned   bool
+		wantErr      error
+	}{
+		{"public", public.DocumentID, false, public.File, false, nil},
+		{"private without permission", private.DocumentID, false, "", false, ErrDocumentPrivate},
+		{"private", private.DocumentID, true, private.File, true, nil},
+		{"external link", external.DocumentID, false, external.File, false, nil},
+		{"missing", 99, true, "", false, sql.ErrNoRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.svc.GetDownloadURL(tt.id, tt.allowPrivate)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetDownloadURL error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			u, err := url.Parse(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query := u.Query()
+			u.RawQuery = ""
+			if u.String() != tt.wantURL {
+				t.Errorf("GetDownloadURL = %q, want %q", got, tt.wantURL)
+			}
+			if tt.wantSigned {
+				key, _ := d.private.Key(u.String())
+				if !d.private.VerifySignature(key, query.Get("expires"), query.Get("signature")) {
+					t.Errorf("GetDownloadURL = %q, want a valid signature", got)
+				}
+			}
+		})
+	}
+}
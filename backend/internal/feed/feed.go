@@ -0,0 +1,295 @@
+package feed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	ContentTypeRSS  = "application/rss+xml; charset=utf-8"
+	ContentTypeAtom = "application/atom+xml; charset=utf-8"
+	ContentTypeJSON = "application/feed+json; charset=utf-8"
+)
+
+// Feed คือข้อมูลกลางที่แปลงเป็น RSS 2.0, Atom หรือ JSON Feed 1.1 ได้
+type Feed struct {
+	Title       string
+	Description string
+	Link        string
+	FeedURL     string
+	Language    string
+	Updated     time.Time
+	Items       []Item
+}
+
+type Item struct {
+	ID         string
+	Title      string
+	Link       string
+	Summary    string
+	Content    string
+	Image      string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+// AbsoluteURL แปลง URL ที่เก็บแบบ path ให้เป็น URL เต็มโดยอ้างอิง base
+// URL ที่เป็นแบบเต็มอยู่แล้วจะคืนค่าเดิม
+func AbsoluteURL(base string, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	r, err := url.Parse(ref)
+	if err != nil || r.IsAbs() {
+		return ref
+	}
+	b, err := url.Parse(strings.TrimRight(base, "/") + "/")
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(&url.URL{Path: strings.TrimLeft(r.Path, "/"), RawQuery: r.RawQuery}).String()
+}
+
+// ETag สร้าง weak ETag จากรูปแบบ feed และข้อมูลที่มีผลต่อเนื้อหา
+func ETag(format string, f Feed) string {
+	h := sha256.New()
+	h.Write([]byte(format + "\n" + f.Language + "\n" + f.Title + "\n" + f.FeedURL + "\n"))
+	for _, item := range f.Items {
+		h.Write([]byte(item.ID + "@" + item.Updated.UTC().Format(time.RFC3339Nano) + "\n"))
+		// แท็กเปลี่ยนได้โดยที่ updated_at ของข่าวไม่เปลี่ยน
+		h.Write([]byte(strings.Join(item.Categories, "\x00") + "\n"))
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil))[:32] + `"`
+}
+
+func imageType(u string) string {
+	if p, err := url.Parse(u); err == nil {
+		if t := mime.TypeByExtension(strings.ToLower(path.Ext(p.Path))); t != "" {
+			return t
+		}
+	}
+	return "image/jpeg"
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	MediaNS string     `xml:"xmlns:media,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Self          atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string      `xml:"title"`
+	Link        string      `xml:"link"`
+	GUID        rssGUID     `xml:"guid"`
+	PubDate     string      `xml:"pubDate"`
+	Categories  []string    `xml:"category"`
+	Description string      `xml:"description"`
+	Media       *mediaImage `xml:"media:content"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type mediaImage struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Medium string `xml:"medium,attr"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// RSS สร้างเอกสาร RSS 2.0
+func RSS(f Feed) ([]byte, error) {
+	doc := rss{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		MediaNS: "http://search.yahoo.com/mrss/",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			Language:    f.Language,
+			Self:        atomLink{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+			Items:       []rssItem{},
+		},
+	}
+	if !f.Updated.IsZero() {
+		doc.Channel.LastBuildDate = f.Updated.Format(time.RFC1123Z)
+	}
+
+	for _, item := range f.Items {
+		entry := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{IsPermaLink: true, Value: item.Link},
+			PubDate:     item.Published.Format(time.RFC1123Z),
+			Categories:  item.Categories,
+			Description: item.Content,
+		}
+		if item.Image != "" {
+			entry.Media = &mediaImage{URL: item.Image, Type: imageType(item.Image), Medium: "image"}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, entry)
+	}
+
+	return marshalXML(doc)
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang     string      `xml:"xml:lang,attr,omitempty"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Categories []atomCategory `xml:"category"`
+	Summary    atomText       `xml:"summary"`
+	Content    atomText       `xml:"content"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Atom สร้างเอกสาร Atom 1.0
+func Atom(f Feed) ([]byte, error) {
+	// Atom บังคับให้มี updated เสมอ แม้ feed จะว่าง
+	updated := f.Updated
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	doc := atomFeed{
+		Lang:     f.Language,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		ID:       f.FeedURL,
+		Updated:  updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+		},
+		Entries: []atomEntry{},
+	}
+
+	for _, item := range f.Items {
+		entry := atomEntry{
+			Title:     item.Title,
+			ID:        item.Link,
+			Links:     []atomLink{{Href: item.Link, Rel: "alternate", Type: "text/html"}},
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+			Summary:   atomText{Type: "text", Value: item.Summary},
+			Content:   atomText{Type: "text", Value: item.Content},
+		}
+		if item.Image != "" {
+			entry.Links = append(entry.Links, atomLink{Href: item.Image, Rel: "enclosure", Type: imageType(item.Image)})
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return marshalXML(doc)
+}
+
+type jsonFeed struct {
+	Version     string     `json:"version"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	HomePageURL string     `json:"home_page_url"`
+	FeedURL     string     `json:"feed_url"`
+	Language    string     `json:"language,omitempty"`
+	Items       []jsonItem `json:"items"`
+}
+
+type jsonItem struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	ContentText   string   `json:"content_text"`
+	Summary       string   `json:"summary,omitempty"`
+	Image         string   `json:"image,omitempty"`
+	DatePublished string   `json:"date_published"`
+	DateModified  string   `json:"date_modified"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+// JSON สร้างเอกสาร JSON Feed 1.1
+func JSON(f Feed) ([]byte, error) {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		Description: f.Description,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Language:    f.Language,
+		Items:       []jsonItem{},
+	}
+
+	for _, item := range f.Items {
+		doc.Items = append(doc.Items, jsonItem{
+			ID:            item.ID,
+			URL:           item.Link,
+			Title:         item.Title,
+			ContentText:   item.Content,
+			Summary:       item.Summary,
+			Image:         item.Image,
+			DatePublished: item.Published.UTC().Format(time.RFC3339),
+			DateModified:  item.Updated.UTC().Format(time.RFC3339),
+			Tags:          item.Categories,
+		})
+	}
+
+	return json.Marshal(doc)
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
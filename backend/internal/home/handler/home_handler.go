@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"cpsu/internal/home/service"
+	"cpsu/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HomeHandler struct {
+	homeService service.HomeService
+}
+
+func NewHomeHandler(homeService service.HomeService) *HomeHandler {
+	return &HomeHandler{homeService: homeService}
+}
+
+func (h *HomeHandler) GetHome(c *gin.Context) {
+	home, err := h.homeService.GetHome()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get home"})
+		return
+	}
+
+	for i := range home.Pinned {
+		i18n.Apply(c, &home.Pinned[i])
+	}
+	for i := range home.Featured {
+		i18n.Apply(c, &home.Featured[i])
+	}
+	for i := range home.Events {
+		i18n.Apply(c, &home.Events[i])
+	}
+	for i := range home.Admissions {
+		i18n.Apply(c, &home.Admissions[i])
+	}
+	c.JSON(http.StatusOK, home)
+}
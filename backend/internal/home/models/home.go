@@ -0,0 +1,15 @@
+package models
+
+import (
+	admissionmodels "cpsu/internal/admission/models"
+	calendarmodels "cpsu/internal/calendar/models"
+	newsmodels "cpsu/internal/news/models"
+)
+
+// Home รวมข้อมูลที่หน้าแรกต้องใช้ไว้ใน response เดียว
+type Home struct {
+	Pinned     []newsmodels.News           `json:"pinned"`
+	Featured   []newsmodels.News           `json:"featured"`
+	Events     []calendarmodels.Calendar   `json:"events"`
+	Admissions []admissionmodels.Admission `json:"admissions"`
+}
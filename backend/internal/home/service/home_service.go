@@ -0,0 +1,86 @@
+package service
+
+import (
+	admissionmodels "cpsu/internal/admission/models"
+	admissionservice "cpsu/internal/admission/service"
+	calendarmodels "cpsu/internal/calendar/models"
+	calendarservice "cpsu/internal/calendar/service"
+	"cpsu/internal/home/models"
+	newsmodels "cpsu/internal/news/models"
+	newsservice "cpsu/internal/news/service"
+	"cpsu/internal/pagination"
+)
+
+const (
+	highlightLimit = 10
+	eventLimit     = 5
+	admissionLimit = 3
+)
+
+type HomeService interface {
+	GetHome() (*models.Home, error)
+}
+
+type homeService struct {
+	newsService      newsservice.NewsService
+	calendarService  calendarservice.CalendarService
+	admissionService admissionservice.AdmissionService
+}
+
+func NewHomeService(
+	newsService newsservice.NewsService,
+	calendarService calendarservice.CalendarService,
+	admissionService admissionservice.AdmissionService,
+) HomeService {
+	return &homeService{
+		newsService:      newsService,
+		calendarService:  calendarService,
+		admissionService: admissionService,
+	}
+}
+
+func (s *homeService) GetHome() (*models.Home, error) {
+	pinned, err := s.newsService.GetAllNews(newsmodels.NewsQueryParam{
+		Params: pagination.Params{PageSize: highlightLimit},
+		Status: newsmodels.StatusPublished,
+		Pinned: true,
+		Sort:   "pin_slot",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	featured, err := s.newsService.GetAllNews(newsmodels.NewsQueryParam{
+		Params:   pagination.Params{PageSize: highlightLimit},
+		Status:   newsmodels.StatusPublished,
+		Featured: true,
+		Sort:     "feature_slot",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.calendarService.GetAllCalendars(calendarmodels.CalendarQueryParam{
+		Params:   pagination.Params{PageSize: eventLimit},
+		Upcoming: true,
+		Sort:     "start_date",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	admissions, err := s.admissionService.GetAllAdmission(admissionmodels.AdmissionQueryParam{
+		Params: pagination.Params{PageSize: admissionLimit},
+		Sort:   "-admission_id",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Home{
+		Pinned:     pinned.Items,
+		Featured:   featured.Items,
+		Events:     events.Items,
+		Admissions: admissions.Items,
+	}, nil
+}
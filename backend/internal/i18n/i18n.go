@@ -0,0 +1,153 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	Thai    = "th"
+	English = "en"
+	Default = Thai
+
+	contextKey = "lang"
+)
+
+// Languages คือภาษาที่เนื้อหาในระบบรองรับ เรียงตามลำดับที่ใช้ fallback
+var Languages = []string{Thai, English}
+
+func IsSupported(lang string) bool {
+	for _, l := range Languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// Negotiate เลือกภาษาจาก ?lang= ก่อน แล้วจึงดู Accept-Language ตามค่า q
+// แท็กแบบมีภูมิภาค เช่น en-US จะใช้เฉพาะส่วนภาษา ถ้าไม่ตรงกับภาษาที่รองรับเลยจะใช้ Default
+func Negotiate(query string, acceptLanguage string) string {
+	if lang := base(query); IsSupported(lang) {
+		return lang
+	}
+
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		lang := base(fields[0])
+		if lang == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{lang, q})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	for _, c := range candidates {
+		if IsSupported(c.lang) {
+			return c.lang
+		}
+	}
+	return Default
+}
+
+func base(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	return tag
+}
+
+// Middleware ใช้กับ route สาธารณะ เลือกภาษาแล้วเก็บไว้ใน context ให้ handler แปลงเนื้อหา
+// route ที่ไม่ได้ผ่าน middleware นี้ (หน้า admin) จะได้ข้อมูลทุกภาษาพร้อมรายการคำแปลที่ขาด
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := Negotiate(c.Query("lang"), c.GetHeader("Accept-Language"))
+		c.Set(contextKey, lang)
+		c.Header("Content-Language", lang)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// FromContext คืนภาษาที่ Middleware เลือกไว้ ok เป็น false เมื่อ route ไม่ได้ผ่าน Middleware
+func FromContext(c *gin.Context) (string, bool) {
+	lang := c.GetString(contextKey)
+	return lang, lang != ""
+}
+
+// Translatable คือ model ที่มีฟิลด์หลายภาษา
+type Translatable interface {
+	// Localize แทนที่ฟิลด์ด้วยข้อความในภาษาที่ขอ
+	Localize(lang string)
+	// CheckTranslations ตั้งรายการคำแปลที่ขาดให้หน้า admin
+	CheckTranslations()
+}
+
+// Apply แปลงเนื้อหาตามภาษาที่เลือกเมื่อเป็น route สาธารณะ ถ้าไม่ใช่จะรายงานคำแปลที่ขาดแทน
+func Apply(c *gin.Context, items ...Translatable) {
+	lang, ok := FromContext(c)
+	for _, item := range items {
+		if ok {
+			item.Localize(lang)
+		} else {
+			item.CheckTranslations()
+		}
+	}
+}
+
+// Pick คืนข้อความในภาษาที่ขอ ถ้าว่างจะใช้อีกภาษาแทน
+func Pick(lang string, th string, en string) string {
+	if lang == English {
+		if strings.TrimSpace(en) != "" {
+			return en
+		}
+		return th
+	}
+	if strings.TrimSpace(th) != "" {
+		return th
+	}
+	return en
+}
+
+// Field คือฟิลด์ที่แปลได้ ใช้ตรวจว่าภาษาไหนยังไม่มีข้อความ
+type Field struct {
+	Name string
+	TH   string
+	EN   string
+}
+
+// Missing คืนชื่อฟิลด์ที่มีข้อความเพียงภาษาเดียวในรูป "<field>.<lang>" เช่น "title.en"
+// ฟิลด์ที่ว่างทั้งสองภาษาไม่นับว่าขาดคำแปล
+func Missing(fields ...Field) []string {
+	missing := []string{}
+	for _, f := range fields {
+		th, en := strings.TrimSpace(f.TH) != "", strings.TrimSpace(f.EN) != ""
+		switch {
+		case th && !en:
+			missing = append(missing, f.Name+"."+English)
+		case en && !th:
+			missing = append(missing, f.Name+"."+Thai)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,76 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		acceptLanguage string
+		want           string
+	}{
+		{"empty", "", "", Default},
+		{"query", "en", "", English},
+		{"query wins over header", "en", "th", English},
+		{"query with region", "EN-us", "th", English},
+		{"unsupported query falls back to header", "fr", "en", English},
+		{"header", "", "en", English},
+		{"header with region", "", "en-US,en;q=0.9", English},
+		{"header q-values", "", "th;q=0.5,en;q=0.8", English},
+		{"header order for equal q", "", "en,th", English},
+		{"header q zero is ignored", "", "en;q=0,th;q=0.1", Thai},
+		{"unsupported header skipped", "", "fr,en;q=0.5", English},
+		{"unsupported only", "", "fr,de;q=0.8", Default},
+		{"malformed q keeps default weight", "", "en;q=abc", English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Negotiate(tt.query, tt.acceptLanguage); got != tt.want {
+				t.Errorf("Negotiate(%q, %q) = %q, want %q", tt.query, tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddlewareKeepsVaryOrigin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(cors.New(cors.Config{
+		AllowOrigins:     []string{"http://localhost:3000"},
+		AllowCredentials: true,
+	}))
+	r.Use(Middleware())
+	r.GET("/", func(c *gin.Context) {
+		lang, _ := FromContext(c)
+		c.String(http.StatusOK, lang)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/?lang=en", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	vary := w.Header().Values("Vary")
+	for _, want := range []string{"Origin", "Accept-Language"} {
+		found := false
+		for _, v := range vary {
+			if v == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Vary = %v, want it to contain %q", vary, want)
+		}
+	}
+	if got := w.Header().Get("Content-Language"); got != English {
+		t.Errorf("Content-Language = %q, want %q", got, English)
+	}
+}
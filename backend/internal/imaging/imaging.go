@@ -0,0 +1,253 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	_ "image/gif"
+
+	"github.com/HugoSmits86/nativewebp"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type, only jpeg, png, gif and webp are allowed")
+	ErrFileTooLarge    = errors.New("image file is too large")
+	ErrTooManyPixels   = errors.New("image dimensions are too large")
+)
+
+// Widths คือขนาดความกว้าง (px) ของ thumbnail ที่สร้างให้ทุกรูป
+var Widths = []int{150, 400, 1200}
+
+var allowedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+const (
+	FormatJPEG = "jpg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+
+	jpegQuality = 85
+
+	// maxPixels จำกัดขนาดภาพก่อน decode ไฟล์เล็กอาจประกาศขนาดภาพใหญ่มากจนกินหน่วยความจำ (decompression bomb)
+	maxPixels = 50_000_000
+)
+
+type Object struct {
+	Name        string
+	ContentType string
+	Width       int
+	Format      string
+	Data        []byte
+}
+
+type Processed struct {
+	Original Object
+	Variants []Object
+}
+
+func (p *Processed) Objects() []Object {
+	return append([]Object{p.Original}, p.Variants...)
+}
+
+type Pipeline struct {
+	maxSize int64
+}
+
+func NewPipeline(maxSize int64) *Pipeline {
+	return &Pipeline{maxSize: maxSize}
+}
+
+func (p *Pipeline) ProcessFile(fileHeader *multipart.FileHeader) (*Processed, error) {
+	if fileHeader == nil {
+		return nil, errors.New("file is nil")
+	}
+	if p.maxSize > 0 && fileHeader.Size > p.maxSize {
+		return nil, ErrFileTooLarge
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return p.Process(file)
+}
+
+// Process ตรวจชนิดไฟล์จาก magic bytes แล้ว decode/encode ใหม่ทั้งหมด
+// ทำให้ข้อมูล EXIF และ metadata อื่นๆ ถูกตัดออกไปด้วย
+func (p *Pipeline) Process(r io.Reader) (*Processed, error) {
+	if p.maxSize > 0 {
+		r = io.LimitReader(r, p.maxSize+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if p.maxSize > 0 && int64(len(data)) > p.maxSize {
+		return nil, ErrFileTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	if !allowedTypes[contentType] {
+		return nil, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || int64(config.Width)*int64(config.Height) > maxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+
+	if contentType == "image/jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	result := &Processed{}
+
+	// เก็บต้นฉบับเป็น jpeg ถ้าต้นทางเป็น jpeg นอกนั้นเก็บเป็น png เพื่อไม่ให้เสีย alpha
+	originalFormat := FormatPNG
+	if contentType == "image/jpeg" {
+		originalFormat = FormatJPEG
+	}
+	original, err := encode(img, originalFormat)
+	if err != nil {
+		return nil, err
+	}
+	// ชื่อต้นฉบับมีความกว้างของรูป ให้ VariantURLs รู้ความกว้างจริงของ thumbnail จาก URL
+	result.Original = Object{
+		Name:        fmt.Sprintf("original.%d.%s", img.Bounds().Dx(), originalFormat),
+		ContentType: contentTypeOf(originalFormat),
+		Width:       img.Bounds().Dx(),
+		Format:      originalFormat,
+		Data:        original,
+	}
+
+	for _, width := range Widths {
+		resized := resize(img, width)
+		for _, format := range []string{FormatWebP, FormatJPEG} {
+			encoded, err := encode(resized, format)
+			if err != nil {
+				return nil, err
+			}
+			result.Variants = append(result.Variants, Object{
+				Name:        fmt.Sprintf("%d.%s", width, format),
+				ContentType: contentTypeOf(format),
+				Width:       resized.Bounds().Dx(),
+				Format:      format,
+				Data:        encoded,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// resize ย่อรูปให้กว้างเท่ากับ width โดยรักษาสัดส่วนเดิม และไม่ขยายรูปที่เล็กกว่า
+func resize(src image.Image, width int) image.Image {
+	b := src.Bounds()
+	if b.Dx() <= width {
+		return src
+	}
+
+	height := b.Dy() * width / b.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+	return dst
+}
+
+func encode(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+
+	switch format {
+	case FormatJPEG:
+		err = jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: jpegQuality})
+	case FormatPNG:
+		err = png.Encode(&buf, img)
+	case FormatWebP:
+		err = nativewebp.Encode(&buf, unpalette(img), nil)
+	default:
+		err = fmt.Errorf("unknown image format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// jpeg ไม่รองรับ alpha จึงวางรูปบนพื้นขาวก่อน encode
+func flatten(img image.Image) image.Image {
+	if op, ok := img.(interface{ Opaque() bool }); ok && op.Opaque() {
+		return img
+	}
+
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+	return dst
+}
+
+// nativewebp เข้ารหัส *image.Paletted (เช่น gif ที่เล็กกว่า thumbnail จึงไม่ได้ย่อ) เป็นไฟล์ที่ decoder อ่านไม่ได้
+// จึงแปลงเป็น NRGBA ก่อน
+func unpalette(img image.Image) image.Image {
+	if _, ok := img.(*image.Paletted); !ok {
+		return img
+	}
+
+	b := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+func contentTypeOf(format string) string {
+	switch format {
+	case FormatJPEG:
+		return "image/jpeg"
+	case FormatPNG:
+		return "image/png"
+	case FormatWebP:
+		return "image/webp"
+	}
+	return "application/octet-stream"
+}
+
+// HTTPStatus คืน status code สำหรับ error ที่มาจาก pipeline ถ้าไม่ใช่จะคืนค่า 0
+func HTTPStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrFileTooLarge), errors.Is(err, ErrTooManyPixels):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType
+	}
+	return 0
+}
@@ -0,0 +1,336 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+func solid(w, h int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: c}, image.Point{}, draw.Src)
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pngWithSize แก้ขนาดภาพใน IHDR ของไฟล์ png ที่ถูกต้อง ใช้จำลองไฟล์เล็กที่ประกาศขนาดภาพใหญ่
+func pngWithSize(t *testing.T, width, height uint32) []byte {
+	t.Helper()
+	data := encodePNG(t, solid(1, 1, color.White))
+	// signature 8 byte, length 4 byte, "IHDR" 4 byte แล้วจึงเป็น width และ height
+	ihdr := data[12 : 12+4+13]
+	binary.BigEndian.PutUint32(ihdr[4:8], width)
+	binary.BigEndian.PutUint32(ihdr[8:12], height)
+	binary.BigEndian.PutUint32(data[12+4+13:], crc32.ChecksumIEEE(ihdr))
+	return data
+}
+
+// withExif แทรก APP1 ที่มี EXIF Orientation ต่อจาก SOI ของไฟล์ jpeg
+func withExif(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = append(tiff, 0x00, 0x02) // จำนวน entry
+	tiff = append(tiff, 0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, byte(orientation>>8), byte(orientation), 0x00, 0x00)
+	// Artist (0x013B) ใช้ตรวจว่า metadata ถูกตัดออก
+	tiff = append(tiff, 0x01, 0x3b, 0x00, 0x02, 0x00, 0x00, 0x00, 0x04, 'G', 'P', 'S', 0x00)
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x00)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func TestProcessRejectsByMagicBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"text", []byte("hello, this is not an image")},
+		{"html", []byte("<html><body><img src=x onerror=alert(1)></body></html>")},
+		{"pdf", []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")},
+		{"bmp", append([]byte("BM"), make([]byte, 64)...)},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`)},
+		{"truncated png", encodePNG(t, solid(8, 8, color.White))[:40]},
+		{"png magic only", []byte("\x89PNG\r\n\x1a\n")},
+	}
+
+	p := NewPipeline(0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := p.Process(bytes.NewReader(tt.data))
+			if !errors.Is(err, ErrUnsupportedType) {
+				t.Fatalf("Process error = %v, want ErrUnsupportedType", err)
+			}
+			if got := HTTPStatus(err); got != http.StatusUnsupportedMediaType {
+				t.Errorf("HTTPStatus = %d, want %d", got, http.StatusUnsupportedMediaType)
+			}
+		})
+	}
+}
+
+func TestProcessLimits(t *testing.T) {
+	small := encodePNG(t, solid(8, 8, color.Black))
+
+	tests := []struct {
+		name    string
+		maxSize int64
+		data    []byte
+		want    error
+	}{
+		{"over pixel cap", 0, pngWithSize(t, 10_000, 5_001), ErrTooManyPixels},
+		{"huge width", 0, pngWithSize(t, 1<<30, 1), ErrTooManyPixels},
+		{"over file size", int64(len(small)) - 1, small, ErrFileTooLarge},
+		// ไฟล์ใหญ่กว่า maxSize ต้องถูกปฏิเสธก่อนตรวจชนิดไฟล์
+		{"over file size before type check", 10, []byte("this is not an image"), ErrFileTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPipeline(tt.maxSize).Process(bytes.NewReader(tt.data))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Process error = %v, want %v", err, tt.want)
+			}
+			if got := HTTPStatus(err); got != http.StatusRequestEntityTooLarge {
+				t.Errorf("HTTPStatus = %d, want %d", got, http.StatusRequestEntityTooLarge)
+			}
+		})
+	}
+
+	// ภาพที่ขนาดพอดี 50 ล้านพิกเซลต้องผ่านขั้นตรวจขนาด (แล้วจึงล้มเพราะข้อมูลภาพไม่ครบ)
+	_, err := NewPipeline(0).Process(bytes.NewReader(pngWithSize(t, 10_000, 5_000)))
+	if errors.Is(err, ErrTooManyPixels) {
+		t.Errorf("Process at exactly %d pixels = %v, want it to pass the pixel check", maxPixels, err)
+	}
+}
+
+func TestProcessOrientation(t *testing.T) {
+	// ภาพกว้าง 64 สูง 32 มุมล่างซ้ายเป็นสีแดง
+	src := solid(64, 32, color.NRGBA{0, 0, 255, 255})
+	draw.Draw(src, image.Rect(0, 16, 16, 32), &image.Uniform{C: color.NRGBA{255, 0, 0, 255}}, image.Point{}, draw.Src)
+
+	tests := []struct {
+		orientation uint16
+		width       int
+		height      int
+		red         image.Point
+	}{
+		{1, 64, 32, image.Pt(4, 28)},
+		{3, 64, 32, image.Pt(60, 4)},
+		{6, 32, 64, image.Pt(4, 4)},
+		{8, 32, 64, image.Pt(28, 60)},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("orientation %d", tt.orientation), func(t *testing.T) {
+			data := withExif(encodeJPEG(t, src), tt.orientation)
+			if got := jpegOrientation(data); got != int(tt.orientation) {
+				t.Fatalf("jpegOrientation = %d, want %d", got, tt.orientation)
+			}
+
+			processed, err := NewPipeline(0).Process(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("Process error: %v", err)
+			}
+			if name := fmt.Sprintf("original.%d.jpg", tt.width); processed.Original.Format != FormatJPEG || processed.Original.Name != name {
+				t.Errorf("original = %s (%s), want %s", processed.Original.Name, processed.Original.Format, name)
+			}
+			if bytes.Contains(processed.Original.Data, []byte("Exif")) || bytes.Contains(processed.Original.Data, []byte("GPS")) {
+				t.Error("original still contains EXIF metadata")
+			}
+
+			img, err := jpeg.Decode(bytes.NewReader(processed.Original.Data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := img.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+				t.Fatalf("size = %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.width, tt.height)
+			}
+			r, g, b, _ := img.At(tt.red.X, tt.red.Y).RGBA()
+			if r>>8 < 200 || g>>8 > 60 || b>>8 > 60 {
+				t.Errorf("pixel at %v = (%d, %d, %d), want red", tt.red, r>>8, g>>8, b>>8)
+			}
+		})
+	}
+}
+
+func TestJPEGOrientationMalformed(t *testing.T) {
+	plain := encodeJPEG(t, solid(4, 4, color.White))
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"no exif", plain},
+		{"not jpeg", encodePNG(t, solid(4, 4, color.White))},
+		{"out of range", withExif(plain, 9)},
+		{"truncated segment", withExif(plain, 6)[:20]},
+	}
+
+	for _, tt := range tests {
+		if got := jpegOrientation(tt.data); got != 1 {
+			t.Errorf("%s: jpegOrientation = %d, want 1", tt.name, got)
+		}
+	}
+}
+
+func TestProcessVariants(t *testing.T) {
+	var gifBuf bytes.Buffer
+	if err := gif.Encode(&gifBuf, solid(300, 100, color.White), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		data           []byte
+		originalFormat string
+		originalWidth  int
+		wantWidths     []int
+	}{
+		{"large png", encodePNG(t, solid(2000, 1000, color.NRGBA{10, 20, 30, 128})), FormatPNG, 2000, []int{150, 400, 1200}},
+		{"large jpeg", encodeJPEG(t, solid(1600, 900, color.White)), FormatJPEG, 1600, []int{150, 400, 1200}},
+		// รูปที่เล็กกว่าขนาดของ thumbnail จะไม่ถูกขยาย
+		{"small gif", gifBuf.Bytes(), FormatPNG, 300, []int{150, 300, 300}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processed, err := NewPipeline(0).Process(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("Process error: %v", err)
+			}
+			if processed.Original.Format != tt.originalFormat || processed.Original.Width != tt.originalWidth {
+				t.Errorf("original = %s %dpx, want %s %dpx",
+					processed.Original.Format, processed.Original.Width, tt.originalFormat, tt.originalWidth)
+			}
+
+			var names []string
+			for _, v := range processed.Variants {
+				names = append(names, v.Name)
+			}
+			wantNames := []string{"150.webp", "150.jpg", "400.webp", "400.jpg", "1200.webp", "1200.jpg"}
+			if len(names) != len(wantNames) {
+				t.Fatalf("variants = %v, want %v", names, wantNames)
+			}
+
+			for i, v := range processed.Variants {
+				if v.Name != wantNames[i] {
+					t.Errorf("variant %d = %s, want %s", i, v.Name, wantNames[i])
+				}
+				width := tt.wantWidths[i/2]
+
+				var img image.Image
+				switch v.Format {
+				case FormatWebP:
+					img, err = webp.Decode(bytes.NewReader(v.Data))
+				case FormatJPEG:
+					img, err = jpeg.Decode(bytes.NewReader(v.Data))
+				default:
+					t.Fatalf("variant %s format = %s", v.Name, v.Format)
+				}
+				if err != nil {
+					t.Fatalf("decode %s: %v", v.Name, err)
+				}
+				if http.DetectContentType(v.Data) != v.ContentType {
+					t.Errorf("%s content type = %s, data is %s", v.Name, v.ContentType, http.DetectContentType(v.Data))
+				}
+				if img.Bounds().Dx() != width || v.Width != width {
+					t.Errorf("%s width = %d (reported %d), want %d", v.Name, img.Bounds().Dx(), v.Width, width)
+				}
+			}
+		})
+	}
+}
+
+func TestObjectKeys(t *testing.T) {
+	keys := ObjectKeys("news/abc/original.640.jpg")
+	want := []string{
+		"news/abc/original.640.jpg",
+		"news/abc/150.webp", "news/abc/150.jpg",
+		"news/abc/400.webp", "news/abc/400.jpg",
+		"news/abc/1200.webp", "news/abc/1200.jpg",
+	}
+	if len(keys) != len(want) {
+		t.Fatalf("ObjectKeys = %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("ObjectKeys[%d] = %s, want %s", i, keys[i], want[i])
+		}
+	}
+
+	if keys := ObjectKeys("news/legacy.jpg"); len(keys) != 1 || keys[0] != "news/legacy.jpg" {
+		t.Errorf("ObjectKeys(legacy) = %v", keys)
+	}
+}
+
+func TestVariantURLs(t *testing.T) {
+	// ย่อเป็น "ความกว้าง:ชื่อไฟล์" ของ thumbnail webp ส่วน jpg ต้องตามมาในขนาดเดียวกันเสมอ
+	tests := []struct {
+		name string
+		url  string
+		want []string
+	}{
+		{"legacy", "http://cdn/news/legacy.jpg", nil},
+		{"empty", "", nil},
+		{"large", "http://cdn/news/abc/original.2000.png", []string{"150:150", "400:400", "1200:1200"}},
+		{"between sizes", "http://cdn/news/abc/original.500.jpg", []string{"150:150", "400:400", "500:1200"}},
+		{"exactly a size", "http://cdn/news/abc/original.400.jpg", []string{"150:150", "400:400"}},
+		{"smaller than every size", "http://cdn/news/abc/original.100.png", []string{"100:150"}},
+		{"width not recorded", "http://cdn/news/abc/original.png", []string{"150:150", "400:400", "1200:1200"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls := VariantURLs(tt.url)
+			var got []string
+			for i, v := range urls {
+				if i%2 == 1 {
+					if first := urls[i-1]; v.Format != FormatJPEG || v.Width != first.Width || strings.TrimSuffix(v.URL, ".jpg") != strings.TrimSuffix(first.URL, ".webp") {
+						t.Errorf("variant %d = %+v, want the jpg of %+v", i, v, first)
+					}
+					continue
+				}
+				got = append(got, fmt.Sprintf("%d:%s", v.Width, strings.TrimSuffix(path.Base(v.URL), ".webp")))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("VariantURLs(%q) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"path"
+	"strings"
+
+	"cpsu/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// Upload ส่งรูปเข้า pipeline แล้วเก็บต้นฉบับและ thumbnail ทุกขนาด
+// ไว้ใต้ <folder>/<uuid>/ คืนค่า URL ของรูปต้นฉบับ
+// ถ้าเก็บไฟล์ใดไม่สำเร็จ ไฟล์ที่เก็บไปแล้วในชุดเดียวกันจะถูกลบออก
+func (p *Pipeline) Upload(ctx context.Context, store storage.Storage, folder string, fileHeader *multipart.FileHeader) (string, error) {
+	processed, err := p.ProcessFile(fileHeader)
+	if err != nil {
+		return "", err
+	}
+
+	dir := fmt.Sprintf("%s/%s", folder, uuid.New().String())
+	var written []string
+	for _, obj := range processed.Objects() {
+		key := dir + "/" + obj.Name
+		err = store.Put(ctx, key, bytes.NewReader(obj.Data), int64(len(obj.Data)), obj.ContentType)
+		if err != nil {
+			RemoveUnused(store, written...)
+			return "", err
+		}
+		written = append(written, store.URL(key))
+	}
+
+	return store.URL(dir + "/" + processed.Original.Name), nil
+}
+
+// ObjectKeys คืน key ของต้นฉบับและ thumbnail ทุกขนาดที่อยู่ในชุดเดียวกัน
+// ถ้า key ไม่ได้มาจาก pipeline จะคืนค่า key นั้นอย่างเดียว
+func ObjectKeys(key string) []string {
+	if !strings.HasPrefix(path.Base(key), "original.") {
+		return []string{key}
+	}
+
+	dir := path.Dir(key)
+	keys := []string{key}
+	for _, width := range Widths {
+		for _, format := range []string{FormatWebP, FormatJPEG} {
+			keys = append(keys, fmt.Sprintf("%s/%d.%s", dir, width, format))
+		}
+	}
+	return keys
+}
+
+// Remove ลบรูปที่อ้างอิงด้วย URL พร้อม thumbnail ทุกขนาด
+func Remove(ctx context.Context, store storage.Storage, url string) error {
+	key, ok := store.Key(url)
+	if !ok {
+		return nil
+	}
+
+	for _, k := range ObjectKeys(key) {
+		if err := store.Delete(ctx, k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveUnused ลบไฟล์ที่ไม่ถูกอ้างอิงแล้ว URL ว่างจะถูกข้าม
+// ถ้าลบไม่สำเร็จจะบันทึก log แล้วเหลือให้คำสั่ง storage-reconcile เก็บกวาดภายหลัง
+func RemoveUnused(store storage.Storage, urls ...string) {
+	for _, url := range urls {
+		if url == "" {
+			continue
+		}
+		if err := Remove(context.Background(), store, url); err != nil {
+			log.Printf("remove object %s failed: %v", url, err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"mime/multipart"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+
+	"cpsu/internal/storage"
+)
+
+// fileHeader สร้าง *multipart.FileHeader จากข้อมูลไฟล์ เหมือนที่ handler ได้จาก form
+func fileHeader(t *testing.T, name string, data []byte) *multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	w.Close()
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["file"][0]
+}
+
+// failingStore เก็บไฟล์ตามปกติจนกว่าจะเจอ key ที่ลงท้ายด้วย failOn
+type failingStore struct {
+	storage.Storage
+	failOn string
+}
+
+var errPutFailed = errors.New("put failed")
+
+func (s *failingStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if strings.HasSuffix(key, s.failOn) {
+		return errPutFailed
+	}
+	return s.Storage.Put(ctx, key, r, size, contentType)
+}
+
+func listKeys(t *testing.T, store storage.Storage) []string {
+	t.Helper()
+	objects, err := store.List(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []string{}
+	for _, obj := range objects {
+		keys = append(keys, obj.Key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestUpload(t *testing.T) {
+	store, err := storage.NewLocalStorage(t.TempDir(), "http://files.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url, err := NewPipeline(0).Upload(context.Background(), store, "news", fileHeader(t, "a.png", encodePNG(t, solid(500, 250, color.White))))
+	if err != nil {
+		t.Fatalf("Upload error: %v", err)
+	}
+
+	key, ok := store.Key(url)
+	if !ok || !strings.HasPrefix(key, "news/") || path.Base(key) != "original.500.png" {
+		t.Fatalf("Upload URL = %s, want http://files.test/news/<uuid>/original.500.png", url)
+	}
+
+	want := ObjectKeys(key)
+	sort.Strings(want)
+	if got := listKeys(t, store); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("stored = %v, want %v", got, want)
+	}
+
+	// ความกว้างที่ VariantURLs บอกต้องตรงกับไฟล์ที่เก็บจริง รูปกว้าง 500 จึงไม่มี thumbnail กว้าง 1200
+	widths := []int{}
+	for _, v := range VariantURLs(url) {
+		variantKey, _ := store.Key(v.URL)
+		r, err := store.Get(context.Background(), variantKey)
+		if err != nil {
+			t.Fatalf("variant %s: %v", v.URL, err)
+		}
+		config, _, err := image.DecodeConfig(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("variant %s: %v", v.URL, err)
+		}
+		if config.Width != v.Width {
+			t.Errorf("variant %s width = %d, reported %d", v.URL, config.Width, v.Width)
+		}
+		if v.Format == FormatWebP {
+			widths = append(widths, v.Width)
+		}
+	}
+	if fmt.Sprint(widths) != "[150 400 500]" {
+		t.Errorf("variant widths = %v, want [150 400 500]", widths)
+	}
+}
+
+func TestUploadRemovesWrittenObjectsOnFailure(t *testing.T) {
+	for _, failOn := range []string{"original.500.png", "150.webp", "400.jpg", "1200.jpg"} {
+		t.Run(failOn, func(t *testing.T) {
+			local, err := storage.NewLocalStorage(t.TempDir(), "http://files.test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			store := &failingStore{Storage: local, failOn: failOn}
+
+			url, err := NewPipeline(0).Upload(context.Background(), store, "news", fileHeader(t, "a.png", encodePNG(t, solid(500, 250, color.White))))
+			if !errors.Is(err, errPutFailed) || url != "" {
+				t.Fatalf("Upload = %q, %v; want errPutFailed", url, err)
+			}
+			if keys := listKeys(t, local); len(keys) != 0 {
+				t.Errorf("objects left after failed upload: %v", keys)
+			}
+		})
+	}
+}
+
+func TestUploadRejectsBeforeStoring(t *testing.T) {
+	store, err := storage.NewLocalStorage(t.TempDir(), "http://files.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewPipeline(0).Upload(context.Background(), store, "news", fileHeader(t, "a.jpg", []byte("not really a jpeg")))
+	if !errors.Is(err, ErrUnsupportedType) {
+		t.Fatalf("Upload error = %v, want ErrUnsupportedType", err)
+	}
+	if keys := listKeys(t, store); len(keys) != 0 {
+		t.Errorf("objects stored for a rejected file: %v", keys)
+	}
+}
@@ -0,0 +1,52 @@
+package imaging
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+type VariantURL struct {
+	Width  int    `json:"width"`
+	Format string `json:"format"`
+	URL    string `json:"url"`
+}
+
+// VariantURLs คืน URL ของ thumbnail จาก URL ของรูปต้นฉบับ พร้อมความกว้างจริงของแต่ละไฟล์
+// รูปที่แคบกว่าขนาดใดจะไม่ถูกขยาย thumbnail ขนาดที่ใหญ่กว่านั้นเป็นรูปเดียวกันจึงไม่คืนซ้ำ
+// ต้นฉบับชื่อ original.<ext> ที่อัปโหลดก่อนเก็บความกว้างไว้ในชื่อ จะคืนความกว้างตามชื่อ thumbnail
+// รูปเก่าที่ไม่ได้อัปโหลดผ่าน pipeline (ไม่ได้ชื่อ original.*) จะคืนค่า nil
+func VariantURLs(originalURL string) []VariantURL {
+	name := path.Base(originalURL)
+	if originalURL == "" || !strings.HasPrefix(name, "original.") {
+		return nil
+	}
+
+	dir := originalURL[:strings.LastIndex(originalURL, "/")]
+
+	originalWidth := 0
+	if parts := strings.Split(name, "."); len(parts) == 3 {
+		originalWidth, _ = strconv.Atoi(parts[1])
+	}
+
+	variants := make([]VariantURL, 0, len(Widths)*2)
+	for _, width := range Widths {
+		actual := width
+		if originalWidth > 0 && originalWidth < width {
+			actual = originalWidth
+		}
+		for _, format := range []string{FormatWebP, FormatJPEG} {
+			variants = append(variants, VariantURL{
+				Width:  actual,
+				Format: format,
+				URL:    fmt.Sprintf("%s/%d.%s", dir, width, format),
+			})
+		}
+		if originalWidth > 0 && originalWidth <= width {
+			break
+		}
+	}
+
+	return variants
+}
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cpsu/internal/feed"
+	"cpsu/internal/i18n"
+	"cpsu/internal/news/models"
+	"cpsu/internal/news/service"
+	"cpsu/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	feedSize      = 20
+	summaryLength = 200
+)
+
+// ชื่อและคำอธิบาย feed แยกตามภาษา
+var (
+	feedTitles = map[string]string{
+		i18n.Thai:    "ข่าวสาร ภาควิชาคอมพิวเตอร์ มหาวิทยาลัยศิลปากร",
+		i18n.English: "News - Department of Computing, Silpakorn University",
+	}
+	feedDescriptions = map[string]string{
+		i18n.Thai:    "ข่าวประชาสัมพันธ์ ทุนการศึกษา รางวัล และกิจกรรมของภาควิชาคอมพิวเตอร์",
+		i18n.English: "Announcements, scholarships, awards and activities of the Department of Computing",
+	}
+)
+
+// NewsFeedHandler เสิร์ฟข่าวที่เผยแพร่แล้วเป็น RSS, Atom และ JSON Feed
+// กรองได้ด้วยพารามิเตอร์เดียวกับ /api/v1/news เช่น ?type=scholarship หรือ ?tag=
+type NewsFeedHandler struct {
+	newsService  service.NewsService
+	siteURL      string
+	imageBaseURL string
+}
+
+func NewNewsFeedHandler(newsService service.NewsService, siteURL string, imageBaseURL string) *NewsFeedHandler {
+	return &NewsFeedHandler{
+		newsService:  newsService,
+		siteURL:      strings.TrimRight(siteURL, "/"),
+		imageBaseURL: imageBaseURL,
+	}
+}
+
+func (h *NewsFeedHandler) RSS(c *gin.Context) {
+	h.serve(c, "rss", feed.ContentTypeRSS, feed.RSS)
+}
+
+func (h *NewsFeedHandler) Atom(c *gin.Context) {
+	h.serve(c, "atom", feed.ContentTypeAtom, feed.Atom)
+}
+
+func (h *NewsFeedHandler) JSON(c *gin.Context) {
+	h.serve(c, "json", feed.ContentTypeJSON, feed.JSON)
+}
+
+func (h *NewsFeedHandler) serve(c *gin.Context, format string, contentType string, render func(feed.Feed) ([]byte, error)) {
+	var param models.NewsQueryParam
+	if err := c.BindQuery(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameter"})
+		return
+	}
+
+	// feed แสดงข่าวล่าสุดเสมอ ไม่รองรับการแบ่งหน้าหรือเปลี่ยนลำดับ
+	param.Status = models.StatusPublished
+	param.Sort = "-publish_at"
+	param.Order = ""
+	param.Params = pagination.Params{PageSize: param.PageSize}
+	if param.PageSize <= 0 {
+		param.PageSize = feedSize
+	}
+
+	news, err := h.newsService.GetAllNews(param)
+	if err != nil {
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get news"})
+		}
+		return
+	}
+
+	lang, ok := i18n.FromContext(c)
+	if !ok {
+		lang = i18n.Default
+	}
+
+	title, err := h.title(param, lang)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get news types"})
+		return
+	}
+
+	f := feed.Feed{
+		Title:       title,
+		Description: feedDescriptions[lang],
+		Link:        h.siteURL + "/news",
+		FeedURL:     requestURL(c),
+		Language:    lang,
+	}
+	for _, n := range news.Items {
+		n.Localize(lang)
+		item := h.item(n)
+		if item.Updated.After(f.Updated) {
+			f.Updated = item.Updated
+		}
+		f.Items = append(f.Items, item)
+	}
+
+	etag := feed.ETag(format, f)
+	lastModified := f.Updated.UTC().Truncate(time.Second)
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=300")
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	body, err := render(f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render feed"})
+		return
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// title ต่อชื่อหมวดหมู่ท้ายชื่อ feed เมื่อกรองตามหมวดหมู่
+func (h *NewsFeedHandler) title(param models.NewsQueryParam, lang string) (string, error) {
+	feedTitle := feedTitles[lang]
+	if param.Type == "" && param.TypeID <= 0 {
+		return feedTitle, nil
+	}
+
+	types, err := h.newsService.GetNewsTypes()
+	if err != nil {
+		return "", err
+	}
+	for _, t := range types {
+		if t.Slug == param.Type || t.TypeID == param.TypeID {
+			return feedTitle + " - " + t.TypeName, nil
+		}
+	}
+	return feedTitle, nil
+}
+
+func (h *NewsFeedHandler) item(n models.News) feed.Item {
+	published := n.CreatedAt
+	if n.PublishAt != nil {
+		published = *n.PublishAt
+	}
+	updated := n.UpdatedAt
+	if published.After(updated) {
+		updated = published
+	}
+
+	categories := []string{}
+	if n.TypeName != "" {
+		categories = append(categories, n.TypeName)
+	}
+	for _, tag := range n.Tags {
+		categories = append(categories, tag.Name)
+	}
+
+	link := h.siteURL + "/news/" + strconv.Itoa(n.NewsID)
+
+	return feed.Item{
+		ID:         link,
+		Title:      n.Title,
+		Link:       link,
+		Summary:    summarize(n.Content, summaryLength),
+		Content:    n.Content,
+		Image:      feed.AbsoluteURL(h.imageBaseURL, n.CoverImage),
+		Categories: categories,
+		Published:  published,
+		Updated:    updated,
+	}
+}
+
+// notModified ตรวจ If-None-Match ก่อน ถ้าไม่มีจึงใช้ If-Modified-Since
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if since := r.Header.Get("If-Modified-Since"); since != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(since)
+		return err == nil && !lastModified.After(t)
+	}
+	return false
+}
+
+func requestURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host + c.Request.URL.RequestURI()
+}
+
+func summarize(content string, length int) string {
+	runes := []rune(strings.Join(strings.Fields(content), " "))
+	if len(runes) <= length {
+		return string(runes)
+	}
+	return string(runes[:length]) + "…"
+}
@@ -6,9 +6,13 @@ import (
 	"mime/multipart"
 	"net/http"
 	"strconv"
+	"time"
 
+	"cpsu/internal/i18n"
+	"cpsu/internal/imaging"
 	"cpsu/internal/news/models"
 	"cpsu/internal/news/service"
+	"cpsu/internal/pagination"
 
 	"cpsu/internal/auth/repository"
 
@@ -31,15 +35,37 @@ func (h *NewsHandler) GetAllNews(c *gin.Context) {
 		return
 	}
 
+	h.getAllNews(c, param)
+}
+
+// GetPublicNews แสดงเฉพาะข่าวที่เผยแพร่แล้ว
+func (h *NewsHandler) GetPublicNews(c *gin.Context) {
+	var param models.NewsQueryParam
+	if err := c.BindQuery(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameter"})
+		return
+	}
+
+	param.Status = models.StatusPublished
+	param.PinnedFirst = true
+	h.getAllNews(c, param)
+}
+
+func (h *NewsHandler) getAllNews(c *gin.Context, param models.NewsQueryParam) {
 	newsList, err := h.newsService.GetAllNews(param)
 	if err != nil {
-		if err.Error() == "news type not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+		} else if errors.Is(err, service.ErrInvalidStatus) || errors.Is(err, service.ErrInvalidHighlight) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get news"})
 		}
 		return
 	}
+	for i := range newsList.Items {
+		i18n.Apply(c, &newsList.Items[i])
+	}
 	c.JSON(http.StatusOK, newsList)
 }
 
@@ -60,9 +86,43 @@ func (h *NewsHandler) GetNewsByID(c *gin.Context) {
 		}
 		return
 	}
+	i18n.Apply(c, news)
 	c.JSON(http.StatusOK, news)
 }
 
+func (h *NewsHandler) GetPublicNewsByID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news ID"})
+		return
+	}
+
+	news, err := h.newsService.GetNewsByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "news ID not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if !isPublished(news) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "news ID not found"})
+		return
+	}
+	i18n.Apply(c, news)
+	c.JSON(http.StatusOK, news)
+}
+
+func isPublished(news *models.News) bool {
+	if news.Status != models.StatusPublished {
+		return false
+	}
+	return news.UnpublishAt == nil || news.UnpublishAt.After(time.Now())
+}
+
 func (h *NewsHandler) CreateNews(c *gin.Context) {
 	title := c.PostForm("title")
 	content := c.PostForm("content")
@@ -93,14 +153,19 @@ func (h *NewsHandler) CreateNews(c *gin.Context) {
 	userAgent := c.GetHeader("User-Agent")
 
 	created, err := h.newsService.CreateNews(
-		title, content, typeID, "", detailURL, coverImage, fileImages, userID, ip, userAgent,
+		title, content, c.PostForm("title_en"), c.PostForm("content_en"), typeID, "", detailURL, coverImage, fileImages, userID, ip, userAgent,
 	)
 
 	if err != nil {
+		if status := imaging.HTTPStatus(err); status != 0 {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	i18n.Apply(c, created)
 	c.JSON(http.StatusCreated, created)
 }
 
@@ -139,15 +204,36 @@ func (h *NewsHandler) UpdateNews(c *gin.Context) {
 	ip := c.ClientIP()
 	userAgent := c.GetHeader("User-Agent")
 
-	updated, err := h.newsService.UpdateNews(id, title, content, typeID, "", detailURL, coverImage, fileImages, userID, ip, userAgent)
+	titleEn := optionalPostForm(c, "title_en")
+	contentEn := optionalPostForm(c, "content_en")
+
+	updated, err := h.newsService.UpdateNews(id, title, content, titleEn, contentEn, typeID, "", detailURL, coverImage, fileImages, userID, ip, userAgent)
 	if err != nil {
+		if status := imaging.HTTPStatus(err); status != 0 {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "news ID not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	i18n.Apply(c, updated)
 	c.JSON(http.StatusOK, updated)
 }
 
+// optionalPostForm คืน nil เมื่อฟอร์มไม่มีฟิลด์นั้น เพื่อแยกจากการส่งค่าว่างมาเพื่อลบ
+func optionalPostForm(c *gin.Context, key string) *string {
+	value, ok := c.GetPostForm(key)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
 func (h *NewsHandler) DeleteNews(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
@@ -172,3 +258,49 @@ func (h *NewsHandler) DeleteNews(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "News deleted successfully"})
 }
+
+func (h *NewsHandler) ChangeNewsStatus(c *gin.Context) {
+	h.updateStatus(c, h.newsService.ChangeStatus)
+}
+
+func (h *NewsHandler) PublishNews(c *gin.Context) {
+	h.updateStatus(c, h.newsService.Publish)
+}
+
+type statusFunc func(id int, req models.NewsStatusRequest, userID int, ip string, userAgent string) (*models.News, error)
+
+func (h *NewsHandler) updateStatus(c *gin.Context, update statusFunc) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news ID"})
+		return
+	}
+
+	var req models.NewsStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	ip := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+
+	news, err := update(id, req, userID, ip, userAgent)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "news ID not found"})
+		case errors.Is(err, service.ErrStatusNeedsPublish):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidStatus), errors.Is(err, service.ErrInvalidPublishTime):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, news)
+}
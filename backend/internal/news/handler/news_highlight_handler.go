@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"cpsu/internal/news/models"
+	"cpsu/internal/news/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *NewsHandler) PinNews(c *gin.Context) {
+	h.setHighlight(c, models.HighlightPinned)
+}
+
+func (h *NewsHandler) UnpinNews(c *gin.Context) {
+	h.clearHighlight(c, models.HighlightPinned)
+}
+
+func (h *NewsHandler) FeatureNews(c *gin.Context) {
+	h.setHighlight(c, models.HighlightFeatured)
+}
+
+func (h *NewsHandler) UnfeatureNews(c *gin.Context) {
+	h.clearHighlight(c, models.HighlightFeatured)
+}
+
+func (h *NewsHandler) setHighlight(c *gin.Context, kind string) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news ID"})
+		return
+	}
+
+	// body ว่างได้ หมายถึงต่อท้าย slot สุดท้ายและไม่มีกำหนดเวลา
+	var req models.NewsHighlightRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	news, err := h.newsService.SetHighlight(id, kind, req, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		highlightErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, news)
+}
+
+func (h *NewsHandler) clearHighlight(c *gin.Context, kind string) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news ID"})
+		return
+	}
+
+	news, err := h.newsService.ClearHighlight(id, kind, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		highlightErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, news)
+}
+
+func (h *NewsHandler) ReorderHighlights(c *gin.Context) {
+	var req models.NewsHighlightOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.newsService.ReorderHighlights(req, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent")); err != nil {
+		highlightErrorResponse(c, err)
+		return
+	}
+
+	param := models.NewsQueryParam{Highlight: req.Kind, Sort: "pin_slot"}
+	if req.Kind == models.HighlightFeatured {
+		param.Sort = "feature_slot"
+	}
+	h.getAllNews(c, param)
+}
+
+func highlightErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "news ID not found"})
+	case errors.Is(err, service.ErrInvalidHighlight), errors.Is(err, service.ErrInvalidSlot),
+		errors.Is(err, service.ErrInvalidHighlightTime), errors.Is(err, service.ErrDuplicateNewsID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
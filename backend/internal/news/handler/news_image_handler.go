@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"cpsu/internal/imaging"
+	"cpsu/internal/news/models"
+	"cpsu/internal/news/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *NewsHandler) GetNewsImages(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news ID"})
+		return
+	}
+
+	images, err := h.newsService.GetNewsImages(id)
+	if err != nil {
+		imageErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, images)
+}
+
+// AddNewsImages รับไฟล์ในฟิลด์ images ได้หลายไฟล์ พร้อม caption และ alt_text (ถ้ามี)
+func (h *NewsHandler) AddNewsImages(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news ID"})
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to parse form"})
+		return
+	}
+
+	var fileImages []*multipart.FileHeader
+	if form != nil {
+		fileImages = form.File["images"]
+	}
+
+	images, err := h.newsService.AddNewsImages(
+		id, fileImages, c.PostForm("caption"), c.PostForm("alt_text"),
+		c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent"),
+	)
+	if err != nil {
+		imageErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, images)
+}
+
+func (h *NewsHandler) UpdateNewsImage(c *gin.Context) {
+	id, imageID, ok := imageParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.NewsImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	image, err := h.newsService.UpdateNewsImage(id, imageID, req, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		imageErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, image)
+}
+
+func (h *NewsHandler) DeleteNewsImage(c *gin.Context) {
+	id, imageID, ok := imageParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.newsService.DeleteNewsImage(id, imageID, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent")); err != nil {
+		imageErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "news image deleted successfully"})
+}
+
+func (h *NewsHandler) ReorderNewsImages(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news ID"})
+		return
+	}
+
+	var req models.NewsImageOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	images, err := h.newsService.ReorderNewsImages(id, req, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		imageErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, images)
+}
+
+func imageParams(c *gin.Context) (int, int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news ID"})
+		return 0, 0, false
+	}
+	imageID, err := strconv.Atoi(c.Param("image"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid image ID"})
+		return 0, 0, false
+	}
+	return id, imageID, true
+}
+
+func imageErrorResponse(c *gin.Context, err error) {
+	if status := imaging.HTTPStatus(err); status != 0 {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "news image not found"})
+	case errors.Is(err, service.ErrNoImages), errors.Is(err, service.ErrInvalidImageOrder):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"cpsu/internal/news/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *NewsHandler) GetRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news ID"})
+		return
+	}
+
+	revisions, err := h.newsService.GetRevisions(id)
+	if err != nil {
+		revisionErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, revisions)
+}
+
+func (h *NewsHandler) GetRevision(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news ID"})
+		return
+	}
+	revisionNo, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision"})
+		return
+	}
+
+	revision, err := h.newsService.GetRevision(id, revisionNo)
+	if err != nil {
+		revisionErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffRevisions เปรียบเทียบสอง revision ผ่าน ?from=&to=
+func (h *NewsHandler) DiffRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news ID"})
+		return
+	}
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from revision"})
+		return
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to revision"})
+		return
+	}
+
+	diff, err := h.newsService.DiffRevisions(id, from, to)
+	if err != nil {
+		revisionErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+func (h *NewsHandler) RollbackNews(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news ID"})
+		return
+	}
+	revisionNo, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	ip := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+
+	news, err := h.newsService.Rollback(id, revisionNo, userID, ip, userAgent)
+	if err != nil {
+		revisionErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, news)
+}
+
+func revisionErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "news ID not found"})
+	case errors.Is(err, service.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"cpsu/internal/news/models"
+	newsrepo "cpsu/internal/news/repository"
+	"cpsu/internal/news/service"
+	"cpsu/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *NewsHandler) GetNewsTypes(c *gin.Context) {
+	var param pagination.Params
+	if err := c.BindQuery(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameter"})
+		return
+	}
+
+	types, err := h.newsService.GetNewsTypes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get news types"})
+		return
+	}
+
+	page, err := pagination.Slice(types, param)
+	if err != nil {
+		body, _ := pagination.BadRequest(err)
+		c.JSON(http.StatusBadRequest, body)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *NewsHandler) CreateNewsType(c *gin.Context) {
+	var req models.NewsTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.newsService.CreateNewsType(req, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		taxonomyErrorResponse(c, err, "news type not found")
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+func (h *NewsHandler) UpdateNewsType(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news type ID"})
+		return
+	}
+
+	var req models.NewsTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.newsService.UpdateNewsType(id, req, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		taxonomyErrorResponse(c, err, "news type not found")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *NewsHandler) DeleteNewsType(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news type ID"})
+		return
+	}
+
+	if err := h.newsService.DeleteNewsType(id, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent")); err != nil {
+		taxonomyErrorResponse(c, err, "news type not found")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "news type deleted successfully"})
+}
+
+func (h *NewsHandler) ReorderNewsTypes(c *gin.Context) {
+	var req models.NewsTypeOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	types, err := h.newsService.ReorderNewsTypes(req, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		taxonomyErrorResponse(c, err, "news type not found")
+		return
+	}
+	c.JSON(http.StatusOK, types)
+}
+
+func (h *NewsHandler) GetNewsTags(c *gin.Context) {
+	var param pagination.Params
+	if err := c.BindQuery(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameter"})
+		return
+	}
+
+	tags, err := h.newsService.GetNewsTags()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get news tags"})
+		return
+	}
+
+	page, err := pagination.Slice(tags, param)
+	if err != nil {
+		body, _ := pagination.BadRequest(err)
+		c.JSON(http.StatusBadRequest, body)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *NewsHandler) CreateNewsTag(c *gin.Context) {
+	var req models.NewsTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.newsService.CreateNewsTag(req, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		taxonomyErrorResponse(c, err, "news tag not found")
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+func (h *NewsHandler) UpdateNewsTag(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news tag ID"})
+		return
+	}
+
+	var req models.NewsTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.newsService.UpdateNewsTag(id, req, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		taxonomyErrorResponse(c, err, "news tag not found")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *NewsHandler) DeleteNewsTag(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news tag ID"})
+		return
+	}
+
+	if err := h.newsService.DeleteNewsTag(id, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent")); err != nil {
+		taxonomyErrorResponse(c, err, "news tag not found")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "news tag deleted successfully"})
+}
+
+// SetNewsTags แทนที่แท็กทั้งหมดของข่าวด้วย tag_ids ที่ส่งมา
+func (h *NewsHandler) SetNewsTags(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid news ID"})
+		return
+	}
+
+	var req models.NewsTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	news, err := h.newsService.SetNewsTags(id, req, c.GetInt("user_id"), c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		taxonomyErrorResponse(c, err, "news ID not found")
+		return
+	}
+	c.JSON(http.StatusOK, news)
+}
+
+// GetNewsCounts คืนจำนวนข่าวที่เผยแพร่ในแต่ละหมวดหมู่และแท็ก
+func (h *NewsHandler) GetNewsCounts(c *gin.Context) {
+	counts, err := h.newsService.CountNews()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count news"})
+		return
+	}
+	c.JSON(http.StatusOK, counts)
+}
+
+func taxonomyErrorResponse(c *gin.Context, err error, notFound string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	case errors.Is(err, newsrepo.ErrDuplicateSlug), errors.Is(err, newsrepo.ErrNewsTypeInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidSlug), errors.Is(err, service.ErrInvalidTypeOrder),
+		errors.Is(err, newsrepo.ErrUnknownNewsTag):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,37 +1,98 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"cpsu/internal/i18n"
+	"cpsu/internal/imaging"
+	"cpsu/internal/pagination"
+)
 
 type News struct {
-	NewsID     int          `json:"news_id"`
-	Title      string       `json:"title"`
-	Content    string       `json:"content"`
-	TypeID     int          `json:"type_id"`
-	TypeName   string       `json:"type_name"`
-	DetailURL  string       `json:"detail_url"`
-	CoverImage string       `json:"cover_image"`
-	Images     []NewsImages `json:"images"`
-	CreatedAt  time.Time    `json:"created_at"`
-	UpdatedAt  time.Time    `json:"update_at"`
+	NewsID             int                  `json:"news_id"`
+	Title              string               `json:"title"`
+	Content            string               `json:"content"`
+	TitleEn            string               `json:"title_en"`
+	ContentEn          string               `json:"content_en"`
+	Lang               string               `json:"lang,omitempty"`
+	TypeID             int                  `json:"type_id"`
+	TypeName           string               `json:"type_name"`
+	TypeSlug           string               `json:"type_slug"`
+	Tags               []NewsTag            `json:"tags"`
+	DetailURL          string               `json:"detail_url"`
+	CoverImage         string               `json:"cover_image"`
+	CoverImageVariants []imaging.VariantURL `json:"cover_image_variants,omitempty"`
+	Images             []NewsImages         `json:"images"`
+	Status             string               `json:"status"`
+	PublishAt          *time.Time           `json:"publish_at"`
+	UnpublishAt        *time.Time           `json:"unpublish_at"`
+	Pinned             *NewsHighlight       `json:"pinned"`
+	Featured           *NewsHighlight       `json:"featured"`
+	CreatedAt          time.Time            `json:"created_at"`
+	UpdatedAt          time.Time            `json:"update_at"`
+	// MissingTranslations แสดงเฉพาะหน้า admin เช่น ["content.en"]
+	MissingTranslations []string `json:"missing_translations,omitempty"`
+}
+
+// Localize ให้ title/content เป็นภาษาที่ขอ ถ้ายังไม่มีคำแปลจะใช้ภาษาไทย
+func (n *News) Localize(lang string) {
+	n.Title = i18n.Pick(lang, n.Title, n.TitleEn)
+	n.Content = i18n.Pick(lang, n.Content, n.ContentEn)
+	n.Lang = lang
+}
+
+func (n *News) CheckTranslations() {
+	n.MissingTranslations = i18n.Missing(
+		i18n.Field{Name: "title", TH: n.Title, EN: n.TitleEn},
+		i18n.Field{Name: "content", TH: n.Content, EN: n.ContentEn},
+	)
 }
 
 type NewsImages struct {
-	ImageID   int    `json:"image_id"`
-	NewsID    int    `json:"news_id"`
-	FileImage string `json:"file_image"`
+	ImageID   int                  `json:"image_id"`
+	NewsID    int                  `json:"news_id"`
+	FileImage string               `json:"file_image"`
+	Position  int                  `json:"position"`
+	Caption   string               `json:"caption"`
+	AltText   string               `json:"alt_text"`
+	Variants  []imaging.VariantURL `json:"variants,omitempty"`
+}
+
+// NewsImageRequest แก้คำบรรยายหรือข้อความแทนรูป ฟิลด์ที่ไม่ได้ส่งมาจะคงค่าเดิม
+type NewsImageRequest struct {
+	Caption *string `json:"caption"`
+	AltText *string `json:"alt_text"`
+}
+
+// NewsImageOrderRequest ต้องระบุรูปทุกรูปของข่าว เรียงตามลำดับที่ต้องการแสดง
+type NewsImageOrderRequest struct {
+	ImageIDs []int `json:"image_ids" binding:"required"`
 }
 
 type NewsQueryParam struct {
+	pagination.Params
 	Search string `form:"search"`
-	Limit  int    `form:"limit"`
 	TypeID int    `form:"type_id"`
-	Sort   string `form:"sort"`
-	Order  string `form:"order"`
+	Type   string `form:"type"`
+	TagID  int    `form:"tag_id"`
+	Tag    string `form:"tag"`
+	Status string `form:"status"`
+	// Pinned/Featured กรองข่าวที่ปักหมุดหรือเป็นข่าวเด่นอยู่ในขณะนี้
+	// Highlight กรองข่าวที่ตั้งค่าไว้ (pinned หรือ featured) รวมที่ยังไม่ถึงเวลาหรือหมดเวลาแล้ว
+	Pinned    bool   `form:"pinned"`
+	Featured  bool   `form:"featured"`
+	Highlight string `form:"highlight"`
+	// PinnedFirst ให้ข่าวที่ปักหมุดอยู่ขึ้นก่อนลำดับที่ขอ ใช้กับหน้าเว็บสาธารณะ
+	PinnedFirst bool   `form:"-"`
+	Sort        string `form:"sort"`
+	Order       string `form:"order"`
 }
 
 type NewsRequest struct {
 	Title      string       `json:"title"`
 	Content    string       `json:"content"`
+	TitleEn    string       `json:"title_en"`
+	ContentEn  string       `json:"content_en"`
 	TypeID     int          `json:"type_id"`
 	DetailURL  string       `json:"detail_url"`
 	CoverImage string       `json:"cover_image"`
@@ -39,3 +100,92 @@ type NewsRequest struct {
 	CreatedAt  time.Time    `json:"created_at"`
 	UpdatedAt  time.Time    `json:"update_at"`
 }
+
+const (
+	StatusDraft     = "draft"
+	StatusInReview  = "in_review"
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusDraft, StatusInReview, StatusScheduled, StatusPublished, StatusArchived:
+		return true
+	}
+	return false
+}
+
+// NewsStatusRequest ใช้เปลี่ยนสถานะข่าว publish_at/unpublish_at ใช้เฉพาะตอนเผยแพร่
+type NewsStatusRequest struct {
+	Status      string     `json:"status" binding:"required"`
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
+}
+
+const (
+	HighlightPinned   = "pinned"
+	HighlightFeatured = "featured"
+)
+
+func IsValidHighlight(kind string) bool {
+	return kind == HighlightPinned || kind == HighlightFeatured
+}
+
+// NewsHighlight คือการปักหมุดหรือตั้งเป็นข่าวเด่น มีผลเฉพาะในช่วง start_at ถึง end_at
+type NewsHighlight struct {
+	Slot    int        `json:"slot"`
+	StartAt *time.Time `json:"start_at"`
+	EndAt   *time.Time `json:"end_at"`
+	Active  bool       `json:"active"`
+}
+
+// NewsHighlightRequest ถ้าไม่ส่ง slot จะต่อท้าย slot สุดท้าย
+type NewsHighlightRequest struct {
+	Slot    *int       `json:"slot"`
+	StartAt *time.Time `json:"start_at"`
+	EndAt   *time.Time `json:"end_at"`
+}
+
+// NewsHighlightOrderRequest เรียง slot ใหม่ตามลำดับ news_ids เริ่มที่ 1
+// ข่าวที่ตั้งค่าไว้แต่ไม่ได้ส่งมาจะต่อท้ายตามลำดับเดิม
+type NewsHighlightOrderRequest struct {
+	Kind    string `json:"kind" binding:"required"`
+	NewsIDs []int  `json:"news_ids" binding:"required"`
+}
+
+// NewsRevision คือสำเนาข่าว ณ เวลาที่บันทึก ใช้ดูประวัติและย้อนกลับ
+type NewsRevision struct {
+	RevisionID int       `json:"revision_id"`
+	NewsID     int       `json:"news_id"`
+	RevisionNo int       `json:"revision_no"`
+	Title      string    `json:"title"`
+	Content    string    `json:"content"`
+	TitleEn    string    `json:"title_en"`
+	ContentEn  string    `json:"content_en"`
+	TypeID     int       `json:"type_id"`
+	TypeName   string    `json:"type_name"`
+	DetailURL  string    `json:"detail_url"`
+	CoverImage string    `json:"cover_image"`
+	Images     []string  `json:"images"`
+	AuthorID   *int      `json:"author_id"`
+	AuthorName string    `json:"author_name"`
+	RollbackOf *int      `json:"rollback_of,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type RevisionFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type NewsRevisionDiff struct {
+	NewsID        int                   `json:"news_id"`
+	From          int                   `json:"from"`
+	To            int                   `json:"to"`
+	Changes       []RevisionFieldChange `json:"changes"`
+	ImagesAdded   []string              `json:"images_added"`
+	ImagesRemoved []string              `json:"images_removed"`
+}
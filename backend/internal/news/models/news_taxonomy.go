@@ -0,0 +1,54 @@
+package models
+
+// NewsType คือหมวดหมู่ข่าว เรียงตาม sort_order บนหน้าเว็บ
+type NewsType struct {
+	TypeID    int    `json:"type_id"`
+	TypeName  string `json:"type_name"`
+	Slug      string `json:"slug"`
+	SortOrder int    `json:"sort_order"`
+}
+
+// NewsTypeRequest ถ้าไม่ส่ง slug จะสร้างจากชื่อ ถ้าไม่ส่ง sort_order ตอนสร้างจะต่อท้ายสุด
+type NewsTypeRequest struct {
+	TypeName  string `json:"type_name" binding:"required"`
+	Slug      string `json:"slug"`
+	SortOrder *int   `json:"sort_order"`
+}
+
+// NewsTypeOrderRequest คือลำดับใหม่ของหมวดหมู่ทั้งหมด
+type NewsTypeOrderRequest struct {
+	TypeIDs []int `json:"type_ids" binding:"required"`
+}
+
+// NewsTag คือแท็กของข่าว ข่าวหนึ่งมีได้หลายแท็ก
+type NewsTag struct {
+	TagID int    `json:"tag_id"`
+	Name  string `json:"name"`
+	Slug  string `json:"slug"`
+}
+
+type NewsTagRequest struct {
+	Name string `json:"name" binding:"required"`
+	Slug string `json:"slug"`
+}
+
+// NewsTagsRequest กำหนดแท็กทั้งหมดของข่าว แทนที่แท็กเดิม
+type NewsTagsRequest struct {
+	TagIDs []int `json:"tag_ids"`
+}
+
+type NewsTypeCount struct {
+	NewsType
+	Count int `json:"count"`
+}
+
+type NewsTagCount struct {
+	NewsTag
+	Count int `json:"count"`
+}
+
+// NewsCounts คือจำนวนข่าวที่เผยแพร่อยู่ในแต่ละหมวดหมู่และแท็ก ใช้แสดงที่ sidebar
+type NewsCounts struct {
+	Types []NewsTypeCount `json:"types"`
+	Tags  []NewsTagCount  `json:"tags"`
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"cpsu/internal/news/models"
+
+	"github.com/lib/pq"
+)
+
+// prefix ของคอลัมน์ slot/start_at/end_at ในตาราง news ของแต่ละประเภท
+var highlightColumns = map[string]string{
+	models.HighlightPinned:   "pin",
+	models.HighlightFeatured: "feature",
+}
+
+// activeHighlight คือเงื่อนไขว่าข่าวปักหมุดหรือเป็นข่าวเด่นอยู่ในขณะนี้
+func activeHighlight(kind string) string {
+	prefix := "n." + highlightColumns[kind]
+	return "(" + prefix + "_slot IS NOT NULL" +
+		" AND (" + prefix + "_start_at IS NULL OR " + prefix + "_start_at <= NOW())" +
+		" AND (" + prefix + "_end_at IS NULL OR " + prefix + "_end_at > NOW()))"
+}
+
+// pinnedFirst ใช้เป็นคีย์เรียงลำดับแรก ข่าวที่ปักหมุดอยู่ได้ slot ส่วนข่าวอื่นเป็น NULL ซึ่งอยู่ท้ายเมื่อเรียง ASC
+var pinnedFirst = "(CASE WHEN " + activeHighlight(models.HighlightPinned) + " THEN n.pin_slot END)"
+
+func (r *newsRepository) SetHighlight(newsID int, kind string, req models.NewsHighlightRequest) error {
+	prefix := highlightColumns[kind]
+	result, err := r.db.Exec(`
+		UPDATE news
+		SET `+prefix+`_slot = COALESCE($1, `+prefix+`_slot, (SELECT COALESCE(MAX(`+prefix+`_slot), 0) + 1 FROM news)),
+			`+prefix+`_start_at = $2,
+			`+prefix+`_end_at = $3
+		WHERE news_id = $4
+	`, req.Slot, req.StartAt, req.EndAt, newsID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+func (r *newsRepository) ClearHighlight(newsID int, kind string) error {
+	prefix := highlightColumns[kind]
+	result, err := r.db.Exec(`
+		UPDATE news
+		SET `+prefix+`_slot = NULL, `+prefix+`_start_at = NULL, `+prefix+`_end_at = NULL
+		WHERE news_id = $1
+	`, newsID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// ReorderHighlights ตั้ง slot ตามลำดับ newsIDs แล้วต่อท้ายข่าวที่ตั้งค่าไว้แต่ไม่ได้ระบุตามลำดับเดิม
+// ข่าวที่ยังไม่ได้ปักหมุดหรือตั้งเป็นข่าวเด่นจะถูกข้าม
+func (r *newsRepository) ReorderHighlights(kind string, newsIDs []int) error {
+	prefix := highlightColumns[kind]
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE news n
+		SET `+prefix+`_slot = o.ord
+		FROM unnest($1::int[]) WITH ORDINALITY AS o(news_id, ord)
+		WHERE n.news_id = o.news_id AND n.`+prefix+`_slot IS NOT NULL
+	`, pq.Array(newsIDs))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE news n
+		SET `+prefix+`_slot = $2 + r.rn
+		FROM (
+			SELECT news_id, ROW_NUMBER() OVER (ORDER BY `+prefix+`_slot, news_id) AS rn
+			FROM news
+			WHERE `+prefix+`_slot IS NOT NULL AND NOT news_id = ANY($1)
+		) r
+		WHERE n.news_id = r.news_id
+	`, pq.Array(newsIDs), len(newsIDs))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func requireAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// highlightColumnsSQL คือคอลัมน์ที่ต้อง select เพื่อใช้กับ highlightScan
+const highlightColumnsSQL = `n.pin_slot, n.pin_start_at, n.pin_end_at,
+	n.feature_slot, n.feature_start_at, n.feature_end_at`
+
+// highlightScan เก็บค่าคอลัมน์จาก highlightColumnsSQL ระหว่าง scan
+type highlightScan struct {
+	pinSlot, featureSlot     sql.NullInt64
+	pinStart, pinEnd         sql.NullTime
+	featureStart, featureEnd sql.NullTime
+}
+
+func (h *highlightScan) dest() []interface{} {
+	return []interface{}{
+		&h.pinSlot, &h.pinStart, &h.pinEnd,
+		&h.featureSlot, &h.featureStart, &h.featureEnd,
+	}
+}
+
+func (h *highlightScan) apply(news *models.News) {
+	now := time.Now()
+	news.Pinned = newHighlight(h.pinSlot, h.pinStart, h.pinEnd, now)
+	news.Featured = newHighlight(h.featureSlot, h.featureStart, h.featureEnd, now)
+}
+
+// newHighlight คำนวณ Active ด้วยเงื่อนไขเดียวกับ activeHighlight ค่าที่ scan จาก TIMESTAMPTZ เป็นเวลาจริงจึงเทียบกับ now ได้ตรง
+func newHighlight(slot sql.NullInt64, start sql.NullTime, end sql.NullTime, now time.Time) *models.NewsHighlight {
+	if !slot.Valid {
+		return nil
+	}
+	h := &models.NewsHighlight{
+		Slot:    int(slot.Int64),
+		StartAt: nullTimePtr(start),
+		EndAt:   nullTimePtr(end),
+	}
+	h.Active = (h.StartAt == nil || !h.StartAt.After(now)) && (h.EndAt == nil || h.EndAt.After(now))
+	return h
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestNewHighlight(t *testing.T) {
+	now := time.Date(2026, 3, 1, 5, 0, 0, 0, time.UTC)
+	bangkok := time.FixedZone("Asia/Bangkok", 7*60*60)
+	at := func(hour int, loc *time.Location) sql.NullTime {
+		return sql.NullTime{Time: time.Date(2026, 3, 1, hour, 0, 0, 0, loc), Valid: true}
+	}
+	slot := sql.NullInt64{Int64: 2, Valid: true}
+
+	tests := []struct {
+		name       string
+		slot       sql.NullInt64
+		start, end sql.NullTime
+		want       bool
+	}{
+		{"no window", slot, sql.NullTime{}, sql.NullTime{}, true},
+		{"started", slot, at(4, time.UTC), sql.NullTime{}, true},
+		{"starts exactly now", slot, at(5, time.UTC), sql.NullTime{}, true},
+		{"not started", slot, at(6, time.UTC), sql.NullTime{}, false},
+		{"ended", slot, sql.NullTime{}, at(5, time.UTC), false},
+		// 11:00 และ 13:00 ที่ +07:00 คือ 04:00 และ 06:00 UTC ตัวเลขนาฬิกาไม่ใช่สิ่งที่ใช้เทียบ
+		{"started in +07:00", slot, at(11, bangkok), sql.NullTime{}, true},
+		{"not started in +07:00", slot, at(13, bangkok), sql.NullTime{}, false},
+		{"ends later in +07:00", slot, at(11, bangkok), at(13, bangkok), true},
+		{"ended in +07:00", slot, sql.NullTime{}, at(11, bangkok), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHighlight(tt.slot, tt.start, tt.end, now)
+			if h == nil || h.Slot != 2 || h.Active != tt.want {
+				t.Errorf("newHighlight = %+v, want slot 2 active %v", h, tt.want)
+			}
+		})
+	}
+
+	if h := newHighlight(sql.NullInt64{}, at(4, time.UTC), sql.NullTime{}, now); h != nil {
+		t.Errorf("newHighlight without slot = %+v, want nil", h)
+	}
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"database/sql"
+
+	"cpsu/internal/news/models"
+	"cpsu/internal/pagination"
+
+	"github.com/lib/pq"
+)
+
+const imageColumns = "image_id, news_id, file_image, position, caption, alt_text"
+
+func scanImage(row pagination.Scanner) (models.NewsImages, error) {
+	var img models.NewsImages
+	err := row.Scan(&img.ImageID, &img.NewsID, &img.FileImage, &img.Position, &img.Caption, &img.AltText)
+	return img, err
+}
+
+// GetNewsImages คืนรูปของข่าวตามลำดับที่แสดง
+func (r *newsRepository) GetNewsImages(newsID int) ([]models.NewsImages, error) {
+	rows, err := r.db.Query(`
+		SELECT `+imageColumns+`
+		FROM news_images
+		WHERE news_id = $1
+		ORDER BY position, image_id
+	`, newsID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	images := []models.NewsImages{}
+	for rows.Next() {
+		img, err := scanImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+	return images, rows.Err()
+}
+
+func (r *newsRepository) GetNewsImage(newsID int, imageID int) (*models.NewsImages, error) {
+	img, err := scanImage(r.db.QueryRow(
+		"SELECT "+imageColumns+" FROM news_images WHERE news_id = $1 AND image_id = $2",
+		newsID, imageID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
+
+// AddNewsImages เพิ่มรูปต่อท้ายแกลเลอรีของข่าวและบันทึก revision ใน transaction เดียวกัน
+func (r *newsRepository) AddNewsImages(newsID int, images []string, caption string, altText string, authorID int) ([]models.NewsImages, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockNews(tx, newsID); err != nil {
+		return nil, err
+	}
+
+	created, err := appendImages(tx, newsID, images, caption, altText)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := insertRevision(tx, newsID, authorID, nil); err != nil {
+		return nil, err
+	}
+
+	return created, tx.Commit()
+}
+
+func appendImages(tx *sql.Tx, newsID int, images []string, caption string, altText string) ([]models.NewsImages, error) {
+	created := make([]models.NewsImages, 0, len(images))
+	for _, fileImage := range images {
+		img, err := scanImage(tx.QueryRow(`
+			INSERT INTO news_images (news_id, file_image, position, caption, alt_text)
+			VALUES ($1, $2, (SELECT COALESCE(MAX(position), 0) + 1 FROM news_images WHERE news_id = $1), $3, $4)
+			RETURNING `+imageColumns,
+			newsID, fileImage, caption, altText,
+		))
+		if err != nil {
+			return nil, mapConstraintError(err, sql.ErrNoRows)
+		}
+		created = append(created, img)
+	}
+	return created, nil
+}
+
+func (r *newsRepository) UpdateNewsImage(newsID int, imageID int, req models.NewsImageRequest) (*models.NewsImages, error) {
+	img, err := scanImage(r.db.QueryRow(`
+		UPDATE news_images
+		SET caption = COALESCE($1, caption), alt_text = COALESCE($2, alt_text)
+		WHERE news_id = $3 AND image_id = $4
+		RETURNING `+imageColumns,
+		req.Caption, req.AltText, newsID, imageID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
+
+// DeleteNewsImage ลบรูปแล้วเลื่อนลำดับรูปที่เหลือให้ต่อเนื่อง พร้อมบันทึก revision
+func (r *newsRepository) DeleteNewsImage(newsID int, imageID int, authorID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockNews(tx, newsID); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec("DELETE FROM news_images WHERE news_id = $1 AND image_id = $2", newsID, imageID)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result); err != nil {
+		return err
+	}
+
+	if err := renumberImages(tx, newsID); err != nil {
+		return err
+	}
+	if _, err := insertRevision(tx, newsID, authorID, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReorderNewsImages ตั้งลำดับตาม imageIDs ซึ่ง service ตรวจแล้วว่าครบทุกรูปของข่าว พร้อมบันทึก revision
+func (r *newsRepository) ReorderNewsImages(newsID int, imageIDs []int, authorID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockNews(tx, newsID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE news_images ni
+		SET position = o.ord
+		FROM unnest($2::int[]) WITH ORDINALITY AS o(image_id, ord)
+		WHERE ni.news_id = $1 AND ni.image_id = o.image_id
+	`, newsID, pq.Array(imageIDs))
+	if err != nil {
+		return err
+	}
+	if _, err := insertRevision(tx, newsID, authorID, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func renumberImages(tx *sql.Tx, newsID int) error {
+	_, err := tx.Exec(`
+		UPDATE news_images ni
+		SET position = r.rn
+		FROM (
+			SELECT image_id, ROW_NUMBER() OVER (ORDER BY position, image_id) AS rn
+			FROM news_images
+			WHERE news_id = $1
+		) r
+		WHERE ni.image_id = r.image_id
+	`, newsID)
+	return err
+}
@@ -4,18 +4,46 @@ import (
 	"database/sql"
 	"strconv"
 	"strings"
+	"time"
 
 	"cpsu/internal/news/models"
+	"cpsu/internal/pagination"
+	"cpsu/internal/textsearch"
+
+	"github.com/lib/pq"
 )
 
 type NewsRepository interface {
-	GetAllNews(param models.NewsQueryParam) ([]models.News, error)
+	GetAllNews(param models.NewsQueryParam) (*pagination.Page[models.News], error)
 	GetNewsByID(id int) (*models.News, error)
-	CreateNews(news *models.NewsRequest) (*models.News, error)
-	UpdateNews(id int, news *models.NewsRequest) (*models.News, error)
+	CreateNews(news *models.NewsRequest, authorID int) (*models.News, error)
+	UpdateNews(id int, news *models.NewsRequest, newImages []string, authorID int) (*models.News, error)
 	DeleteNews(id int) error
-	AddNewsImages(newsID int, images []string) error
-	UpdateNewsImages(newsID int, images []string) ([]models.NewsImages, error)
+	GetNewsImages(newsID int) ([]models.NewsImages, error)
+	GetNewsImage(newsID int, imageID int) (*models.NewsImages, error)
+	AddNewsImages(newsID int, images []string, caption string, altText string, authorID int) ([]models.NewsImages, error)
+	UpdateNewsImage(newsID int, imageID int, req models.NewsImageRequest) (*models.NewsImages, error)
+	DeleteNewsImage(newsID int, imageID int, authorID int) error
+	ReorderNewsImages(newsID int, imageIDs []int, authorID int) error
+	UpdateNewsStatus(id int, req models.NewsStatusRequest) error
+	PublishDueNews() (published int64, archived int64, err error)
+	RollbackNews(newsID int, revision *models.NewsRevision, authorID int) (*models.NewsRevision, error)
+	GetRevisions(newsID int) ([]models.NewsRevision, error)
+	GetRevision(newsID int, revisionNo int) (*models.NewsRevision, error)
+	GetNewsTypes() ([]models.NewsType, error)
+	CreateNewsType(req models.NewsTypeRequest) (*models.NewsType, error)
+	UpdateNewsType(id int, req models.NewsTypeRequest) (*models.NewsType, error)
+	DeleteNewsType(id int) error
+	ReorderNewsTypes(ids []int) error
+	GetNewsTags() ([]models.NewsTag, error)
+	CreateNewsTag(req models.NewsTagRequest) (*models.NewsTag, error)
+	UpdateNewsTag(id int, req models.NewsTagRequest) (*models.NewsTag, error)
+	DeleteNewsTag(id int) error
+	SetNewsTags(newsID int, tagIDs []int) error
+	CountNews() (*models.NewsCounts, error)
+	SetHighlight(newsID int, kind string, req models.NewsHighlightRequest) error
+	ClearHighlight(newsID int, kind string) error
+	ReorderHighlights(kind string, newsIDs []int) error
 }
 
 type newsRepository struct {
@@ -26,16 +54,23 @@ func NewNewsRepository(db *sql.DB) NewsRepository {
 	return &newsRepository{db: db}
 }
 
-func (r *newsRepository) GetAllNews(param models.NewsQueryParam) ([]models.News, error) {
-	query := `
-		SELECT n.news_id, n.title, n.content, nt.type_id, nt.type_name,
-			n.detail_url, n.cover_image, ni.image_id, ni.file_image,
-			n.created_at, n.updated_at
-		FROM news n
-		LEFT JOIN news_images ni ON n.news_id = ni.news_id
-		LEFT JOIN news_types nt ON n.type_id = nt.type_id
-	`
+var newsSort = pagination.Sorter{
+	Columns: map[string]string{
+		"news_id":      "n.news_id",
+		"title":        "n.title",
+		"type_id":      "n.type_id",
+		"status":       "n.status",
+		"publish_at":   "n.publish_at",
+		"created_at":   "n.created_at",
+		"updated_at":   "n.updated_at",
+		"pin_slot":     "n.pin_slot",
+		"feature_slot": "n.feature_slot",
+	},
+	Default: "-created_at",
+	Key:     "n.news_id",
+}
 
+func (r *newsRepository) GetAllNews(param models.NewsQueryParam) (*pagination.Page[models.News], error) {
 	conditions := []string{}
 	args := []interface{}{}
 	argIndex := 1
@@ -46,98 +81,167 @@ func (r *newsRepository) GetAllNews(param models.NewsQueryParam) ([]models.News,
 		argIndex++
 	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
+	if param.Type != "" {
+		conditions = append(conditions, "nt.slug = $"+strconv.Itoa(argIndex))
+		args = append(args, param.Type)
+		argIndex++
 	}
 
-	sort := "n.updated_at"
-	if param.Sort != "" {
-		sort = "n." + param.Sort
+	if param.TagID > 0 {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM news_tag_map m WHERE m.news_id = n.news_id AND m.tag_id = $"+strconv.Itoa(argIndex)+")")
+		args = append(args, param.TagID)
+		argIndex++
 	}
 
-	order := "ASC"
-	if strings.ToUpper(param.Order) == "DESC" {
-		order = "DESC"
+	// tag=a,b คือข่าวที่มีแท็กใดแท็กหนึ่งในรายการ
+	if tags := splitList(param.Tag); len(tags) > 0 {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM news_tag_map m
+			JOIN news_tags t ON m.tag_id = t.tag_id
+			WHERE m.news_id = n.news_id AND t.slug = ANY($`+strconv.Itoa(argIndex)+`))`)
+		args = append(args, pq.Array(tags))
+		argIndex++
 	}
 
-	query += " ORDER BY " + sort + " " + order
+	if param.Status == models.StatusPublished {
+		conditions = append(conditions, VisibleNewsCondition)
+	} else if param.Status != "" {
+		conditions = append(conditions, "n.status = $"+strconv.Itoa(argIndex))
+		args = append(args, param.Status)
+		argIndex++
+	}
 
-	if param.Limit > 0 {
-		query += " LIMIT " + strconv.Itoa(param.Limit)
+	if terms := textsearch.Terms(param.Search); len(terms) > 0 {
+		condition, searchArgs, next := textsearch.Condition([]string{"n.title", "n.content", "n.title_en", "n.content_en"}, terms, argIndex)
+		conditions = append(conditions, condition)
+		args = append(args, searchArgs...)
+		argIndex = next
 	}
 
-	rows, err := r.db.Query(query, args...)
+	if param.Pinned {
+		conditions = append(conditions, activeHighlight(models.HighlightPinned))
+	}
+	if param.Featured {
+		conditions = append(conditions, activeHighlight(models.HighlightFeatured))
+	}
+	if column, ok := highlightColumns[param.Highlight]; ok {
+		conditions = append(conditions, "n."+column+"_slot IS NOT NULL")
+	}
+
+	orderBy, err := newsSort.Parse(param.Sort, param.Order)
 	if err != nil {
 		return nil, err
 	}
+	if param.PinnedFirst {
+		orderBy = append([]pagination.SortKey{{Column: pinnedFirst}}, orderBy...)
+	}
 
-	defer rows.Close()
-
-	newsMap := make(map[int]*models.News)
-	var allNewsPt []*models.News
+	// แบ่งหน้าที่ตาราง news ก่อน แล้วค่อยดึงรูปของข่าวในหน้านั้น เพื่อให้ LIMIT นับเป็นจำนวนข่าว
+	stmt := pagination.Statement{
+		Columns: `
+			n.news_id, n.title, n.content, n.title_en, n.content_en, nt.type_id, nt.type_name, nt.slug,
+			n.detail_url, n.cover_image,
+			n.status, n.publish_at, n.unpublish_at, n.created_at, n.updated_at,
+			` + highlightColumnsSQL + `
+		`,
+		From: `
+			FROM news n
+			LEFT JOIN news_types nt ON n.type_id = nt.type_id
+		`,
+		Conditions: conditions,
+		Args:       args,
+		OrderBy:    orderBy,
+	}
 
-	for rows.Next() {
+	page, err := pagination.Fetch(r.db, stmt, param.Params, func(row pagination.Scanner) (models.News, error) {
 		var (
-			newsID                                          int
-			title, content, typeName, detailURL, coverImage string
-			typeID, imageID                                 sql.NullInt64
-			fileImage                                       sql.NullString
-			createdAt, updatedAt                            sql.NullTime
+			n                      models.News
+			typeID                 sql.NullInt64
+			publishAt, unpublishAt sql.NullTime
+			createdAt, updatedAt   sql.NullTime
+			highlights             highlightScan
 		)
 
-		err := rows.Scan(
-			&newsID, &title, &content, &typeID, &typeName,
-			&detailURL, &coverImage, &imageID, &fileImage,
-			&createdAt, &updatedAt,
-		)
-		if err != nil {
-			return nil, err
+		dest := []interface{}{
+			&n.NewsID, &n.Title, &n.Content, &n.TitleEn, &n.ContentEn, &typeID, &n.TypeName, &n.TypeSlug,
+			&n.DetailURL, &n.CoverImage,
+			&n.Status, &publishAt, &unpublishAt, &createdAt, &updatedAt,
 		}
-
-		n, exists := newsMap[newsID]
-		if !exists {
-			n = &models.News{
-				NewsID:     newsID,
-				Title:      title,
-				Content:    content,
-				TypeID:     int(typeID.Int64),
-				TypeName:   typeName,
-				DetailURL:  detailURL,
-				CoverImage: coverImage,
-				CreatedAt:  createdAt.Time,
-				UpdatedAt:  updatedAt.Time,
-				Images:     []models.NewsImages{},
-			}
-			newsMap[newsID] = n
-			allNewsPt = append(allNewsPt, n)
+		if err := row.Scan(append(dest, highlights.dest()...)...); err != nil {
+			return n, err
 		}
 
-		if imageID.Valid && fileImage.Valid {
-			n.Images = append(n.Images, models.NewsImages{
-				ImageID:   int(imageID.Int64),
-				NewsID:    newsID,
-				FileImage: fileImage.String,
-			})
-		}
+		highlights.apply(&n)
+
+		n.TypeID = int(typeID.Int64)
+		n.PublishAt = nullTimePtr(publishAt)
+		n.UnpublishAt = nullTimePtr(unpublishAt)
+		n.CreatedAt = createdAt.Time
+		n.UpdatedAt = updatedAt.Time
+		n.Images = []models.NewsImages{}
+		return n, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	var allNews []models.News
-	for _, p := range allNewsPt {
-		allNews = append(allNews, *p)
+	if err := r.loadImages(page.Items); err != nil {
+		return nil, err
+	}
+	if err := r.loadTags(page.Items); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func (r *newsRepository) loadImages(newsList []models.News) error {
+	if len(newsList) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(newsList))
+	index := make(map[int]int, len(newsList))
+	for i, n := range newsList {
+		ids[i] = int64(n.NewsID)
+		index[n.NewsID] = i
+	}
+
+	rows, err := r.db.Query(`
+		SELECT `+imageColumns+`
+		FROM news_images
+		WHERE news_id = ANY($1)
+		ORDER BY news_id, position, image_id
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		img, err := scanImage(rows)
+		if err != nil {
+			return err
+		}
+		i := index[img.NewsID]
+		newsList[i].Images = append(newsList[i].Images, img)
 	}
 
-	return allNews, nil
+	return rows.Err()
 }
 
 func (r *newsRepository) GetNewsByID(id int) (*models.News, error) {
 	query := `
-		SELECT n.news_id, n.title, n.content, nt.type_id, nt.type_name,
+		SELECT n.news_id, n.title, n.content, n.title_en, n.content_en, nt.type_id, nt.type_name, nt.slug,
 			n.detail_url, n.cover_image, ni.image_id, ni.file_image,
-			n.created_at, n.updated_at
+			COALESCE(ni.position, 0), COALESCE(ni.caption, ''), COALESCE(ni.alt_text, ''),
+			n.status, n.publish_at, n.unpublish_at, n.created_at, n.updated_at,
+			` + highlightColumnsSQL + `
 		FROM news n
 		LEFT JOIN news_images ni ON n.news_id = ni.news_id
 		LEFT JOIN news_types nt ON n.type_id = nt.type_id
 		WHERE n.news_id = $1
+		ORDER BY ni.position, ni.image_id
 	`
 	rows, err := r.db.Query(query, id)
 	if err != nil {
@@ -150,35 +254,50 @@ func (r *newsRepository) GetNewsByID(id int) (*models.News, error) {
 
 	for rows.Next() {
 		var (
-			newsID                                          int
-			title, content, typeName, detailURL, coverImage string
-			typeID, imageID                                 sql.NullInt64
-			fileImage                                       sql.NullString
-			createdAt, updatedAt                            sql.NullTime
+			newsID                                        int
+			title, content, typeName, typeSlug, detailURL string
+			titleEn, contentEn                            string
+			coverImage                                    string
+			typeID, imageID                               sql.NullInt64
+			fileImage                                     sql.NullString
+			position                                      int
+			caption, altText                              string
+			status                                        string
+			publishAt, unpublishAt                        sql.NullTime
+			createdAt, updatedAt                          sql.NullTime
+			highlights                                    highlightScan
 		)
 
-		err := rows.Scan(
-			&newsID, &title, &content, &typeID, &typeName,
+		dest := []interface{}{
+			&newsID, &title, &content, &titleEn, &contentEn, &typeID, &typeName, &typeSlug,
 			&detailURL, &coverImage, &imageID, &fileImage,
-			&createdAt, &updatedAt,
-		)
-		if err != nil {
+			&position, &caption, &altText,
+			&status, &publishAt, &unpublishAt, &createdAt, &updatedAt,
+		}
+		if err := rows.Scan(append(dest, highlights.dest()...)...); err != nil {
 			return nil, err
 		}
 
 		if news == nil {
 			news = &models.News{
-				NewsID:     newsID,
-				Title:      title,
-				Content:    content,
-				TypeID:     int(typeID.Int64),
-				TypeName:   typeName,
-				DetailURL:  detailURL,
-				CoverImage: coverImage,
-				CreatedAt:  createdAt.Time,
-				UpdatedAt:  updatedAt.Time,
-				Images:     []models.NewsImages{},
+				NewsID:      newsID,
+				Title:       title,
+				Content:     content,
+				TitleEn:     titleEn,
+				ContentEn:   contentEn,
+				TypeID:      int(typeID.Int64),
+				TypeName:    typeName,
+				TypeSlug:    typeSlug,
+				DetailURL:   detailURL,
+				CoverImage:  coverImage,
+				Status:      status,
+				PublishAt:   nullTimePtr(publishAt),
+				UnpublishAt: nullTimePtr(unpublishAt),
+				CreatedAt:   createdAt.Time,
+				UpdatedAt:   updatedAt.Time,
+				Images:      []models.NewsImages{},
 			}
+			highlights.apply(news)
 		}
 
 		if imageID.Valid && fileImage.Valid {
@@ -186,6 +305,9 @@ func (r *newsRepository) GetNewsByID(id int) (*models.News, error) {
 				ImageID:   int(imageID.Int64),
 				NewsID:    newsID,
 				FileImage: fileImage.String,
+				Position:  position,
+				Caption:   caption,
+				AltText:   altText,
 			})
 		}
 	}
@@ -194,19 +316,31 @@ func (r *newsRepository) GetNewsByID(id int) (*models.News, error) {
 		return nil, sql.ErrNoRows
 	}
 
-	return news, nil
+	list := []models.News{*news}
+	if err := r.loadTags(list); err != nil {
+		return nil, err
+	}
+
+	return &list[0], nil
 }
 
-func (r *newsRepository) CreateNews(req *models.NewsRequest) (*models.News, error) {
+// CreateNews บันทึกข่าว รูป และ revision แรกใน transaction เดียวกัน
+func (r *newsRepository) CreateNews(req *models.NewsRequest, authorID int) (*models.News, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO news (title, content, type_id, detail_url, cover_image)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO news (title, content, title_en, content_en, type_id, detail_url, cover_image)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING news_id, created_at, updated_at
 	`
 	var news models.News
-	err := r.db.QueryRow(
+	err = tx.QueryRow(
 		query,
-		req.Title, req.Content, req.TypeID, req.DetailURL, req.CoverImage,
+		req.Title, req.Content, req.TitleEn, req.ContentEn, req.TypeID, req.DetailURL, req.CoverImage,
 	).Scan(&news.NewsID, &news.CreatedAt, &news.UpdatedAt)
 	if err != nil {
 		return nil, err
@@ -214,32 +348,62 @@ func (r *newsRepository) CreateNews(req *models.NewsRequest) (*models.News, erro
 
 	news.Title = req.Title
 	news.Content = req.Content
+	news.TitleEn = req.TitleEn
+	news.ContentEn = req.ContentEn
 	news.TypeID = req.TypeID
 	news.DetailURL = req.DetailURL
 	news.CoverImage = req.CoverImage
 	news.Images = req.Images
 
-	if len(req.Images) > 0 {
-		err := r.AddNewsImages(news.NewsID, ImagesAsStrings(req.Images))
-		if err != nil {
-			return nil, err
-		}
+	if _, err := appendImages(tx, news.NewsID, ImagesAsStrings(req.Images), "", ""); err != nil {
+		return nil, err
+	}
+	if _, err := insertRevision(tx, news.NewsID, authorID, nil); err != nil {
+		return nil, err
 	}
 
-	return &news, nil
+	return &news, tx.Commit()
 }
 
-func (r *newsRepository) UpdateNews(id int, newsreq *models.NewsRequest) (*models.News, error) {
+// UpdateNews แก้ข่าว ต่อท้ายรูปใหม่ และบันทึก revision ใน transaction เดียวกัน
+func (r *newsRepository) UpdateNews(id int, newsreq *models.NewsRequest, newImages []string, authorID int) (*models.News, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockNews(tx, id); err != nil {
+		return nil, err
+	}
+
+	news, err := updateNews(tx, id, newsreq)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := appendImages(tx, id, newImages, "", ""); err != nil {
+		return nil, err
+	}
+	if _, err := insertRevision(tx, id, authorID, nil); err != nil {
+		return nil, err
+	}
+
+	return news, tx.Commit()
+}
+
+func updateNews(tx *sql.Tx, id int, newsreq *models.NewsRequest) (*models.News, error) {
 	query := `
 		UPDATE news
-		SET title = $1, content = $2, type_id = $3, detail_url = $4, cover_image = $5, updated_at = NOW()
-		WHERE news_id = $6
+		SET title = $1, content = $2, title_en = $3, content_en = $4,
+			type_id = $5, detail_url = $6, cover_image = $7, updated_at = NOW()
+		WHERE news_id = $8
 		RETURNING news_id, created_at, updated_at
 	`
 	var news models.News
-	err := r.db.QueryRow(
+	err := tx.QueryRow(
 		query,
-		newsreq.Title, newsreq.Content, newsreq.TypeID, newsreq.DetailURL, newsreq.CoverImage, id,
+		newsreq.Title, newsreq.Content, newsreq.TitleEn, newsreq.ContentEn,
+		newsreq.TypeID, newsreq.DetailURL, newsreq.CoverImage, id,
 	).Scan(&news.NewsID, &news.CreatedAt, &news.UpdatedAt)
 	if err != nil {
 		return nil, err
@@ -247,6 +411,8 @@ func (r *newsRepository) UpdateNews(id int, newsreq *models.NewsRequest) (*model
 
 	news.Title = newsreq.Title
 	news.Content = newsreq.Content
+	news.TitleEn = newsreq.TitleEn
+	news.ContentEn = newsreq.ContentEn
 	news.TypeID = newsreq.TypeID
 	news.DetailURL = newsreq.DetailURL
 	news.CoverImage = newsreq.CoverImage
@@ -270,60 +436,84 @@ func (r *newsRepository) DeleteNews(id int) error {
 	return nil
 }
 
-func (r *newsRepository) AddNewsImages(newsID int, images []string) error {
+func (r *newsRepository) GetTypeNameByID(typeID int) (string, error) {
+	var typeName string
+	err := r.db.QueryRow("SELECT type_name FROM news_types WHERE type_id = $1", typeID).Scan(&typeName)
+	if err != nil {
+		return "", err
+	}
+	return typeName, nil
+}
+
+func ImagesAsStrings(images []models.NewsImages) []string {
+	var files []string
 	for _, img := range images {
-		_, err := r.db.Exec("INSERT INTO news_images (news_id, file_image) VALUES ($1, $2)", newsID, img)
-		if err != nil {
-			return err
-		}
+		files = append(files, img.FileImage)
 	}
-	return nil
+	return files
 }
 
-func (r *newsRepository) UpdateNewsImages(newsID int, images []string) ([]models.NewsImages, error) {
-	_, err := r.db.Exec("DELETE FROM news_images WHERE news_id = $1", newsID)
+func (r *newsRepository) UpdateNewsStatus(id int, req models.NewsStatusRequest) error {
+	result, err := r.db.Exec(`
+		UPDATE news
+		SET status = $1, publish_at = $2, unpublish_at = $3, updated_at = NOW()
+		WHERE news_id = $4
+	`, req.Status, req.PublishAt, req.UnpublishAt, id)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	for _, img := range images {
-		_, err := r.db.Exec("INSERT INTO news_images (news_id, file_image) VALUES ($1, $2)", newsID, img)
-		if err != nil {
-			return nil, err
-		}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
 	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
 
-	rows, err := r.db.Query("SELECT image_id, news_id, file_image FROM news_images WHERE news_id = $1", newsID)
+// PublishDueNews เผยแพร่ข่าว scheduled ที่ถึงเวลา publish_at และเก็บข่าวที่เลยเวลา unpublish_at
+func (r *newsRepository) PublishDueNews() (int64, int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE news SET status = 'published'
+		WHERE status = 'scheduled' AND publish_at <= NOW()
+	`)
 	if err != nil {
-		return nil, err
+		return 0, 0, err
+	}
+	published, err := result.RowsAffected()
+	if err != nil {
+		return 0, 0, err
 	}
-	defer rows.Close()
 
-	var result []models.NewsImages
-	for rows.Next() {
-		var img models.NewsImages
-		if err := rows.Scan(&img.ImageID, &img.NewsID, &img.FileImage); err != nil {
-			return nil, err
-		}
-		result = append(result, img)
+	result, err = r.db.Exec(`
+		UPDATE news SET status = 'archived'
+		WHERE status = 'published' AND unpublish_at <= NOW()
+	`)
+	if err != nil {
+		return published, 0, err
+	}
+	archived, err := result.RowsAffected()
+	if err != nil {
+		return published, 0, err
 	}
 
-	return result, nil
+	return published, archived, nil
 }
 
-func (r *newsRepository) GetTypeNameByID(typeID int) (string, error) {
-	var typeName string
-	err := r.db.QueryRow("SELECT type_name FROM news_types WHERE type_id = $1", typeID).Scan(&typeName)
-	if err != nil {
-		return "", err
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
 	}
-	return typeName, nil
+	return &t.Time
 }
 
-func ImagesAsStrings(images []models.NewsImages) []string {
-	var files []string
-	for _, img := range images {
-		files = append(files, img.FileImage)
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
-	return files
+	return items
 }
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"database/sql"
+
+	"cpsu/internal/news/models"
+
+	"github.com/lib/pq"
+)
+
+const revisionColumns = `
+	r.revision_id, r.news_id, r.revision_no, r.title, r.content, r.title_en, r.content_en,
+	r.type_id, COALESCE(nt.type_name, ''), COALESCE(r.detail_url, ''), r.cover_image, r.images,
+	r.author_id, COALESCE(u.username, ''), r.rollback_of, r.created_at
+`
+
+const revisionJoins = `
+	FROM news_revisions r
+	LEFT JOIN news_types nt ON r.type_id = nt.type_id
+	LEFT JOIN users u ON r.author_id = u.user_id
+`
+
+// lockNews ล็อกแถวข่าวจนจบ transaction เพื่อให้การแก้ไขพร้อมกันได้เลข revision ต่อกัน
+func lockNews(tx *sql.Tx, newsID int) error {
+	var id int
+	return tx.QueryRow("SELECT news_id FROM news WHERE news_id = $1 FOR UPDATE", newsID).Scan(&id)
+}
+
+// insertRevision บันทึกสถานะปัจจุบันของข่าวใน tx เป็น revision ใหม่ ผู้เรียกต้องล็อกแถวข่าวไว้ก่อน
+func insertRevision(tx *sql.Tx, newsID int, authorID int, rollbackOf *int) (int, error) {
+	var author *int
+	if authorID > 0 {
+		author = &authorID
+	}
+
+	var revisionID int
+	err := tx.QueryRow(`
+		INSERT INTO news_revisions (
+			news_id, revision_no, title, content, title_en, content_en,
+			type_id, detail_url, cover_image, images, author_id, rollback_of
+		)
+		SELECT
+			n.news_id,
+			COALESCE((SELECT MAX(revision_no) FROM news_revisions WHERE news_id = n.news_id), 0) + 1,
+			n.title, n.content, n.title_en, n.content_en, n.type_id, n.detail_url, n.cover_image,
+			ARRAY(SELECT ni.file_image FROM news_images ni WHERE ni.news_id = n.news_id ORDER BY ni.position, ni.image_id),
+			$2, $3
+		FROM news n
+		WHERE n.news_id = $1
+		RETURNING revision_id
+	`, newsID, author, rollbackOf).Scan(&revisionID)
+	return revisionID, err
+}
+
+// RollbackNews นำเนื้อหาและชุดรูปของ revision กลับมาใช้ แล้วบันทึกเป็น revision ใหม่ใน transaction เดียวกัน
+// รูปที่ยังอยู่ในแกลเลอรีจะคง image_id คำบรรยาย และข้อความแทนรูปเดิม
+func (r *newsRepository) RollbackNews(newsID int, revision *models.NewsRevision, authorID int) (*models.NewsRevision, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockNews(tx, newsID); err != nil {
+		return nil, err
+	}
+
+	newsReq := &models.NewsRequest{
+		Title:      revision.Title,
+		Content:    revision.Content,
+		TitleEn:    revision.TitleEn,
+		ContentEn:  revision.ContentEn,
+		TypeID:     revision.TypeID,
+		DetailURL:  revision.DetailURL,
+		CoverImage: revision.CoverImage,
+	}
+	if _, err := updateNews(tx, newsID, newsReq); err != nil {
+		return nil, err
+	}
+	if err := restoreImages(tx, newsID, revision.Images); err != nil {
+		return nil, err
+	}
+
+	revisionNo := revision.RevisionNo
+	revisionID, err := insertRevision(tx, newsID, authorID, &revisionNo)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return scanRevision(r.db.QueryRow("SELECT "+revisionColumns+revisionJoins+" WHERE r.revision_id = $1", revisionID))
+}
+
+// restoreImages ทำให้แกลเลอรีตรงกับ images ตามลำดับ รูปที่ยังอยู่คงแถวเดิม รูปที่ไม่อยู่ในรายการถูกลบ
+func restoreImages(tx *sql.Tx, newsID int, images []string) error {
+	_, err := tx.Exec(`
+		WITH wanted AS (
+			SELECT f.file_image, f.ord,
+				ROW_NUMBER() OVER (PARTITION BY f.file_image ORDER BY f.ord) AS dup
+			FROM unnest($2::text[]) WITH ORDINALITY AS f(file_image, ord)
+		), kept AS (
+			SELECT image_id, file_image,
+				ROW_NUMBER() OVER (PARTITION BY file_image ORDER BY position, image_id) AS dup
+			FROM news_images
+			WHERE news_id = $1
+		), moved AS (
+			UPDATE news_images ni
+			SET position = w.ord
+			FROM kept k
+			JOIN wanted w ON w.file_image = k.file_image AND w.dup = k.dup
+			WHERE ni.image_id = k.image_id
+			RETURNING ni.image_id
+		)
+		DELETE FROM news_images
+		WHERE news_id = $1 AND image_id NOT IN (SELECT image_id FROM moved)
+	`, newsID, pq.Array(images))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO news_images (news_id, file_image, position)
+		SELECT $1, w.file_image, w.ord
+		FROM (
+			SELECT f.file_image, f.ord,
+				ROW_NUMBER() OVER (PARTITION BY f.file_image ORDER BY f.ord) AS dup
+			FROM unnest($2::text[]) WITH ORDINALITY AS f(file_image, ord)
+		) w
+		WHERE w.dup > (SELECT COUNT(*) FROM news_images WHERE news_id = $1 AND file_image = w.file_image)
+	`, newsID, pq.Array(images))
+	return err
+}
+
+func (r *newsRepository) GetRevisions(newsID int) ([]models.NewsRevision, error) {
+	rows, err := r.db.Query(
+		"SELECT "+revisionColumns+revisionJoins+" WHERE r.news_id = $1 ORDER BY r.revision_no DESC",
+		newsID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.NewsRevision{}
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+func (r *newsRepository) GetRevision(newsID int, revisionNo int) (*models.NewsRevision, error) {
+	return scanRevision(r.db.QueryRow(
+		"SELECT "+revisionColumns+revisionJoins+" WHERE r.news_id = $1 AND r.revision_no = $2",
+		newsID, revisionNo,
+	))
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRevision(row rowScanner) (*models.NewsRevision, error) {
+	var (
+		revision   models.NewsRevision
+		authorID   sql.NullInt64
+		rollbackOf sql.NullInt64
+	)
+
+	err := row.Scan(
+		&revision.RevisionID, &revision.NewsID, &revision.RevisionNo, &revision.Title, &revision.Content,
+		&revision.TitleEn, &revision.ContentEn,
+		&revision.TypeID, &revision.TypeName, &revision.DetailURL, &revision.CoverImage, pq.Array(&revision.Images),
+		&authorID, &revision.AuthorName, &rollbackOf, &revision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if authorID.Valid {
+		id := int(authorID.Int64)
+		revision.AuthorID = &id
+	}
+	if rollbackOf.Valid {
+		no := int(rollbackOf.Int64)
+		revision.RollbackOf = &no
+	}
+	if revision.Images == nil {
+		revision.Images = []string{}
+	}
+
+	return &revision, nil
+}
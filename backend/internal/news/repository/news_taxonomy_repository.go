@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"cpsu/internal/news/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrNewsTypeInUse  = errors.New("news type still has news, move them to another type before deleting")
+	ErrDuplicateSlug  = errors.New("name or slug already exists")
+	ErrUnknownNewsTag = errors.New("news tag not found")
+)
+
+// VisibleNewsCondition คือข่าวที่แสดงบนหน้าเว็บสาธารณะ ข่าวที่เลยเวลา unpublish_at แล้วแต่ publisher ยังไม่ได้รันต้องไม่แสดง
+// ใช้ร่วมกับโมดูลอื่นที่ค้นหรือแสดงข่าว ต้องใช้ alias n กับตาราง news
+const VisibleNewsCondition = "n.status = 'published' AND (n.unpublish_at IS NULL OR n.unpublish_at > NOW())"
+
+// mapConstraintError แปลง error จาก constraint ของฐานข้อมูล
+// foreignKeyErr คือ error ที่ใช้เมื่อชน foreign key ซึ่งความหมายขึ้นกับคำสั่งที่รัน
+func mapConstraintError(err error, foreignKeyErr error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505":
+			return ErrDuplicateSlug
+		case "23503":
+			if foreignKeyErr != nil {
+				return foreignKeyErr
+			}
+		}
+	}
+	return err
+}
+
+func (r *newsRepository) GetNewsTypes() ([]models.NewsType, error) {
+	rows, err := r.db.Query(`
+		SELECT type_id, type_name, slug, sort_order
+		FROM news_types
+		ORDER BY sort_order, type_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := []models.NewsType{}
+	for rows.Next() {
+		var t models.NewsType
+		if err := rows.Scan(&t.TypeID, &t.TypeName, &t.Slug, &t.SortOrder); err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+	return types, rows.Err()
+}
+
+func (r *newsRepository) CreateNewsType(req models.NewsTypeRequest) (*models.NewsType, error) {
+	var t models.NewsType
+	err := r.db.QueryRow(`
+		INSERT INTO news_types (type_name, slug, sort_order)
+		VALUES ($1, $2, COALESCE($3, (SELECT COALESCE(MAX(sort_order), 0) + 1 FROM news_types)))
+		RETURNING type_id, type_name, slug, sort_order
+	`, req.TypeName, req.Slug, req.SortOrder).Scan(&t.TypeID, &t.TypeName, &t.Slug, &t.SortOrder)
+	if err != nil {
+		return nil, mapConstraintError(err, nil)
+	}
+	return &t, nil
+}
+
+func (r *newsRepository) UpdateNewsType(id int, req models.NewsTypeRequest) (*models.NewsType, error) {
+	var t models.NewsType
+	err := r.db.QueryRow(`
+		UPDATE news_types
+		SET type_name = $1, slug = $2, sort_order = COALESCE($3, sort_order)
+		WHERE type_id = $4
+		RETURNING type_id, type_name, slug, sort_order
+	`, req.TypeName, req.Slug, req.SortOrder, id).Scan(&t.TypeID, &t.TypeName, &t.Slug, &t.SortOrder)
+	if err != nil {
+		return nil, mapConstraintError(err, nil)
+	}
+	return &t, nil
+}
+
+func (r *newsRepository) DeleteNewsType(id int) error {
+	result, err := r.db.Exec("DELETE FROM news_types WHERE type_id = $1", id)
+	if err != nil {
+		return mapConstraintError(err, ErrNewsTypeInUse)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ReorderNewsTypes ตั้ง sort_order ตามลำดับของ ids เริ่มที่ 1
+func (r *newsRepository) ReorderNewsTypes(ids []int) error {
+	_, err := r.db.Exec(`
+		UPDATE news_types t
+		SET sort_order = o.ord
+		FROM unnest($1::int[]) WITH ORDINALITY AS o(type_id, ord)
+		WHERE t.type_id = o.type_id
+	`, pq.Array(ids))
+	return err
+}
+
+func (r *newsRepository) GetNewsTags() ([]models.NewsTag, error) {
+	rows, err := r.db.Query(`
+		SELECT tag_id, name, slug
+		FROM news_tags
+		ORDER BY name, tag_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.NewsTag{}
+	for rows.Next() {
+		var t models.NewsTag
+		if err := rows.Scan(&t.TagID, &t.Name, &t.Slug); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+func (r *newsRepository) CreateNewsTag(req models.NewsTagRequest) (*models.NewsTag, error) {
+	var t models.NewsTag
+	err := r.db.QueryRow(`
+		INSERT INTO news_tags (name, slug)
+		VALUES ($1, $2)
+		RETURNING tag_id, name, slug
+	`, req.Name, req.Slug).Scan(&t.TagID, &t.Name, &t.Slug)
+	if err != nil {
+		return nil, mapConstraintError(err, nil)
+	}
+	return &t, nil
+}
+
+func (r *newsRepository) UpdateNewsTag(id int, req models.NewsTagRequest) (*models.NewsTag, error) {
+	var t models.NewsTag
+	err := r.db.QueryRow(`
+		UPDATE news_tags SET name = $1, slug = $2
+		WHERE tag_id = $3
+		RETURNING tag_id, name, slug
+	`, req.Name, req.Slug, id).Scan(&t.TagID, &t.Name, &t.Slug)
+	if err != nil {
+		return nil, mapConstraintError(err, nil)
+	}
+	return &t, nil
+}
+
+func (r *newsRepository) DeleteNewsTag(id int) error {
+	result, err := r.db.Exec("DELETE FROM news_tags WHERE tag_id = $1", id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetNewsTags แทนที่แท็กทั้งหมดของข่าวด้วย tagIDs คืน sql.ErrNoRows เมื่อไม่มีข่าวนี้
+func (r *newsRepository) SetNewsTags(newsID int, tagIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// ตรวจว่ามีข่าวนี้ก่อน foreign key ที่ชนหลังจากนี้จึงมาจากแท็กเท่านั้น
+	if err := lockNews(tx, newsID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM news_tag_map WHERE news_id = $1", newsID); err != nil {
+		return err
+	}
+
+	if len(tagIDs) > 0 {
+		_, err := tx.Exec(`
+			INSERT INTO news_tag_map (news_id, tag_id)
+			SELECT DISTINCT $1::int, unnest($2::int[])
+		`, newsID, pq.Array(tagIDs))
+		if err != nil {
+			return mapConstraintError(err, ErrUnknownNewsTag)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// loadTags ดึงแท็กของข่าวในหน้าเดียวด้วย query เดียว
+func (r *newsRepository) loadTags(newsList []models.News) error {
+	if len(newsList) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(newsList))
+	index := make(map[int]int, len(newsList))
+	for i, n := range newsList {
+		ids[i] = int64(n.NewsID)
+		index[n.NewsID] = i
+		newsList[i].Tags = []models.NewsTag{}
+	}
+
+	rows, err := r.db.Query(`
+		SELECT m.news_id, t.tag_id, t.name, t.slug
+		FROM news_tag_map m
+		JOIN news_tags t ON m.tag_id = t.tag_id
+		WHERE m.news_id = ANY($1)
+		ORDER BY t.name, t.tag_id
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			newsID int
+			tag    models.NewsTag
+		)
+		if err := rows.Scan(&newsID, &tag.TagID, &tag.Name, &tag.Slug); err != nil {
+			return err
+		}
+		i := index[newsID]
+		newsList[i].Tags = append(newsList[i].Tags, tag)
+	}
+
+	return rows.Err()
+}
+
+// CountNews นับข่าวที่เผยแพร่อยู่ในแต่ละหมวดหมู่และแท็ก หมวดหมู่หรือแท็กที่ไม่มีข่าวได้ 0
+func (r *newsRepository) CountNews() (*models.NewsCounts, error) {
+	counts := &models.NewsCounts{
+		Types: []models.NewsTypeCount{},
+		Tags:  []models.NewsTagCount{},
+	}
+
+	rows, err := r.db.Query(`
+		SELECT t.type_id, t.type_name, t.slug, t.sort_order, COUNT(n.news_id)
+		FROM news_types t
+		LEFT JOIN news n ON n.type_id = t.type_id AND ` + VisibleNewsCondition + `
+		GROUP BY t.type_id
+		ORDER BY t.sort_order, t.type_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.NewsTypeCount
+		if err := rows.Scan(&c.TypeID, &c.TypeName, &c.Slug, &c.SortOrder, &c.Count); err != nil {
+			return nil, err
+		}
+		counts.Types = append(counts.Types, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tagRows, err := r.db.Query(`
+		SELECT t.tag_id, t.name, t.slug, COUNT(n.news_id)
+		FROM news_tags t
+		LEFT JOIN news_tag_map m ON m.tag_id = t.tag_id
+		LEFT JOIN news n ON n.news_id = m.news_id AND ` + VisibleNewsCondition + `
+		GROUP BY t.tag_id
+		ORDER BY t.name, t.tag_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer tagRows.Close()
+
+	for tagRows.Next() {
+		var c models.NewsTagCount
+		if err := tagRows.Scan(&c.TagID, &c.Name, &c.Slug, &c.Count); err != nil {
+			return nil, err
+		}
+		counts.Tags = append(counts.Tags, c)
+	}
+
+	return counts, tagRows.Err()
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+
+	"cpsu/internal/news/models"
+)
+
+var (
+	ErrInvalidHighlight     = errors.New("highlight must be pinned or featured")
+	ErrInvalidSlot          = errors.New("slot must be at least 1")
+	ErrInvalidHighlightTime = errors.New("end_at must be after start_at")
+	ErrDuplicateNewsID      = errors.New("news_ids must not contain duplicates")
+)
+
+// SetHighlight ปักหมุดหรือตั้งข่าวเป็นข่าวเด่น ถ้าตั้งไว้แล้วจะแทนที่ช่วงเวลาเดิม
+func (s *newsService) SetHighlight(newsID int, kind string, req models.NewsHighlightRequest, userID int, ip string, userAgent string) (*models.News, error) {
+	if !models.IsValidHighlight(kind) {
+		return nil, ErrInvalidHighlight
+	}
+	if req.Slot != nil && *req.Slot < 1 {
+		return nil, ErrInvalidSlot
+	}
+	if req.StartAt != nil && req.EndAt != nil && !req.EndAt.After(*req.StartAt) {
+		return nil, ErrInvalidHighlightTime
+	}
+
+	if err := s.repo.SetHighlight(newsID, kind, req); err != nil {
+		return nil, err
+	}
+
+	news, err := s.GetNewsByID(newsID)
+	if err != nil {
+		return nil, err
+	}
+
+	highlight := news.Pinned
+	if kind == models.HighlightFeatured {
+		highlight = news.Featured
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "update", "news", strconv.Itoa(newsID),
+		map[string]interface{}{
+			"highlight": kind,
+			"slot":      highlight.Slot,
+			"start_at":  highlight.StartAt,
+			"end_at":    highlight.EndAt,
+		},
+		ip,
+		userAgent,
+	)
+
+	return news, nil
+}
+
+func (s *newsService) ClearHighlight(newsID int, kind string, userID int, ip string, userAgent string) (*models.News, error) {
+	if !models.IsValidHighlight(kind) {
+		return nil, ErrInvalidHighlight
+	}
+
+	if err := s.repo.ClearHighlight(newsID, kind); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "update", "news", strconv.Itoa(newsID),
+		map[string]interface{}{
+			"highlight": kind,
+			"cleared":   true,
+		},
+		ip,
+		userAgent,
+	)
+
+	return s.GetNewsByID(newsID)
+}
+
+func (s *newsService) ReorderHighlights(req models.NewsHighlightOrderRequest, userID int, ip string, userAgent string) error {
+	if !models.IsValidHighlight(req.Kind) {
+		return ErrInvalidHighlight
+	}
+	seen := make(map[int]bool, len(req.NewsIDs))
+	for _, id := range req.NewsIDs {
+		if seen[id] {
+			return ErrDuplicateNewsID
+		}
+		seen[id] = true
+	}
+
+	if err := s.repo.ReorderHighlights(req.Kind, req.NewsIDs); err != nil {
+		return err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "update", "news", "",
+		map[string]interface{}{
+			"highlight": req.Kind,
+			"order":     req.NewsIDs,
+		},
+		ip,
+		userAgent,
+	)
+
+	return nil
+}
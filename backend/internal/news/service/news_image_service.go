@@ -0,0 +1,181 @@
+package service
+
+import (
+	"errors"
+	"mime/multipart"
+	"strconv"
+	"strings"
+
+	"cpsu/internal/imaging"
+	"cpsu/internal/news/models"
+)
+
+var (
+	ErrNoImages          = errors.New("at least one image is required")
+	ErrInvalidImageOrder = errors.New("image_ids must list every image of the news exactly once")
+)
+
+func (s *newsService) GetNewsImages(newsID int) ([]models.NewsImages, error) {
+	if _, err := s.repo.GetNewsByID(newsID); err != nil {
+		return nil, err
+	}
+
+	images, err := s.repo.GetNewsImages(newsID)
+	if err != nil {
+		return nil, err
+	}
+	setGalleryVariants(images)
+	return images, nil
+}
+
+// AddNewsImages อัปโหลดรูปแล้วต่อท้ายแกลเลอรีตามลำดับที่ส่งมา caption และ alt_text ใช้กับทุกรูปในครั้งนั้น
+func (s *newsService) AddNewsImages(newsID int, images []*multipart.FileHeader, caption string, altText string, userID int, ip string, userAgent string) ([]models.NewsImages, error) {
+	if len(images) == 0 {
+		return nil, ErrNoImages
+	}
+	if _, err := s.repo.GetNewsByID(newsID); err != nil {
+		return nil, err
+	}
+
+	var uploaded []string
+	for _, fileHeader := range images {
+		url, err := s.UploadImages(fileHeader)
+		if err != nil {
+			imaging.RemoveUnused(s.store, uploaded...)
+			return nil, err
+		}
+		uploaded = append(uploaded, url)
+	}
+
+	caption = strings.TrimSpace(caption)
+	altText = strings.TrimSpace(altText)
+
+	created, err := s.repo.AddNewsImages(newsID, uploaded, caption, altText, userID)
+	if err != nil {
+		imaging.RemoveUnused(s.store, uploaded...)
+		return nil, err
+	}
+
+	for _, img := range created {
+		_ = s.auditRepo.LogAudit(
+			userID, "create", "news_images", strconv.Itoa(img.ImageID),
+			map[string]interface{}{
+				"news_id":    newsID,
+				"file_image": img.FileImage,
+				"position":   img.Position,
+			},
+			ip,
+			userAgent,
+		)
+	}
+
+	return s.GetNewsImages(newsID)
+}
+
+// UpdateNewsImage แก้ caption/alt_text ซึ่งไม่ได้เก็บใน revision จึงไม่สร้าง revision ใหม่
+func (s *newsService) UpdateNewsImage(newsID int, imageID int, req models.NewsImageRequest, userID int, ip string, userAgent string) (*models.NewsImages, error) {
+	existing, err := s.repo.GetNewsImage(newsID, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Caption != nil {
+		caption := strings.TrimSpace(*req.Caption)
+		req.Caption = &caption
+	}
+	if req.AltText != nil {
+		altText := strings.TrimSpace(*req.AltText)
+		req.AltText = &altText
+	}
+
+	updated, err := s.repo.UpdateNewsImage(newsID, imageID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "update", "news_images", strconv.Itoa(imageID),
+		map[string]interface{}{
+			"news_id":       newsID,
+			"caption_from":  existing.Caption,
+			"caption_to":    updated.Caption,
+			"alt_text_from": existing.AltText,
+			"alt_text_to":   updated.AltText,
+		},
+		ip,
+		userAgent,
+	)
+
+	updated.Variants = imaging.VariantURLs(updated.FileImage)
+	return updated, nil
+}
+
+// DeleteNewsImage เอารูปออกจากแกลเลอรี ไฟล์ยังถูกอ้างอิงใน revision ก่อนหน้า จึงไม่ลบทิ้ง
+func (s *newsService) DeleteNewsImage(newsID int, imageID int, userID int, ip string, userAgent string) error {
+	existing, err := s.repo.GetNewsImage(newsID, imageID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteNewsImage(newsID, imageID, userID); err != nil {
+		return err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "delete", "news_images", strconv.Itoa(imageID),
+		map[string]interface{}{
+			"news_id":    newsID,
+			"file_image": existing.FileImage,
+		},
+		ip,
+		userAgent,
+	)
+
+	return nil
+}
+
+func (s *newsService) ReorderNewsImages(newsID int, req models.NewsImageOrderRequest, userID int, ip string, userAgent string) ([]models.NewsImages, error) {
+	images, err := s.GetNewsImages(newsID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.ImageIDs) != len(images) {
+		return nil, ErrInvalidImageOrder
+	}
+	existing := make(map[int]bool, len(images))
+	from := make([]int, len(images))
+	for i, img := range images {
+		existing[img.ImageID] = true
+		from[i] = img.ImageID
+	}
+	for _, id := range req.ImageIDs {
+		if !existing[id] {
+			return nil, ErrInvalidImageOrder
+		}
+		delete(existing, id)
+	}
+
+	if err := s.repo.ReorderNewsImages(newsID, req.ImageIDs, userID); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "update", "news_images", "",
+		map[string]interface{}{
+			"news_id":    newsID,
+			"order_from": from,
+			"order_to":   req.ImageIDs,
+		},
+		ip,
+		userAgent,
+	)
+
+	return s.GetNewsImages(newsID)
+}
+
+func setGalleryVariants(images []models.NewsImages) {
+	for i := range images {
+		images[i].Variants = imaging.VariantURLs(images[i].FileImage)
+	}
+}
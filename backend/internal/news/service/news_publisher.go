@@ -0,0 +1,30 @@
+package service
+
+import (
+	"log"
+
+	"github.com/robfig/cron/v3"
+)
+
+// PublishScheduledNews ตรวจข่าวที่ตั้งเวลาไว้ทุกนาที
+func PublishScheduledNews(newsService NewsService) {
+	c := cron.New(cron.WithSeconds())
+
+	_, err := c.AddFunc("0 * * * * *", func() {
+		published, archived, err := newsService.PublishDueNews()
+		if err != nil {
+			log.Println("[CRON] Publish scheduled news failed:", err)
+			return
+		}
+
+		if published > 0 || archived > 0 {
+			log.Printf("[CRON] Published %d news, archived %d news\n", published, archived)
+		}
+	})
+
+	if err != nil {
+		log.Fatal("cannot start news publisher cron:", err)
+	}
+
+	c.Start()
+}
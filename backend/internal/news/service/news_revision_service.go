@@ -0,0 +1,151 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"cpsu/internal/news/models"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+func (s *newsService) GetRevisions(newsID int) ([]models.NewsRevision, error) {
+	if _, err := s.repo.GetNewsByID(newsID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetRevisions(newsID)
+}
+
+func (s *newsService) GetRevision(newsID int, revisionNo int) (*models.NewsRevision, error) {
+	revision, err := s.repo.GetRevision(newsID, revisionNo)
+	if err != nil {
+		return nil, revisionError(err)
+	}
+	return revision, nil
+}
+
+func (s *newsService) DiffRevisions(newsID int, from int, to int) (*models.NewsRevisionDiff, error) {
+	fromRevision, err := s.GetRevision(newsID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := s.GetRevision(newsID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.NewsRevisionDiff{
+		NewsID:        newsID,
+		From:          from,
+		To:            to,
+		Changes:       []models.RevisionFieldChange{},
+		ImagesAdded:   missingFrom(toRevision.Images, fromRevision.Images),
+		ImagesRemoved: missingFrom(fromRevision.Images, toRevision.Images),
+	}
+
+	addChange := func(field string, a, b interface{}) {
+		if a != b {
+			diff.Changes = append(diff.Changes, models.RevisionFieldChange{Field: field, From: a, To: b})
+		}
+	}
+	addChange("title", fromRevision.Title, toRevision.Title)
+	addChange("content", fromRevision.Content, toRevision.Content)
+	addChange("title_en", fromRevision.TitleEn, toRevision.TitleEn)
+	addChange("content_en", fromRevision.ContentEn, toRevision.ContentEn)
+	addChange("type_id", fromRevision.TypeID, toRevision.TypeID)
+	addChange("detail_url", fromRevision.DetailURL, toRevision.DetailURL)
+	addChange("cover_image", fromRevision.CoverImage, toRevision.CoverImage)
+
+	// ชุดรูปเหมือนเดิมแต่ลำดับเปลี่ยน
+	if len(diff.ImagesAdded) == 0 && len(diff.ImagesRemoved) == 0 && !sameOrder(fromRevision.Images, toRevision.Images) {
+		diff.Changes = append(diff.Changes, models.RevisionFieldChange{Field: "images", From: fromRevision.Images, To: toRevision.Images})
+	}
+
+	return diff, nil
+}
+
+// Rollback นำเนื้อหาของ revision เดิมกลับมาใช้ และบันทึกเป็น revision ใหม่ ประวัติเดิมไม่ถูกแก้ไข
+func (s *newsService) Rollback(newsID int, revisionNo int, userID int, ip string, userAgent string) (*models.News, error) {
+	revision, err := s.GetRevision(newsID, revisionNo)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.RollbackNews(newsID, revision, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "rollback", "news", strconv.Itoa(newsID),
+		map[string]interface{}{
+			"title":        revision.Title,
+			"rollback_to":  revision.RevisionNo,
+			"new_revision": created.RevisionNo,
+		},
+		ip,
+		userAgent,
+	)
+
+	return s.GetNewsByID(newsID)
+}
+
+func revisionError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrRevisionNotFound
+	}
+	return err
+}
+
+// newsFiles รวมไฟล์ทั้งหมดของข่าวรวมถึงไฟล์ใน revision เก่า ใช้ตอนลบข่าว
+func newsFiles(news *models.News, revisions []models.NewsRevision) []string {
+	seen := map[string]bool{}
+	var files []string
+	add := func(url string) {
+		if url != "" && !seen[url] {
+			seen[url] = true
+			files = append(files, url)
+		}
+	}
+
+	add(news.CoverImage)
+	for _, img := range news.Images {
+		add(img.FileImage)
+	}
+	for _, revision := range revisions {
+		add(revision.CoverImage)
+		for _, url := range revision.Images {
+			add(url)
+		}
+	}
+	return files
+}
+
+// missingFrom คืนรายการใน a ที่ไม่มีใน b
+func missingFrom(a, b []string) []string {
+	inB := map[string]bool{}
+	for _, url := range b {
+		inB[url] = true
+	}
+
+	result := []string{}
+	for _, url := range a {
+		if !inB[url] {
+			result = append(result, url)
+		}
+	}
+	return result
+}
+
+func sameOrder(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
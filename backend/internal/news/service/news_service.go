@@ -3,90 +3,115 @@ package service
 import (
 	"context"
 	"errors"
-	"fmt"
 	"mime/multipart"
-	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"cpsu/internal/news/models"
-	"cpsu/internal/news/repository"
 	newsrepo "cpsu/internal/news/repository"
 
 	authrepo "cpsu/internal/auth/repository"
-
-	"github.com/google/uuid"
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
+	"cpsu/internal/imaging"
+	"cpsu/internal/pagination"
+	"cpsu/internal/storage"
 )
 
 type NewsService interface {
-	GetAllNews(param models.NewsQueryParam) ([]models.News, error)
+	GetAllNews(param models.NewsQueryParam) (*pagination.Page[models.News], error)
 	GetNewsByID(id int) (*models.News, error)
-	CreateNews(title, content string, typeID int, typeName, detailURL string, coverImage *multipart.FileHeader, images []*multipart.FileHeader, userID int, ip string, userAgent string) (*models.News, error)
-	UpdateNews(id int, title, content string, type_id int, typeName, detailURL string, coverImage *multipart.FileHeader, images []*multipart.FileHeader, userID int, ip string, userAgent string) (*models.News, error)
+	CreateNews(title, content, titleEn, contentEn string, typeID int, typeName, detailURL string, coverImage *multipart.FileHeader, images []*multipart.FileHeader, userID int, ip string, userAgent string) (*models.News, error)
+	UpdateNews(id int, title, content string, titleEn, contentEn *string, type_id int, typeName, detailURL string, coverImage *multipart.FileHeader, images []*multipart.FileHeader, userID int, ip string, userAgent string) (*models.News, error)
 	DeleteNews(id int, userID int, ip string, userAgent string) error
+	ChangeStatus(id int, req models.NewsStatusRequest, userID int, ip string, userAgent string) (*models.News, error)
+	Publish(id int, req models.NewsStatusRequest, userID int, ip string, userAgent string) (*models.News, error)
+	PublishDueNews() (published int64, archived int64, err error)
+	GetRevisions(newsID int) ([]models.NewsRevision, error)
+	GetRevision(newsID int, revisionNo int) (*models.NewsRevision, error)
+	DiffRevisions(newsID int, from int, to int) (*models.NewsRevisionDiff, error)
+	Rollback(newsID int, revisionNo int, userID int, ip string, userAgent string) (*models.News, error)
+	GetNewsTypes() ([]models.NewsType, error)
+	CreateNewsType(req models.NewsTypeRequest, userID int, ip string, userAgent string) (*models.NewsType, error)
+	UpdateNewsType(id int, req models.NewsTypeRequest, userID int, ip string, userAgent string) (*models.NewsType, error)
+	DeleteNewsType(id int, userID int, ip string, userAgent string) error
+	ReorderNewsTypes(req models.NewsTypeOrderRequest, userID int, ip string, userAgent string) ([]models.NewsType, error)
+	GetNewsTags() ([]models.NewsTag, error)
+	CreateNewsTag(req models.NewsTagRequest, userID int, ip string, userAgent string) (*models.NewsTag, error)
+	UpdateNewsTag(id int, req models.NewsTagRequest, userID int, ip string, userAgent string) (*models.NewsTag, error)
+	DeleteNewsTag(id int, userID int, ip string, userAgent string) error
+	SetNewsTags(newsID int, req models.NewsTagsRequest, userID int, ip string, userAgent string) (*models.News, error)
+	CountNews() (*models.NewsCounts, error)
+	GetNewsImages(newsID int) ([]models.NewsImages, error)
+	AddNewsImages(newsID int, images []*multipart.FileHeader, caption string, altText string, userID int, ip string, userAgent string) ([]models.NewsImages, error)
+	UpdateNewsImage(newsID int, imageID int, req models.NewsImageRequest, userID int, ip string, userAgent string) (*models.NewsImages, error)
+	DeleteNewsImage(newsID int, imageID int, userID int, ip string, userAgent string) error
+	ReorderNewsImages(newsID int, req models.NewsImageOrderRequest, userID int, ip string, userAgent string) ([]models.NewsImages, error)
+	SetHighlight(newsID int, kind string, req models.NewsHighlightRequest, userID int, ip string, userAgent string) (*models.News, error)
+	ClearHighlight(newsID int, kind string, userID int, ip string, userAgent string) (*models.News, error)
+	ReorderHighlights(req models.NewsHighlightOrderRequest, userID int, ip string, userAgent string) error
 }
 
+var (
+	ErrInvalidStatus      = errors.New("invalid news status")
+	ErrStatusNeedsPublish = errors.New("status requires publish permission")
+	ErrInvalidPublishTime = errors.New("unpublish_at must be after publish_at")
+)
+
 type newsService struct {
-	repo        newsrepo.NewsRepository
-	auditRepo   *authrepo.AuditRepository
-	minioClient *minio.Client
-	bucket      string
-	publicBase  string
+	repo      newsrepo.NewsRepository
+	auditRepo *authrepo.AuditRepository
+	store     storage.Storage
+	images    *imaging.Pipeline
 }
 
 func NewNewsService(
 	repo newsrepo.NewsRepository,
 	auditRepo *authrepo.AuditRepository,
-	endpoint string,
-	accessKey string,
-	secretKey string,
-	bucket string,
-	useSSL bool,
-	publicBaseURL string,
+	store storage.Storage,
+	images *imaging.Pipeline,
 ) NewsService {
 
-	client, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
-		Secure: useSSL,
-	})
-	if err != nil {
-		panic(err)
-	}
-
 	return &newsService{
-		repo:        repo,
-		auditRepo:   auditRepo,
-		minioClient: client,
-		bucket:      bucket,
-		publicBase:  publicBaseURL,
+		repo:      repo,
+		auditRepo: auditRepo,
+		store:     store,
+		images:    images,
 	}
 }
 
-func (s *newsService) GetAllNews(param models.NewsQueryParam) ([]models.News, error) {
-	if param.Sort == "" {
-		param.Sort = "created_at"
+func (s *newsService) GetAllNews(param models.NewsQueryParam) (*pagination.Page[models.News], error) {
+	if param.Status != "" && !models.IsValidStatus(param.Status) {
+		return nil, ErrInvalidStatus
 	}
-	if param.Order == "" {
-		param.Order = "desc"
+	if param.Highlight != "" && !models.IsValidHighlight(param.Highlight) {
+		return nil, ErrInvalidHighlight
 	}
 	newsList, err := s.repo.GetAllNews(param)
 	if err != nil {
 		return nil, err
 	}
-	if len(newsList) == 0 && param.TypeID > 0 {
-		return nil, errors.New("news type not found")
+	for i := range newsList.Items {
+		setImageVariants(&newsList.Items[i])
 	}
 
 	return newsList, nil
 }
 
 func (s *newsService) GetNewsByID(id int) (*models.News, error) {
-	return s.repo.GetNewsByID(id)
+	news, err := s.repo.GetNewsByID(id)
+	if err != nil {
+		return nil, err
+	}
+	setImageVariants(news)
+	return news, nil
 }
 
-func (s *newsService) CreateNews(title, content string, typeID int, typeName, detailURL string, coverImage *multipart.FileHeader, images []*multipart.FileHeader, userID int, ip string, userAgent string) (*models.News, error) {
+func setImageVariants(news *models.News) {
+	news.CoverImageVariants = imaging.VariantURLs(news.CoverImage)
+	setGalleryVariants(news.Images)
+}
+
+func (s *newsService) CreateNews(title, content, titleEn, contentEn string, typeID int, typeName, detailURL string, coverImage *multipart.FileHeader, images []*multipart.FileHeader, userID int, ip string, userAgent string) (*models.News, error) {
 	if strings.TrimSpace(title) == "" || strings.TrimSpace(content) == "" {
 		return nil, errors.New("title and content are required")
 	}
@@ -95,6 +120,7 @@ func (s *newsService) CreateNews(title, content string, typeID int, typeName, de
 	for _, fileHeader := range images {
 		url, err := s.UploadImages(fileHeader)
 		if err != nil {
+			imaging.RemoveUnused(s.store, uploadedFlies...)
 			return nil, err
 		}
 		uploadedFlies = append(uploadedFlies, url)
@@ -104,6 +130,7 @@ func (s *newsService) CreateNews(title, content string, typeID int, typeName, de
 	if coverImage != nil {
 		url, err := s.UploadImages(coverImage)
 		if err != nil {
+			imaging.RemoveUnused(s.store, uploadedFlies...)
 			return nil, err
 		}
 		coverURL = url
@@ -112,6 +139,8 @@ func (s *newsService) CreateNews(title, content string, typeID int, typeName, de
 	newsReq := &models.NewsRequest{
 		Title:      title,
 		Content:    content,
+		TitleEn:    strings.TrimSpace(titleEn),
+		ContentEn:  contentEn,
 		TypeID:     typeID,
 		DetailURL:  detailURL,
 		CoverImage: coverURL,
@@ -121,8 +150,9 @@ func (s *newsService) CreateNews(title, content string, typeID int, typeName, de
 		newsReq.Images = append(newsReq.Images, models.NewsImages{FileImage: url})
 	}
 
-	created, err := s.repo.CreateNews(newsReq)
+	created, err := s.repo.CreateNews(newsReq, userID)
 	if err != nil {
+		imaging.RemoveUnused(s.store, append(uploadedFlies, coverURL)...)
 		return nil, err
 	}
 
@@ -135,27 +165,35 @@ func (s *newsService) CreateNews(title, content string, typeID int, typeName, de
 		userAgent,
 	)
 
-	return s.repo.GetNewsByID(created.NewsID)
+	return s.GetNewsByID(created.NewsID)
 }
 
-func (s *newsService) UpdateNews(id int, title, content string, typeID int, typeName, detailURL string, coverImage *multipart.FileHeader, images []*multipart.FileHeader, userID int, ip string, userAgent string) (*models.News, error) {
+// UpdateNews ถ้า titleEn/contentEn เป็น nil จะคงคำแปลเดิมไว้
+func (s *newsService) UpdateNews(id int, title, content string, titleEn, contentEn *string, typeID int, typeName, detailURL string, coverImage *multipart.FileHeader, images []*multipart.FileHeader, userID int, ip string, userAgent string) (*models.News, error) {
 	if strings.TrimSpace(title) == "" || strings.TrimSpace(content) == "" {
 		return nil, errors.New("title and content are required")
 	}
 
+	existing, err := s.repo.GetNewsByID(id)
+	if err != nil {
+		return nil, err
+	}
+
 	var uploadedFlies []string
 	for _, fileHeader := range images {
 		url, err := s.UploadImages(fileHeader)
 		if err != nil {
+			imaging.RemoveUnused(s.store, uploadedFlies...)
 			return nil, err
 		}
 		uploadedFlies = append(uploadedFlies, url)
 	}
 
-	var coverURL string
+	coverURL := existing.CoverImage
 	if coverImage != nil {
 		url, err := s.UploadImages(coverImage)
 		if err != nil {
+			imaging.RemoveUnused(s.store, uploadedFlies...)
 			return nil, err
 		}
 		coverURL = url
@@ -164,22 +202,32 @@ func (s *newsService) UpdateNews(id int, title, content string, typeID int, type
 	newsReq := &models.NewsRequest{
 		Title:      title,
 		Content:    content,
+		TitleEn:    existing.TitleEn,
+		ContentEn:  existing.ContentEn,
 		TypeID:     typeID,
 		DetailURL:  detailURL,
 		CoverImage: coverURL,
 	}
+	if titleEn != nil {
+		newsReq.TitleEn = strings.TrimSpace(*titleEn)
+	}
+	if contentEn != nil {
+		newsReq.ContentEn = *contentEn
+	}
 
+	// รูปเดิมคง image_id และลำดับไว้ รูปที่อัปโหลดใหม่ต่อท้ายแกลเลอรี
+	newsReq.Images = existing.Images
 	for _, url := range uploadedFlies {
 		newsReq.Images = append(newsReq.Images, models.NewsImages{FileImage: url})
 	}
 
-	_, err := s.repo.UpdateNews(id, newsReq)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = s.repo.UpdateNewsImages(id, repository.ImagesAsStrings(newsReq.Images))
+	// รูปเดิมยังถูกอ้างอิงใน revision ก่อนหน้า จึงไม่ลบทิ้ง
+	_, err = s.repo.UpdateNews(id, newsReq, uploadedFlies, userID)
 	if err != nil {
+		if coverImage != nil {
+			uploadedFlies = append(uploadedFlies, coverURL)
+		}
+		imaging.RemoveUnused(s.store, uploadedFlies...)
 		return nil, err
 	}
 
@@ -195,16 +243,28 @@ func (s *newsService) UpdateNews(id int, title, content string, typeID int, type
 		userAgent,
 	)
 
-	return s.repo.GetNewsByID(id)
+	return s.GetNewsByID(id)
 }
 
 func (s *newsService) DeleteNews(id int, userID int, ip string, userAgent string) error {
 
-	err := s.repo.DeleteNews(id)
+	existing, err := s.repo.GetNewsByID(id)
+	if err != nil {
+		return err
+	}
+
+	revisions, err := s.repo.GetRevisions(id)
 	if err != nil {
 		return err
 	}
 
+	err = s.repo.DeleteNews(id)
+	if err != nil {
+		return err
+	}
+
+	imaging.RemoveUnused(s.store, newsFiles(existing, revisions)...)
+
 	err = s.auditRepo.LogAudit(
 		userID, "delete", "news", strconv.Itoa(id),
 		map[string]interface{}{}, ip, userAgent,
@@ -212,40 +272,97 @@ func (s *newsService) DeleteNews(id int, userID int, ip string, userAgent string
 
 	return nil
 }
-func (s *newsService) UploadImages(fileHeader *multipart.FileHeader) (string, error) {
-	file, err := fileHeader.Open()
+
+// ChangeStatus ใช้ย้ายข่าวระหว่าง draft กับ in_review เท่านั้น
+// การเผยแพร่ ตั้งเวลา หรือเก็บเข้าคลังต้องผ่าน Publish
+func (s *newsService) ChangeStatus(id int, req models.NewsStatusRequest, userID int, ip string, userAgent string) (*models.News, error) {
+	if !models.IsValidStatus(req.Status) {
+		return nil, ErrInvalidStatus
+	}
+	if req.Status != models.StatusDraft && req.Status != models.StatusInReview {
+		return nil, ErrStatusNeedsPublish
+	}
+
+	existing, err := s.repo.GetNewsByID(id)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer file.Close()
 
-	ext := filepath.Ext(fileHeader.Filename)
-	objectName := fmt.Sprintf(
-		"news/%s%s",
-		uuid.New().String(),
-		ext,
-	)
+	// คงเวลาเดิมไว้ เผื่อส่งกลับมาเผยแพร่อีกครั้ง
+	req.PublishAt = existing.PublishAt
+	req.UnpublishAt = existing.UnpublishAt
 
-	_, err = s.minioClient.PutObject(
-		context.Background(),
-		s.bucket,
-		objectName,
-		file,
-		fileHeader.Size,
-		minio.PutObjectOptions{
-			ContentType: fileHeader.Header.Get("Content-Type"),
+	if err := s.repo.UpdateNewsStatus(id, req); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "update_status", "news", strconv.Itoa(id),
+		map[string]interface{}{
+			"from": existing.Status,
+			"to":   req.Status,
 		},
+		ip,
+		userAgent,
 	)
+
+	return s.GetNewsByID(id)
+}
+
+// Publish เผยแพร่ข่าวทันที หรือตั้งเวลาเมื่อ publish_at อยู่ในอนาคต
+// และใช้เก็บข่าวเข้าคลัง (archived)
+func (s *newsService) Publish(id int, req models.NewsStatusRequest, userID int, ip string, userAgent string) (*models.News, error) {
+	if req.Status != models.StatusPublished && req.Status != models.StatusScheduled && req.Status != models.StatusArchived {
+		return nil, ErrInvalidStatus
+	}
+
+	existing, err := s.repo.GetNewsByID(id)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if req.Status == models.StatusArchived {
+		req.PublishAt = existing.PublishAt
+		req.UnpublishAt = existing.UnpublishAt
+	} else {
+		if req.PublishAt == nil {
+			req.PublishAt = &now
+		}
+		if req.UnpublishAt != nil && !req.UnpublishAt.After(*req.PublishAt) {
+			return nil, ErrInvalidPublishTime
+		}
+
+		req.Status = models.StatusPublished
+		if req.PublishAt.After(now) {
+			req.Status = models.StatusScheduled
+		}
 	}
 
-	imageURL := fmt.Sprintf(
-		"%s/%s/%s",
-		s.publicBase,
-		s.bucket,
-		objectName,
+	if err := s.repo.UpdateNewsStatus(id, req); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "publish", "news", strconv.Itoa(id),
+		map[string]interface{}{
+			"from":         existing.Status,
+			"to":           req.Status,
+			"publish_at":   req.PublishAt,
+			"unpublish_at": req.UnpublishAt,
+		},
+		ip,
+		userAgent,
 	)
 
-	return imageURL, nil
+	return s.GetNewsByID(id)
+}
+
+func (s *newsService) PublishDueNews() (int64, int64, error) {
+	return s.repo.PublishDueNews()
+}
+
+func (s *newsService) UploadImages(fileHeader *multipart.FileHeader) (string, error) {
+	return s.images.Upload(context.Background(), s.store, "news", fileHeader)
 }
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"cpsu/internal/imaging"
+	"cpsu/internal/news/models"
+	"cpsu/internal/news/repository"
+
+	authrepo "cpsu/internal/auth/repository"
+
+	_ "github.com/lib/pq"
+)
+
+// publishRepo จำสถานะที่บันทึกของข่าวเดียว เมธอดอื่นของ NewsRepository ไม่ได้ใช้ในเทสต์นี้
+type publishRepo struct {
+	repository.NewsRepository
+
+	news models.News
+}
+
+func (r *publishRepo) GetNewsByID(id int) (*models.News, error) {
+	news := r.news
+	news.NewsID = id
+	return &news, nil
+}
+
+func (r *publishRepo) UpdateNewsStatus(id int, req models.NewsStatusRequest) error {
+	r.news.Status = req.Status
+	r.news.PublishAt = req.PublishAt
+	r.news.UnpublishAt = req.UnpublishAt
+	return nil
+}
+
+// newPublishTestService ใช้ audit repository ที่ต่อฐานข้อมูลไม่ได้ การบันทึก audit ล้มเหลวจะถูกข้ามไปเหมือนตอนใช้งานจริง
+func newPublishTestService(t *testing.T, repo repository.NewsRepository) NewsService {
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewNewsService(repo, authrepo.NewAuditRepository(db), nil, imaging.NewPipeline(0))
+}
+
+func TestPublish(t *testing.T) {
+	// เวลาจากหน้าเว็บมาพร้อม offset +07:00 ต้องตัดสินตามเวลาจริง ไม่ใช่ตัวเลขนาฬิกา
+	bangkok := time.FixedZone("Asia/Bangkok", 7*60*60)
+	at := func(d time.Duration) *time.Time {
+		t := time.Now().Add(d).In(bangkok)
+		return &t
+	}
+
+	tests := []struct {
+		name        string
+		status      string
+		publishAt   *time.Time
+		unpublishAt *time.Time
+		wantStatus  string
+		wantErr     error
+	}{
+		{"now", models.StatusPublished, nil, nil, models.StatusPublished, nil},
+		{"one hour ago in +07:00", models.StatusPublished, at(-time.Hour), nil, models.StatusPublished, nil},
+		{"six hours ago in +07:00", models.StatusScheduled, at(-6 * time.Hour), nil, models.StatusPublished, nil},
+		{"one hour ahead in +07:00", models.StatusPublished, at(time.Hour), nil, models.StatusScheduled, nil},
+		{"in utc", models.StatusPublished, func() *time.Time { t := time.Now().Add(time.Hour).UTC(); return &t }(), nil, models.StatusScheduled, nil},
+		{"unpublish after publish", models.StatusPublished, at(-time.Hour), at(time.Hour), models.StatusPublished, nil},
+		{"unpublish before publish", models.StatusPublished, at(time.Hour), at(-time.Hour), "", ErrInvalidPublishTime},
+		{"unpublish without publish in the past", models.StatusPublished, nil, at(-time.Minute), "", ErrInvalidPublishTime},
+		{"draft", models.StatusDraft, nil, nil, "", ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &publishRepo{news: models.News{Status: models.StatusDraft}}
+			svc := newPublishTestService(t, repo)
+
+			req := models.NewsStatusRequest{Status: tt.status, PublishAt: tt.publishAt, UnpublishAt: tt.unpublishAt}
+			news, err := svc.Publish(1, req, 1, "", "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Publish error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if repo.news.Status != models.StatusDraft {
+					t.Errorf("status = %s after error, want unchanged", repo.news.Status)
+				}
+				return
+			}
+			if news.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", news.Status, tt.wantStatus)
+			}
+			if news.PublishAt == nil || (tt.publishAt != nil && !news.PublishAt.Equal(*tt.publishAt)) {
+				t.Errorf("publish_at = %v, want %v", news.PublishAt, tt.publishAt)
+			}
+		})
+	}
+}
+
+func TestPublishArchiveKeepsTimes(t *testing.T) {
+	publishAt := time.Date(2026, 1, 5, 9, 0, 0, 0, time.FixedZone("Asia/Bangkok", 7*60*60))
+	repo := &publishRepo{news: models.News{Status: models.StatusPublished, PublishAt: &publishAt}}
+	svc := newPublishTestService(t, repo)
+
+	other := publishAt.Add(time.Hour)
+	news, err := svc.Publish(1, models.NewsStatusRequest{Status: models.StatusArchived, PublishAt: &other}, 1, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if news.Status != models.StatusArchived || news.PublishAt == nil || !news.PublishAt.Equal(publishAt) || news.UnpublishAt != nil {
+		t.Errorf("archived = %s %v %v, want archived %v <nil>", news.Status, news.PublishAt, news.UnpublishAt, publishAt)
+	}
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"cpsu/internal/news/models"
+)
+
+var (
+	ErrInvalidSlug      = errors.New("slug may contain only lowercase letters, digits and hyphens")
+	ErrInvalidTypeOrder = errors.New("type_ids must list every news type exactly once")
+)
+
+func (s *newsService) GetNewsTypes() ([]models.NewsType, error) {
+	return s.repo.GetNewsTypes()
+}
+
+func (s *newsService) CreateNewsType(req models.NewsTypeRequest, userID int, ip string, userAgent string) (*models.NewsType, error) {
+	if err := normalizeTypeRequest(&req); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.CreateNewsType(req)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "create", "news_types", strconv.Itoa(created.TypeID),
+		map[string]interface{}{
+			"type_name": created.TypeName,
+			"slug":      created.Slug,
+		},
+		ip,
+		userAgent,
+	)
+
+	return created, nil
+}
+
+func (s *newsService) UpdateNewsType(id int, req models.NewsTypeRequest, userID int, ip string, userAgent string) (*models.NewsType, error) {
+	if err := normalizeTypeRequest(&req); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.UpdateNewsType(id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "update", "news_types", strconv.Itoa(id),
+		map[string]interface{}{
+			"type_name":  updated.TypeName,
+			"slug":       updated.Slug,
+			"sort_order": updated.SortOrder,
+		},
+		ip,
+		userAgent,
+	)
+
+	return updated, nil
+}
+
+func (s *newsService) DeleteNewsType(id int, userID int, ip string, userAgent string) error {
+	if err := s.repo.DeleteNewsType(id); err != nil {
+		return err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "delete", "news_types", strconv.Itoa(id),
+		map[string]interface{}{}, ip, userAgent,
+	)
+
+	return nil
+}
+
+// ReorderNewsTypes ต้องส่งหมวดหมู่มาครบทุกตัว เพื่อไม่ให้ sort_order ซ้ำกับตัวที่ไม่ได้ส่งมา
+func (s *newsService) ReorderNewsTypes(req models.NewsTypeOrderRequest, userID int, ip string, userAgent string) ([]models.NewsType, error) {
+	types, err := s.repo.GetNewsTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.TypeIDs) != len(types) {
+		return nil, ErrInvalidTypeOrder
+	}
+	existing := make(map[int]bool, len(types))
+	for _, t := range types {
+		existing[t.TypeID] = true
+	}
+	for _, id := range req.TypeIDs {
+		if !existing[id] {
+			return nil, ErrInvalidTypeOrder
+		}
+		delete(existing, id)
+	}
+
+	if err := s.repo.ReorderNewsTypes(req.TypeIDs); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "update", "news_types", "",
+		map[string]interface{}{
+			"order": req.TypeIDs,
+		},
+		ip,
+		userAgent,
+	)
+
+	return s.repo.GetNewsTypes()
+}
+
+func (s *newsService) GetNewsTags() ([]models.NewsTag, error) {
+	return s.repo.GetNewsTags()
+}
+
+func (s *newsService) CreateNewsTag(req models.NewsTagRequest, userID int, ip string, userAgent string) (*models.NewsTag, error) {
+	if err := normalizeTagRequest(&req); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.CreateNewsTag(req)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "create", "news_tags", strconv.Itoa(created.TagID),
+		map[string]interface{}{
+			"name": created.Name,
+			"slug": created.Slug,
+		},
+		ip,
+		userAgent,
+	)
+
+	return created, nil
+}
+
+func (s *newsService) UpdateNewsTag(id int, req models.NewsTagRequest, userID int, ip string, userAgent string) (*models.NewsTag, error) {
+	if err := normalizeTagRequest(&req); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.UpdateNewsTag(id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "update", "news_tags", strconv.Itoa(id),
+		map[string]interface{}{
+			"name": updated.Name,
+			"slug": updated.Slug,
+		},
+		ip,
+		userAgent,
+	)
+
+	return updated, nil
+}
+
+func (s *newsService) DeleteNewsTag(id int, userID int, ip string, userAgent string) error {
+	if err := s.repo.DeleteNewsTag(id); err != nil {
+		return err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "delete", "news_tags", strconv.Itoa(id),
+		map[string]interface{}{}, ip, userAgent,
+	)
+
+	return nil
+}
+
+func (s *newsService) SetNewsTags(newsID int, req models.NewsTagsRequest, userID int, ip string, userAgent string) (*models.News, error) {
+	existing, err := s.repo.GetNewsByID(newsID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetNewsTags(newsID, req.TagIDs); err != nil {
+		return nil, err
+	}
+
+	from := make([]int, len(existing.Tags))
+	for i, tag := range existing.Tags {
+		from[i] = tag.TagID
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "update", "news", strconv.Itoa(newsID),
+		map[string]interface{}{
+			"tags_from": from,
+			"tags_to":   req.TagIDs,
+		},
+		ip,
+		userAgent,
+	)
+
+	return s.GetNewsByID(newsID)
+}
+
+func (s *newsService) CountNews() (*models.NewsCounts, error) {
+	return s.repo.CountNews()
+}
+
+func normalizeTypeRequest(req *models.NewsTypeRequest) error {
+	req.TypeName = strings.TrimSpace(req.TypeName)
+	slug, err := normalizeSlug(req.Slug, req.TypeName)
+	if err != nil {
+		return err
+	}
+	req.Slug = slug
+	return nil
+}
+
+func normalizeTagRequest(req *models.NewsTagRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	slug, err := normalizeSlug(req.Slug, req.Name)
+	if err != nil {
+		return err
+	}
+	req.Slug = slug
+	return nil
+}
+
+// normalizeSlug สร้าง slug จากชื่อเมื่อไม่ได้ส่งมา ถ้าส่งมาต้องอยู่ในรูปที่ slugify แล้ว
+func normalizeSlug(slug string, name string) (string, error) {
+	if strings.TrimSpace(slug) == "" {
+		slug = slugify(name)
+	} else if slugify(slug) != slug {
+		return "", ErrInvalidSlug
+	}
+	if slug == "" {
+		return "", ErrInvalidSlug
+	}
+	return slug, nil
+}
+
+// slugify เก็บตัวอักษร (รวมภาษาไทยพร้อมสระและวรรณยุกต์) กับตัวเลข ส่วนอื่นแทนด้วย "-"
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"cpsu/internal/outcome/models"
+	"cpsu/internal/outcome/repository"
+	"cpsu/internal/outcome/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+type OutcomeHandler struct {
+	outcomeService service.OutcomeService
+}
+
+func NewOutcomeHandler(outcomeService service.OutcomeService) *OutcomeHandler {
+	return &OutcomeHandler{outcomeService: outcomeService}
+}
+
+func (h *OutcomeHandler) GetPLOs(c *gin.Context) {
+	plos, err := h.outcomeService.GetPLOs(c.Param("id"))
+	if err != nil {
+		outcomeErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, plos)
+}
+
+func (h *OutcomeHandler) GetCurriculumMap(c *gin.Context) {
+	curriculumMap, err := h.outcomeService.GetCurriculumMap(c.Param("id"))
+	if err != nil {
+		outcomeErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, curriculumMap)
+}
+
+func (h *OutcomeHandler) ExportCurriculumMap(c *gin.Context) {
+	courseID := c.Param("id")
+	data, err := h.outcomeService.ExportCurriculumMap(courseID)
+	if err != nil {
+		outcomeErrorResponse(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("curriculum-map-%s.xlsx", courseID)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, xlsxContentType, data)
+}
+
+func (h *OutcomeHandler) SetPLOs(c *gin.Context) {
+	var req models.OutcomeItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plos, err := h.outcomeService.SetPLOs(
+		c.Param("id"),
+		req,
+		c.GetInt("user_id"),
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+	)
+	if err != nil {
+		outcomeErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, plos)
+}
+
+// SetCLOs แทนที่ CLO ของรายวิชา :code ในหลักสูตร :id
+func (h *OutcomeHandler) SetCLOs(c *gin.Context) {
+	var req models.OutcomeItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subject, err := h.outcomeService.SetCLOs(
+		c.Param("id"),
+		c.Param("code"),
+		req,
+		c.GetInt("user_id"),
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+	)
+	if err != nil {
+		outcomeErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, subject)
+}
+
+func (h *OutcomeHandler) SetCurriculumMap(c *gin.Context) {
+	var req models.CurriculumMapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	curriculumMap, err := h.outcomeService.SetCurriculumMap(
+		c.Param("id"),
+		req,
+		c.GetInt("user_id"),
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+	)
+	if err != nil {
+		outcomeErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, curriculumMap)
+}
+
+func outcomeErrorResponse(c *gin.Context, err error) {
+	var mappingErr *service.MappingError
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
+	case errors.Is(err, service.ErrUnknownSubject):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrDuplicateCode),
+		errors.Is(err, repository.ErrNoCLORecord):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.As(err, &mappingErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid curriculum map", "problems": mappingErr.Problems})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
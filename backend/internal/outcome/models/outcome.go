@@ -0,0 +1,98 @@
+package models
+
+// ระดับที่ CLO ส่งเสริม PLO เรียงจากต่ำไปสูง
+const (
+	LevelIntroduce = "I"
+	LevelReinforce = "R"
+	LevelMaster    = "M"
+)
+
+var Levels = []string{LevelIntroduce, LevelReinforce, LevelMaster}
+
+// LevelNames ใช้เป็นคำอธิบายในไฟล์ส่งออก
+var LevelNames = map[string]string{
+	LevelIntroduce: "Introduce",
+	LevelReinforce: "Reinforce",
+	LevelMaster:    "Master",
+}
+
+// LevelRank คืนลำดับของระดับ ระดับที่ไม่รู้จักได้ 0
+func LevelRank(level string) int {
+	for i, l := range Levels {
+		if l == level {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// PLO คือผลลัพธ์การเรียนรู้ระดับหลักสูตรหนึ่งข้อ
+type PLO struct {
+	PLOItemID   int    `json:"plo_item_id"`
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// CLO คือผลลัพธ์การเรียนรู้ระดับรายวิชาหนึ่งข้อ levels คือระดับที่ส่งเสริมแต่ละ PLO โดยใช้รหัส PLO เป็น key
+type CLO struct {
+	CLOItemID   int               `json:"clo_item_id"`
+	Code        string            `json:"code"`
+	Description string            `json:"description"`
+	Levels      map[string]string `json:"levels"`
+}
+
+// SubjectOutcomes คือแถวของรายวิชาใน curriculum map levels ของรายวิชาคือระดับสูงสุดจาก CLO ทุกข้อ
+type SubjectOutcomes struct {
+	SubjectID   string            `json:"subject_id"`
+	ThaiSubject string            `json:"thai_subject"`
+	EngSubject  string            `json:"eng_subject"`
+	CLOID       *string           `json:"clo_id"`
+	CLOs        []CLO             `json:"clos"`
+	Levels      map[string]string `json:"levels"`
+}
+
+// CurriculumMap คือตารางความสัมพันธ์ CLO กับ PLO ของหลักสูตร ใช้ในรายงาน มคอ. และ AUN-QA
+type CurriculumMap struct {
+	CourseID   string            `json:"course_id"`
+	ThaiCourse string            `json:"thai_course"`
+	EngCourse  string            `json:"eng_course"`
+	PLOs       []PLO             `json:"plos"`
+	Subjects   []SubjectOutcomes `json:"subjects"`
+}
+
+// OutcomeItemsRequest แทนที่รายการทั้งหมด รหัสที่มีอยู่แล้วจะคงรหัสอ้างอิงเดิมไว้เพื่อไม่ให้ mapping หาย
+type OutcomeItemsRequest struct {
+	Items []OutcomeItem `json:"items" binding:"dive"`
+}
+
+type OutcomeItem struct {
+	Code        string `json:"code" binding:"required"`
+	Description string `json:"description" binding:"required"`
+}
+
+// CurriculumMapRequest แทนที่ mapping ทั้งหมดของหลักสูตร
+type CurriculumMapRequest struct {
+	Mappings []OutcomeMapping `json:"mappings" binding:"dive"`
+}
+
+type OutcomeMapping struct {
+	SubjectID string `json:"subject_id" binding:"required"`
+	CLOCode   string `json:"clo_code" binding:"required"`
+	PLOCode   string `json:"plo_code" binding:"required"`
+	Level     string `json:"level" binding:"required"`
+}
+
+// MapLink คือ mapping ที่ตรวจแล้วพร้อมบันทึก
+type MapLink struct {
+	CLOItemID int
+	PLOItemID int
+	Level     string
+}
+
+// CourseSubjectCLO คือรายวิชาของหลักสูตรกับ clo ที่อ้างถึง
+type CourseSubjectCLO struct {
+	SubjectID   string
+	ThaiSubject string
+	EngSubject  string
+	CLOID       *string
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"cpsu/internal/outcome/models"
+
+	"github.com/lib/pq"
+)
+
+var ErrNoCLORecord = errors.New("subject has no clo record and its code cannot be used as clo_id")
+
+type OutcomeRepository interface {
+	GetCourse(courseID string) (*models.CurriculumMap, error)
+	GetPLOs(courseID string) ([]models.PLO, error)
+	GetCourseSubjects(courseID string) ([]models.CourseSubjectCLO, error)
+	GetCLOs(cloIDs []string) (map[string][]models.CLO, error)
+	GetMapLevels(courseID string) (map[int]map[string]string, error)
+	ReplacePLOs(courseID string, items []models.OutcomeItem, text string) error
+	ReplaceCLOs(courseID string, subjectID string, items []models.OutcomeItem, text string) (string, error)
+	ReplaceMapLinks(courseID string, links []models.MapLink) error
+}
+
+type outcomeRepository struct {
+	db *sql.DB
+}
+
+func NewOutcomeRepository(db *sql.DB) OutcomeRepository {
+	return &outcomeRepository{db: db}
+}
+
+// GetCourse คืน sql.ErrNoRows เมื่อไม่มีหลักสูตรนี้
+func (r *outcomeRepository) GetCourse(courseID string) (*models.CurriculumMap, error) {
+	m := &models.CurriculumMap{CourseID: courseID}
+	err := r.db.QueryRow(`
+		SELECT thai_course, eng_course FROM courses WHERE course_id = $1
+	`, courseID).Scan(&m.ThaiCourse, &m.EngCourse)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (r *outcomeRepository) GetPLOs(courseID string) ([]models.PLO, error) {
+	rows, err := r.db.Query(`
+		SELECT plo_item_id, code, description
+		FROM plo_items
+		WHERE course_id = $1
+		ORDER BY sort_order, plo_item_id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plos := []models.PLO{}
+	for rows.Next() {
+		var p models.PLO
+		if err := rows.Scan(&p.PLOItemID, &p.Code, &p.Description); err != nil {
+			return nil, err
+		}
+		plos = append(plos, p)
+	}
+	return plos, rows.Err()
+}
+
+// GetCourseSubjects คืนรายวิชาของหลักสูตร รหัสเดียวกันหลายแผนการเรียนใช้แถวที่มี clo_id ก่อน
+func (r *outcomeRepository) GetCourseSubjects(courseID string) ([]models.CourseSubjectCLO, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT ON (subject_id) subject_id, thai_subject, COALESCE(eng_subject, ''), clo_id
+		FROM subjects
+		WHERE course_id = $1
+		ORDER BY subject_id, clo_id IS NULL, id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subjects := []models.CourseSubjectCLO{}
+	for rows.Next() {
+		var s models.CourseSubjectCLO
+		if err := rows.Scan(&s.SubjectID, &s.ThaiSubject, &s.EngSubject, &s.CLOID); err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, s)
+	}
+	return subjects, rows.Err()
+}
+
+// GetCLOs คืน CLO รายข้อของ clo ทุกตัวที่ขอ โดยใช้ clo_id เป็น key
+func (r *outcomeRepository) GetCLOs(cloIDs []string) (map[string][]models.CLO, error) {
+	clos := map[string][]models.CLO{}
+	if len(cloIDs) == 0 {
+		return clos, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT clo_id, clo_item_id, code, description
+		FROM clo_items
+		WHERE clo_id = ANY($1)
+		ORDER BY clo_id, sort_order, clo_item_id
+	`, pq.Array(cloIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cloID string
+			c     models.CLO
+		)
+		if err := rows.Scan(&cloID, &c.CLOItemID, &c.Code, &c.Description); err != nil {
+			return nil, err
+		}
+		clos[cloID] = append(clos[cloID], c)
+	}
+	return clos, rows.Err()
+}
+
+// GetMapLevels คืนระดับของ mapping ในหลักสูตร key แรกคือ clo_item_id key ที่สองคือรหัส PLO
+func (r *outcomeRepository) GetMapLevels(courseID string) (map[int]map[string]string, error) {
+	rows, err := r.db.Query(`
+		SELECT m.clo_item_id, p.code, m.level
+		FROM clo_plo_map m
+		JOIN plo_items p ON p.plo_item_id = m.plo_item_id
+		WHERE p.course_id = $1
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	levels := map[int]map[string]string{}
+	for rows.Next() {
+		var (
+			cloItemID int
+			ploCode   string
+			level     string
+		)
+		if err := rows.Scan(&cloItemID, &ploCode, &level); err != nil {
+			return nil, err
+		}
+		if levels[cloItemID] == nil {
+			levels[cloItemID] = map[string]string{}
+		}
+		levels[cloItemID][ploCode] = level
+	}
+	return levels, rows.Err()
+}
+
+// ReplacePLOs แทนที่ PLO ของหลักสูตร และเขียนข้อความรวมกลับไปที่ plo ที่หลักสูตรอ้างถึง
+func (r *outcomeRepository) ReplacePLOs(courseID string, items []models.OutcomeItem, text string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceItems(tx, "plo_items", "course_id", courseID, items); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		UPDATE plo SET plo = $1
+		FROM courses c
+		WHERE c.plo_id = plo.plo_id AND c.course_id = $2
+	`, text, courseID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReplaceCLOs แทนที่ CLO ของรายวิชาในหลักสูตร ถ้ารายวิชายังไม่มี clo จะสร้างใหม่โดยใช้รหัสวิชาเป็น clo_id
+// clo หนึ่งตัวอาจถูกใช้ร่วมกันหลายหลักสูตร การแก้ไขจึงมีผลกับทุกหลักสูตรที่อ้างถึง
+func (r *outcomeRepository) ReplaceCLOs(courseID string, subjectID string, items []models.OutcomeItem, text string) (string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var cloID sql.NullString
+	err = tx.QueryRow(`
+		SELECT clo_id FROM subjects
+		WHERE course_id = $1 AND subject_id = $2
+		ORDER BY clo_id IS NULL, id
+		LIMIT 1
+	`, courseID, subjectID).Scan(&cloID)
+	if err != nil {
+		return "", err
+	}
+
+	if !cloID.Valid {
+		if len(subjectID) > 6 {
+			return "", ErrNoCLORecord
+		}
+		cloID = sql.NullString{String: subjectID, Valid: true}
+		if _, err := tx.Exec("INSERT INTO clo (clo_id, clo) VALUES ($1, '') ON CONFLICT (clo_id) DO NOTHING", cloID.String); err != nil {
+			return "", err
+		}
+		_, err := tx.Exec(`
+			UPDATE subjects SET clo_id = $1
+			WHERE course_id = $2 AND subject_id = $3 AND clo_id IS NULL
+		`, cloID.String, courseID, subjectID)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := replaceItems(tx, "clo_items", "clo_id", cloID.String, items); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec("UPDATE clo SET clo = $1 WHERE clo_id = $2", text, cloID.String); err != nil {
+		return "", err
+	}
+	return cloID.String, tx.Commit()
+}
+
+// ReplaceMapLinks แทนที่ mapping ทั้งหมดที่ชี้ไปยัง PLO ของหลักสูตร
+func (r *outcomeRepository) ReplaceMapLinks(courseID string, links []models.MapLink) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		DELETE FROM clo_plo_map m
+		USING plo_items p
+		WHERE m.plo_item_id = p.plo_item_id AND p.course_id = $1
+	`, courseID)
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		_, err := tx.Exec(`
+			INSERT INTO clo_plo_map (clo_item_id, plo_item_id, level)
+			VALUES ($1, $2, $3)
+		`, link.CLOItemID, link.PLOItemID, link.Level)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// replaceItems เพิ่มหรือแก้รายการตามรหัสแล้วลบรหัสที่ไม่อยู่ในรายการใหม่
+// table และ ownerColumn เป็นค่าคงที่จากโค้ด ไม่ได้มาจาก request
+func replaceItems(tx *sql.Tx, table string, ownerColumn string, ownerID string, items []models.OutcomeItem) error {
+	codes := make([]string, len(items))
+	for i, item := range items {
+		codes[i] = item.Code
+		_, err := tx.Exec(`
+			INSERT INTO `+table+` (`+ownerColumn+`, code, description, sort_order)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (`+ownerColumn+`, code) DO UPDATE
+			SET description = EXCLUDED.description, sort_order = EXCLUDED.sort_order
+		`, ownerID, item.Code, item.Description, i+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec(`
+		DELETE FROM `+table+`
+		WHERE `+ownerColumn+` = $1 AND NOT (code = ANY($2))
+	`, ownerID, pq.Array(codes))
+	return err
+}
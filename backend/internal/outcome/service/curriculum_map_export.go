@@ -0,0 +1,132 @@
+package service
+
+import (
+	"fmt"
+
+	"cpsu/internal/outcome/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	mapSheet = "Curriculum Map"
+	ploSheet = "PLO"
+)
+
+// ExportCurriculumMap สร้างไฟล์ XLSX ของ curriculum map
+// แถวของรายวิชาแสดงระดับสูงสุด ตามด้วยแถวของ CLO แต่ละข้อ คอลัมน์หลังชื่อวิชาคือ PLO ตามลำดับ
+func (s *outcomeService) ExportCurriculumMap(courseID string) ([]byte, error) {
+	curriculumMap, err := s.GetCurriculumMap(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName(f.GetSheetName(0), mapSheet); err != nil {
+		return nil, err
+	}
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+	center, err := f.NewStyle(&excelize.Style{Alignment: &excelize.Alignment{Horizontal: "center"}})
+	if err != nil {
+		return nil, err
+	}
+
+	title := curriculumMap.ThaiCourse
+	if curriculumMap.EngCourse != "" {
+		title += " (" + curriculumMap.EngCourse + ")"
+	}
+	if err := f.SetCellValue(mapSheet, "A1", title); err != nil {
+		return nil, err
+	}
+
+	header := []interface{}{"รหัสวิชา", "CLO", "ชื่อวิชา / CLO"}
+	for _, plo := range curriculumMap.PLOs {
+		header = append(header, plo.Code)
+	}
+	if err := f.SetSheetRow(mapSheet, "A3", &header); err != nil {
+		return nil, err
+	}
+	lastCol, err := excelize.ColumnNumberToName(len(header))
+	if err != nil {
+		return nil, err
+	}
+	if err := f.SetCellStyle(mapSheet, "A3", fmt.Sprintf("%s3", lastCol), bold); err != nil {
+		return nil, err
+	}
+
+	levelCells := func(levels map[string]string) []interface{} {
+		cells := make([]interface{}, len(curriculumMap.PLOs))
+		for i, plo := range curriculumMap.PLOs {
+			cells[i] = levels[plo.Code]
+		}
+		return cells
+	}
+
+	row := 4
+	for _, subject := range curriculumMap.Subjects {
+		values := append([]interface{}{subject.SubjectID, "", subject.ThaiSubject}, levelCells(subject.Levels)...)
+		if err := f.SetSheetRow(mapSheet, fmt.Sprintf("A%d", row), &values); err != nil {
+			return nil, err
+		}
+		if err := f.SetCellStyle(mapSheet, fmt.Sprintf("A%d", row), fmt.Sprintf("C%d", row), bold); err != nil {
+			return nil, err
+		}
+		row++
+		for _, clo := range subject.CLOs {
+			values := append([]interface{}{subject.SubjectID, clo.Code, clo.Description}, levelCells(clo.Levels)...)
+			if err := f.SetSheetRow(mapSheet, fmt.Sprintf("A%d", row), &values); err != nil {
+				return nil, err
+			}
+			row++
+		}
+	}
+	if len(curriculumMap.PLOs) > 0 && row > 4 {
+		if err := f.SetCellStyle(mapSheet, "D4", fmt.Sprintf("%s%d", lastCol, row-1), center); err != nil {
+			return nil, err
+		}
+	}
+
+	// คำอธิบายระดับไว้ท้ายตาราง
+	row++
+	for _, level := range models.Levels {
+		values := []interface{}{level, models.LevelNames[level]}
+		if err := f.SetSheetRow(mapSheet, fmt.Sprintf("A%d", row), &values); err != nil {
+			return nil, err
+		}
+		row++
+	}
+	if err := f.SetColWidth(mapSheet, "C", "C", 60); err != nil {
+		return nil, err
+	}
+
+	if _, err := f.NewSheet(ploSheet); err != nil {
+		return nil, err
+	}
+	ploHeader := []interface{}{"PLO", "คำอธิบาย"}
+	if err := f.SetSheetRow(ploSheet, "A1", &ploHeader); err != nil {
+		return nil, err
+	}
+	if err := f.SetCellStyle(ploSheet, "A1", "B1", bold); err != nil {
+		return nil, err
+	}
+	for i, plo := range curriculumMap.PLOs {
+		values := []interface{}{plo.Code, plo.Description}
+		if err := f.SetSheetRow(ploSheet, fmt.Sprintf("A%d", i+2), &values); err != nil {
+			return nil, err
+		}
+	}
+	if err := f.SetColWidth(ploSheet, "B", "B", 100); err != nil {
+		return nil, err
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cpsu/internal/outcome/models"
+	"cpsu/internal/outcome/repository"
+
+	authrepo "cpsu/internal/auth/repository"
+)
+
+var (
+	ErrDuplicateCode  = errors.New("duplicate outcome code")
+	ErrUnknownSubject = errors.New("subject not found in course")
+)
+
+// MappingError รวมปัญหาทุกข้อของ curriculum map ที่ส่งมา เพื่อให้แก้ได้ในครั้งเดียว
+type MappingError struct {
+	Problems []string
+}
+
+func (e *MappingError) Error() string {
+	return "invalid curriculum map: " + strings.Join(e.Problems, "; ")
+}
+
+type OutcomeService interface {
+	GetPLOs(courseID string) ([]models.PLO, error)
+	GetCurriculumMap(courseID string) (*models.CurriculumMap, error)
+	SetPLOs(courseID string, req models.OutcomeItemsRequest, userID int, ip string, userAgent string) ([]models.PLO, error)
+	SetCLOs(courseID string, subjectID string, req models.OutcomeItemsRequest, userID int, ip string, userAgent string) (*models.SubjectOutcomes, error)
+	SetCurriculumMap(courseID string, req models.CurriculumMapRequest, userID int, ip string, userAgent string) (*models.CurriculumMap, error)
+	ExportCurriculumMap(courseID string) ([]byte, error)
+}
+
+type outcomeService struct {
+	repo      repository.OutcomeRepository
+	auditRepo *authrepo.AuditRepository
+}
+
+func NewOutcomeService(
+	repo repository.OutcomeRepository,
+	auditRepo *authrepo.AuditRepository,
+) OutcomeService {
+	return &outcomeService{
+		repo:      repo,
+		auditRepo: auditRepo,
+	}
+}
+
+func (s *outcomeService) GetPLOs(courseID string) ([]models.PLO, error) {
+	if _, err := s.repo.GetCourse(courseID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPLOs(courseID)
+}
+
+// GetCurriculumMap คืนตาราง PLO x รายวิชา ระดับของรายวิชาคือระดับสูงสุดจาก CLO ทุกข้อของวิชานั้น
+func (s *outcomeService) GetCurriculumMap(courseID string) (*models.CurriculumMap, error) {
+	curriculumMap, err := s.repo.GetCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+	if curriculumMap.PLOs, err = s.repo.GetPLOs(courseID); err != nil {
+		return nil, err
+	}
+
+	subjects, err := s.repo.GetCourseSubjects(courseID)
+	if err != nil {
+		return nil, err
+	}
+	var cloIDs []string
+	for _, subject := range subjects {
+		if subject.CLOID != nil {
+			cloIDs = append(cloIDs, *subject.CLOID)
+		}
+	}
+	clos, err := s.repo.GetCLOs(cloIDs)
+	if err != nil {
+		return nil, err
+	}
+	levels, err := s.repo.GetMapLevels(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	curriculumMap.Subjects = []models.SubjectOutcomes{}
+	for _, subject := range subjects {
+		row := models.SubjectOutcomes{
+			SubjectID:   subject.SubjectID,
+			ThaiSubject: subject.ThaiSubject,
+			EngSubject:  subject.EngSubject,
+			CLOID:       subject.CLOID,
+			CLOs:        []models.CLO{},
+			Levels:      map[string]string{},
+		}
+		if subject.CLOID != nil {
+			for _, clo := range clos[*subject.CLOID] {
+				clo.Levels = levels[clo.CLOItemID]
+				if clo.Levels == nil {
+					clo.Levels = map[string]string{}
+				}
+				for ploCode, level := range clo.Levels {
+					if models.LevelRank(level) > models.LevelRank(row.Levels[ploCode]) {
+						row.Levels[ploCode] = level
+					}
+				}
+				row.CLOs = append(row.CLOs, clo)
+			}
+		}
+		curriculumMap.Subjects = append(curriculumMap.Subjects, row)
+	}
+
+	return curriculumMap, nil
+}
+
+func (s *outcomeService) SetPLOs(courseID string, req models.OutcomeItemsRequest, userID int, ip string, userAgent string) ([]models.PLO, error) {
+	if _, err := s.repo.GetCourse(courseID); err != nil {
+		return nil, err
+	}
+	items, err := normalizeItems(req.Items)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplacePLOs(courseID, items, OutcomeText(items)); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID,
+		"update",
+		"course_plo",
+		courseID,
+		map[string]interface{}{
+			"items": len(items),
+		},
+		ip,
+		userAgent,
+	)
+
+	return s.repo.GetPLOs(courseID)
+}
+
+// SetCLOs แทนที่ CLO ของรายวิชา clo ของรายวิชาอาจใช้ร่วมกับหลักสูตรอื่น การแก้ไขจึงมีผลกับทุกหลักสูตรที่อ้างถึง
+func (s *outcomeService) SetCLOs(courseID string, subjectID string, req models.OutcomeItemsRequest, userID int, ip string, userAgent string) (*models.SubjectOutcomes, error) {
+	if _, err := s.repo.GetCourse(courseID); err != nil {
+		return nil, err
+	}
+	items, err := normalizeItems(req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	cloID, err := s.repo.ReplaceCLOs(courseID, subjectID, items, OutcomeText(items))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSubject, subjectID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID,
+		"update",
+		"subject_clo",
+		cloID,
+		map[string]interface{}{
+			"course_id":  courseID,
+			"subject_id": subjectID,
+			"items":      len(items),
+		},
+		ip,
+		userAgent,
+	)
+
+	curriculumMap, err := s.GetCurriculumMap(courseID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range curriculumMap.Subjects {
+		if curriculumMap.Subjects[i].SubjectID == subjectID {
+			return &curriculumMap.Subjects[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownSubject, subjectID)
+}
+
+// SetCurriculumMap แทนที่ mapping ทั้งหมดของหลักสูตร ถ้ามีรายการที่ไม่ถูกต้องจะไม่บันทึกเลย
+func (s *outcomeService) SetCurriculumMap(courseID string, req models.CurriculumMapRequest, userID int, ip string, userAgent string) (*models.CurriculumMap, error) {
+	current, err := s.GetCurriculumMap(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	plos := map[string]int{}
+	for _, plo := range current.PLOs {
+		plos[plo.Code] = plo.PLOItemID
+	}
+	subjects := map[string]models.SubjectOutcomes{}
+	for _, subject := range current.Subjects {
+		subjects[subject.SubjectID] = subject
+	}
+
+	var (
+		problems []string
+		links    []models.MapLink
+	)
+	seen := map[[2]int]int{}
+	for i, m := range req.Mappings {
+		problem := func(format string, args ...interface{}) {
+			problems = append(problems, fmt.Sprintf("mappings[%d]: ", i)+fmt.Sprintf(format, args...))
+		}
+
+		level := strings.ToUpper(strings.TrimSpace(m.Level))
+		if models.LevelRank(level) == 0 {
+			problem("unknown level %q, expected one of %s", m.Level, strings.Join(models.Levels, ", "))
+			continue
+		}
+		ploItemID, ok := plos[strings.TrimSpace(m.PLOCode)]
+		if !ok {
+			problem("unknown plo %s", m.PLOCode)
+			continue
+		}
+		subject, ok := subjects[strings.TrimSpace(m.SubjectID)]
+		if !ok {
+			problem("unknown subject %s", m.SubjectID)
+			continue
+		}
+		cloItemID := 0
+		for _, clo := range subject.CLOs {
+			if clo.Code == strings.TrimSpace(m.CLOCode) {
+				cloItemID = clo.CLOItemID
+				break
+			}
+		}
+		if cloItemID == 0 {
+			problem("subject %s has no clo %s", subject.SubjectID, m.CLOCode)
+			continue
+		}
+
+		key := [2]int{cloItemID, ploItemID}
+		if first, dup := seen[key]; dup {
+			problem("same clo and plo as mappings[%d]", first)
+			continue
+		}
+		seen[key] = i
+		links = append(links, models.MapLink{CLOItemID: cloItemID, PLOItemID: ploItemID, Level: level})
+	}
+	if len(problems) > 0 {
+		return nil, &MappingError{Problems: problems}
+	}
+
+	if err := s.repo.ReplaceMapLinks(courseID, links); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID,
+		"update",
+		"curriculum_map",
+		courseID,
+		map[string]interface{}{
+			"mappings": len(links),
+		},
+		ip,
+		userAgent,
+	)
+
+	return s.GetCurriculumMap(courseID)
+}
+
+// normalizeItems ตัดช่องว่างและตรวจว่ารหัสไม่ซ้ำกัน
+func normalizeItems(items []models.OutcomeItem) ([]models.OutcomeItem, error) {
+	normalized := make([]models.OutcomeItem, 0, len(items))
+	seen := map[string]bool{}
+	for _, item := range items {
+		item.Code = strings.TrimSpace(item.Code)
+		item.Description = strings.TrimSpace(item.Description)
+		if seen[item.Code] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateCode, item.Code)
+		}
+		seen[item.Code] = true
+		normalized = append(normalized, item)
+	}
+	return normalized, nil
+}
+
+// OutcomeText สร้างข้อความรวมแบบเดิมของตาราง plo และ clo บรรทัดละหนึ่งข้อ
+func OutcomeText(items []models.OutcomeItem) string {
+	lines := make([]string, len(items))
+	for i, item := range items {
+		lines[i] = item.Code + " " + item.Description
+	}
+	return strings.Join(lines, "\n")
+}
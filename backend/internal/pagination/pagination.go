@@ -0,0 +1,369 @@
+package pagination
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Params คือพารามิเตอร์แบ่งหน้าที่ฝังไว้ในทุก *QueryParam
+// ใช้ได้สองแบบ คือ page/offset ตามปกติ หรือส่ง cursor จาก next_cursor ของหน้าก่อน (keyset)
+// limit คือพารามิเตอร์เดิม ใช้เป็นขนาดหน้าเมื่อไม่ได้ส่ง page_size มา
+type Params struct {
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+	Offset   int    `form:"offset"`
+	Cursor   string `form:"cursor"`
+	Limit    int    `form:"limit"`
+
+	all bool
+}
+
+// All ใช้ภายในระบบเมื่อต้องการข้อมูลทุกแถวโดยไม่แบ่งหน้า
+func All() Params {
+	return Params{all: true}
+}
+
+// Page คือรูปแบบ response มาตรฐานของ endpoint ที่คืนรายการ
+type Page[T any] struct {
+	Items      []T     `json:"items"`
+	Total      int     `json:"total"`
+	Page       int     `json:"page"`
+	PageSize   int     `json:"page_size"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// SortKey คือคอลัมน์หนึ่งใน ORDER BY
+type SortKey struct {
+	Column string
+	Desc   bool
+}
+
+// Statement คือคำสั่ง SELECT ที่ยังไม่ได้ใส่ ORDER BY และ LIMIT
+// OrderBy ตัวสุดท้ายต้องเป็นคอลัมน์ที่ไม่ซ้ำ (เช่น primary key) เพื่อให้ลำดับแน่นอน
+type Statement struct {
+	Columns    string
+	From       string
+	Conditions []string
+	GroupBy    string
+	Args       []interface{}
+	OrderBy    []SortKey
+}
+
+type cursor struct {
+	Page   int       `json:"p"`
+	Values []*string `json:"v"`
+}
+
+// Scanner คือ *sql.Rows ที่เก็บค่า cursor ของแถวไว้ให้อัตโนมัติ
+type Scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+type cursorRow struct {
+	rows   *sql.Rows
+	values []sql.NullString
+}
+
+func (r *cursorRow) Scan(dest ...interface{}) error {
+	for i := range r.values {
+		dest = append(dest, &r.values[i])
+	}
+	return r.rows.Scan(dest...)
+}
+
+func (p Params) size() int {
+	size := p.PageSize
+	if size <= 0 {
+		size = p.Limit
+	}
+	if size <= 0 {
+		return DefaultPageSize
+	}
+	if size > MaxPageSize {
+		return MaxPageSize
+	}
+	return size
+}
+
+func (p Params) offset() int {
+	if p.Offset > 0 {
+		return p.Offset
+	}
+	if p.Page > 1 {
+		return (p.Page - 1) * p.size()
+	}
+	return 0
+}
+
+// Fetch นับจำนวนทั้งหมดและดึงข้อมูลหน้าที่ต้องการตาม Params
+func Fetch[T any](db *sql.DB, stmt Statement, p Params, scan func(row Scanner) (T, error)) (*Page[T], error) {
+	return FetchContext(context.Background(), db, stmt, p, scan)
+}
+
+func FetchContext[T any](ctx context.Context, db *sql.DB, stmt Statement, p Params, scan func(row Scanner) (T, error)) (*Page[T], error) {
+	if len(stmt.OrderBy) == 0 {
+		return nil, errors.New("pagination: statement has no order")
+	}
+
+	var after *cursor
+	if p.Cursor != "" && !p.all {
+		c, err := decodeCursor(p.Cursor, len(stmt.OrderBy))
+		if err != nil {
+			return nil, err
+		}
+		after = c
+	}
+
+	total, err := count(ctx, db, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := stmt.Conditions
+	args := stmt.Args
+	if after != nil {
+		condition, keysetArgs := keysetCondition(stmt.OrderBy, after.Values, len(args)+1)
+		conditions = append(append([]string{}, conditions...), condition)
+		args = append(append([]interface{}{}, args...), keysetArgs...)
+	}
+
+	columns := stmt.Columns
+	order := make([]string, len(stmt.OrderBy))
+	for i, key := range stmt.OrderBy {
+		columns += ", (" + key.Column + ")::text"
+		order[i] = key.Column + direction(key.Desc)
+	}
+
+	query := "SELECT " + columns + " " + stmt.From + where(conditions) + groupBy(stmt.GroupBy) +
+		" ORDER BY " + strings.Join(order, ", ")
+
+	size := p.size()
+	page := 1
+	if !p.all {
+		// ดึงเกินมาหนึ่งแถวเพื่อดูว่ายังมีหน้าถัดไปหรือไม่
+		query += " LIMIT " + strconv.Itoa(size+1)
+		if after != nil {
+			page = after.Page
+		} else if offset := p.offset(); offset > 0 {
+			query += " OFFSET " + strconv.Itoa(offset)
+			page = offset/size + 1
+		}
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	row := &cursorRow{rows: rows, values: make([]sql.NullString, len(stmt.OrderBy))}
+	items := []T{}
+	var last []sql.NullString
+	hasMore := false
+
+	for rows.Next() {
+		if !p.all && len(items) == size {
+			hasMore = true
+			break
+		}
+		item, err := scan(row)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		last = append(last[:0], row.values...)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &Page[T]{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: size,
+	}
+	if p.all {
+		result.PageSize = len(items)
+	}
+	if hasMore {
+		next := encodeCursor(cursor{Page: page + 1, Values: cursorValues(last)})
+		result.NextCursor = &next
+	}
+
+	return result, nil
+}
+
+// Map แปลง Items ของหน้าโดยคงข้อมูลการแบ่งหน้าไว้เหมือนเดิม
+func Map[T, U any](page *Page[T], convert func(T) U) *Page[U] {
+	items := make([]U, len(page.Items))
+	for i, item := range page.Items {
+		items[i] = convert(item)
+	}
+	return &Page[U]{
+		Items:      items,
+		Total:      page.Total,
+		Page:       page.Page,
+		PageSize:   page.PageSize,
+		NextCursor: page.NextCursor,
+	}
+}
+
+// Slice แบ่งหน้ารายการที่เรียงลำดับไว้แล้วในหน่วยความจำ เช่นผลค้นหาที่จัดอันดับในโค้ด
+// cursor ของ Slice เก็บตำแหน่งเริ่มของหน้าถัดไป
+func Slice[T any](items []T, p Params) (*Page[T], error) {
+	if p.all {
+		return &Page[T]{Items: items, Total: len(items), Page: 1, PageSize: len(items)}, nil
+	}
+
+	size := p.size()
+	offset := p.offset()
+	if p.Cursor != "" {
+		c, err := decodeCursor(p.Cursor, 1)
+		if err != nil || c.Values[0] == nil {
+			return nil, ErrInvalidCursor
+		}
+		if offset, err = strconv.Atoi(*c.Values[0]); err != nil || offset < 0 {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	start := min(offset, len(items))
+	end := min(start+size, len(items))
+
+	page := &Page[T]{
+		Items:    append([]T{}, items[start:end]...),
+		Total:    len(items),
+		Page:     offset/size + 1,
+		PageSize: size,
+	}
+	if end < len(items) {
+		value := strconv.Itoa(end)
+		next := encodeCursor(cursor{Page: page.Page + 1, Values: []*string{&value}})
+		page.NextCursor = &next
+	}
+	return page, nil
+}
+
+func count(ctx context.Context, db *sql.DB, stmt Statement) (int, error) {
+	query := "SELECT COUNT(*) FROM (SELECT 1 " + stmt.From + where(stmt.Conditions) + groupBy(stmt.GroupBy) + ") counted"
+
+	var total int
+	if err := db.QueryRowContext(ctx, query, stmt.Args...).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// keysetCondition สร้างเงื่อนไขหาแถวที่อยู่ถัดจาก cursor ตามลำดับของ keys
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... โดยใช้ < กับคอลัมน์ที่เรียงแบบ DESC
+func keysetCondition(keys []SortKey, values []*string, argIndex int) (string, []interface{}) {
+	var (
+		parts []string
+		args  []interface{}
+	)
+
+	for i, key := range keys {
+		// NULL อยู่ท้ายสุดเมื่อเรียง ASC จึงไม่มีแถวใดอยู่ถัดไปในคอลัมน์นี้
+		if values[i] == nil && !key.Desc {
+			continue
+		}
+
+		var terms []string
+		for j := 0; j < i; j++ {
+			if values[j] == nil {
+				terms = append(terms, keys[j].Column+" IS NULL")
+				continue
+			}
+			terms = append(terms, keys[j].Column+" = $"+strconv.Itoa(argIndex))
+			args = append(args, *values[j])
+			argIndex++
+		}
+
+		// PostgreSQL วาง NULL ไว้หน้าสุดเมื่อเรียง DESC
+		if values[i] == nil {
+			terms = append(terms, key.Column+" IS NOT NULL")
+		} else {
+			op := " > $"
+			if key.Desc {
+				op = " < $"
+			}
+			term := key.Column + op + strconv.Itoa(argIndex)
+			if !key.Desc {
+				term = "(" + term + " OR " + key.Column + " IS NULL)"
+			}
+			terms = append(terms, term)
+			args = append(args, *values[i])
+			argIndex++
+		}
+
+		parts = append(parts, "("+strings.Join(terms, " AND ")+")")
+	}
+
+	if len(parts) == 0 {
+		return "false", nil
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", args
+}
+
+func where(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+func groupBy(columns string) string {
+	if columns == "" {
+		return ""
+	}
+	return " GROUP BY " + columns
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+func cursorValues(values []sql.NullString) []*string {
+	result := make([]*string, len(values))
+	for i, v := range values {
+		if v.Valid {
+			s := v.String
+			result[i] = &s
+		}
+	}
+	return result
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string, keys int) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Page < 1 || len(c.Values) != keys {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
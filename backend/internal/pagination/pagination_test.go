@@ -0,0 +1,510 @@
+package pagination
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func str(s string) *string {
+	return &s
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		c    cursor
+	}{
+		{"single value", cursor{Page: 2, Values: []*string{str("42")}}},
+		{"null value", cursor{Page: 3, Values: []*string{nil, str("7")}}},
+		{"thai and symbols", cursor{Page: 5, Values: []*string{str("ข่าว \"ใหม่\" /?&="), str("2026-10-19 11:05:22+07")}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := encodeCursor(tt.c)
+			if strings.ContainsAny(encoded, "+/=") {
+				t.Errorf("encodeCursor = %q, want URL-safe text without padding", encoded)
+			}
+			got, err := decodeCursor(encoded, len(tt.c.Values))
+			if err != nil {
+				t.Fatalf("decodeCursor error: %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.c) {
+				t.Errorf("decodeCursor = %+v, want %+v", *got, tt.c)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	raw := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+		keys   int
+	}{
+		{"not base64", "!!!", 1},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"p":2,"v":["1"]}`)), 1},
+		{"not json", raw("page=2"), 1},
+		{"page zero", raw(`{"p":0,"v":["1"]}`), 1},
+		{"negative page", raw(`{"p":-1,"v":["1"]}`), 1},
+		{"missing values", raw(`{"p":2}`), 1},
+		{"too few values", raw(`{"p":2,"v":["1"]}`), 2},
+		{"too many values", raw(`{"p":2,"v":["1","2"]}`), 1},
+		{"wrong value type", raw(`{"p":2,"v":[1]}`), 1},
+		{"truncated", encodeCursor(cursor{Page: 2, Values: []*string{str("1")}})[:8], 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.cursor, tt.keys); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decodeCursor(%q) error = %v, want ErrInvalidCursor", tt.cursor, err)
+			}
+		})
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []SortKey
+		values   []*string
+		argIndex int
+		want     string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "asc",
+			keys:     []SortKey{{Column: "n.title"}, {Column: "n.news_id"}},
+			values:   []*string{str("a"), str("5")},
+			argIndex: 1,
+			want:     "(((n.title > $1 OR n.title IS NULL)) OR (n.title = $2 AND (n.news_id > $3 OR n.news_id IS NULL)))",
+			wantArgs: []interface{}{"a", "a", "5"},
+		},
+		{
+			name:     "desc",
+			keys:     []SortKey{{Column: "n.created_at", Desc: true}, {Column: "n.news_id", Desc: true}},
+			values:   []*string{str("2026-10-19"), str("5")},
+			argIndex: 3,
+			want:     "((n.created_at < $3) OR (n.created_at = $4 AND n.news_id < $5))",
+			wantArgs: []interface{}{"2026-10-19", "2026-10-19", "5"},
+		},
+		{
+			// NULL อยู่ท้ายสุดเมื่อเรียง ASC แถวถัดไปต้องมี title เป็น NULL เหมือนกัน
+			name:     "asc null",
+			keys:     []SortKey{{Column: "n.title"}, {Column: "n.news_id"}},
+			values:   []*string{nil, str("5")},
+			argIndex: 1,
+			want:     "((n.title IS NULL AND (n.news_id > $1 OR n.news_id IS NULL)))",
+			wantArgs: []interface{}{"5"},
+		},
+		{
+			// NULL อยู่หน้าสุดเมื่อเรียง DESC แถวที่มีค่าทุกแถวอยู่ถัดไป
+			name:     "desc null",
+			keys:     []SortKey{{Column: "n.title", Desc: true}, {Column: "n.news_id"}},
+			values:   []*string{nil, str("5")},
+			argIndex: 1,
+			want:     "((n.title IS NOT NULL) OR (n.title IS NULL AND (n.news_id > $1 OR n.news_id IS NULL)))",
+			wantArgs: []interface{}{"5"},
+		},
+		{
+			name:     "mixed directions",
+			keys:     []SortKey{{Column: "s.year", Desc: true}, {Column: "s.name"}, {Column: "s.id"}},
+			values:   []*string{str("2566"), str("x"), str("9")},
+			argIndex: 1,
+			want: "((s.year < $1) OR (s.year = $2 AND (s.name > $3 OR s.name IS NULL)) OR " +
+				"(s.year = $4 AND s.name = $5 AND (s.id > $6 OR s.id IS NULL)))",
+			wantArgs: []interface{}{"2566", "2566", "x", "2566", "x", "9"},
+		},
+		{
+			name:     "only asc nulls",
+			keys:     []SortKey{{Column: "n.title"}},
+			values:   []*string{nil},
+			argIndex: 1,
+			want:     "false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args := keysetCondition(tt.keys, tt.values, tt.argIndex)
+			if got != tt.want {
+				t.Errorf("keysetCondition =\n  %s\nwant\n  %s", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("keysetCondition args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestParamsPageMath(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     Params
+		wantSize   int
+		wantOffset int
+	}{
+		{"defaults", Params{}, DefaultPageSize, 0},
+		{"page size", Params{PageSize: 10}, 10, 0},
+		{"legacy limit", Params{Limit: 15}, 15, 0},
+		{"page size wins over limit", Params{PageSize: 10, Limit: 15}, 10, 0},
+		{"capped", Params{PageSize: 500}, MaxPageSize, 0},
+		{"negative size", Params{PageSize: -3}, DefaultPageSize, 0},
+		{"page one", Params{Page: 1, PageSize: 10}, 10, 0},
+		{"page three", Params{Page: 3, PageSize: 10}, 10, 20},
+		{"offset wins over page", Params{Page: 3, PageSize: 10, Offset: 5}, 10, 5},
+		{"negative page", Params{Page: -2}, DefaultPageSize, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.params.size(); got != tt.wantSize {
+				t.Errorf("size() = %d, want %d", got, tt.wantSize)
+			}
+			if got := tt.params.offset(); got != tt.wantOffset {
+				t.Errorf("offset() = %d, want %d", got, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestSlice(t *testing.T) {
+	items := make([]int, 45)
+	for i := range items {
+		items[i] = i
+	}
+
+	tests := []struct {
+		name      string
+		params    Params
+		wantFirst int
+		wantLen   int
+		wantPage  int
+		wantNext  bool
+	}{
+		{"first page", Params{PageSize: 20}, 0, 20, 1, true},
+		{"second page", Params{Page: 2, PageSize: 20}, 20, 20, 2, true},
+		{"last page", Params{Page: 3, PageSize: 20}, 40, 5, 3, false},
+		{"past the end", Params{Page: 9, PageSize: 20}, 0, 0, 9, false},
+		{"offset", Params{Offset: 30, PageSize: 20}, 30, 15, 2, false},
+		{"exact fit", Params{PageSize: 45}, 0, 45, 1, false},
+		{"all", All(), 0, 45, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := Slice(items, tt.params)
+			if err != nil {
+				t.Fatalf("Slice error: %v", err)
+			}
+			if page.Total != len(items) || page.Page != tt.wantPage || len(page.Items) != tt.wantLen {
+				t.Errorf("Slice = total %d, page %d, %d items; want total %d, page %d, %d items",
+					page.Total, page.Page, len(page.Items), len(items), tt.wantPage, tt.wantLen)
+			}
+			if tt.wantLen > 0 && page.Items[0] != tt.wantFirst {
+				t.Errorf("first item = %d, want %d", page.Items[0], tt.wantFirst)
+			}
+			if (page.NextCursor != nil) != tt.wantNext {
+				t.Errorf("next_cursor = %v, want present %v", page.NextCursor, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestSliceCursor(t *testing.T) {
+	items := make([]int, 45)
+	for i := range items {
+		items[i] = i
+	}
+
+	var pages [][]int
+	p := Params{PageSize: 20}
+	for {
+		page, err := Slice(items, p)
+		if err != nil {
+			t.Fatalf("Slice error: %v", err)
+		}
+		if page.Page != len(pages)+1 {
+			t.Errorf("page = %d, want %d", page.Page, len(pages)+1)
+		}
+		pages = append(pages, page.Items)
+		if page.NextCursor == nil {
+			break
+		}
+		p.Cursor = *page.NextCursor
+	}
+
+	if len(pages) != 3 || pages[1][0] != 20 || len(pages[2]) != 5 || pages[2][4] != 44 {
+		t.Errorf("walked pages = %v", pages)
+	}
+
+	for _, bad := range []string{
+		"not-a-cursor",
+		encodeCursor(cursor{Page: 2, Values: []*string{nil}}),
+		encodeCursor(cursor{Page: 2, Values: []*string{str("abc")}}),
+		encodeCursor(cursor{Page: 2, Values: []*string{str("-20")}}),
+	} {
+		if page, err := Slice(items, Params{Cursor: bad}); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Slice(cursor %q) = %v, %v; want ErrInvalidCursor", bad, page, err)
+		}
+	}
+}
+
+// fakeDB คืนผลลัพธ์ที่กำหนดไว้ให้ Fetch และจำคำสั่ง SQL ที่ได้รับ
+// คำสั่ง COUNT(*) ได้ total ส่วนคำสั่งอื่นได้ rows ทั้งหมดโดยไม่สนใจ LIMIT
+type fakeDB struct {
+	mu      sync.Mutex
+	total   int
+	columns []string
+	rows    [][]driver.Value
+	queries []string
+	args    [][]driver.NamedValue
+}
+
+var (
+	fakeDBs   = map[string]*fakeDB{}
+	fakeDBsMu sync.Mutex
+)
+
+func init() {
+	sql.Register("pagination-fake", fakeDriver{})
+}
+
+func openFake(t *testing.T, fake *fakeDB) *sql.DB {
+	fakeDBsMu.Lock()
+	fakeDBs[t.Name()] = fake
+	fakeDBsMu.Unlock()
+
+	db, err := sql.Open("pagination-fake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeDBsMu.Lock()
+	defer fakeDBsMu.Unlock()
+	return &fakeConn{db: fakeDBs[name]}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("fake: prepare not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("fake: transactions not supported")
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	c.db.queries = append(c.db.queries, query)
+	c.db.args = append(c.db.args, args)
+	if strings.HasPrefix(query, "SELECT COUNT(*)") {
+		return &fakeRows{columns: []string{"count"}, rows: [][]driver.Value{{int64(c.db.total)}}}, nil
+	}
+	return &fakeRows{columns: c.db.columns, rows: c.db.rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+var newsOrder = []SortKey{{Column: "n.created_at", Desc: true}, {Column: "n.news_id", Desc: true}}
+
+func newsStatement() Statement {
+	return Statement{
+		Columns:    "n.news_id, n.title",
+		From:       "FROM news n",
+		Conditions: []string{"n.type_id = $1"},
+		Args:       []interface{}{1},
+		OrderBy:    newsOrder,
+	}
+}
+
+type newsRow struct {
+	ID    int
+	Title string
+}
+
+func scanNews(row Scanner) (newsRow, error) {
+	var n newsRow
+	err := row.Scan(&n.ID, &n.Title)
+	return n, err
+}
+
+// newsRows สร้างแถวที่มีคอลัมน์ข้อมูลตามด้วยค่า cursor ที่ Fetch ต่อท้ายไว้เป็น text
+func newsRows(ids ...int) [][]driver.Value {
+	rows := [][]driver.Value{}
+	for _, id := range ids {
+		rows = append(rows, []driver.Value{
+			int64(id), "news " + strconv.Itoa(id),
+			"2026-10-" + strconv.Itoa(10+id) + " 09:00:00+07", strconv.Itoa(id),
+		})
+	}
+	return rows
+}
+
+func TestFetch(t *testing.T) {
+	fake := &fakeDB{
+		total:   5,
+		columns: []string{"news_id", "title", "created_at", "news_id"},
+		rows:    newsRows(9, 8, 7),
+	}
+	db := openFake(t, fake)
+
+	page, err := Fetch(db, newsStatement(), Params{PageSize: 2}, scanNews)
+	if err != nil {
+		t.Fatalf("Fetch error: %v", err)
+	}
+
+	wantQuery := "SELECT n.news_id, n.title, (n.created_at)::text, (n.news_id)::text FROM news n WHERE n.type_id = $1 " +
+		"ORDER BY n.created_at DESC, n.news_id DESC LIMIT 3"
+	if len(fake.queries) != 2 || fake.queries[1] != wantQuery {
+		t.Fatalf("queries = %q, want count then %q", fake.queries, wantQuery)
+	}
+	if want := "SELECT COUNT(*) FROM (SELECT 1 FROM news n WHERE n.type_id = $1) counted"; fake.queries[0] != want {
+		t.Errorf("count query = %q, want %q", fake.queries[0], want)
+	}
+
+	if page.Total != 5 || page.Page != 1 || page.PageSize != 2 {
+		t.Errorf("page = total %d, page %d, size %d", page.Total, page.Page, page.PageSize)
+	}
+	if want := []newsRow{{9, "news 9"}, {8, "news 8"}}; !reflect.DeepEqual(page.Items, want) {
+		t.Errorf("items = %v, want %v", page.Items, want)
+	}
+	if page.NextCursor == nil {
+		t.Fatal("next_cursor = nil, want a cursor after the extra row")
+	}
+
+	// cursor ต้องเก็บค่าของแถวสุดท้ายที่ส่งกลับไป ไม่ใช่แถวที่ดึงเกินมา
+	next, err := decodeCursor(*page.NextCursor, len(newsOrder))
+	if err != nil {
+		t.Fatalf("decodeCursor(next_cursor) error: %v", err)
+	}
+	if want := (cursor{Page: 2, Values: []*string{str("2026-10-18 09:00:00+07"), str("8")}}); !reflect.DeepEqual(*next, want) {
+		t.Errorf("next_cursor = %+v, want %+v", *next, want)
+	}
+
+	fake.rows = newsRows(7, 6)
+	page, err = Fetch(db, newsStatement(), Params{PageSize: 2, Cursor: *page.NextCursor}, scanNews)
+	if err != nil {
+		t.Fatalf("Fetch with cursor error: %v", err)
+	}
+
+	wantQuery = "SELECT n.news_id, n.title, (n.created_at)::text, (n.news_id)::text FROM news n " +
+		"WHERE n.type_id = $1 AND ((n.created_at < $2) OR (n.created_at = $3 AND n.news_id < $4)) " +
+		"ORDER BY n.created_at DESC, n.news_id DESC LIMIT 3"
+	if got := fake.queries[3]; got != wantQuery {
+		t.Errorf("cursor query =\n  %s\nwant\n  %s", got, wantQuery)
+	}
+	var args []interface{}
+	for _, arg := range fake.args[3] {
+		args = append(args, arg.Value)
+	}
+	if want := []interface{}{int64(1), "2026-10-18 09:00:00+07", "2026-10-18 09:00:00+07", "8"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("cursor args = %v, want %v", args, want)
+	}
+	if page.Page != 2 || len(page.Items) != 2 || page.NextCursor != nil {
+		t.Errorf("last page = page %d, %d items, next %v; want page 2, 2 items, no next", page.Page, len(page.Items), page.NextCursor)
+	}
+}
+
+func TestFetchOffset(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   Params
+		rows     [][]driver.Value
+		wantTail string
+		wantPage int
+		wantNext bool
+	}{
+		{"middle page", Params{Page: 2, PageSize: 2}, newsRows(7, 6, 5), " LIMIT 3 OFFSET 2", 2, true},
+		{"last page", Params{Page: 3, PageSize: 2}, newsRows(5), " LIMIT 3 OFFSET 4", 3, false},
+		{"exact last page", Params{Page: 2, PageSize: 2}, newsRows(7, 6), " LIMIT 3 OFFSET 2", 2, false},
+		{"offset", Params{Offset: 3, PageSize: 2}, newsRows(6, 5), " LIMIT 3 OFFSET 3", 2, false},
+		{"all", All(), newsRows(9, 8, 7, 6, 5), " n.news_id DESC", 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeDB{total: 5, columns: []string{"news_id", "title", "created_at", "news_id"}, rows: tt.rows}
+			db := openFake(t, fake)
+
+			page, err := Fetch(db, newsStatement(), tt.params, scanNews)
+			if err != nil {
+				t.Fatalf("Fetch error: %v", err)
+			}
+			if query := fake.queries[1]; !strings.HasSuffix(query, tt.wantTail) {
+				t.Errorf("query = %q, want suffix %q", query, tt.wantTail)
+			}
+			if page.Page != tt.wantPage || len(page.Items) != min(len(tt.rows), page.PageSize) {
+				t.Errorf("page = page %d, %d items; want page %d", page.Page, len(page.Items), tt.wantPage)
+			}
+			if (page.NextCursor != nil) != tt.wantNext {
+				t.Errorf("next_cursor = %v, want present %v", page.NextCursor, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestFetchInvalidCursor(t *testing.T) {
+	fake := &fakeDB{total: 5, columns: []string{"news_id", "title", "created_at", "news_id"}, rows: newsRows(9)}
+	db := openFake(t, fake)
+
+	valid := encodeCursor(cursor{Page: 2, Values: []*string{str("a"), str("8")}})
+	truncated := valid[:len(valid)-4]
+
+	for _, bad := range []string{
+		"garbage",
+		encodeCursor(cursor{Page: 2, Values: []*string{str("8")}}),
+		truncated,
+	} {
+		page, err := Fetch(db, newsStatement(), Params{Cursor: bad}, scanNews)
+		if !errors.Is(err, ErrInvalidCursor) || page != nil {
+			t.Errorf("Fetch(cursor %q) = %v, %v; want ErrInvalidCursor", bad, page, err)
+		}
+	}
+	if len(fake.queries) != 0 {
+		t.Errorf("queries = %q, want none for an invalid cursor", fake.queries)
+	}
+}
@@ -0,0 +1,103 @@
+package pagination
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidSort = errors.New("invalid sort key")
+
+// SortError บอกว่า key ไหนใช้ไม่ได้และ key ที่อนุญาตมีอะไรบ้าง
+type SortError struct {
+	Key     string
+	Allowed []string
+}
+
+func (e *SortError) Error() string {
+	return "invalid sort key \"" + e.Key + "\", allowed: " + strings.Join(e.Allowed, ", ")
+}
+
+func (e *SortError) Is(target error) bool {
+	return target == ErrInvalidSort
+}
+
+// Sorter กำหนด key ที่ผู้ใช้ส่งมาใน ?sort= ได้ และคอลัมน์ที่ key นั้นแทน
+// ห้ามนำค่า sort จาก query string ไปต่อเป็น SQL โดยตรง ต้องผ่าน Parse เสมอ
+type Sorter struct {
+	Columns map[string]string
+	Default string
+	Key     string
+}
+
+// Parse แปลง spec เช่น "-created_at,title" เป็นลำดับการเรียง
+// "-" นำหน้าคือเรียงจากมากไปน้อย ส่วน order=desc แบบเดิมใช้กับ key ที่ไม่มี "-"
+// คอลัมน์ Key จะถูกต่อท้ายเสมอเพื่อให้ลำดับแน่นอนสำหรับการแบ่งหน้า
+func (s Sorter) Parse(spec string, order string) ([]SortKey, error) {
+	if strings.TrimSpace(spec) == "" {
+		spec = s.Default
+	}
+	legacyDesc := strings.ToUpper(order) == "DESC"
+
+	var keys []SortKey
+	seen := map[string]bool{}
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		desc := legacyDesc
+		name := part
+		if strings.HasPrefix(part, "-") {
+			desc = true
+			name = part[1:]
+		}
+
+		column, ok := s.Columns[name]
+		if !ok {
+			return nil, &SortError{Key: name, Allowed: s.allowed()}
+		}
+		if seen[column] {
+			continue
+		}
+		seen[column] = true
+
+		keys = append(keys, SortKey{Column: column, Desc: desc})
+	}
+
+	if !seen[s.Key] {
+		desc := legacyDesc
+		if len(keys) > 0 {
+			desc = keys[0].Desc
+		}
+		keys = append(keys, SortKey{Column: s.Key, Desc: desc})
+	}
+
+	return keys, nil
+}
+
+func (s Sorter) allowed() []string {
+	keys := make([]string, 0, len(s.Columns))
+	for key := range s.Columns {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// BadRequest คืน body สำหรับตอบ 400 เมื่อ err เกิดจาก sort หรือ cursor ที่ไม่ถูกต้อง
+func BadRequest(err error) (map[string]interface{}, bool) {
+	var sortErr *SortError
+	if errors.As(err, &sortErr) {
+		return map[string]interface{}{
+			"error":        sortErr.Error(),
+			"allowed_sort": sortErr.Allowed,
+		}, true
+	}
+	if errors.Is(err, ErrInvalidCursor) {
+		return map[string]interface{}{"error": err.Error()}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,192 @@
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var newsSort = Sorter{
+	Columns: map[string]string{
+		"created_at": "n.created_at",
+		"title":      "n.title",
+		"id":         "n.news_id",
+		"news_id":    "n.news_id",
+	},
+	Default: "-created_at",
+	Key:     "n.news_id",
+}
+
+func TestSorterParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  string
+		order string
+		want  []SortKey
+	}{
+		{
+			name: "default",
+			want: []SortKey{{"n.created_at", true}, {"n.news_id", true}},
+		},
+		{
+			name: "blank uses default",
+			spec: "  ",
+			want: []SortKey{{"n.created_at", true}, {"n.news_id", true}},
+		},
+		{
+			name: "single asc",
+			spec: "title",
+			want: []SortKey{{"n.title", false}, {"n.news_id", false}},
+		},
+		{
+			name: "multi key",
+			spec: "-created_at,title",
+			want: []SortKey{{"n.created_at", true}, {"n.title", false}, {"n.news_id", true}},
+		},
+		{
+			name: "spaces and empty parts",
+			spec: " title , ,-created_at ",
+			want: []SortKey{{"n.title", false}, {"n.created_at", true}, {"n.news_id", false}},
+		},
+		{
+			name:  "legacy order desc",
+			spec:  "title",
+			order: "desc",
+			want:  []SortKey{{"n.title", true}, {"n.news_id", true}},
+		},
+		{
+			name:  "legacy order asc",
+			spec:  "title",
+			order: "ASC",
+			want:  []SortKey{{"n.title", false}, {"n.news_id", false}},
+		},
+		{
+			name:  "minus wins over legacy order",
+			spec:  "-created_at,title",
+			order: "DESC",
+			want:  []SortKey{{"n.created_at", true}, {"n.title", true}, {"n.news_id", true}},
+		},
+		{
+			name:  "legacy order with default",
+			order: "desc",
+			want:  []SortKey{{"n.created_at", true}, {"n.news_id", true}},
+		},
+		{
+			name: "duplicate key keeps first",
+			spec: "title,-title",
+			want: []SortKey{{"n.title", false}, {"n.news_id", false}},
+		},
+		{
+			name: "aliases of the same column",
+			spec: "-id,news_id",
+			want: []SortKey{{"n.news_id", true}},
+		},
+		{
+			name: "tiebreak follows first key",
+			spec: "title,-created_at",
+			want: []SortKey{{"n.title", false}, {"n.created_at", true}, {"n.news_id", false}},
+		},
+		{
+			name: "explicit tiebreak keeps its direction",
+			spec: "-title,id",
+			want: []SortKey{{"n.title", true}, {"n.news_id", false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newsSort.Parse(tt.spec, tt.order)
+			if err != nil {
+				t.Fatalf("Parse(%q, %q) error: %v", tt.spec, tt.order, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q, %q) = %v, want %v", tt.spec, tt.order, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSorterParseInvalid(t *testing.T) {
+	allowed := []string{"created_at", "id", "news_id", "title"}
+
+	tests := []struct {
+		spec    string
+		wantKey string
+	}{
+		{"views", "views"},
+		{"title,views", "views"},
+		{"-views", "views"},
+		{"n.title", "n.title"},
+		{"title; DROP TABLE news", "title; DROP TABLE news"},
+		{"created_at DESC", "created_at DESC"},
+		{"-", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			keys, err := newsSort.Parse(tt.spec, "")
+			if keys != nil {
+				t.Errorf("Parse(%q) keys = %v, want nil", tt.spec, keys)
+			}
+
+			var sortErr *SortError
+			if !errors.As(err, &sortErr) {
+				t.Fatalf("Parse(%q) error = %v, want *SortError", tt.spec, err)
+			}
+			if !errors.Is(err, ErrInvalidSort) {
+				t.Errorf("Parse(%q) error is not ErrInvalidSort", tt.spec)
+			}
+			if sortErr.Key != tt.wantKey || !reflect.DeepEqual(sortErr.Allowed, allowed) {
+				t.Errorf("Parse(%q) error = %+v, want key %q allowed %v", tt.spec, sortErr, tt.wantKey, allowed)
+			}
+		})
+	}
+}
+
+func TestBadRequest(t *testing.T) {
+	_, sortErr := newsSort.Parse("views", "")
+
+	tests := []struct {
+		name   string
+		err    error
+		want   map[string]interface{}
+		wantOK bool
+	}{
+		{
+			name: "sort",
+			err:  sortErr,
+			want: map[string]interface{}{
+				"error":        `invalid sort key "views", allowed: created_at, id, news_id, title`,
+				"allowed_sort": []string{"created_at", "id", "news_id", "title"},
+			},
+			wantOK: true,
+		},
+		{
+			name: "wrapped sort",
+			err:  fmt.Errorf("get news: %w", sortErr),
+			want: map[string]interface{}{
+				"error":        `invalid sort key "views", allowed: created_at, id, news_id, title`,
+				"allowed_sort": []string{"created_at", "id", "news_id", "title"},
+			},
+			wantOK: true,
+		},
+		{
+			name:   "cursor",
+			err:    ErrInvalidCursor,
+			want:   map[string]interface{}{"error": "invalid cursor"},
+			wantOK: true,
+		},
+		{name: "other", err: errors.New("connection refused")},
+		{name: "nil"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := BadRequest(tt.err)
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BadRequest(%v) = %v, %v; want %v, %v", tt.err, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -6,7 +6,10 @@ import (
 	"net/http"
 	"strconv"
 
+	"cpsu/internal/imaging"
+	"cpsu/internal/pagination"
 	"cpsu/internal/personnel/models"
+	personnelrepo "cpsu/internal/personnel/repository"
 	"cpsu/internal/personnel/service"
 
 	"cpsu/internal/auth/repository"
@@ -32,6 +35,60 @@ func (h *PersonnelHandler) GetAllPersonnels(c *gin.Context) {
 
 	personnel, err := h.personnelService.GetAllPersonnels(param)
 	if err != nil {
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+		} else if errors.Is(err, service.ErrInvalidStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, personnel)
+}
+
+// GetPublicPersonnels แสดงเฉพาะบุคลากรที่ยังปฏิบัติงานอยู่ เว้นแต่จะระบุ status หรือ alumni มาเอง
+func (h *PersonnelHandler) GetPublicPersonnels(c *gin.Context) {
+	var param models.PersonnelQueryParam
+	if err := c.BindQuery(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameter"})
+		return
+	}
+
+	if param.Status == "" && !param.Alumni {
+		param.Status = models.StatusActive
+	}
+
+	personnel, err := h.personnelService.GetAllPersonnels(param)
+	if err != nil {
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+		} else if errors.Is(err, service.ErrInvalidStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, personnel)
+}
+
+func (h *PersonnelHandler) GetAlumniPersonnels(c *gin.Context) {
+	var param models.PersonnelQueryParam
+	if err := c.BindQuery(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameter"})
+		return
+	}
+
+	param.Status = ""
+	param.Alumni = true
+
+	personnel, err := h.personnelService.GetAllPersonnels(param)
+	if err != nil {
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -39,8 +96,17 @@ func (h *PersonnelHandler) GetAllPersonnels(c *gin.Context) {
 }
 
 func (h *PersonnelHandler) GetPersonnelByID(c *gin.Context) {
+	h.getPersonnelByID(c, h.personnelService.GetPersonnelByID)
+}
+
+// GetPublicPersonnelByID ใช้เงื่อนไขสถานะเดียวกับ GetPublicPersonnels
+func (h *PersonnelHandler) GetPublicPersonnelByID(c *gin.Context) {
+	h.getPersonnelByID(c, h.personnelService.GetPublicPersonnelByID)
+}
+
+func (h *PersonnelHandler) getPersonnelByID(c *gin.Context, get func(id int) (*models.Personnels, error)) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	personnel, err := h.personnelService.GetPersonnelByID(id)
+	personnel, err := get(id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
@@ -80,6 +146,10 @@ func (h *PersonnelHandler) CreatePersonnel(c *gin.Context) {
 
 	createdPersonnel, err := h.personnelService.CreatePersonnel(req, fileImage, userID, ip, userAgent)
 	if err != nil {
+		if status := imaging.HTTPStatus(err); status != 0 {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -121,6 +191,10 @@ func (h *PersonnelHandler) UpdatePersonnel(c *gin.Context) {
 
 	updatedPersonnel, err := h.personnelService.UpdatePersonnel(id, req, fileImage, userID, ip, userAgent)
 	if err != nil {
+		if status := imaging.HTTPStatus(err); status != 0 {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "personnel ID not found"})
 		} else {
@@ -160,6 +234,10 @@ func (h *PersonnelHandler) UpdateTeacher(c *gin.Context) {
 
 	updatedTeacher, err := h.personnelService.UpdateTeacher(id, req, fileImage, userID, ip, userAgent)
 	if err != nil {
+		if status := imaging.HTTPStatus(err); status != 0 {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "teacher ID not found"})
 		} else {
@@ -186,6 +264,8 @@ func (h *PersonnelHandler) DeletePersonnel(c *gin.Context) {
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "personnel not found"})
+		} else if errors.Is(err, personnelrepo.ErrPersonnelHasResearch) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
@@ -247,9 +327,65 @@ func (h *PersonnelHandler) GetAllResearch(c *gin.Context) {
 
 	rs, err := h.personnelService.GetAllResearch(param)
 	if err != nil {
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, rs)
 }
+
+func (h *PersonnelHandler) GetPersonnelStatusHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid personnel ID"})
+		return
+	}
+
+	history, err := h.personnelService.GetPersonnelStatusHistory(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "personnel not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func (h *PersonnelHandler) ChangePersonnelStatus(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid personnel ID"})
+		return
+	}
+
+	var req models.PersonnelStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	ip := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+
+	status, err := h.personnelService.ChangePersonnelStatus(id, req, userID, ip, userAgent)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "personnel not found"})
+		} else if errors.Is(err, service.ErrInvalidStatus) || errors.Is(err, service.ErrInvalidStatusDate) || errors.Is(err, service.ErrInvalidDate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, status)
+}
@@ -1,32 +1,43 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"cpsu/internal/imaging"
+	"cpsu/internal/pagination"
+)
 
 type Personnels struct {
-	PersonnelID            int        `json:"personnel_id"`
-	TypePersonnel          string     `json:"type_personnel"`
-	DepartmentPositionID   int        `json:"department_position_id"`
-	DepartmentPositionName string     `json:"department_position_name"`
-	AcademicPositionID     *int       `json:"academic_position_id,omitempty"`
-	ThaiAcademicPosition   *string    `json:"thai_academic_position,omitempty"`
-	EngAcademicPosition    *string    `json:"eng_academic_position,omitempty"`
-	ThaiName               string     `json:"thai_name"`
-	EngName                string     `json:"eng_name"`
-	Education              *string    `json:"education,omitempty"`
-	RelatedFields          *string    `json:"related_fields,omitempty"`
-	Email                  *string    `json:"email,omitempty"`
-	Website                *string    `json:"website,omitempty"`
-	FileImage              string     `json:"file_image"`
-	ScopusID               *string    `json:"scopus_id,omitempty"`
-	Researches             []Research `json:"researches,omitempty"`
+	PersonnelID            int                  `json:"personnel_id"`
+	TypePersonnel          string               `json:"type_personnel"`
+	DepartmentPositionID   int                  `json:"department_position_id"`
+	DepartmentPositionName string               `json:"department_position_name"`
+	AcademicPositionID     *int                 `json:"academic_position_id,omitempty"`
+	ThaiAcademicPosition   *string              `json:"thai_academic_position,omitempty"`
+	EngAcademicPosition    *string              `json:"eng_academic_position,omitempty"`
+	ThaiName               string               `json:"thai_name"`
+	EngName                string               `json:"eng_name"`
+	Education              *string              `json:"education,omitempty"`
+	RelatedFields          *string              `json:"related_fields,omitempty"`
+	Email                  *string              `json:"email,omitempty"`
+	Website                *string              `json:"website,omitempty"`
+	FileImage              string               `json:"file_image"`
+	FileImageVariants      []imaging.VariantURL `json:"file_image_variants,omitempty"`
+	ScopusID               *string              `json:"scopus_id,omitempty"`
+	Status                 string               `json:"status"`
+	StatusEffectiveFrom    *time.Time           `json:"status_effective_from,omitempty"`
+	StatusEffectiveTo      *time.Time           `json:"status_effective_to,omitempty"`
+	Researches             []Research           `json:"researches,omitempty"`
 }
 
 type PersonnelQueryParam struct {
+	pagination.Params
 	Search               string `form:"search"`
-	Limit                int    `form:"limit"`
 	TypePersonnel        string `form:"type_personnel"`
 	DepartmentPositionID int    `form:"department_position_id"`
 	AcademicPositionID   *int   `form:"academic_position_id"`
+	Status               string `form:"status"`
+	Alumni               bool   `form:"alumni"`
 	Sort                 string `form:"sort"`
 	Order                string `form:"order"`
 }
@@ -82,9 +93,45 @@ type ResearchAuthor struct {
 	AuthorOrder int    `json:"author_order"`
 }
 
+const (
+	StatusActive     = "active"
+	StatusStudyLeave = "study_leave"
+	StatusRetired    = "retired"
+	StatusFormer     = "former"
+)
+
+// AlumniStatuses คือสถานะของบุคลากรที่ไม่ได้ปฏิบัติงานในภาควิชาแล้ว ใช้กับหน้า alumni staff
+var AlumniStatuses = []string{StatusRetired, StatusFormer}
+
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusActive, StatusStudyLeave, StatusRetired, StatusFormer:
+		return true
+	}
+	return false
+}
+
+type PersonnelStatus struct {
+	StatusID      int        `json:"status_id"`
+	PersonnelID   int        `json:"personnel_id"`
+	Status        string     `json:"status"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty"`
+	Note          *string    `json:"note,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// PersonnelStatusRequest รับวันที่เป็น YYYY-MM-DD ไม่ระบุ effective_from คือมีผลวันนี้
+type PersonnelStatusRequest struct {
+	Status        string  `json:"status" binding:"required"`
+	EffectiveFrom *string `json:"effective_from"`
+	EffectiveTo   *string `json:"effective_to"`
+	Note          *string `json:"note"`
+}
+
 type ResearchQueryParam struct {
+	pagination.Params
 	Search      string `form:"search"`
-	Limit       int    `form:"limit"`
 	PersonnelID int    `form:"personnel_id"`
 	Sort        string `form:"sort"`
 	Order       string `form:"order"`
@@ -2,25 +2,52 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"strconv"
-	"strings"
 	"time"
 
+	"cpsu/internal/pagination"
 	"cpsu/internal/personnel/models"
+	"cpsu/internal/textsearch"
 
 	"github.com/lib/pq"
 )
 
+var ErrPersonnelHasResearch = errors.New("personnel has research records, set status to former instead of deleting")
+
+// CurrentStatusJoin เพิ่ม st เป็นสถานะที่มีผล ณ วันปัจจุบันของบุคลากร p
+// คือแถวล่าสุดที่เริ่มมีผลแล้วและยังไม่หมดอายุ ใช้ร่วมกับโมดูลอื่นที่ต้องแสดงเฉพาะบุคลากรที่ปฏิบัติงานอยู่
+const CurrentStatusJoin = `
+	LEFT JOIN LATERAL (
+		SELECT ps.status, ps.effective_from, ps.effective_to
+		FROM personnel_status ps
+		WHERE ps.personnel_id = p.personnel_id
+			AND ps.effective_from <= CURRENT_DATE
+			AND (ps.effective_to IS NULL OR ps.effective_to >= CURRENT_DATE)
+		ORDER BY ps.effective_from DESC, ps.status_id DESC
+		LIMIT 1
+	) st ON true
+`
+
+// CurrentStatus คือสถานะปัจจุบันจาก CurrentStatusJoin บุคลากรที่ไม่มีประวัติสถานะถือว่า active
+const CurrentStatus = "COALESCE(st.status, 'active')"
+
+// ActiveCondition คือเงื่อนไขของบุคลากรที่แสดงบนหน้าเว็บสาธารณะ ต้องใช้คู่กับ CurrentStatusJoin
+const ActiveCondition = CurrentStatus + " = '" + models.StatusActive + "'"
+
 type PersonnelRepository interface {
-	GetAllPersonnels(param models.PersonnelQueryParam) ([]models.Personnels, error)
+	GetAllPersonnels(param models.PersonnelQueryParam) (*pagination.Page[models.Personnels], error)
 	GetPersonnelByID(id int) (*models.Personnels, error)
+	GetPublicPersonnelByID(id int) (*models.Personnels, error)
 	CreatePersonnel(req models.PersonnelRequest) (*models.Personnels, error)
 	UpdatePersonnel(id int, req models.PersonnelRequest) (*models.Personnels, error)
 	UpdateTeacher(id int, req models.TeacherRequest) (*models.Personnels, error)
 	DeletePersonnel(id int) error
 	GetScopusIDByPersonnelID(id int) (*string, error)
 	SaveResearch(personnelID int, researches []models.Research) (err error)
-	GetAllResearch(param models.ResearchQueryParam) ([]models.Research, error)
+	GetAllResearch(param models.ResearchQueryParam) (*pagination.Page[models.Research], error)
+	GetPersonnelStatusHistory(personnelID int) ([]models.PersonnelStatus, error)
+	AddPersonnelStatus(status models.PersonnelStatus) (*models.PersonnelStatus, error)
 }
 
 type personnelRepository struct {
@@ -31,17 +58,22 @@ func NewPersonnelRepository(db *sql.DB) PersonnelRepository {
 	return &personnelRepository{db: db}
 }
 
-func (r *personnelRepository) GetAllPersonnels(param models.PersonnelQueryParam) ([]models.Personnels, error) {
-	query := `
-		SELECT
-			p.personnel_id, p.type_personnel, d.department_position_id, d.department_position_name,
-			a.academic_position_id, a.thai_academic_position, a.eng_academic_position, p.thai_name, 
-			p.eng_name, p.education, p.related_fields, p.email, p.website, p.file_image, p.scopus_id
-		FROM personnels p
-		LEFT JOIN department_position d ON p.department_position_id = d.department_position_id
-		LEFT JOIN academic_position a ON p.academic_position_id = a.academic_position_id
-	`
+var personnelSort = pagination.Sorter{
+	Columns: map[string]string{
+		"personnel_id":           "p.personnel_id",
+		"type_personnel":         "p.type_personnel",
+		"thai_name":              "p.thai_name",
+		"eng_name":               "p.eng_name",
+		"email":                  "p.email",
+		"department_position_id": "p.department_position_id",
+		"academic_position_id":   "p.academic_position_id",
+		"status":                 CurrentStatus,
+	},
+	Default: "personnel_id",
+	Key:     "p.personnel_id",
+}
 
+func (r *personnelRepository) GetAllPersonnels(param models.PersonnelQueryParam) (*pagination.Page[models.Personnels], error) {
 	conditions := []string{}
 	args := []interface{}{}
 	argIndex := 1
@@ -64,76 +96,111 @@ func (r *personnelRepository) GetAllPersonnels(param models.PersonnelQueryParam)
 		argIndex++
 	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
-	}
-
-	sort := "p.personnel_id"
-	if param.Sort != "" {
-		sort = "p." + param.Sort
-	}
-
-	order := "ASC"
-	if strings.ToUpper(param.Order) == "DESC" {
-		order = "DESC"
+	if param.Alumni {
+		conditions = append(conditions, CurrentStatus+" = ANY($"+strconv.Itoa(argIndex)+")")
+		args = append(args, pq.Array(models.AlumniStatuses))
+		argIndex++
+	} else if param.Status != "" {
+		conditions = append(conditions, CurrentStatus+" = $"+strconv.Itoa(argIndex))
+		args = append(args, param.Status)
+		argIndex++
 	}
 
-	query += " ORDER BY " + sort + " " + order
-
-	if param.Limit > 0 {
-		query += " LIMIT " + strconv.Itoa(param.Limit)
+	if terms := textsearch.Terms(param.Search); len(terms) > 0 {
+		condition, searchArgs, next := textsearch.Condition([]string{"p.thai_name", "p.eng_name", "p.related_fields"}, terms, argIndex)
+		conditions = append(conditions, condition)
+		args = append(args, searchArgs...)
+		argIndex = next
 	}
 
-	rows, err := r.db.Query(query, args...)
+	orderBy, err := personnelSort.Parse(param.Sort, param.Order)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var personnels []models.Personnels
-	for rows.Next() {
+	stmt := pagination.Statement{
+		Columns: `
+			p.personnel_id, p.type_personnel, d.department_position_id, d.department_position_name,
+			a.academic_position_id, a.thai_academic_position, a.eng_academic_position, p.thai_name, 
+			p.eng_name, p.education, p.related_fields, p.email, p.website, p.file_image, p.scopus_id,
+			` + CurrentStatus + `, st.effective_from, st.effective_to
+		`,
+		From: `
+			FROM personnels p
+			LEFT JOIN department_position d ON p.department_position_id = d.department_position_id
+			LEFT JOIN academic_position a ON p.academic_position_id = a.academic_position_id
+		` + CurrentStatusJoin,
+		Conditions: conditions,
+		Args:       args,
+		OrderBy:    orderBy,
+	}
+
+	return pagination.Fetch(r.db, stmt, param.Params, func(row pagination.Scanner) (models.Personnels, error) {
 		var personnel models.Personnels
 		var scopus sql.NullString
-		err := rows.Scan(
+		var statusFrom, statusTo sql.NullTime
+		err := row.Scan(
 			&personnel.PersonnelID, &personnel.TypePersonnel, &personnel.DepartmentPositionID, &personnel.DepartmentPositionName,
 			&personnel.AcademicPositionID, &personnel.ThaiAcademicPosition, &personnel.EngAcademicPosition,
 			&personnel.ThaiName, &personnel.EngName, &personnel.Education, &personnel.RelatedFields,
 			&personnel.Email, &personnel.Website, &personnel.FileImage, &scopus,
+			&personnel.Status, &statusFrom, &statusTo,
 		)
 		if err != nil {
-			return nil, err
+			return personnel, err
 		}
 		if scopus.Valid {
 			personnel.ScopusID = &scopus.String
 		} else {
 			personnel.ScopusID = nil
 		}
-		personnels = append(personnels, personnel)
-	}
-	return personnels, nil
+		if statusFrom.Valid {
+			personnel.StatusEffectiveFrom = &statusFrom.Time
+		}
+		if statusTo.Valid {
+			personnel.StatusEffectiveTo = &statusTo.Time
+		}
+		return personnel, nil
+	})
 }
 
 func (r *personnelRepository) GetPersonnelByID(id int) (*models.Personnels, error) {
+	return r.getPersonnelByID(id, false)
+}
+
+// GetPublicPersonnelByID คืน sql.ErrNoRows เมื่อบุคลากรไม่ได้ปฏิบัติงานอยู่ในวันนี้ เหมือนรายการบนหน้าเว็บสาธารณะ
+func (r *personnelRepository) GetPublicPersonnelByID(id int) (*models.Personnels, error) {
+	return r.getPersonnelByID(id, true)
+}
+
+func (r *personnelRepository) getPersonnelByID(id int, publicOnly bool) (*models.Personnels, error) {
 	query := `
 		SELECT
 			p.personnel_id, p.type_personnel, d.department_position_id, d.department_position_name,
 			a.academic_position_id, a.thai_academic_position, a.eng_academic_position, p.thai_name, 
-			p.eng_name, p.education, p.related_fields, p.email, p.website, p.file_image, p.scopus_id
+			p.eng_name, p.education, p.related_fields, p.email, p.website, p.file_image, p.scopus_id,
+			` + CurrentStatus + `, st.effective_from, st.effective_to
 		FROM personnels p
 		LEFT JOIN department_position d ON p.department_position_id = d.department_position_id
 		LEFT JOIN academic_position a ON p.academic_position_id = a.academic_position_id
+	` + CurrentStatusJoin + `
 		WHERE p.personnel_id = $1
 	`
+	if publicOnly {
+		query += " AND " + ActiveCondition
+	}
 
 	row := r.db.QueryRow(query, id)
 
 	var personnel models.Personnels
 	var scopus sql.NullString
+	var statusFrom, statusTo sql.NullTime
 	err := row.Scan(
 		&personnel.PersonnelID, &personnel.TypePersonnel, &personnel.DepartmentPositionID, &personnel.DepartmentPositionName,
 		&personnel.AcademicPositionID, &personnel.ThaiAcademicPosition, &personnel.EngAcademicPosition,
 		&personnel.ThaiName, &personnel.EngName, &personnel.Education, &personnel.RelatedFields,
 		&personnel.Email, &personnel.Website, &personnel.FileImage, &scopus,
+		&personnel.Status, &statusFrom, &statusTo,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -146,6 +213,12 @@ func (r *personnelRepository) GetPersonnelByID(id int) (*models.Personnels, erro
 	} else {
 		personnel.ScopusID = nil
 	}
+	if statusFrom.Valid {
+		personnel.StatusEffectiveFrom = &statusFrom.Time
+	}
+	if statusTo.Valid {
+		personnel.StatusEffectiveTo = &statusTo.Time
+	}
 	return &personnel, nil
 }
 
@@ -305,6 +378,10 @@ func (r *personnelRepository) UpdateTeacher(id int, req models.TeacherRequest) (
 func (r *personnelRepository) DeletePersonnel(id int) error {
 	result, err := r.db.Exec("DELETE FROM personnels WHERE personnel_id = $1", id)
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return ErrPersonnelHasResearch
+		}
 		return err
 	}
 	rowsAffected, err := result.RowsAffected()
@@ -441,16 +518,20 @@ func (r *personnelRepository) SaveResearch(personnelID int, researches []models.
 	return nil
 }
 
-func (r *personnelRepository) GetAllResearch(param models.ResearchQueryParam) ([]models.Research, error) {
-	query := `
-		SELECT r.research_id, p.personnel_id, p.thai_name, r.title, r.journal,
-    	r.year, r.volume, r.issue, r.pages, r.doi, r.cited, r.created_at,
-    	COALESCE(ARRAY_AGG(a.author_name ORDER BY a.author_order)
-        FILTER (WHERE a.author_name IS NOT NULL),'{}') AS authors
-		FROM research r
-		LEFT JOIN personnels p ON r.personnel_id = p.personnel_id
-		LEFT JOIN research_authors a ON r.research_id = a.research_id
-	`
+var researchSort = pagination.Sorter{
+	Columns: map[string]string{
+		"research_id": "r.research_id",
+		"title":       "r.title",
+		"journal":     "r.journal",
+		"year":        "r.year",
+		"cited":       "r.cited",
+		"created_at":  "r.created_at",
+	},
+	Default: "research_id",
+	Key:     "r.research_id",
+}
+
+func (r *personnelRepository) GetAllResearch(param models.ResearchQueryParam) (*pagination.Page[models.Research], error) {
 	conditions := []string{}
 	args := []interface{}{}
 	argIndex := 1
@@ -461,46 +542,48 @@ func (r *personnelRepository) GetAllResearch(param models.ResearchQueryParam) ([
 		argIndex++
 	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
+	if terms := textsearch.Terms(param.Search); len(terms) > 0 {
+		condition, searchArgs, next := textsearch.Condition([]string{"r.title", "r.journal"}, terms, argIndex)
+		conditions = append(conditions, condition)
+		args = append(args, searchArgs...)
+		argIndex = next
 	}
 
-	query += `GROUP BY r.research_id, p.personnel_id, p.thai_name, r.title,
-    r.journal, r.year, r.volume, r.issue, r.pages, r.doi, r.cited, r.created_at
-	`
-
-	sort := "research_id"
-	if param.Sort != "" {
-		sort = param.Sort
-	}
-	order := "ASC"
-	if strings.ToUpper(param.Order) == "DESC" {
-		order = "DESC"
-	}
-	query += " ORDER BY " + sort + " " + order
-
-	if param.Limit > 0 {
-		query += " LIMIT " + strconv.Itoa(param.Limit)
-	}
-
-	rows, err := r.db.Query(query, args...)
+	orderBy, err := researchSort.Parse(param.Sort, param.Order)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var researches []models.Research
-	for rows.Next() {
+	stmt := pagination.Statement{
+		Columns: `
+			r.research_id, p.personnel_id, p.thai_name, r.title, r.journal,
+			r.year, r.volume, r.issue, r.pages, r.doi, r.cited, r.created_at,
+			COALESCE(ARRAY_AGG(a.author_name ORDER BY a.author_order)
+			FILTER (WHERE a.author_name IS NOT NULL),'{}') AS authors
+		`,
+		From: `
+			FROM research r
+			LEFT JOIN personnels p ON r.personnel_id = p.personnel_id
+			LEFT JOIN research_authors a ON r.research_id = a.research_id
+		`,
+		Conditions: conditions,
+		GroupBy: `r.research_id, p.personnel_id, p.thai_name, r.title,
+			r.journal, r.year, r.volume, r.issue, r.pages, r.doi, r.cited, r.created_at`,
+		Args:    args,
+		OrderBy: orderBy,
+	}
+
+	return pagination.Fetch(r.db, stmt, param.Params, func(row pagination.Scanner) (models.Research, error) {
 		var r models.Research
 		var vol, iss, pages, doi sql.NullString
 		var authors []string
 
-		err := rows.Scan(
+		err := row.Scan(
 			&r.ResearchID, &r.PersonnelID, &r.ThaiName, &r.Title, &r.Journal, &r.Year,
 			&vol, &iss, &pages, &doi, &r.Cited, &r.CreatedAt, pq.Array(&authors),
 		)
 		if err != nil {
-			return nil, err
+			return r, err
 		}
 
 		if vol.Valid {
@@ -517,8 +600,79 @@ func (r *personnelRepository) GetAllResearch(param models.ResearchQueryParam) ([
 		}
 
 		r.Authors = authors
-		researches = append(researches, r)
+		return r, nil
+	})
+}
+
+func (r *personnelRepository) GetPersonnelStatusHistory(personnelID int) ([]models.PersonnelStatus, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM personnels WHERE personnel_id = $1)`, personnelID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	rows, err := r.db.Query(`
+		SELECT status_id, personnel_id, status, effective_from, effective_to, note, created_at
+		FROM personnel_status
+		WHERE personnel_id = $1
+		ORDER BY effective_from DESC, status_id DESC
+	`, personnelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []models.PersonnelStatus{}
+	for rows.Next() {
+		var ps models.PersonnelStatus
+		var effectiveTo sql.NullTime
+		if err := rows.Scan(
+			&ps.StatusID, &ps.PersonnelID, &ps.Status, &ps.EffectiveFrom,
+			&effectiveTo, &ps.Note, &ps.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if effectiveTo.Valid {
+			ps.EffectiveTo = &effectiveTo.Time
+		}
+		history = append(history, ps)
+	}
+
+	return history, nil
+}
+
+func (r *personnelRepository) AddPersonnelStatus(status models.PersonnelStatus) (*models.PersonnelStatus, error) {
+	// ส่งเป็นข้อความวันที่ เพื่อไม่ให้ timezone ของ session เลื่อนวัน
+	var to interface{}
+	if status.EffectiveTo != nil {
+		to = status.EffectiveTo.Format("2006-01-02")
+	}
+
+	var ps models.PersonnelStatus
+	var effectiveTo sql.NullTime
+	err := r.db.QueryRow(`
+		INSERT INTO personnel_status (personnel_id, status, effective_from, effective_to, note)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING status_id, personnel_id, status, effective_from, effective_to, note, created_at
+	`,
+		status.PersonnelID, status.Status, status.EffectiveFrom.Format("2006-01-02"), to, status.Note,
+	).Scan(
+		&ps.StatusID, &ps.PersonnelID, &ps.Status, &ps.EffectiveFrom,
+		&effectiveTo, &ps.Note, &ps.CreatedAt,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+	if effectiveTo.Valid {
+		ps.EffectiveTo = &effectiveTo.Time
 	}
 
-	return researches, nil
+	return &ps, nil
 }
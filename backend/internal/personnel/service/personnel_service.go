@@ -8,23 +8,22 @@ import (
 	"mime/multipart"
 	"net/http"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	authrepo "cpsu/internal/auth/repository"
+	"cpsu/internal/imaging"
+	"cpsu/internal/pagination"
 	"cpsu/internal/personnel/models"
 	"cpsu/internal/personnel/repository"
-
-	"github.com/google/uuid"
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
+	"cpsu/internal/storage"
 )
 
 type PersonnelService interface {
-	GetAllPersonnels(param models.PersonnelQueryParam) ([]models.Personnels, error)
+	GetAllPersonnels(param models.PersonnelQueryParam) (*pagination.Page[models.Personnels], error)
 	GetPersonnelByID(id int) (*models.Personnels, error)
+	GetPublicPersonnelByID(id int) (*models.Personnels, error)
 	CreatePersonnel(req models.PersonnelRequest, fileImage *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Personnels, error)
 	UpdatePersonnel(id int, req models.PersonnelRequest, fileImage *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Personnels, error)
 	UpdateTeacher(id int, req models.TeacherRequest, fileImage *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Personnels, error)
@@ -32,51 +31,67 @@ type PersonnelService interface {
 	SyncResearch(personnelID int) ([]models.Research, error)
 	GetResearchFromScopus(scopusID string) ([]models.Research, error)
 	SyncAllFromScopus() (int, error)
-	GetAllResearch(param models.ResearchQueryParam) ([]models.Research, error)
+	GetAllResearch(param models.ResearchQueryParam) (*pagination.Page[models.Research], error)
+	GetPersonnelStatusHistory(personnelID int) ([]models.PersonnelStatus, error)
+	ChangePersonnelStatus(personnelID int, req models.PersonnelStatusRequest, userID int, ip string, userAgent string) (*models.PersonnelStatus, error)
 }
 
+var (
+	ErrInvalidStatus     = errors.New("invalid personnel status")
+	ErrInvalidStatusDate = errors.New("effective_to must not be before effective_from")
+	ErrInvalidDate       = errors.New("effective_from and effective_to must be dates in YYYY-MM-DD format")
+)
+
 type personnelService struct {
-	repo        repository.PersonnelRepository
-	auditRepo   *authrepo.AuditRepository
-	minioClient *minio.Client
-	bucket      string
-	publicBase  string
+	repo      repository.PersonnelRepository
+	auditRepo *authrepo.AuditRepository
+	store     storage.Storage
+	images    *imaging.Pipeline
 }
 
 func NewPersonnelService(
 	repo repository.PersonnelRepository,
 	auditRepo *authrepo.AuditRepository,
-	endpoint string,
-	accessKey string,
-	secretKey string,
-	bucket string,
-	useSSL bool,
-	publicBaseURL string,
+	store storage.Storage,
+	images *imaging.Pipeline,
 ) PersonnelService {
 
-	client, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
-		Secure: useSSL,
-	})
-	if err != nil {
-		panic(err)
-	}
-
 	return &personnelService{
-		repo:        repo,
-		auditRepo:   auditRepo,
-		minioClient: client,
-		bucket:      bucket,
-		publicBase:  publicBaseURL,
+		repo:      repo,
+		auditRepo: auditRepo,
+		store:     store,
+		images:    images,
 	}
 }
 
-func (s *personnelService) GetAllPersonnels(param models.PersonnelQueryParam) ([]models.Personnels, error) {
-	return s.repo.GetAllPersonnels(param)
+func (s *personnelService) GetAllPersonnels(param models.PersonnelQueryParam) (*pagination.Page[models.Personnels], error) {
+	if param.Status != "" && !models.IsValidStatus(param.Status) {
+		return nil, ErrInvalidStatus
+	}
+	personnels, err := s.repo.GetAllPersonnels(param)
+	if err != nil {
+		return nil, err
+	}
+	for i := range personnels.Items {
+		personnels.Items[i].FileImageVariants = imaging.VariantURLs(personnels.Items[i].FileImage)
+	}
+	return personnels, nil
 }
 
 func (s *personnelService) GetPersonnelByID(id int) (*models.Personnels, error) {
-	return s.repo.GetPersonnelByID(id)
+	return withPersonnelVariants(s.repo.GetPersonnelByID(id))
+}
+
+func (s *personnelService) GetPublicPersonnelByID(id int) (*models.Personnels, error) {
+	return withPersonnelVariants(s.repo.GetPublicPersonnelByID(id))
+}
+
+func withPersonnelVariants(personnel *models.Personnels, err error) (*models.Personnels, error) {
+	if err != nil {
+		return nil, err
+	}
+	personnel.FileImageVariants = imaging.VariantURLs(personnel.FileImage)
+	return personnel, nil
 }
 
 func (s *personnelService) CreatePersonnel(req models.PersonnelRequest, fileImage *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Personnels, error) {
@@ -91,6 +106,9 @@ func (s *personnelService) CreatePersonnel(req models.PersonnelRequest, fileImag
 
 	created, err := s.repo.CreatePersonnel(req)
 	if err != nil {
+		if fileImage != nil {
+			imaging.RemoveUnused(s.store, req.FileImage)
+		}
 		return nil, err
 	}
 
@@ -103,11 +121,18 @@ func (s *personnelService) CreatePersonnel(req models.PersonnelRequest, fileImag
 		ip, userAgent,
 	)
 
+	created.FileImageVariants = imaging.VariantURLs(created.FileImage)
 	return created, nil
 }
 
 func (s *personnelService) UpdatePersonnel(id int, req models.PersonnelRequest, fileImage *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Personnels, error) {
 
+	existing, err := s.repo.GetPersonnelByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req.FileImage = existing.FileImage
 	if fileImage != nil {
 		url, err := s.uploadFile(fileImage)
 		if err != nil {
@@ -118,9 +143,16 @@ func (s *personnelService) UpdatePersonnel(id int, req models.PersonnelRequest,
 
 	updated, err := s.repo.UpdatePersonnel(id, req)
 	if err != nil {
+		if fileImage != nil {
+			imaging.RemoveUnused(s.store, req.FileImage)
+		}
 		return nil, err
 	}
 
+	if existing.FileImage != updated.FileImage {
+		imaging.RemoveUnused(s.store, existing.FileImage)
+	}
+
 	err = s.auditRepo.LogAudit(
 		userID, "update", "personnel", strconv.Itoa(id),
 		map[string]interface{}{
@@ -129,11 +161,18 @@ func (s *personnelService) UpdatePersonnel(id int, req models.PersonnelRequest,
 		ip, userAgent,
 	)
 
+	updated.FileImageVariants = imaging.VariantURLs(updated.FileImage)
 	return updated, nil
 }
 
 func (s *personnelService) UpdateTeacher(id int, req models.TeacherRequest, fileImage *multipart.FileHeader, userID int, ip string, userAgent string) (*models.Personnels, error) {
 
+	existing, err := s.repo.GetPersonnelByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req.FileImage = existing.FileImage
 	if fileImage != nil {
 		url, err := s.uploadFile(fileImage)
 		if err != nil {
@@ -142,11 +181,6 @@ func (s *personnelService) UpdateTeacher(id int, req models.TeacherRequest, file
 		req.FileImage = url
 	}
 
-	existing, err := s.repo.GetPersonnelByID(id)
-	if err != nil {
-		return nil, err
-	}
-
 	personnelReq := models.PersonnelRequest{
 		TypePersonnel:        existing.TypePersonnel,
 		DepartmentPositionID: &existing.DepartmentPositionID,
@@ -165,9 +199,16 @@ func (s *personnelService) UpdateTeacher(id int, req models.TeacherRequest, file
 
 	updated, err := s.repo.UpdatePersonnel(id, personnelReq)
 	if err != nil {
+		if fileImage != nil {
+			imaging.RemoveUnused(s.store, req.FileImage)
+		}
 		return nil, err
 	}
 
+	if existing.FileImage != updated.FileImage {
+		imaging.RemoveUnused(s.store, existing.FileImage)
+	}
+
 	err = s.auditRepo.LogAudit(
 		userID, "update", "personnel", strconv.Itoa(id),
 		map[string]interface{}{
@@ -176,16 +217,24 @@ func (s *personnelService) UpdateTeacher(id int, req models.TeacherRequest, file
 		ip, userAgent,
 	)
 
+	updated.FileImageVariants = imaging.VariantURLs(updated.FileImage)
 	return updated, nil
 }
 
 func (s *personnelService) DeletePersonnel(id int, userID int, ip string, userAgent string) error {
 
-	err := s.repo.DeletePersonnel(id)
+	existing, err := s.repo.GetPersonnelByID(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.DeletePersonnel(id)
 	if err != nil {
 		return err
 	}
 
+	imaging.RemoveUnused(s.store, existing.FileImage)
+
 	err = s.auditRepo.LogAudit(
 		userID,
 		"delete",
@@ -200,45 +249,7 @@ func (s *personnelService) DeletePersonnel(id int, userID int, ip string, userAg
 }
 
 func (s *personnelService) uploadFile(fileHeader *multipart.FileHeader) (string, error) {
-	if fileHeader == nil {
-		return "", errors.New("file is nil")
-	}
-
-	file, err := fileHeader.Open()
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	ext := filepath.Ext(fileHeader.Filename)
-	objectName := fmt.Sprintf(
-		"personnel/%s%s",
-		uuid.New().String(),
-		ext,
-	)
-
-	_, err = s.minioClient.PutObject(
-		context.Background(),
-		s.bucket,
-		objectName,
-		file,
-		fileHeader.Size,
-		minio.PutObjectOptions{
-			ContentType: fileHeader.Header.Get("Content-Type"),
-		},
-	)
-	if err != nil {
-		return "", err
-	}
-
-	imageURL := fmt.Sprintf(
-		"%s/%s/%s",
-		s.publicBase,
-		s.bucket,
-		objectName,
-	)
-
-	return imageURL, nil
+	return s.images.Upload(context.Background(), s.store, "personnel", fileHeader)
 }
 
 func (s *personnelService) SyncResearch(personnelID int) ([]models.Research, error) {
@@ -264,8 +275,12 @@ func (s *personnelService) SyncResearch(personnelID int) ([]models.Research, err
 		return nil, err
 	}
 
-	param := models.ResearchQueryParam{PersonnelID: personnelID}
-	return s.repo.GetAllResearch(param)
+	param := models.ResearchQueryParam{Params: pagination.All(), PersonnelID: personnelID}
+	research, err := s.repo.GetAllResearch(param)
+	if err != nil {
+		return nil, err
+	}
+	return research.Items, nil
 }
 
 func (s *personnelService) getAuthorsFromAbstract(eid string) []string {
@@ -454,14 +469,14 @@ func (s *personnelService) GetResearchFromScopus(scopusID string) ([]models.Rese
 }
 
 func (s *personnelService) SyncAllFromScopus() (int, error) {
-	personnels, err := s.repo.GetAllPersonnels(models.PersonnelQueryParam{})
+	personnels, err := s.repo.GetAllPersonnels(models.PersonnelQueryParam{Params: pagination.All()})
 	if err != nil {
 		return 0, err
 	}
 
 	processed := 0
 
-	for _, p := range personnels {
+	for _, p := range personnels.Items {
 		if p.ScopusID == nil || *p.ScopusID == "" {
 			continue
 		}
@@ -486,6 +501,66 @@ func (s *personnelService) SyncAllFromScopus() (int, error) {
 	return processed, nil
 }
 
-func (s *personnelService) GetAllResearch(param models.ResearchQueryParam) ([]models.Research, error) {
+func (s *personnelService) GetAllResearch(param models.ResearchQueryParam) (*pagination.Page[models.Research], error) {
 	return s.repo.GetAllResearch(param)
 }
+
+func (s *personnelService) GetPersonnelStatusHistory(personnelID int) ([]models.PersonnelStatus, error) {
+	return s.repo.GetPersonnelStatusHistory(personnelID)
+}
+
+func (s *personnelService) ChangePersonnelStatus(personnelID int, req models.PersonnelStatusRequest, userID int, ip string, userAgent string) (*models.PersonnelStatus, error) {
+	if !models.IsValidStatus(req.Status) {
+		return nil, ErrInvalidStatus
+	}
+	effectiveFrom, err := parseStatusDate(req.EffectiveFrom)
+	if err != nil {
+		return nil, err
+	}
+	effectiveTo, err := parseStatusDate(req.EffectiveTo)
+	if err != nil {
+		return nil, err
+	}
+	if effectiveFrom == nil {
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		effectiveFrom = &today
+	}
+	if effectiveTo != nil && effectiveTo.Before(*effectiveFrom) {
+		return nil, ErrInvalidStatusDate
+	}
+
+	created, err := s.repo.AddPersonnelStatus(models.PersonnelStatus{
+		PersonnelID:   personnelID,
+		Status:        req.Status,
+		EffectiveFrom: *effectiveFrom,
+		EffectiveTo:   effectiveTo,
+		Note:          req.Note,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "update_status", "personnel", strconv.Itoa(personnelID),
+		map[string]interface{}{
+			"status":         created.Status,
+			"effective_from": created.EffectiveFrom.Format("2006-01-02"),
+		},
+		ip, userAgent,
+	)
+
+	return created, nil
+}
+
+// parseStatusDate คืน nil เมื่อไม่ได้ระบุวันที่หรือส่งค่าว่างมา
+func parseStatusDate(value *string) (*time.Time, error) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(*value))
+	if err != nil {
+		return nil, ErrInvalidDate
+	}
+	return &date, nil
+}
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"strconv"
 
+	"cpsu/internal/imaging"
+	"cpsu/internal/pagination"
 	"cpsu/internal/roadmap/models"
 	"cpsu/internal/roadmap/service"
 
@@ -27,8 +29,27 @@ func (h *RoadmapHandler) GetAllRoadmap(c *gin.Context) {
 		return
 	}
 
+	h.getAllRoadmap(c, param)
+}
+
+func (h *RoadmapHandler) GetPublicRoadmap(c *gin.Context) {
+	var param models.RoadmapQueryParam
+	if err := c.BindQuery(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	param.PublicOnly = true
+	h.getAllRoadmap(c, param)
+}
+
+func (h *RoadmapHandler) getAllRoadmap(c *gin.Context, param models.RoadmapQueryParam) {
 	roadmaps, err := h.roadmapService.GetAllRoadmap(param)
 	if err != nil {
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -37,6 +58,14 @@ func (h *RoadmapHandler) GetAllRoadmap(c *gin.Context) {
 }
 
 func (h *RoadmapHandler) GetRoadmapByID(c *gin.Context) {
+	h.getRoadmapByID(c, h.roadmapService.GetRoadmapByID)
+}
+
+func (h *RoadmapHandler) GetPublicRoadmapByID(c *gin.Context) {
+	h.getRoadmapByID(c, h.roadmapService.GetPublicRoadmapByID)
+}
+
+func (h *RoadmapHandler) getRoadmapByID(c *gin.Context, get func(id int) (*models.Roadmap, error)) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
@@ -44,7 +73,7 @@ func (h *RoadmapHandler) GetRoadmapByID(c *gin.Context) {
 		return
 	}
 
-	roadmap, err := h.roadmapService.GetRoadmapByID(id)
+	roadmap, err := get(id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "roadmap not found"})
@@ -72,6 +101,10 @@ func (h *RoadmapHandler) CreateRoadmap(c *gin.Context) {
 
 	created, err := h.roadmapService.CreateRoadmap(courseID, file)
 	if err != nil {
+		if status := imaging.HTTPStatus(err); status != 0 {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -79,6 +112,38 @@ func (h *RoadmapHandler) CreateRoadmap(c *gin.Context) {
 	c.JSON(http.StatusCreated, created)
 }
 
+// UpdateRoadmap เปลี่ยนรูปแผนการศึกษา course_id เป็น optional ถ้าไม่ส่งจะใช้ค่าเดิม
+func (h *RoadmapHandler) UpdateRoadmap(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid roadmap ID"})
+		return
+	}
+
+	file, err := c.FormFile("roadmap_url")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "roadmap_url is required"})
+		return
+	}
+
+	updated, err := h.roadmapService.UpdateRoadmap(id, c.PostForm("course_id"), file)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "roadmap not found"})
+			return
+		}
+		if status := imaging.HTTPStatus(err); status != 0 {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
 func (h *RoadmapHandler) DeleteRoadmap(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
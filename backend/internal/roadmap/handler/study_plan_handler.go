@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"cpsu/internal/roadmap/models"
+	"cpsu/internal/roadmap/repository"
+	"cpsu/internal/roadmap/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *RoadmapHandler) GetStudyPlans(c *gin.Context) {
+	plans, err := h.roadmapService.GetStudyPlans(c.Param("id"))
+	if err != nil {
+		studyPlanErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, plans)
+}
+
+func (h *RoadmapHandler) GetStudyPlan(c *gin.Context) {
+	planID, ok := studyPlanID(c)
+	if !ok {
+		return
+	}
+
+	plan, err := h.roadmapService.GetStudyPlan(c.Param("id"), planID)
+	if err != nil {
+		studyPlanErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
+// DownloadStudyPlan ส่งแผนการศึกษาเป็นไฟล์ ?format=png (ค่าเริ่มต้น) หรือ pdf
+func (h *RoadmapHandler) DownloadStudyPlan(c *gin.Context) {
+	planID, ok := studyPlanID(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "png")
+	data, contentType, err := h.roadmapService.RenderStudyPlan(c.Param("id"), planID, format)
+	if err != nil {
+		studyPlanErrorResponse(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("study-plan-%s-%d.%s", c.Param("id"), planID, format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func (h *RoadmapHandler) ValidateStudyPlan(c *gin.Context) {
+	planID, ok := studyPlanID(c)
+	if !ok {
+		return
+	}
+
+	validation, err := h.roadmapService.ValidateStudyPlan(c.Param("id"), planID)
+	if err != nil {
+		studyPlanErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, validation)
+}
+
+// CreateStudyPlan ถ้า ?dry_run=true จะตรวจอย่างเดียวไม่บันทึก แผนที่มีปัญหาระดับ error จะได้ 422 พร้อมผลการตรวจ
+func (h *RoadmapHandler) CreateStudyPlan(c *gin.Context) {
+	dryRun, ok := studyPlanDryRun(c)
+	if !ok {
+		return
+	}
+
+	var req models.StudyPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validation, err := h.roadmapService.CreateStudyPlan(
+		c.Param("id"),
+		req,
+		dryRun,
+		c.GetInt("user_id"),
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+	)
+	if err != nil {
+		studyPlanErrorResponse(c, err)
+		return
+	}
+
+	switch {
+	case !validation.Valid:
+		c.JSON(http.StatusUnprocessableEntity, validation)
+	case dryRun:
+		c.JSON(http.StatusOK, validation)
+	default:
+		c.JSON(http.StatusCreated, validation)
+	}
+}
+
+func (h *RoadmapHandler) UpdateStudyPlan(c *gin.Context) {
+	planID, ok := studyPlanID(c)
+	if !ok {
+		return
+	}
+	dryRun, ok := studyPlanDryRun(c)
+	if !ok {
+		return
+	}
+
+	var req models.StudyPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validation, err := h.roadmapService.UpdateStudyPlan(
+		c.Param("id"),
+		planID,
+		req,
+		dryRun,
+		c.GetInt("user_id"),
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+	)
+	if err != nil {
+		studyPlanErrorResponse(c, err)
+		return
+	}
+
+	if !validation.Valid {
+		c.JSON(http.StatusUnprocessableEntity, validation)
+		return
+	}
+	c.JSON(http.StatusOK, validation)
+}
+
+func (h *RoadmapHandler) DeleteStudyPlan(c *gin.Context) {
+	planID, ok := studyPlanID(c)
+	if !ok {
+		return
+	}
+
+	err := h.roadmapService.DeleteStudyPlan(
+		c.Param("id"),
+		planID,
+		c.GetInt("user_id"),
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+	)
+	if err != nil {
+		studyPlanErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "study plan deleted successfully"})
+}
+
+func studyPlanID(c *gin.Context) (int, bool) {
+	planID, err := strconv.Atoi(c.Param("plan"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid study plan ID"})
+		return 0, false
+	}
+	return planID, true
+}
+
+func studyPlanDryRun(c *gin.Context) (bool, bool) {
+	value := c.Query("dry_run")
+	if value == "" {
+		return false, true
+	}
+	dryRun, err := strconv.ParseBool(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+		return false, false
+	}
+	return dryRun, true
+}
+
+func studyPlanErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "study plan not found"})
+	case errors.Is(err, repository.ErrDuplicatePlanType):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUnsupportedPlanFormat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
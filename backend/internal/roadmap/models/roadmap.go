@@ -1,18 +1,27 @@
 package models
 
+import (
+	"cpsu/internal/imaging"
+	"cpsu/internal/pagination"
+)
+
 type Roadmap struct {
-	RoadmapID  int    `json:"roadmap_id"`
-	CourseID   string `json:"course_id"`
-	ThaiCourse string `json:"thai_course"`
-	RoadmapURL string `json:"roadmap_url"`
+	RoadmapID       int                  `json:"roadmap_id"`
+	CourseID        string               `json:"course_id"`
+	ThaiCourse      string               `json:"thai_course"`
+	RoadmapURL      string               `json:"roadmap_url"`
+	RoadmapVariants []imaging.VariantURL `json:"roadmap_variants,omitempty"`
 }
 
 type RoadmapQueryParam struct {
+	pagination.Params
 	Search   string `form:"search"`
-	Limit    int    `form:"limit"`
 	CourseID string `form:"course_id"`
 	Sort     string `form:"sort"`
 	Order    string `form:"order"`
+
+	// PublicOnly ตั้งโดย handler ของหน้าเว็บสาธารณะ
+	PublicOnly bool `form:"-"`
 }
 
 type RoadmapRequest struct {
@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// SummerSemester คือภาคฤดูร้อน ภาคปกติคือ 1 และ 2
+const SummerSemester = 3
+
+// หน่วยกิตสูงสุดต่อภาคเมื่อไม่ได้กำหนด ตรงกับค่าเริ่มต้นของตาราง study_plans
+const (
+	DefaultMaxSemesterCredits = 22
+	DefaultMaxSummerCredits   = 9
+)
+
+// StudyPlan คือแผนการศึกษาของหลักสูตรแยกตามแผนการเรียน เช่น โครงงานวิจัย หรือ สหกิจศึกษา
+type StudyPlan struct {
+	PlanID     int    `json:"plan_id"`
+	CourseID   string `json:"course_id"`
+	ThaiCourse string `json:"thai_course"`
+	EngCourse  string `json:"eng_course"`
+	PlanType   string `json:"plan_type"`
+	NameEN     string `json:"name_en"`
+	// หน่วยกิตสูงสุดที่ลงได้ต่อภาคปกติและภาคฤดูร้อน
+	MaxSemesterCredits int             `json:"max_semester_credits"`
+	MaxSummerCredits   int             `json:"max_summer_credits"`
+	TotalCredits       int             `json:"total_credits"`
+	Terms              []StudyPlanTerm `json:"terms"`
+	UpdatedAt          *time.Time      `json:"updated_at,omitempty"`
+}
+
+type StudyPlanTerm struct {
+	Year     int                `json:"year"`
+	Semester int                `json:"semester"`
+	Credits  int                `json:"credits"`
+	Subjects []StudyPlanSubject `json:"subjects"`
+}
+
+// StudyPlanSubject คือรายวิชาในภาคการศึกษา subject_id เป็น null เมื่อเป็นช่องวิชาเลือกที่ระบุแค่ label
+type StudyPlanSubject struct {
+	SubjectID   *string `json:"subject_id"`
+	Label       string  `json:"label,omitempty"`
+	ThaiSubject string  `json:"thai_subject"`
+	EngSubject  string  `json:"eng_subject"`
+	Credits     string  `json:"credits"`
+	CreditValue int     `json:"credit_value"`
+	NonCredit   bool    `json:"non_credit"`
+}
+
+type StudyPlanRequest struct {
+	PlanType           string                 `json:"plan_type" binding:"required"`
+	NameEN             string                 `json:"name_en"`
+	MaxSemesterCredits *int                   `json:"max_semester_credits" binding:"omitempty,min=1"`
+	MaxSummerCredits   *int                   `json:"max_summer_credits" binding:"omitempty,min=0"`
+	Terms              []StudyPlanTermRequest `json:"terms" binding:"dive"`
+}
+
+type StudyPlanTermRequest struct {
+	Year     int                     `json:"year" binding:"required,min=1,max=8"`
+	Semester int                     `json:"semester" binding:"required,min=1,max=3"`
+	Subjects []StudyPlanEntryRequest `json:"subjects"`
+}
+
+// StudyPlanEntryRequest ระบุ subject_id สำหรับรายวิชา หรือ label กับ credits สำหรับช่องวิชาเลือก
+type StudyPlanEntryRequest struct {
+	SubjectID string `json:"subject_id"`
+	Label     string `json:"label"`
+	Credits   int    `json:"credits"`
+}
+
+const (
+	StudyPlanSeverityError   = "error"
+	StudyPlanSeverityWarning = "warning"
+)
+
+// StudyPlanIssue คือปัญหาที่พบในแผน error ทำให้บันทึกไม่ได้ ส่วน warning แสดงให้ตรวจสอบ
+type StudyPlanIssue struct {
+	Severity  string `json:"severity"`
+	Code      string `json:"code"`
+	Year      int    `json:"year,omitempty"`
+	Semester  int    `json:"semester,omitempty"`
+	SubjectID string `json:"subject_id,omitempty"`
+	Message   string `json:"message"`
+}
+
+type StudyPlanValidation struct {
+	DryRun bool             `json:"dry_run"`
+	Valid  bool             `json:"valid"`
+	Issues []StudyPlanIssue `json:"issues"`
+	Plan   *StudyPlan       `json:"plan,omitempty"`
+}
+
+// PlanPrerequisite คือเงื่อนไขวิชาบังคับก่อนที่ใช้ตรวจแผน group เดียวกันคือทางเลือก "หรือ"
+type PlanPrerequisite struct {
+	SubjectID  string
+	RequiresID string
+	Kind       string
+	Group      int
+}
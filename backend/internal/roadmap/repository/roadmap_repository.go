@@ -3,16 +3,29 @@ package repository
 import (
 	"database/sql"
 	"strconv"
-	"strings"
 
+	coursemodels "cpsu/internal/course/models"
+	"cpsu/internal/pagination"
 	"cpsu/internal/roadmap/models"
+
+	"github.com/lib/pq"
 )
 
 type RoadmapRepository interface {
-	GetAllRoadmap(param models.RoadmapQueryParam) ([]models.Roadmap, error)
+	GetAllRoadmap(param models.RoadmapQueryParam) (*pagination.Page[models.Roadmap], error)
 	GetRoadmapByID(id int) (*models.Roadmap, error)
+	GetPublicRoadmapByID(id int) (*models.Roadmap, error)
 	CreateRoadmap(req *models.RoadmapRequest) (*models.Roadmap, error)
+	UpdateRoadmap(id int, req *models.RoadmapRequest) (*models.Roadmap, error)
 	DeleteRoadmap(id int) error
+
+	CourseExists(courseID string) error
+	GetStudyPlans(courseID string, planID int) ([]models.StudyPlan, error)
+	GetPlanSubjects(courseID string) (map[string]models.StudyPlanSubject, error)
+	GetPlanPrerequisites(courseID string) ([]models.PlanPrerequisite, error)
+	CreateStudyPlan(plan *models.StudyPlan) (int, error)
+	UpdateStudyPlan(plan *models.StudyPlan) error
+	DeleteStudyPlan(courseID string, planID int) error
 }
 
 type roadmapRepository struct {
@@ -23,13 +36,16 @@ func NewRoadmapRepository(db *sql.DB) RoadmapRepository {
 	return &roadmapRepository{db: db}
 }
 
-func (r *roadmapRepository) GetAllRoadmap(param models.RoadmapQueryParam) ([]models.Roadmap, error) {
-	query := `
-		SELECT r.roadmap_id, c.course_id, c.thai_course, r.roadmap_url
-		FROM roadmap r
-		LEFT JOIN courses c ON r.course_id = c.course_id
-	`
+var roadmapSort = pagination.Sorter{
+	Columns: map[string]string{
+		"roadmap_id": "r.roadmap_id",
+		"course_id":  "r.course_id",
+	},
+	Default: "roadmap_id",
+	Key:     "r.roadmap_id",
+}
 
+func (r *roadmapRepository) GetAllRoadmap(param models.RoadmapQueryParam) (*pagination.Page[models.Roadmap], error) {
 	conditions := []string{}
 	args := []interface{}{}
 	argIndex := 1
@@ -40,53 +56,57 @@ func (r *roadmapRepository) GetAllRoadmap(param models.RoadmapQueryParam) ([]mod
 		argIndex++
 	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
-	}
-
-	sort := "r.roadmap_id"
-	if param.Sort != "" {
-		sort = "r." + param.Sort
-	}
-
-	order := "ASC"
-	if strings.ToUpper(param.Order) == "DESC" {
-		order = "DESC"
-	}
-
-	query += " ORDER BY " + sort + " " + order
-
-	if param.Limit > 0 {
-		query += " LIMIT " + strconv.Itoa(param.Limit)
+	if param.PublicOnly {
+		conditions = append(conditions, "c.status = ANY($"+strconv.Itoa(argIndex)+")")
+		args = append(args, pq.Array(coursemodels.PublicStatuses))
+		argIndex++
 	}
 
-	rows, err := r.db.Query(query, args...)
+	orderBy, err := roadmapSort.Parse(param.Sort, param.Order)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var roadmaps []models.Roadmap
-	for rows.Next() {
-		var roadmap models.Roadmap
-		err := rows.Scan(&roadmap.RoadmapID, &roadmap.CourseID, &roadmap.ThaiCourse, &roadmap.RoadmapURL)
-		if err != nil {
-			return nil, err
-		}
-		roadmaps = append(roadmaps, roadmap)
+	stmt := pagination.Statement{
+		Columns: "r.roadmap_id, c.course_id, c.thai_course, r.roadmap_url",
+		From: `
+			FROM roadmap r
+			LEFT JOIN courses c ON r.course_id = c.course_id
+		`,
+		Conditions: conditions,
+		Args:       args,
+		OrderBy:    orderBy,
 	}
 
-	return roadmaps, nil
+	return pagination.Fetch(r.db, stmt, param.Params, func(row pagination.Scanner) (models.Roadmap, error) {
+		var roadmap models.Roadmap
+		err := row.Scan(&roadmap.RoadmapID, &roadmap.CourseID, &roadmap.ThaiCourse, &roadmap.RoadmapURL)
+		return roadmap, err
+	})
 }
 
 func (r *roadmapRepository) GetRoadmapByID(id int) (*models.Roadmap, error) {
+	return r.getRoadmapByID(id, false)
+}
+
+// GetPublicRoadmapByID คืน sql.ErrNoRows เมื่อหลักสูตรยังไม่เผยแพร่
+func (r *roadmapRepository) GetPublicRoadmapByID(id int) (*models.Roadmap, error) {
+	return r.getRoadmapByID(id, true)
+}
+
+func (r *roadmapRepository) getRoadmapByID(id int, publicOnly bool) (*models.Roadmap, error) {
 	query := `
 		SELECT r.roadmap_id, c.course_id, c.thai_course, r.roadmap_url
 		FROM roadmap r
 		LEFT JOIN courses c ON r.course_id = c.course_id
 		WHERE r.roadmap_id = $1
 	`
-	row := r.db.QueryRow(query, id)
+	args := []interface{}{id}
+	if publicOnly {
+		query += " AND c.status = ANY($2)"
+		args = append(args, pq.Array(coursemodels.PublicStatuses))
+	}
+	row := r.db.QueryRow(query, args...)
 
 	var roadmap models.Roadmap
 	err := row.Scan(&roadmap.RoadmapID, &roadmap.CourseID, &roadmap.ThaiCourse, &roadmap.RoadmapURL)
@@ -122,6 +142,27 @@ func (r *roadmapRepository) CreateRoadmap(req *models.RoadmapRequest) (*models.R
 	return &roadmap, nil
 }
 
+// UpdateRoadmap เปลี่ยนรูปแผนการศึกษา course_id ที่ว่างคือใช้ค่าเดิม
+func (r *roadmapRepository) UpdateRoadmap(id int, req *models.RoadmapRequest) (*models.Roadmap, error) {
+	result, err := r.db.Exec(`
+		UPDATE roadmap
+		SET course_id = COALESCE(NULLIF($1, ''), course_id), roadmap_url = $2
+		WHERE roadmap_id = $3
+	`, req.CourseID, req.RoadmapURL, id)
+	if err != nil {
+		return nil, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return r.GetRoadmapByID(id)
+}
+
 func (r *roadmapRepository) DeleteRoadmap(id int) error {
 	result, err := r.db.Exec("DELETE FROM roadmap WHERE roadmap_id = $1", id)
 	if err != nil {
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"cpsu/internal/roadmap/models"
+
+	"github.com/lib/pq"
+)
+
+var ErrDuplicatePlanType = errors.New("study plan for this plan type already exists")
+
+const studyPlanColumns = `
+	p.plan_id, p.course_id, c.thai_course, c.eng_course, p.plan_type, p.name_en,
+	p.max_semester_credits, p.max_summer_credits, p.updated_at
+`
+
+// GetStudyPlans คืนแผนการศึกษาทั้งหมดของหลักสูตร planID เป็น 0 คือทุกแผน
+func (r *roadmapRepository) GetStudyPlans(courseID string, planID int) ([]models.StudyPlan, error) {
+	query := "SELECT " + studyPlanColumns + `
+		FROM study_plans p
+		JOIN courses c ON p.course_id = c.course_id
+		WHERE p.course_id = $1`
+	args := []interface{}{courseID}
+	if planID > 0 {
+		query += " AND p.plan_id = $2"
+		args = append(args, planID)
+	}
+	query += " ORDER BY p.plan_id"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plans := []models.StudyPlan{}
+	index := map[int]int{}
+	for rows.Next() {
+		var (
+			p         models.StudyPlan
+			updatedAt sql.NullTime
+		)
+		if err := rows.Scan(
+			&p.PlanID, &p.CourseID, &p.ThaiCourse, &p.EngCourse, &p.PlanType, &p.NameEN,
+			&p.MaxSemesterCredits, &p.MaxSummerCredits, &updatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if updatedAt.Valid {
+			p.UpdatedAt = &updatedAt.Time
+		}
+		p.Terms = []models.StudyPlanTerm{}
+		index[p.PlanID] = len(plans)
+		plans = append(plans, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 {
+		return plans, nil
+	}
+
+	entryQuery := `
+		SELECT e.plan_id, e.year, e.semester, e.subject_id, e.label, e.credit_value,
+			COALESCE(s.thai_subject, ''), COALESCE(s.eng_subject, ''), COALESCE(s.credits, ''),
+			COALESCE(s.credit_value, e.credit_value), COALESCE(s.non_credit, false)
+		FROM study_plan_subjects e
+		JOIN study_plans p ON p.plan_id = e.plan_id
+		LEFT JOIN LATERAL (
+			SELECT thai_subject, eng_subject, credits, credit_value, non_credit
+			FROM subjects
+			WHERE course_id = p.course_id AND subject_id = e.subject_id
+			ORDER BY (plan_type = p.plan_type) DESC, id
+			LIMIT 1
+		) s ON true
+		WHERE p.course_id = $1`
+	if planID > 0 {
+		entryQuery += " AND p.plan_id = $2"
+	}
+	entryQuery += " ORDER BY e.plan_id, e.year, e.semester, e.position"
+
+	entryRows, err := r.db.Query(entryQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer entryRows.Close()
+
+	for entryRows.Next() {
+		var (
+			id, year, semester, slotCredits int
+			subject                         models.StudyPlanSubject
+		)
+		if err := entryRows.Scan(
+			&id, &year, &semester, &subject.SubjectID, &subject.Label, &slotCredits,
+			&subject.ThaiSubject, &subject.EngSubject, &subject.Credits,
+			&subject.CreditValue, &subject.NonCredit,
+		); err != nil {
+			return nil, err
+		}
+		if subject.SubjectID == nil {
+			subject.Credits = strconv.Itoa(slotCredits)
+		}
+
+		plan := &plans[index[id]]
+		if n := len(plan.Terms); n == 0 || plan.Terms[n-1].Year != year || plan.Terms[n-1].Semester != semester {
+			plan.Terms = append(plan.Terms, models.StudyPlanTerm{Year: year, Semester: semester, Subjects: []models.StudyPlanSubject{}})
+		}
+		term := &plan.Terms[len(plan.Terms)-1]
+		term.Subjects = append(term.Subjects, subject)
+	}
+	return plans, entryRows.Err()
+}
+
+// GetPlanSubjects คืนรายวิชาของหลักสูตรโดยใช้รหัสวิชาเป็น key ใช้ตรวจและแสดงแผนที่ยังไม่บันทึก
+func (r *roadmapRepository) GetPlanSubjects(courseID string) (map[string]models.StudyPlanSubject, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT ON (subject_id) subject_id, thai_subject, COALESCE(eng_subject, ''),
+			credits, credit_value, non_credit
+		FROM subjects
+		WHERE course_id = $1
+		ORDER BY subject_id, id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subjects := map[string]models.StudyPlanSubject{}
+	for rows.Next() {
+		var (
+			code string
+			s    models.StudyPlanSubject
+		)
+		if err := rows.Scan(&code, &s.ThaiSubject, &s.EngSubject, &s.Credits, &s.CreditValue, &s.NonCredit); err != nil {
+			return nil, err
+		}
+		s.SubjectID = &code
+		subjects[code] = s
+	}
+	return subjects, rows.Err()
+}
+
+func (r *roadmapRepository) GetPlanPrerequisites(courseID string) ([]models.PlanPrerequisite, error) {
+	rows, err := r.db.Query(`
+		SELECT subject_id, requires_id, kind, group_no
+		FROM subject_prerequisites
+		WHERE course_id = $1
+		ORDER BY subject_id, group_no, prerequisite_id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prerequisites := []models.PlanPrerequisite{}
+	for rows.Next() {
+		var p models.PlanPrerequisite
+		if err := rows.Scan(&p.SubjectID, &p.RequiresID, &p.Kind, &p.Group); err != nil {
+			return nil, err
+		}
+		prerequisites = append(prerequisites, p)
+	}
+	return prerequisites, rows.Err()
+}
+
+// CourseExists คืน sql.ErrNoRows เมื่อไม่มีหลักสูตรนี้
+func (r *roadmapRepository) CourseExists(courseID string) error {
+	var exists int
+	return r.db.QueryRow("SELECT 1 FROM courses WHERE course_id = $1", courseID).Scan(&exists)
+}
+
+func (r *roadmapRepository) CreateStudyPlan(plan *models.StudyPlan) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO study_plans (course_id, plan_type, name_en, max_semester_credits, max_summer_credits)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING plan_id
+	`, plan.CourseID, plan.PlanType, plan.NameEN, plan.MaxSemesterCredits, plan.MaxSummerCredits).Scan(&plan.PlanID)
+	if err != nil {
+		return 0, mapStudyPlanError(err)
+	}
+	if err := insertStudyPlanSubjects(tx, plan); err != nil {
+		return 0, err
+	}
+	return plan.PlanID, tx.Commit()
+}
+
+// UpdateStudyPlan แทนที่รายวิชาทั้งหมดของแผน
+func (r *roadmapRepository) UpdateStudyPlan(plan *models.StudyPlan) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE study_plans
+		SET plan_type = $1, name_en = $2, max_semester_credits = $3, max_summer_credits = $4,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE plan_id = $5 AND course_id = $6
+	`, plan.PlanType, plan.NameEN, plan.MaxSemesterCredits, plan.MaxSummerCredits, plan.PlanID, plan.CourseID)
+	if err != nil {
+		return mapStudyPlanError(err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec("DELETE FROM study_plan_subjects WHERE plan_id = $1", plan.PlanID); err != nil {
+		return err
+	}
+	if err := insertStudyPlanSubjects(tx, plan); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *roadmapRepository) DeleteStudyPlan(courseID string, planID int) error {
+	result, err := r.db.Exec("DELETE FROM study_plans WHERE plan_id = $1 AND course_id = $2", planID, courseID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func insertStudyPlanSubjects(tx *sql.Tx, plan *models.StudyPlan) error {
+	for _, term := range plan.Terms {
+		for i, subject := range term.Subjects {
+			_, err := tx.Exec(`
+				INSERT INTO study_plan_subjects (plan_id, year, semester, position, subject_id, label, credit_value)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)
+			`, plan.PlanID, term.Year, term.Semester, i+1, subject.SubjectID, subject.Label, subject.CreditValue)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func mapStudyPlanError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrDuplicatePlanType
+	}
+	return err
+}
@@ -3,64 +3,83 @@ package service
 import (
 	"context"
 	"errors"
-	"fmt"
 	"mime/multipart"
-	"path/filepath"
 
+	authrepo "cpsu/internal/auth/repository"
+	"cpsu/internal/imaging"
+	"cpsu/internal/pagination"
 	"cpsu/internal/roadmap/models"
 	"cpsu/internal/roadmap/repository"
-
-	"github.com/google/uuid"
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
+	"cpsu/internal/storage"
 )
 
 type RoadmapService interface {
-	GetAllRoadmap(param models.RoadmapQueryParam) ([]models.Roadmap, error)
+	GetAllRoadmap(param models.RoadmapQueryParam) (*pagination.Page[models.Roadmap], error)
 	GetRoadmapByID(id int) (*models.Roadmap, error)
+	GetPublicRoadmapByID(id int) (*models.Roadmap, error)
 	CreateRoadmap(courseID string, file *multipart.FileHeader) (*models.Roadmap, error)
+	UpdateRoadmap(id int, courseID string, file *multipart.FileHeader) (*models.Roadmap, error)
 	DeleteRoadmap(id int) error
+
+	GetStudyPlans(courseID string) ([]models.StudyPlan, error)
+	GetStudyPlan(courseID string, planID int) (*models.StudyPlan, error)
+	ValidateStudyPlan(courseID string, planID int) (*models.StudyPlanValidation, error)
+	CreateStudyPlan(courseID string, req models.StudyPlanRequest, dryRun bool, userID int, ip string, userAgent string) (*models.StudyPlanValidation, error)
+	UpdateStudyPlan(courseID string, planID int, req models.StudyPlanRequest, dryRun bool, userID int, ip string, userAgent string) (*models.StudyPlanValidation, error)
+	DeleteStudyPlan(courseID string, planID int, userID int, ip string, userAgent string) error
+	RenderStudyPlan(courseID string, planID int, format string) ([]byte, string, error)
 }
 
 type roadmapService struct {
-	repo        repository.RoadmapRepository
-	minioClient *minio.Client
-	bucket      string
-	publicBase  string
+	repo      repository.RoadmapRepository
+	auditRepo *authrepo.AuditRepository
+	store     storage.Storage
+	images    *imaging.Pipeline
+	renderer  *StudyPlanRenderer
 }
 
 func NewRoadmapService(
 	repo repository.RoadmapRepository,
-	endpoint string,
-	accessKey string,
-	secretKey string,
-	bucket string,
-	useSSL bool,
-	publicBaseURL string,
+	auditRepo *authrepo.AuditRepository,
+	store storage.Storage,
+	images *imaging.Pipeline,
+	renderer *StudyPlanRenderer,
 ) RoadmapService {
 
-	client, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
-		Secure: useSSL,
-	})
-	if err != nil {
-		panic(err)
-	}
-
 	return &roadmapService{
-		repo:        repo,
-		minioClient: client,
-		bucket:      bucket,
-		publicBase:  publicBaseURL,
+		repo:      repo,
+		auditRepo: auditRepo,
+		store:     store,
+		images:    images,
+		renderer:  renderer,
 	}
 }
 
-func (s *roadmapService) GetAllRoadmap(param models.RoadmapQueryParam) ([]models.Roadmap, error) {
-	return s.repo.GetAllRoadmap(param)
+func (s *roadmapService) GetAllRoadmap(param models.RoadmapQueryParam) (*pagination.Page[models.Roadmap], error) {
+	roadmaps, err := s.repo.GetAllRoadmap(param)
+	if err != nil {
+		return nil, err
+	}
+	for i := range roadmaps.Items {
+		roadmaps.Items[i].RoadmapVariants = imaging.VariantURLs(roadmaps.Items[i].RoadmapURL)
+	}
+	return roadmaps, nil
 }
 
 func (s *roadmapService) GetRoadmapByID(id int) (*models.Roadmap, error) {
-	return s.repo.GetRoadmapByID(id)
+	return withRoadmapVariants(s.repo.GetRoadmapByID(id))
+}
+
+func (s *roadmapService) GetPublicRoadmapByID(id int) (*models.Roadmap, error) {
+	return withRoadmapVariants(s.repo.GetPublicRoadmapByID(id))
+}
+
+func withRoadmapVariants(roadmap *models.Roadmap, err error) (*models.Roadmap, error) {
+	if err != nil {
+		return nil, err
+	}
+	roadmap.RoadmapVariants = imaging.VariantURLs(roadmap.RoadmapURL)
+	return roadmap, nil
 }
 
 func (s *roadmapService) CreateRoadmap(courseID string, file *multipart.FileHeader) (*models.Roadmap, error) {
@@ -81,47 +100,61 @@ func (s *roadmapService) CreateRoadmap(courseID string, file *multipart.FileHead
 		RoadmapURL: url,
 	}
 
-	return s.repo.CreateRoadmap(req)
+	created, err := s.repo.CreateRoadmap(req)
+	if err != nil {
+		imaging.RemoveUnused(s.store, url)
+		return nil, err
+	}
+	created.RoadmapVariants = imaging.VariantURLs(created.RoadmapURL)
+	return created, nil
 }
 
-func (s *roadmapService) DeleteRoadmap(id int) error {
-	return s.repo.DeleteRoadmap(id)
-}
+// UpdateRoadmap เปลี่ยนรูปแผนการศึกษา รูปเดิมจะถูกลบหลังบันทึกสำเร็จ
+func (s *roadmapService) UpdateRoadmap(id int, courseID string, file *multipart.FileHeader) (*models.Roadmap, error) {
+	if file == nil {
+		return nil, errors.New("roadmap image is required")
+	}
 
-func (s *roadmapService) uploadFile(fileHeader *multipart.FileHeader) (string, error) {
-	file, err := fileHeader.Open()
+	existing, err := s.repo.GetRoadmapByID(id)
 	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	ext := filepath.Ext(fileHeader.Filename)
-	objectName := fmt.Sprintf(
-		"roadmap/%s%s",
-		uuid.New().String(),
-		ext,
-	)
-
-	_, err = s.minioClient.PutObject(
-		context.Background(),
-		s.bucket,
-		objectName,
-		file,
-		fileHeader.Size,
-		minio.PutObjectOptions{
-			ContentType: fileHeader.Header.Get("Content-Type"),
-		},
-	)
+		return nil, err
+	}
+
+	url, err := s.uploadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &models.RoadmapRequest{
+		CourseID:   courseID,
+		RoadmapURL: url,
+	}
+
+	updated, err := s.repo.UpdateRoadmap(id, req)
+	if err != nil {
+		imaging.RemoveUnused(s.store, url)
+		return nil, err
+	}
+
+	imaging.RemoveUnused(s.store, existing.RoadmapURL)
+	updated.RoadmapVariants = imaging.VariantURLs(updated.RoadmapURL)
+	return updated, nil
+}
+
+func (s *roadmapService) DeleteRoadmap(id int) error {
+	existing, err := s.repo.GetRoadmapByID(id)
 	if err != nil {
-		return "", err
+		return err
+	}
+
+	if err := s.repo.DeleteRoadmap(id); err != nil {
+		return err
 	}
 
-	imageURL := fmt.Sprintf(
-		"%s/%s/%s",
-		s.publicBase,
-		s.bucket,
-		objectName,
-	)
+	imaging.RemoveUnused(s.store, existing.RoadmapURL)
+	return nil
+}
 
-	return imageURL, nil
+func (s *roadmapService) uploadFile(fileHeader *multipart.FileHeader) (string, error) {
+	return s.images.Upload(context.Background(), s.store, "roadmap", fileHeader)
 }
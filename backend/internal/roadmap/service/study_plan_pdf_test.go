@@ -0,0 +1,169 @@
+package service
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"cpsu/internal/roadmap/models"
+)
+
+var (
+	startxrefPattern = regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+	xrefPattern      = regexp.MustCompile(`^xref\n0 (\d+)\n`)
+	lengthPattern    = regexp.MustCompile(`/Length (\d+)`)
+	sizePattern      = regexp.MustCompile(`trailer\n<< /Size (\d+) /Root 1 0 R >>`)
+)
+
+// pdfObjects อ่าน PDF ผ่านตาราง xref แบบเดียวกับโปรแกรมอ่าน PDF แล้วคืนเนื้อหาของแต่ละ object ตามหมายเลข
+func pdfObjects(t *testing.T, pdf []byte) map[int][]byte {
+	t.Helper()
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) {
+		t.Fatalf("missing PDF header: %q", pdf[:min(len(pdf), 16)])
+	}
+	m := startxrefPattern.FindSubmatch(pdf)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if xref >= len(pdf) {
+		t.Fatalf("startxref %d beyond end of file", xref)
+	}
+
+	table := pdf[xref:]
+	m = xrefPattern.FindSubmatch(table)
+	if m == nil {
+		t.Fatalf("startxref %d does not point at xref: %q", xref, table[:min(len(table), 20)])
+	}
+	count, _ := strconv.Atoi(string(m[1]))
+	if size := sizePattern.FindSubmatch(table); size == nil || string(size[1]) != strconv.Itoa(count) {
+		t.Fatalf("trailer /Size does not match xref count %d", count)
+	}
+
+	// แต่ละรายการยาว 20 byte พอดีตามข้อกำหนดของ PDF
+	entries := table[len(m[0]):]
+	if !bytes.HasPrefix(entries, []byte("0000000000 65535 f \n")) {
+		t.Fatalf("first xref entry = %q", entries[:20])
+	}
+
+	objects := map[int][]byte{}
+	for n := 1; n < count; n++ {
+		entry := string(entries[n*20 : n*20+20])
+		if !strings.HasSuffix(entry, " 00000 n \n") {
+			t.Fatalf("xref entry %d = %q", n, entry)
+		}
+		offset, err := strconv.Atoi(entry[:10])
+		if err != nil {
+			t.Fatalf("xref entry %d = %q", n, entry)
+		}
+
+		header := fmt.Sprintf("%d 0 obj\n", n)
+		if !bytes.HasPrefix(pdf[offset:], []byte(header)) {
+			t.Fatalf("object %d offset %d points at %q", n, offset, pdf[offset:min(len(pdf), offset+20)])
+		}
+		body := pdf[offset+len(header):]
+		end := bytes.Index(body, []byte("endobj\n"))
+		if end < 0 {
+			t.Fatalf("object %d has no endobj", n)
+		}
+		objects[n] = body[:end]
+	}
+	return objects
+}
+
+// pdfStream คืนข้อมูลใน stream ของ object โดยใช้ความยาวจาก /Length
+func pdfStream(t *testing.T, object []byte) []byte {
+	t.Helper()
+
+	m := lengthPattern.FindSubmatch(object)
+	start := bytes.Index(object, []byte("stream\n"))
+	if m == nil || start < 0 {
+		t.Fatalf("object is not a stream: %q", object[:min(len(object), 80)])
+	}
+	length, _ := strconv.Atoi(string(m[1]))
+	start += len("stream\n")
+	if start+length > len(object) || !bytes.HasPrefix(object[start+length:], []byte("\nendstream\n")) {
+		t.Fatalf("/Length %d does not end at endstream", length)
+	}
+	return object[start : start+length]
+}
+
+func TestEncodePDF(t *testing.T) {
+	planImage := NewStudyPlanRenderer("").Render(&models.StudyPlan{
+		CourseID:  "BSCS65",
+		EngCourse: "Computer Science",
+		NameEN:    "Research project",
+		Terms:     []models.StudyPlanTerm{planTerm(1, 1, planSubject("511101", 3)), planTerm(1, 3, planSubject("517111", 3))},
+	})
+
+	small := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	small.Set(0, 0, color.RGBA{0xff, 0, 0, 0xff})
+	small.Set(2, 1, color.RGBA{0, 0, 0xff, 0xff})
+
+	// รูปที่ตัดมาจากรูปใหญ่มี bounds ไม่เริ่มที่ 0
+	offset := image.NewRGBA(image.Rect(0, 0, 10, 10)).SubImage(image.Rect(4, 5, 7, 7)).(*image.RGBA)
+	offset.Set(4, 5, color.RGBA{0xff, 0, 0, 0xff})
+	offset.Set(6, 6, color.RGBA{0, 0, 0xff, 0xff})
+
+	tests := []struct {
+		name string
+		img  *image.RGBA
+	}{
+		{"rendered plan", planImage},
+		{"small", small},
+		{"sub image", offset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdf, err := encodePDF(tt.img)
+			if err != nil {
+				t.Fatal(err)
+			}
+			objects := pdfObjects(t, pdf)
+			if len(objects) != 5 {
+				t.Fatalf("objects = %d, want 5", len(objects))
+			}
+
+			w, h := tt.img.Bounds().Dx(), tt.img.Bounds().Dy()
+			mediaBox := fmt.Sprintf("/MediaBox [0 0 %.2f %.2f]", float64(w)*0.75, float64(h)*0.75)
+			if !bytes.Contains(objects[3], []byte(mediaBox)) {
+				t.Errorf("page = %q, want %s", objects[3], mediaBox)
+			}
+			if size := fmt.Sprintf("/Width %d /Height %d", w, h); !bytes.Contains(objects[4], []byte(size)) {
+				t.Errorf("image = %q, want %s", objects[4][:80], size)
+			}
+			if content := pdfStream(t, objects[5]); !bytes.Contains(content, []byte("/Im0 Do")) {
+				t.Errorf("content = %q, want /Im0 Do", content)
+			}
+
+			zr, err := zlib.NewReader(bytes.NewReader(pdfStream(t, objects[4])))
+			if err != nil {
+				t.Fatal(err)
+			}
+			pixels, err := io.ReadAll(zr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pixels) != w*h*3 {
+				t.Fatalf("pixels = %d bytes, want %d", len(pixels), w*h*3)
+			}
+			bounds := tt.img.Bounds()
+			for _, p := range []image.Point{bounds.Min, bounds.Max.Sub(image.Pt(1, 1))} {
+				i := ((p.Y-bounds.Min.Y)*w + p.X - bounds.Min.X) * 3
+				c := tt.img.RGBAAt(p.X, p.Y)
+				if got := pixels[i : i+3]; !bytes.Equal(got, []byte{c.R, c.G, c.B}) {
+					t.Errorf("pixel %v = %v, want %v", p, got, []byte{c.R, c.G, c.B})
+				}
+			}
+		})
+	}
+}
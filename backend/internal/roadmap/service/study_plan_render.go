@@ -0,0 +1,288 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"os"
+	"unicode/utf8"
+
+	"cpsu/internal/roadmap/models"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	planColumnWidth = 420
+	planMargin      = 32
+	planLineHeight  = 22
+	planTitleHeight = 72
+)
+
+var (
+	planInk    = color.RGBA{0x1f, 0x29, 0x37, 0xff}
+	planMuted  = color.RGBA{0x6b, 0x72, 0x80, 0xff}
+	planHeader = color.RGBA{0xe5, 0xed, 0xf7, 0xff}
+	planBorder = color.RGBA{0xc8, 0xd2, 0xdf, 0xff}
+)
+
+// fontSet คือฟอนต์ขนาดหนึ่ง ตัวอักษรไทยใช้ thai ส่วนอื่นใช้ latin
+type fontSet struct {
+	latin font.Face
+	thai  font.Face
+}
+
+// StudyPlanRenderer วาดแผนการศึกษาเป็นรูป ปีเป็นคอลัมน์ ภาคการศึกษาเป็นแถว
+// ถ้าโหลดฟอนต์ไทยไม่ได้จะใช้ชื่อภาษาอังกฤษแทน
+// font.Drawer ไม่จัดรูปอักษรไทย สระบนกับวรรณยุกต์ที่ซ้อนกันจึงอาจทับกัน (ดู readme.txt ข้อ 3.8)
+type StudyPlanRenderer struct {
+	title  fontSet
+	header fontSet
+	body   fontSet
+}
+
+func NewStudyPlanRenderer(thaiFontPath string) *StudyPlanRenderer {
+	regular, _ := opentype.Parse(goregular.TTF)
+	bold, _ := opentype.Parse(gobold.TTF)
+
+	var thai *opentype.Font
+	if thaiFontPath != "" {
+		data, err := os.ReadFile(thaiFontPath)
+		if err == nil {
+			thai, err = opentype.Parse(data)
+		}
+		if err != nil {
+			log.Printf("load study plan font %s failed, rendering English names: %v", thaiFontPath, err)
+			thai = nil
+		}
+	}
+
+	set := func(latin *opentype.Font, size float64) fontSet {
+		s := fontSet{latin: newFace(latin, size)}
+		if thai != nil {
+			s.thai = newFace(thai, size)
+		}
+		return s
+	}
+	return &StudyPlanRenderer{
+		title:  set(bold, 22),
+		header: set(bold, 15),
+		body:   set(regular, 14),
+	}
+}
+
+func newFace(f *opentype.Font, size float64) font.Face {
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		log.Printf("create font face failed: %v", err)
+		return nil
+	}
+	return face
+}
+
+func (r *StudyPlanRenderer) thai() bool {
+	return r.body.thai != nil
+}
+
+// Render วาดแผนทั้งหมดในรูปเดียว ความสูงของแต่ละภาคเท่ากันทุกปีเพื่อให้ภาคเดียวกันอยู่แถวเดียวกัน
+func (r *StudyPlanRenderer) Render(plan *models.StudyPlan) *image.RGBA {
+	years := 0
+	rows := map[int]int{}
+	for _, term := range plan.Terms {
+		if term.Year > years {
+			years = term.Year
+		}
+		if len(term.Subjects) > rows[term.Semester] {
+			rows[term.Semester] = len(term.Subjects)
+		}
+	}
+	if years == 0 {
+		years = 1
+	}
+
+	// ตำแหน่งแนวตั้งของแต่ละภาค ภาคที่ไม่มีในแผนไม่ต้องเว้นที่
+	top := map[int]int{}
+	y := planMargin + planTitleHeight
+	for semester := 1; semester <= models.SummerSemester; semester++ {
+		if _, ok := rows[semester]; !ok {
+			continue
+		}
+		top[semester] = y
+		y += planLineHeight*(rows[semester]+1) + planLineHeight/2 + 12
+	}
+	height := y + planLineHeight + planMargin
+	width := planMargin*2 + years*planColumnWidth + (years-1)*12
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	title := plan.ThaiCourse
+	subtitle := plan.PlanType
+	if !r.thai() {
+		title = plan.EngCourse
+		subtitle = plan.NameEN
+	}
+	if title == "" {
+		title = plan.CourseID
+	}
+	r.text(img, r.title, planMargin, planMargin+24, title, planInk, width-planMargin*2)
+	r.text(img, r.header, planMargin, planMargin+52, subtitle, planMuted, width-planMargin*2)
+
+	for _, term := range plan.Terms {
+		x := planMargin + (term.Year-1)*(planColumnWidth+12)
+		ty := top[term.Semester]
+		boxHeight := planLineHeight*(rows[term.Semester]+1) + planLineHeight/2
+
+		fill(img, image.Rect(x, ty, x+planColumnWidth, ty+planLineHeight+4), planHeader)
+		outline(img, image.Rect(x, ty, x+planColumnWidth, ty+boxHeight), planBorder)
+
+		r.text(img, r.header, x+8, ty+planLineHeight-5, r.termName(term), planInk, planColumnWidth-90)
+		r.textRight(img, r.header, x+planColumnWidth-8, ty+planLineHeight-5, r.creditsName(term.Credits), planInk)
+
+		line := ty + planLineHeight + 4
+		for _, subject := range term.Subjects {
+			line += planLineHeight
+			name := r.subjectName(subject)
+			r.text(img, r.body, x+8, line-6, name, planInk, planColumnWidth-100)
+			r.textRight(img, r.body, x+planColumnWidth-8, line-6, subject.Credits, planMuted)
+		}
+	}
+
+	total := fmt.Sprintf("Total %d credits", plan.TotalCredits)
+	if r.thai() {
+		total = fmt.Sprintf("รวม %d หน่วยกิต", plan.TotalCredits)
+	}
+	r.textRight(img, r.header, width-planMargin, height-planMargin, total, planInk)
+	return img
+}
+
+func (r *StudyPlanRenderer) termName(term models.StudyPlanTerm) string {
+	if r.thai() {
+		if term.Semester == models.SummerSemester {
+			return fmt.Sprintf("ปีที่ %d ภาคฤดูร้อน", term.Year)
+		}
+		return fmt.Sprintf("ปีที่ %d ภาคการศึกษาที่ %d", term.Year, term.Semester)
+	}
+	if term.Semester == models.SummerSemester {
+		return fmt.Sprintf("Year %d, Summer", term.Year)
+	}
+	return fmt.Sprintf("Year %d, Semester %d", term.Year, term.Semester)
+}
+
+func (r *StudyPlanRenderer) creditsName(credits int) string {
+	if r.thai() {
+		return fmt.Sprintf("%d นก.", credits)
+	}
+	return fmt.Sprintf("%d cr.", credits)
+}
+
+func (r *StudyPlanRenderer) subjectName(subject models.StudyPlanSubject) string {
+	if subject.SubjectID == nil {
+		if r.thai() || isLatin(subject.Label) {
+			return subject.Label
+		}
+		return "Elective"
+	}
+	name := subject.ThaiSubject
+	if !r.thai() {
+		name = subject.EngSubject
+	}
+	return *subject.SubjectID + " " + name
+}
+
+// text วาดข้อความโดยเลือกฟอนต์ตามช่วงตัวอักษร ข้อความที่ยาวเกิน maxWidth จะถูกตัดท้ายด้วย ...
+func (r *StudyPlanRenderer) text(img *image.RGBA, fonts fontSet, x, y int, s string, c color.Color, maxWidth int) {
+	limit := fixed.I(maxWidth)
+	if fonts.measure(s) > limit {
+		ellipsis := fonts.measure("...")
+		for s != "" && fonts.measure(s)+ellipsis > limit {
+			_, size := utf8.DecodeLastRuneInString(s)
+			s = s[:len(s)-size]
+		}
+		s += "..."
+	}
+	fonts.draw(img, fixed.P(x, y), s, c)
+}
+
+func (r *StudyPlanRenderer) textRight(img *image.RGBA, fonts fontSet, right, y int, s string, c color.Color) {
+	dot := fixed.P(right, y)
+	dot.X -= fonts.measure(s)
+	fonts.draw(img, dot, s, c)
+}
+
+func (f fontSet) draw(img *image.RGBA, dot fixed.Point26_6, s string, c color.Color) {
+	d := &font.Drawer{Dst: img, Src: image.NewUniform(c), Dot: dot}
+	for _, run := range f.runs(s) {
+		d.Face = run.face
+		d.DrawString(run.text)
+	}
+}
+
+func (f fontSet) measure(s string) fixed.Int26_6 {
+	var width fixed.Int26_6
+	for _, run := range f.runs(s) {
+		width += font.MeasureString(run.face, run.text)
+	}
+	return width
+}
+
+type textRun struct {
+	face font.Face
+	text string
+}
+
+func (f fontSet) runs(s string) []textRun {
+	var runs []textRun
+	for _, r := range s {
+		face := f.latin
+		if f.thai != nil && isThai(r) {
+			face = f.thai
+		}
+		if n := len(runs); n > 0 && runs[n-1].face == face {
+			runs[n-1].text += string(r)
+			continue
+		}
+		runs = append(runs, textRun{face: face, text: string(r)})
+	}
+	return runs
+}
+
+func isThai(r rune) bool {
+	return r >= 0x0E00 && r <= 0x0E7F
+}
+
+func isLatin(s string) bool {
+	for _, r := range s {
+		if r > 0x024F {
+			return false
+		}
+	}
+	return true
+}
+
+func fill(img *image.RGBA, rect image.Rectangle, c color.Color) {
+	draw.Draw(img, rect, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func outline(img *image.RGBA, rect image.Rectangle, c color.Color) {
+	fill(img, image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+1), c)
+	fill(img, image.Rect(rect.Min.X, rect.Max.Y-1, rect.Max.X, rect.Max.Y), c)
+	fill(img, image.Rect(rect.Min.X, rect.Min.Y, rect.Min.X+1, rect.Max.Y), c)
+	fill(img, image.Rect(rect.Max.X-1, rect.Min.Y, rect.Max.X, rect.Max.Y), c)
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
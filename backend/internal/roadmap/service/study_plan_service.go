@@ -0,0 +1,353 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"cpsu/internal/roadmap/models"
+)
+
+var ErrUnsupportedPlanFormat = errors.New("format must be png or pdf")
+
+func (s *roadmapService) GetStudyPlans(courseID string) ([]models.StudyPlan, error) {
+	if err := s.repo.CourseExists(courseID); err != nil {
+		return nil, err
+	}
+	plans, err := s.repo.GetStudyPlans(courseID, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range plans {
+		summarizeStudyPlan(&plans[i])
+	}
+	return plans, nil
+}
+
+// GetStudyPlan คืน sql.ErrNoRows เมื่อไม่มีแผนนี้ในหลักสูตร
+func (s *roadmapService) GetStudyPlan(courseID string, planID int) (*models.StudyPlan, error) {
+	plans, err := s.repo.GetStudyPlans(courseID, planID)
+	if err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	summarizeStudyPlan(&plans[0])
+	return &plans[0], nil
+}
+
+// ValidateStudyPlan ตรวจแผนที่บันทึกไว้แล้วกับเงื่อนไขวิชาบังคับก่อนและหน่วยกิตต่อภาคปัจจุบัน
+func (s *roadmapService) ValidateStudyPlan(courseID string, planID int) (*models.StudyPlanValidation, error) {
+	plan, err := s.GetStudyPlan(courseID, planID)
+	if err != nil {
+		return nil, err
+	}
+	return s.validate(plan, nil, false)
+}
+
+// CreateStudyPlan บันทึกแผนใหม่เมื่อไม่มีปัญหาระดับ error ถ้าเป็น dryRun จะคืนผลการตรวจโดยไม่บันทึก
+func (s *roadmapService) CreateStudyPlan(courseID string, req models.StudyPlanRequest, dryRun bool, userID int, ip string, userAgent string) (*models.StudyPlanValidation, error) {
+	if err := s.repo.CourseExists(courseID); err != nil {
+		return nil, err
+	}
+
+	plan := &models.StudyPlan{
+		CourseID:           courseID,
+		MaxSemesterCredits: models.DefaultMaxSemesterCredits,
+		MaxSummerCredits:   models.DefaultMaxSummerCredits,
+	}
+	validation, err := s.buildStudyPlan(plan, req, dryRun)
+	if err != nil || !validation.Valid || dryRun {
+		return validation, err
+	}
+
+	if _, err := s.repo.CreateStudyPlan(plan); err != nil {
+		return nil, err
+	}
+	s.logStudyPlan(userID, "create", plan, ip, userAgent)
+
+	validation.Plan, err = s.GetStudyPlan(courseID, plan.PlanID)
+	return validation, err
+}
+
+// UpdateStudyPlan แทนที่รายวิชาทั้งหมดของแผน หน่วยกิตสูงสุดที่ไม่ได้ส่งมาจะใช้ค่าเดิม
+func (s *roadmapService) UpdateStudyPlan(courseID string, planID int, req models.StudyPlanRequest, dryRun bool, userID int, ip string, userAgent string) (*models.StudyPlanValidation, error) {
+	existing, err := s.GetStudyPlan(courseID, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &models.StudyPlan{
+		PlanID:             planID,
+		CourseID:           courseID,
+		ThaiCourse:         existing.ThaiCourse,
+		EngCourse:          existing.EngCourse,
+		MaxSemesterCredits: existing.MaxSemesterCredits,
+		MaxSummerCredits:   existing.MaxSummerCredits,
+	}
+	validation, err := s.buildStudyPlan(plan, req, dryRun)
+	if err != nil || !validation.Valid || dryRun {
+		return validation, err
+	}
+
+	if err := s.repo.UpdateStudyPlan(plan); err != nil {
+		return nil, err
+	}
+	s.logStudyPlan(userID, "update", plan, ip, userAgent)
+
+	validation.Plan, err = s.GetStudyPlan(courseID, planID)
+	return validation, err
+}
+
+func (s *roadmapService) DeleteStudyPlan(courseID string, planID int, userID int, ip string, userAgent string) error {
+	plan, err := s.GetStudyPlan(courseID, planID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteStudyPlan(courseID, planID); err != nil {
+		return err
+	}
+	s.logStudyPlan(userID, "delete", plan, ip, userAgent)
+	return nil
+}
+
+// RenderStudyPlan สร้างไฟล์แผนการศึกษาสำหรับดาวน์โหลด คืนข้อมูลไฟล์และ content type
+func (s *roadmapService) RenderStudyPlan(courseID string, planID int, format string) ([]byte, string, error) {
+	if format != "png" && format != "pdf" {
+		return nil, "", ErrUnsupportedPlanFormat
+	}
+	plan, err := s.GetStudyPlan(courseID, planID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	img := s.renderer.Render(plan)
+	if format == "pdf" {
+		data, err := encodePDF(img)
+		return data, "application/pdf", err
+	}
+	data, err := encodePNG(img)
+	return data, "image/png", err
+}
+
+func (s *roadmapService) logStudyPlan(userID int, action string, plan *models.StudyPlan, ip string, userAgent string) {
+	_ = s.auditRepo.LogAudit(
+		userID,
+		action,
+		"study_plan",
+		fmt.Sprintf("%d", plan.PlanID),
+		map[string]interface{}{
+			"course_id": plan.CourseID,
+			"plan_type": plan.PlanType,
+			"terms":     len(plan.Terms),
+		},
+		ip,
+		userAgent,
+	)
+}
+
+// buildStudyPlan แปลง request เป็นแผนโดยเติมชื่อและหน่วยกิตจากรายวิชาของหลักสูตร แล้วตรวจแผน
+func (s *roadmapService) buildStudyPlan(plan *models.StudyPlan, req models.StudyPlanRequest, dryRun bool) (*models.StudyPlanValidation, error) {
+	subjects, err := s.repo.GetPlanSubjects(plan.CourseID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.PlanType = strings.TrimSpace(req.PlanType)
+	plan.NameEN = strings.TrimSpace(req.NameEN)
+	if req.MaxSemesterCredits != nil {
+		plan.MaxSemesterCredits = *req.MaxSemesterCredits
+	}
+	if req.MaxSummerCredits != nil {
+		plan.MaxSummerCredits = *req.MaxSummerCredits
+	}
+
+	var issues []models.StudyPlanIssue
+	seenTerms := map[[2]int]bool{}
+	plan.Terms = []models.StudyPlanTerm{}
+	for _, t := range req.Terms {
+		key := [2]int{t.Year, t.Semester}
+		if seenTerms[key] {
+			issues = append(issues, planIssue(models.StudyPlanSeverityError, "duplicate_term", t.Year, t.Semester, "",
+				"term is listed more than once"))
+			continue
+		}
+		seenTerms[key] = true
+
+		term := models.StudyPlanTerm{Year: t.Year, Semester: t.Semester, Subjects: []models.StudyPlanSubject{}}
+		for _, entry := range t.Subjects {
+			code := strings.ToUpper(strings.TrimSpace(entry.SubjectID))
+			label := strings.TrimSpace(entry.Label)
+			if code == "" {
+				if label == "" || entry.Credits < 0 {
+					issues = append(issues, planIssue(models.StudyPlanSeverityError, "invalid_entry", t.Year, t.Semester, "",
+						"entry needs a subject_id, or a label with non-negative credits"))
+					continue
+				}
+				term.Subjects = append(term.Subjects, models.StudyPlanSubject{
+					Label:       label,
+					Credits:     fmt.Sprintf("%d", entry.Credits),
+					CreditValue: entry.Credits,
+				})
+				continue
+			}
+
+			subject, ok := subjects[code]
+			if !ok {
+				issues = append(issues, planIssue(models.StudyPlanSeverityError, "unknown_subject", t.Year, t.Semester, code,
+					fmt.Sprintf("subject %s is not in this course", code)))
+				continue
+			}
+			subject.Label = label
+			term.Subjects = append(term.Subjects, subject)
+		}
+		plan.Terms = append(plan.Terms, term)
+	}
+
+	sort.SliceStable(plan.Terms, func(i, j int) bool {
+		return termOrder(plan.Terms[i]) < termOrder(plan.Terms[j])
+	})
+
+	return s.validate(plan, issues, dryRun)
+}
+
+// validate ตรวจรายวิชาซ้ำ หน่วยกิตต่อภาค และลำดับวิชาบังคับก่อน
+// วิชาบังคับก่อนที่อยู่ในแผนแต่อยู่ภาคเดียวกันหรือภาคหลังเป็น error ส่วนที่ไม่อยู่ในแผนเลยเป็น warning
+// เพราะแผนสหกิจศึกษาบางแผนระบุรายวิชาไม่ครบทุกปี
+func (s *roadmapService) validate(plan *models.StudyPlan, issues []models.StudyPlanIssue, dryRun bool) (*models.StudyPlanValidation, error) {
+	prerequisites, err := s.repo.GetPlanPrerequisites(plan.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	summarizeStudyPlan(plan)
+
+	placed := map[string]int{}
+	for _, term := range plan.Terms {
+		for _, subject := range term.Subjects {
+			if subject.SubjectID == nil {
+				continue
+			}
+			code := *subject.SubjectID
+			if _, dup := placed[code]; dup {
+				issues = append(issues, planIssue(models.StudyPlanSeverityError, "duplicate_subject", term.Year, term.Semester, code,
+					fmt.Sprintf("subject %s is already in an earlier term", code)))
+				continue
+			}
+			placed[code] = termOrder(term)
+		}
+
+		limit := plan.MaxSemesterCredits
+		if term.Semester == models.SummerSemester {
+			limit = plan.MaxSummerCredits
+		}
+		if term.Credits > limit {
+			issues = append(issues, planIssue(models.StudyPlanSeverityError, "credit_limit", term.Year, term.Semester, "",
+				fmt.Sprintf("term has %d credits, above the limit of %d", term.Credits, limit)))
+		}
+	}
+
+	// เงื่อนไขของแต่ละวิชาแยกตามกลุ่ม ในกลุ่มเดียวกันผ่านตัวใดตัวหนึ่งก็พอ
+	type groupKey struct {
+		subject string
+		group   int
+		kind    string
+	}
+	options := map[groupKey][]string{}
+	var keys []groupKey
+	for _, p := range prerequisites {
+		k := groupKey{p.SubjectID, p.Group, p.Kind}
+		if _, ok := options[k]; !ok {
+			keys = append(keys, k)
+		}
+		options[k] = append(options[k], p.RequiresID)
+	}
+
+	for _, term := range plan.Terms {
+		order := termOrder(term)
+		for _, subject := range term.Subjects {
+			if subject.SubjectID == nil || placed[*subject.SubjectID] != order {
+				continue
+			}
+			code := *subject.SubjectID
+			for _, k := range keys {
+				if k.subject != code {
+					continue
+				}
+				satisfied, inPlan := false, false
+				for _, option := range options[k] {
+					at, ok := placed[option]
+					if !ok {
+						continue
+					}
+					inPlan = true
+					if at < order || (k.kind == "corequisite" && at == order) {
+						satisfied = true
+						break
+					}
+				}
+				if satisfied {
+					continue
+				}
+				required := strings.Join(options[k], " or ")
+				if inPlan {
+					issues = append(issues, planIssue(models.StudyPlanSeverityError, "prerequisite_order", term.Year, term.Semester, code,
+						fmt.Sprintf("%s requires %s (%s) in an earlier term", code, required, k.kind)))
+				} else {
+					issues = append(issues, planIssue(models.StudyPlanSeverityWarning, "prerequisite_missing", term.Year, term.Semester, code,
+						fmt.Sprintf("%s requires %s (%s), which is not in this plan", code, required, k.kind)))
+				}
+			}
+		}
+	}
+
+	validation := &models.StudyPlanValidation{
+		DryRun: dryRun,
+		Valid:  true,
+		Issues: issues,
+		Plan:   plan,
+	}
+	if validation.Issues == nil {
+		validation.Issues = []models.StudyPlanIssue{}
+	}
+	for _, issue := range validation.Issues {
+		if issue.Severity == models.StudyPlanSeverityError {
+			validation.Valid = false
+			break
+		}
+	}
+	return validation, nil
+}
+
+// summarizeStudyPlan คำนวณหน่วยกิตของแต่ละภาคและทั้งแผน วิชาที่ไม่นับหน่วยกิตไม่นำมารวม
+func summarizeStudyPlan(plan *models.StudyPlan) {
+	plan.TotalCredits = 0
+	for i := range plan.Terms {
+		term := &plan.Terms[i]
+		term.Credits = 0
+		for _, subject := range term.Subjects {
+			if !subject.NonCredit {
+				term.Credits += subject.CreditValue
+			}
+		}
+		plan.TotalCredits += term.Credits
+	}
+}
+
+// termOrder เรียงภาคการศึกษา ภาคฤดูร้อนอยู่หลังภาค 2 ของปีเดียวกัน
+func termOrder(term models.StudyPlanTerm) int {
+	return term.Year*10 + term.Semester
+}
+
+func planIssue(severity, code string, year, semester int, subjectID, message string) models.StudyPlanIssue {
+	return models.StudyPlanIssue{
+		Severity:  severity,
+		Code:      code,
+		Year:      year,
+		Semester:  semester,
+		SubjectID: subjectID,
+		Message:   message,
+	}
+}
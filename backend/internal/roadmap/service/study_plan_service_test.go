@@ -0,0 +1,168 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"cpsu/internal/roadmap/models"
+	"cpsu/internal/roadmap/repository"
+)
+
+// planRepo คืนเงื่อนไขวิชาบังคับก่อนชุดเดียว เมธอดอื่นของ RoadmapRepository ไม่ได้ใช้ในเทสต์นี้
+type planRepo struct {
+	repository.RoadmapRepository
+
+	prerequisites []models.PlanPrerequisite
+}
+
+func (r planRepo) GetPlanPrerequisites(courseID string) ([]models.PlanPrerequisite, error) {
+	return r.prerequisites, nil
+}
+
+func planSubject(code string, credits int) models.StudyPlanSubject {
+	return models.StudyPlanSubject{SubjectID: &code, Credits: fmt.Sprint(credits), CreditValue: credits}
+}
+
+func planTerm(year, semester int, subjects ...models.StudyPlanSubject) models.StudyPlanTerm {
+	return models.StudyPlanTerm{Year: year, Semester: semester, Subjects: subjects}
+}
+
+func TestValidateStudyPlan(t *testing.T) {
+	requires := func(subject, requires string, group int) models.PlanPrerequisite {
+		return models.PlanPrerequisite{SubjectID: subject, RequiresID: requires, Kind: "prerequisite", Group: group}
+	}
+	corequisite := func(subject, requires string) models.PlanPrerequisite {
+		return models.PlanPrerequisite{SubjectID: subject, RequiresID: requires, Kind: "corequisite", Group: 1}
+	}
+	nonCredit := planSubject("N", 3)
+	nonCredit.NonCredit = true
+
+	// ปัญหาย่อเป็น "severity code ปี/ภาค วิชา"
+	tests := []struct {
+		name          string
+		terms         []models.StudyPlanTerm
+		prerequisites []models.PlanPrerequisite
+		want          []string
+		wantValid     bool
+	}{
+		{
+			"prerequisite in an earlier term",
+			[]models.StudyPlanTerm{planTerm(1, 1, planSubject("A", 3)), planTerm(1, 2, planSubject("B", 3))},
+			[]models.PlanPrerequisite{requires("B", "A", 1)},
+			nil, true,
+		},
+		{
+			"prerequisite in the same term",
+			[]models.StudyPlanTerm{planTerm(1, 1, planSubject("A", 3), planSubject("B", 3))},
+			[]models.PlanPrerequisite{requires("B", "A", 1)},
+			[]string{"error prerequisite_order 1/1 B"}, false,
+		},
+		{
+			"prerequisite in a later term",
+			[]models.StudyPlanTerm{planTerm(1, 1, planSubject("B", 3)), planTerm(2, 1, planSubject("A", 3))},
+			[]models.PlanPrerequisite{requires("B", "A", 1)},
+			[]string{"error prerequisite_order 1/1 B"}, false,
+		},
+		{
+			"corequisite in the same term",
+			[]models.StudyPlanTerm{planTerm(1, 1, planSubject("A", 3), planSubject("B", 1))},
+			[]models.PlanPrerequisite{corequisite("B", "A")},
+			nil, true,
+		},
+		{
+			"corequisite in a later term",
+			[]models.StudyPlanTerm{planTerm(1, 1, planSubject("B", 1)), planTerm(1, 2, planSubject("A", 3))},
+			[]models.PlanPrerequisite{corequisite("B", "A")},
+			[]string{"error prerequisite_order 1/1 B"}, false,
+		},
+		{
+			"one alternative is enough",
+			[]models.StudyPlanTerm{planTerm(1, 1, planSubject("C", 3)), planTerm(1, 2, planSubject("B", 3))},
+			[]models.PlanPrerequisite{requires("B", "A", 1), requires("B", "C", 1)},
+			nil, true,
+		},
+		{
+			"every group is needed",
+			[]models.StudyPlanTerm{planTerm(1, 1, planSubject("A", 3)), planTerm(1, 2, planSubject("B", 3), planSubject("C", 3))},
+			[]models.PlanPrerequisite{requires("B", "A", 1), requires("B", "C", 2)},
+			[]string{"error prerequisite_order 1/2 B"}, false,
+		},
+		{
+			"prerequisite not in the plan",
+			[]models.StudyPlanTerm{planTerm(1, 1, planSubject("B", 3))},
+			[]models.PlanPrerequisite{requires("B", "A", 1)},
+			[]string{"warning prerequisite_missing 1/1 B"}, true,
+		},
+		{
+			"summer comes after the second semester",
+			[]models.StudyPlanTerm{planTerm(1, 2, planSubject("B", 3)), planTerm(1, 3, planSubject("A", 3)), planTerm(2, 1, planSubject("C", 3))},
+			[]models.PlanPrerequisite{requires("B", "A", 1), requires("C", "A", 1)},
+			[]string{"error prerequisite_order 1/2 B"}, false,
+		},
+		{
+			"duplicate subject",
+			[]models.StudyPlanTerm{planTerm(1, 1, planSubject("A", 3)), planTerm(2, 1, planSubject("A", 3))},
+			nil,
+			[]string{"error duplicate_subject 2/1 A"}, false,
+		},
+		{
+			"duplicate is not checked against itself",
+			[]models.StudyPlanTerm{planTerm(1, 1, planSubject("A", 3)), planTerm(1, 2, planSubject("B", 3), planSubject("A", 3))},
+			[]models.PlanPrerequisite{requires("B", "A", 1)},
+			[]string{"error duplicate_subject 1/2 A"}, false,
+		},
+		{
+			"semester limit",
+			[]models.StudyPlanTerm{planTerm(1, 1, planSubject("A", 12), planSubject("B", 12))},
+			nil,
+			[]string{"error credit_limit 1/1 "}, false,
+		},
+		{
+			"summer at the limit",
+			[]models.StudyPlanTerm{planTerm(1, 3, planSubject("A", 6), planSubject("B", 3))},
+			nil,
+			nil, true,
+		},
+		{
+			"summer above the limit",
+			[]models.StudyPlanTerm{planTerm(1, 3, planSubject("A", 6), planSubject("B", 6))},
+			nil,
+			[]string{"error credit_limit 1/3 "}, false,
+		},
+		{
+			"non-credit subject does not count",
+			[]models.StudyPlanTerm{planTerm(1, 3, planSubject("A", 9), nonCredit)},
+			nil,
+			nil, true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &roadmapService{repo: planRepo{prerequisites: tt.prerequisites}}
+			plan := &models.StudyPlan{
+				CourseID:           "BSCS65",
+				MaxSemesterCredits: models.DefaultMaxSemesterCredits,
+				MaxSummerCredits:   models.DefaultMaxSummerCredits,
+				Terms:              tt.terms,
+			}
+
+			validation, err := s.validate(plan, nil, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, issue := range validation.Issues {
+				got = append(got, fmt.Sprintf("%s %s %d/%d %s", issue.Severity, issue.Code, issue.Year, issue.Semester, issue.SubjectID))
+			}
+			if !reflect.DeepEqual(got, tt.want) || validation.Valid != tt.wantValid {
+				t.Errorf("validate = %v valid %v, want %v valid %v", got, validation.Valid, tt.want, tt.wantValid)
+			}
+			if validation.Issues == nil {
+				t.Error("issues = nil, want an empty slice")
+			}
+		})
+	}
+}
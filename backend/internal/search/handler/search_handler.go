@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"cpsu/internal/pagination"
+	"cpsu/internal/search/models"
+	"cpsu/internal/search/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	searchService service.SearchService
+}
+
+func NewSearchHandler(searchService service.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: searchService}
+}
+
+func (h *SearchHandler) Search(c *gin.Context) {
+	var param models.SearchQueryParam
+	if err := c.BindQuery(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameter"})
+		return
+	}
+
+	hits, err := h.searchService.Search(param)
+	if err != nil {
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+		} else if errors.Is(err, service.ErrInvalidType) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":         err.Error(),
+				"allowed_types": models.Types,
+			})
+		} else if errors.Is(err, service.ErrEmptyQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, hits)
+}
@@ -0,0 +1,46 @@
+package models
+
+import "cpsu/internal/pagination"
+
+const (
+	TypeNews      = "news"
+	TypeSubject   = "subject"
+	TypeCourse    = "course"
+	TypePersonnel = "personnel"
+	TypeResearch  = "research"
+	TypeDocument  = "document"
+)
+
+// Types คือประเภทข้อมูลที่ค้นได้ ตามลำดับที่ใช้เมื่อคะแนนเท่ากัน
+var Types = []string{TypeNews, TypeCourse, TypeSubject, TypePersonnel, TypeResearch, TypeDocument}
+
+func IsValidType(t string) bool {
+	for _, v := range Types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+type SearchQueryParam struct {
+	pagination.Params
+	Q    string `form:"q"`
+	Type string `form:"type"`
+}
+
+// SearchCandidate คือแถวที่ตรงกับคำค้นจากฐานข้อมูล ก่อนคิดคะแนน
+type SearchCandidate struct {
+	Type  string
+	ID    string
+	Title string
+	Body  []string
+}
+
+type SearchHit struct {
+	Type    string  `json:"type"`
+	ID      string  `json:"id"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	coursemodels "cpsu/internal/course/models"
+	newsrepo "cpsu/internal/news/repository"
+	personnelrepo "cpsu/internal/personnel/repository"
+	"cpsu/internal/search/models"
+	"cpsu/internal/textsearch"
+
+	"github.com/lib/pq"
+)
+
+// จำนวนแถวสูงสุดที่ดึงจากแต่ละแหล่งมาจัดอันดับ
+const candidateLimit = 200
+
+type SearchRepository interface {
+	Search(types []string, terms []string) ([]models.SearchCandidate, error)
+}
+
+type searchRepository struct {
+	db *sql.DB
+}
+
+func NewSearchRepository(db *sql.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// source คือข้อมูลหนึ่งประเภทที่ค้นได้ แสดงเฉพาะข้อมูลที่เปิดเผยต่อสาธารณะ
+type source struct {
+	id         string
+	title      string
+	body       []string
+	from       string
+	conditions []string
+	match      []string
+	orderBy    string
+	// publicCourses จำกัดเฉพาะหลักสูตรที่เผยแพร่แล้ว ต้อง join courses เป็น c
+	publicCourses bool
+}
+
+var sources = map[string]source{
+	models.TypeNews: {
+		id:         "n.news_id::text",
+		title:      "n.title",
+		body:       []string{"n.content", "n.title_en", "n.content_en"},
+		from:       "FROM news n",
+		conditions: []string{newsrepo.VisibleNewsCondition},
+		match:      []string{"n.title", "n.content", "n.title_en", "n.content_en"},
+		orderBy:    "n.publish_at DESC, n.news_id DESC",
+	},
+	models.TypeCourse: {
+		id:            "c.course_id",
+		title:         "c.thai_course",
+		body:          []string{"c.eng_course", "c.thai_degree", "c.eng_degree", "c.philosophy", "c.objective"},
+		from:          "FROM courses c",
+		publicCourses: true,
+		match:         []string{"c.course_id", "c.thai_course", "c.eng_course", "c.thai_degree", "c.eng_degree", "c.philosophy", "c.objective"},
+		orderBy:       "c.course_id",
+	},
+	models.TypeSubject: {
+		id:    "s.id::text",
+		title: "s.subject_id || ' ' || s.thai_subject",
+		body:  []string{"s.eng_subject", "d.description_thai", "d.description_eng"},
+		from: `FROM subjects s
+			JOIN courses c ON s.course_id = c.course_id
+			LEFT JOIN description d ON s.description_id = d.description_id`,
+		publicCourses: true,
+		match:         []string{"s.subject_id", "s.thai_subject", "s.eng_subject", "d.description_thai", "d.description_eng"},
+		orderBy:       "s.id",
+	},
+	models.TypePersonnel: {
+		id:    "p.personnel_id::text",
+		title: "p.thai_name",
+		body:  []string{"p.eng_name", "p.related_fields", "p.education"},
+		// ใช้สถานะปัจจุบันชุดเดียวกับรายการบุคลากรสาธารณะ
+		from:       "FROM personnels p" + personnelrepo.CurrentStatusJoin,
+		conditions: []string{personnelrepo.ActiveCondition},
+		match:      []string{"p.thai_name", "p.eng_name", "p.related_fields", "p.education"},
+		orderBy:    "p.personnel_id",
+	},
+	models.TypeResearch: {
+		id:      "r.research_id::text",
+		title:   "r.title",
+		body:    []string{"r.journal"},
+		from:    "FROM research r",
+		match:   []string{"r.title", "r.journal", "r.doi"},
+		orderBy: "r.year DESC, r.research_id DESC",
+	},
+	models.TypeDocument: {
+		id:         "d.document_id::text",
+		title:      "d.title",
+		body:       []string{"d.description"},
+		from:       "FROM document d",
+		conditions: []string{"d.visibility = 'public'"},
+		match:      []string{"d.title", "d.description"},
+		orderBy:    "d.document_id DESC",
+	},
+}
+
+func (r *searchRepository) Search(types []string, terms []string) ([]models.SearchCandidate, error) {
+	candidates := []models.SearchCandidate{}
+
+	for _, t := range types {
+		src, ok := sources[t]
+		if !ok {
+			continue
+		}
+
+		found, err := r.searchSource(t, src, terms)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, found...)
+	}
+
+	return candidates, nil
+}
+
+func (r *searchRepository) searchSource(t string, src source, terms []string) ([]models.SearchCandidate, error) {
+	condition, args, next := textsearch.Condition(src.match, terms, 1)
+	conditions := append(append([]string{}, src.conditions...), condition)
+	if src.publicCourses {
+		conditions = append(conditions, "c.status = ANY($"+strconv.Itoa(next)+")")
+		args = append(args, pq.Array(coursemodels.PublicStatuses))
+	}
+
+	body := make([]string, len(src.body))
+	for i, column := range src.body {
+		body[i] = "COALESCE(" + column + ", '')"
+	}
+
+	query := "SELECT " + src.id + ", " + src.title + ", ARRAY[" + strings.Join(body, ", ") + "] " +
+		src.from +
+		" WHERE " + strings.Join(conditions, " AND ") +
+		" ORDER BY " + src.orderBy +
+		" LIMIT " + strconv.Itoa(candidateLimit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []models.SearchCandidate
+	for rows.Next() {
+		c := models.SearchCandidate{Type: t}
+		var body pq.StringArray
+		if err := rows.Scan(&c.ID, &c.Title, &body); err != nil {
+			return nil, err
+		}
+		c.Body = body
+		candidates = append(candidates, c)
+	}
+
+	return candidates, rows.Err()
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"cpsu/internal/pagination"
+	"cpsu/internal/search/models"
+	searchrepo "cpsu/internal/search/repository"
+	"cpsu/internal/textsearch"
+)
+
+var (
+	ErrEmptyQuery  = errors.New("search query is required")
+	ErrInvalidType = errors.New("invalid search type")
+)
+
+const (
+	snippetWidth = 160
+	titleWeight  = 3
+	bodyWeight   = 1
+)
+
+type SearchService interface {
+	Search(param models.SearchQueryParam) (*pagination.Page[models.SearchHit], error)
+}
+
+type searchService struct {
+	repo searchrepo.SearchRepository
+}
+
+func NewSearchService(repo searchrepo.SearchRepository) SearchService {
+	return &searchService{repo: repo}
+}
+
+func (s *searchService) Search(param models.SearchQueryParam) (*pagination.Page[models.SearchHit], error) {
+	terms := textsearch.Terms(param.Q)
+	if len(terms) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	types, err := parseTypes(param.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.repo.Search(types, terms)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]models.SearchHit, 0, len(candidates))
+	for _, c := range candidates {
+		hits = append(hits, rank(c, terms))
+	}
+
+	order := make(map[string]int, len(models.Types))
+	for i, t := range models.Types {
+		order[t] = i
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return order[hits[i].Type] < order[hits[j].Type]
+	})
+
+	return pagination.Slice(hits, param.Params)
+}
+
+// rank คิดคะแนนโดยให้ชื่อเรื่องมีน้ำหนักมากกว่าเนื้อหา
+// snippet มาจากเนื้อหาส่วนแรกที่พบคำค้น ถ้าพบเฉพาะในชื่อเรื่องจะใช้ชื่อเรื่องแทน
+func rank(c models.SearchCandidate, terms []string) models.SearchHit {
+	fields := []textsearch.Field{{Text: c.Title, Weight: titleWeight}}
+	snippet := ""
+	for _, body := range c.Body {
+		fields = append(fields, textsearch.Field{Text: body, Weight: bodyWeight})
+		if snippet == "" && textsearch.Contains(body, terms) {
+			snippet = body
+		}
+	}
+	if snippet == "" {
+		snippet = c.Title
+	}
+
+	return models.SearchHit{
+		Type:    c.Type,
+		ID:      c.ID,
+		Title:   c.Title,
+		Snippet: textsearch.Highlight(snippet, terms, snippetWidth),
+		Score:   textsearch.Score(fields, terms),
+	}
+}
+
+func parseTypes(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return models.Types, nil
+	}
+
+	var types []string
+	seen := map[string]bool{}
+	for _, t := range strings.Split(spec, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		if !models.IsValidType(t) {
+			return nil, ErrInvalidType
+		}
+		seen[t] = true
+		types = append(types, t)
+	}
+	return types, nil
+}
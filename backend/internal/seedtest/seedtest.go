@@ -0,0 +1,76 @@
+// Package seedtest อ่านไฟล์ csv ที่ใช้ตอนสร้างฐานข้อมูล ให้ test ของแต่ละ package ใช้เป็นข้อมูลจริงชุดเดียวกัน
+package seedtest
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// SubjectDir คืนโฟลเดอร์ database/docker/csv/subject โดยไม่ขึ้นกับ package ที่เรียก
+func SubjectDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "..", "database", "docker", "csv", "subject")
+}
+
+// Files คืนไฟล์ใน SubjectDir ที่ตรงกับ pattern เช่น "*_subjects.csv"
+func Files(t testing.TB, pattern string) []string {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(SubjectDir(), pattern))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no seed files %s in %s", pattern, SubjectDir())
+	}
+	return paths
+}
+
+// SubjectRows คืนทุกแถวของไฟล์ *_subjects.csv เป็น map ตามหัวตาราง พร้อมชื่อไฟล์ใน "file"
+func SubjectRows(t testing.TB) []map[string]string {
+	t.Helper()
+
+	var rows []map[string]string
+	for _, path := range Files(t, "*_subjects.csv") {
+		rows = append(rows, readRows(t, path)...)
+	}
+	return rows
+}
+
+// CourseRows คืนทุกแถวของ database/docker/csv/course/courses.csv เป็น map ตามหัวตาราง
+func CourseRows(t testing.TB) []map[string]string {
+	t.Helper()
+	return readRows(t, filepath.Join(SubjectDir(), "..", "course", "courses.csv"))
+}
+
+func readRows(t testing.TB, path string) []map[string]string {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+
+	header := records[0]
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	var rows []map[string]string
+	for _, record := range records[1:] {
+		row := map[string]string{"file": filepath.Base(path)}
+		for i, column := range header {
+			if i < len(record) {
+				row[column] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStorage เก็บไฟล์ลงดิสก์ ใช้สำหรับพัฒนาแบบ offline โดยไม่ต้องมี MinIO
+// ไฟล์จะถูกเสิร์ฟผ่าน publicBase (ดู STORAGE_LOCAL_PUBLIC_URL)
+type LocalStorage struct {
+	root       string
+	publicBase string
+	signKey    []byte
+}
+
+func NewLocalStorage(root string, publicBaseURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &LocalStorage{
+		root:       root,
+		publicBase: strings.TrimRight(publicBaseURL, "/"),
+	}, nil
+}
+
+// NewSignedLocalStorage สร้าง local storage ที่ PresignedGet คืน URL พร้อมลายเซ็นและเวลาหมดอายุ
+// ถ้าไม่ได้กำหนด signKey จะสุ่มขึ้นมาใหม่ ลิงก์เดิมจะใช้ไม่ได้หลัง restart
+func NewSignedLocalStorage(root string, publicBaseURL string, signKey []byte) (*LocalStorage, error) {
+	s, err := NewLocalStorage(root, publicBaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(signKey) == 0 {
+		signKey = make([]byte, 32)
+		if _, err := rand.Read(signKey); err != nil {
+			return nil, err
+		}
+	}
+	s.signKey = signKey
+
+	return s, nil
+}
+
+func (s *LocalStorage) Root() string {
+	return s.root
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, r)
+	return err
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// PresignedGet คืน URL ปกติถ้าไม่ได้สร้างด้วย NewSignedLocalStorage
+func (s *LocalStorage) PresignedGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if _, err := s.Stat(ctx, key); err != nil {
+		return "", err
+	}
+	if s.signKey == nil {
+		return s.URL(key), nil
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(key, expires))
+
+	return s.URL(key) + "?" + query.Encode(), nil
+}
+
+// VerifySignature ตรวจลายเซ็นและเวลาหมดอายุของ URL ที่ได้จาก PresignedGet
+func (s *LocalStorage) VerifySignature(key string, expires string, signature string) bool {
+	if s.signKey == nil {
+		return false
+	}
+
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(s.sign(key, expires)))
+}
+
+func (s *LocalStorage) sign(key string, expires string) string {
+	mac := hmac.New(sha256.New, s.signKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &ObjectInfo{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  mime.TypeByExtension(filepath.Ext(key)),
+		LastModified: info.ModTime(),
+	}, nil
+}
+
+func (s *LocalStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, ObjectInfo{
+			Key:          key,
+			Size:         info.Size(),
+			ContentType:  mime.TypeByExtension(filepath.Ext(key)),
+			LastModified: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return s.publicBase + "/" + key
+}
+
+func (s *LocalStorage) Key(url string) (string, bool) {
+	return keyFromURL(s.URL(""), url)
+}
+
+// path แปลง key เป็น path บนดิสก์ และกันไม่ให้ key หลุดออกนอก root
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" {
+		return "", errors.New("invalid object key")
+	}
+	return filepath.Join(s.root, filepath.FromSlash(clean)), nil
+}
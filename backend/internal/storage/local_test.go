@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestStorage(t *testing.T, base string) *LocalStorage {
+	t.Helper()
+	s, err := NewLocalStorage(t.TempDir(), base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func newTestSignedStorage(t *testing.T, base string) *LocalStorage {
+	t.Helper()
+	s, err := NewSignedLocalStorage(t.TempDir(), base, []byte("test-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func put(t *testing.T, s Storage, key string, data string) {
+	t.Helper()
+	if err := s.Put(context.Background(), key, strings.NewReader(data), int64(len(data)), "text/plain"); err != nil {
+		t.Fatalf("Put(%q) error: %v", key, err)
+	}
+}
+
+func TestLocalStoragePath(t *testing.T) {
+	s := newTestStorage(t, "http://files.test")
+
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"news/a/original.jpg", "news/a/original.jpg", false},
+		{"/news/a.jpg", "news/a.jpg", false},
+		{"news/../docs/a.pdf", "docs/a.pdf", false},
+		{"../a.jpg", "a.jpg", false},
+		{"../../etc/passwd", "etc/passwd", false},
+		{"news/../../../etc/passwd", "etc/passwd", false},
+		{"", "", true},
+		{"/", "", true},
+		{"..", "", true},
+		{"../..", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := s.path(tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("path(%q) = %q, want error", tt.key, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("path(%q) error: %v", tt.key, err)
+			}
+			if want := filepath.Join(s.Root(), filepath.FromSlash(tt.want)); got != want {
+				t.Errorf("path(%q) = %q, want %q", tt.key, got, want)
+			}
+		})
+	}
+}
+
+func TestLocalStoragePutStaysInRoot(t *testing.T) {
+	parent := t.TempDir()
+	s, err := NewLocalStorage(filepath.Join(parent, "root"), "http://files.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	put(t, s, "../escaped.txt", "x")
+
+	if _, err := os.Stat(filepath.Join(parent, "escaped.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("file written outside root: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(parent, "root", "escaped.txt")); err != nil {
+		t.Errorf("file not written inside root: %v", err)
+	}
+}
+
+func TestLocalStorageKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   string
+		url    string
+		want   string
+		wantOK bool
+	}{
+		{"object", "http://files.test", "http://files.test/news/a/original.jpg", "news/a/original.jpg", true},
+		{"base with trailing slash", "http://files.test/", "http://files.test/news/a.jpg", "news/a.jpg", true},
+		{"base with path", "http://files.test/uploads", "http://files.test/uploads/docs/a.pdf", "docs/a.pdf", true},
+		{"base only", "http://files.test", "http://files.test/", "", false},
+		{"other host", "http://files.test", "http://other.test/news/a.jpg", "", false},
+		{"other path", "http://files.test/uploads", "http://files.test/news/a.jpg", "", false},
+		{"prefix without slash", "http://files.test", "http://files.testing/a.jpg", "", false},
+		{"empty", "http://files.test", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStorage(t, tt.base)
+			got, ok := s.Key(tt.url)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("Key(%q) = %q, %v; want %q, %v", tt.url, got, ok, tt.want, tt.wantOK)
+			}
+			if ok && s.URL(got) != tt.url {
+				t.Errorf("URL(Key(%q)) = %q", tt.url, s.URL(got))
+			}
+		})
+	}
+}
+
+func TestLocalStorageSignature(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSignedStorage(t, "http://private.test")
+	put(t, s, "docs/a.pdf", "pdf")
+
+	link, err := s.PresignedGet(ctx, "docs/a.pdf", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base := u.Scheme + "://" + u.Host + u.Path; base != "http://private.test/docs/a.pdf" {
+		t.Fatalf("PresignedGet = %s, want http://private.test/docs/a.pdf?...", link)
+	}
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+
+	past := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	other := newTestSignedStorage(t, "http://private.test")
+	other.signKey = []byte("other-key")
+
+	tests := []struct {
+		name      string
+		store     *LocalStorage
+		key       string
+		expires   string
+		signature string
+		want      bool
+	}{
+		{"valid", s, "docs/a.pdf", expires, signature, true},
+		{"expired", s, "docs/a.pdf", past, s.sign("docs/a.pdf", past), false},
+		{"extended expiry", s, "docs/a.pdf", strconv.FormatInt(time.Now().Add(48*time.Hour).Unix(), 10), signature, false},
+		{"another object", s, "docs/b.pdf", expires, signature, false},
+		{"modified signature", s, "docs/a.pdf", expires, strings.Repeat("0", len(signature)), false},
+		{"truncated signature", s, "docs/a.pdf", expires, signature[:len(signature)-1], false},
+		{"invalid expiry", s, "docs/a.pdf", "soon", s.sign("docs/a.pdf", "soon"), false},
+		{"empty", s, "docs/a.pdf", "", "", false},
+		{"other signing key", other, "docs/a.pdf", expires, signature, false},
+		{"unsigned storage", newTestStorage(t, "http://private.test"), "docs/a.pdf", expires, signature, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.store.VerifySignature(tt.key, tt.expires, tt.signature); got != tt.want {
+				t.Errorf("VerifySignature(%q, %q, %q) = %v, want %v", tt.key, tt.expires, tt.signature, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalStorageSign(t *testing.T) {
+	s := newTestSignedStorage(t, "http://private.test")
+
+	sig := s.sign("docs/a.pdf", "100")
+	if len(sig) != 64 || s.sign("docs/a.pdf", "100") != sig {
+		t.Errorf("sign = %q, want stable 64 hex chars", sig)
+	}
+	// key กับ expires คั่นด้วยขึ้นบรรทัดใหม่ จึงย้ายตัวเลขข้ามกันแล้วได้ลายเซ็นเดิมไม่ได้
+	for _, other := range []string{s.sign("docs/a.pdf1", "00"), s.sign("docs/a.pdf", "1000"), s.sign("docs/b.pdf", "100")} {
+		if other == sig {
+			t.Errorf("sign collides: %q", other)
+		}
+	}
+}
+
+func TestLocalStoragePresignedGetUnsigned(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t, "http://files.test")
+	put(t, s, "news/a.jpg", "jpg")
+
+	if got, err := s.PresignedGet(ctx, "news/a.jpg", time.Hour); err != nil || got != "http://files.test/news/a.jpg" {
+		t.Errorf("PresignedGet = %q, %v; want plain URL", got, err)
+	}
+	if _, err := s.PresignedGet(ctx, "news/missing.jpg", time.Hour); !errors.Is(err, ErrNotFound) {
+		t.Errorf("PresignedGet missing error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		url     string
+		want    string
+		wantErr error
+	}{
+		{"object", "http://files.test/docs/a.pdf", "http://private.test/docs/a.pdf", nil},
+		{"external link", "https://example.com/a.pdf", "https://example.com/a.pdf", nil},
+		{"missing object", "http://files.test/docs/missing.pdf", "", ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			public := newTestStorage(t, "http://files.test")
+			private := newTestSignedStorage(t, "http://private.test")
+			put(t, public, "docs/a.pdf", "pdf data")
+
+			got, err := Copy(ctx, public, private, tt.url)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Fatalf("Copy(%q) = %q, %v; want %q, %v", tt.url, got, err, tt.want, tt.wantErr)
+			}
+
+			objects, err := private.List(ctx, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != nil || got == tt.url {
+				if len(objects) != 0 {
+					t.Errorf("private objects = %v, want none", objects)
+				}
+				return
+			}
+
+			key, _ := private.Key(got)
+			r, err := private.Get(ctx, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			data, _ := io.ReadAll(r)
+			if string(data) != "pdf data" {
+				t.Errorf("copied data = %q, want %q", data, "pdf data")
+			}
+			if _, err := public.Stat(ctx, key); err != nil {
+				t.Errorf("source removed by Copy: %v", err)
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t, "http://files.test")
+	put(t, s, "news/a.jpg", "jpg")
+
+	for _, u := range []string{"http://files.test/news/a.jpg", "http://files.test/news/a.jpg", "https://example.com/a.jpg"} {
+		if err := Remove(ctx, s, u); err != nil {
+			t.Errorf("Remove(%q) error: %v", u, err)
+		}
+	}
+	if _, err := s.Stat(ctx, "news/a.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat after Remove = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type MinioStorage struct {
+	client        *minio.Client
+	presignClient *minio.Client
+	bucket        string
+	publicBase    string
+}
+
+func NewMinioStorage(endpoint string, accessKey string, secretKey string, bucket string, useSSL bool, publicBaseURL string) (*MinioStorage, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: useSSL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// presigned URL ต้องลงลายเซ็นด้วย host ที่ผู้ใช้เข้าถึงได้ (MINIO_PUBLIC_BASE_URL)
+	// ไม่ใช่ endpoint ภายใน docker กำหนด region ไว้เพื่อไม่ให้ client ต้องเรียกถาม server
+	presignClient := client
+	if public, err := url.Parse(publicBaseURL); err == nil && public.Host != "" && public.Host != endpoint {
+		presignClient, err = minio.New(public.Host, &minio.Options{
+			Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+			Secure: public.Scheme == "https",
+			Region: "us-east-1",
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &MinioStorage{
+		client:        client,
+		presignClient: presignClient,
+		bucket:        bucket,
+		publicBase:    publicBaseURL,
+	}, nil
+}
+
+func (s *MinioStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+func (s *MinioStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+}
+
+func (s *MinioStorage) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *MinioStorage) PresignedGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	u, err := s.presignClient.PresignedGetObject(ctx, s.bucket, key, expiry, url.Values{})
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func (s *MinioStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &ObjectInfo{
+		Key:          info.Key,
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		LastModified: info.LastModified,
+	}, nil
+}
+
+func (s *MinioStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+		objects = append(objects, ObjectInfo{
+			Key:          obj.Key,
+			Size:         obj.Size,
+			ContentType:  obj.ContentType,
+			LastModified: obj.LastModified,
+		})
+	}
+	return objects, nil
+}
+
+func (s *MinioStorage) URL(key string) string {
+	return fmt.Sprintf("%s/%s/%s", s.publicBase, s.bucket, key)
+}
+
+func (s *MinioStorage) Key(url string) (string, bool) {
+	return keyFromURL(s.URL(""), url)
+}
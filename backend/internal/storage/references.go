@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Folders คือโฟลเดอร์ใน storage ที่ระบบจัดการเอง คำสั่ง reconcile จะตรวจเฉพาะโฟลเดอร์เหล่านี้
+var Folders = []string{"news", "personnel", "admission", "roadmap", "document"}
+
+// PrivateFolders คือโฟลเดอร์ใน private storage (ดู NewPrivate) ที่คำสั่ง reconcile ตรวจ
+var PrivateFolders = []string{"document"}
+
+type referenceColumn struct {
+	table  string
+	column string
+	where  string
+}
+
+// referenceColumns คือคอลัมน์ที่เก็บ URL ของไฟล์ใน storage (คอลัมน์แบบ array ใช้ unnest ได้)
+// ถ้าเพิ่มตารางที่อ้างอิงไฟล์ใหม่ต้องเพิ่มที่นี่ด้วย ไม่เช่นนั้นไฟล์จะถูกมองว่าเป็น orphan
+var referenceColumns = []referenceColumn{
+	{"news", "cover_image", ""},
+	{"news_images", "file_image", ""},
+	{"personnels", "file_image", ""},
+	{"admission", "file_image", ""},
+	{"roadmap", "roadmap_url", ""},
+	{"document", "file", "visibility = 'public'"},
+	{"news_revisions", "cover_image", ""},
+	{"news_revisions", "unnest(images)", ""},
+}
+
+// privateReferenceColumns คือคอลัมน์ที่เก็บ URL ของไฟล์ใน private storage
+var privateReferenceColumns = []referenceColumn{
+	{"document", "file", "visibility = 'private'"},
+}
+
+// ReferencedURLs คืน URL ของไฟล์ทั้งหมดที่ยังถูกอ้างอิงอยู่ในฐานข้อมูล
+func ReferencedURLs(db *sql.DB) ([]string, error) {
+	return referencedURLs(db, referenceColumns)
+}
+
+// ReferencedPrivateURLs คืน URL ของไฟล์ใน private storage ที่ยังถูกอ้างอิงอยู่ในฐานข้อมูล
+func ReferencedPrivateURLs(db *sql.DB) ([]string, error) {
+	return referencedURLs(db, privateReferenceColumns)
+}
+
+func referencedURLs(db *sql.DB, columns []referenceColumn) ([]string, error) {
+	var urls []string
+	for _, ref := range columns {
+		from := ref.table
+		if ref.where != "" {
+			from += " WHERE " + ref.where
+		}
+		query := fmt.Sprintf(
+			"SELECT url FROM (SELECT %s AS url FROM %s) refs WHERE url IS NOT NULL AND url <> ''",
+			ref.column, from,
+		)
+		rows, err := db.Query(query)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var url string
+			if err := rows.Scan(&url); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			urls = append(urls, url)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		rows.Close()
+	}
+	return urls, nil
+}
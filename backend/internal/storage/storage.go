@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"cpsu/internal/config"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Storage คือที่เก็บไฟล์ของระบบ อ้างอิง object ด้วย key เช่น news/<uuid>/original.jpg
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	PresignedGet(ctx context.Context, key string, expiry time.Duration) (string, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	// URL คืน URL สาธารณะของ object
+	URL(key string) string
+	// Key แปลง URL สาธารณะกลับเป็น key คืนค่า false ถ้า URL ไม่ได้อยู่ใน storage นี้
+	Key(url string) (string, bool)
+}
+
+// Remove ลบ object ที่อ้างอิงด้วย URL สาธารณะ URL ที่อยู่นอก storage (เช่น ลิงก์ภายนอก) จะถูกข้ามไป
+func Remove(ctx context.Context, store Storage, url string) error {
+	key, ok := store.Key(url)
+	if !ok {
+		return nil
+	}
+	return store.Delete(ctx, key)
+}
+
+// Copy คัดลอก object จาก storage หนึ่งไปอีกที่หนึ่งโดยใช้ key เดิม แล้วคืน URL ใหม่
+func Copy(ctx context.Context, from Storage, to Storage, url string) (string, error) {
+	key, ok := from.Key(url)
+	if !ok {
+		return url, nil
+	}
+
+	info, err := from.Stat(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	r, err := from.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	if err := to.Put(ctx, key, r, info.Size, info.ContentType); err != nil {
+		return "", err
+	}
+	return to.URL(key), nil
+}
+
+func keyFromURL(base string, url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, base)
+	if !ok || key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+// New สร้าง storage ตาม STORAGE_DRIVER (minio หรือ local)
+func New(cfg config.Config) (Storage, error) {
+	if cfg.StorageDriver == "local" {
+		return NewLocalStorage(cfg.StorageLocalDir, cfg.StorageLocalPublicURL)
+	}
+	return NewMinioStorage(cfg.MinioEndpoint, cfg.MinioAccessKey, cfg.MinioSecretKey, cfg.MinioBucket, cfg.MinioUseSSL, cfg.MinioPublicBaseURL)
+}
+
+// NewPrivate สร้าง storage สำหรับไฟล์ที่ไม่เปิดสาธารณะ เข้าถึงได้ผ่าน PresignedGet เท่านั้น
+func NewPrivate(cfg config.Config) (Storage, error) {
+	if cfg.StorageDriver == "local" {
+		return NewSignedLocalStorage(cfg.StorageLocalPrivateDir, cfg.StorageLocalPrivateURL, []byte(cfg.StorageLocalSigningKey))
+	}
+	return NewMinioStorage(cfg.MinioEndpoint, cfg.MinioAccessKey, cfg.MinioSecretKey, cfg.MinioPrivateBucket, cfg.MinioUseSSL, cfg.MinioPublicBaseURL)
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"mime/multipart"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// UploadFile เก็บไฟล์ที่อัปโหลดไว้ใต้ <folder>/<uuid><ext> แล้วคืน URL สาธารณะ
+func UploadFile(ctx context.Context, store Storage, folder string, fileHeader *multipart.FileHeader) (string, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	key := fmt.Sprintf(
+		"%s/%s%s",
+		folder,
+		uuid.New().String(),
+		filepath.Ext(fileHeader.Filename),
+	)
+
+	err = store.Put(ctx, key, file, fileHeader.Size, fileHeader.Header.Get("Content-Type"))
+	if err != nil {
+		return "", err
+	}
+
+	return store.URL(key), nil
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"cpsu/internal/subject/models"
+	"cpsu/internal/subject/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GraduationAudit คำนวณเงื่อนไขที่เหลือจากวิชาที่ส่งมา ไม่ต้องเข้าสู่ระบบและไม่บันทึกข้อมูล
+func (h *SubjectHandler) GraduationAudit(c *gin.Context) {
+	var req models.GraduationAuditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	audit, err := h.subjectService.GetGraduationAudit(c.Param("id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
+		case errors.Is(err, service.ErrInvalidGrade), errors.Is(err, service.ErrUnknownPlan):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, audit)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"cpsu/internal/subject/models"
+	"cpsu/internal/subject/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *SubjectHandler) GetPrerequisiteGraph(c *gin.Context) {
+	graph, err := h.subjectService.GetPrerequisiteGraph(c.Param("id"))
+	if err != nil {
+		prerequisiteErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, graph)
+}
+
+func (h *SubjectHandler) GetSubjectUnlocks(c *gin.Context) {
+	unlocks, err := h.subjectService.GetSubjectUnlocks(c.Param("id"), c.Param("code"))
+	if err != nil {
+		prerequisiteErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, unlocks)
+}
+
+func (h *SubjectHandler) SetPrerequisites(c *gin.Context) {
+	var req models.PrerequisiteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	edges, err := h.subjectService.SetPrerequisites(
+		c.Param("id"),
+		c.Param("code"),
+		req,
+		c.GetInt("user_id"),
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+	)
+	if err != nil {
+		prerequisiteErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, edges)
+}
+
+// ParsePrerequisites แปลงข้อความ compulsory_subject เดิมทั้งหลักสูตร ?dry_run=true จะแสดงผลโดยไม่บันทึก
+func (h *SubjectHandler) ParsePrerequisites(c *gin.Context) {
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+			return
+		}
+		dryRun = parsed
+	}
+
+	result, err := h.subjectService.ParsePrerequisites(
+		c.Param("id"),
+		dryRun,
+		c.GetInt("user_id"),
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+	)
+	if err != nil {
+		prerequisiteErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func prerequisiteErrorResponse(c *gin.Context, err error) {
+	var cycleErr *service.PrerequisiteCycleError
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
+	case errors.Is(err, service.ErrSubjectNotInCourse):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSelfPrerequisite),
+		errors.Is(err, service.ErrInvalidPrerequisiteKind),
+		errors.Is(err, service.ErrUnknownPrerequisite):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.As(err, &cycleErr):
+		c.JSON(http.StatusConflict, gin.H{"error": "prerequisite cycle", "cycle": cycleErr.Cycle})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
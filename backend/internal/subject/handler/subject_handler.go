@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"strconv"
 
+	"cpsu/internal/credits"
+	"cpsu/internal/pagination"
 	"cpsu/internal/subject/models"
 	"cpsu/internal/subject/service"
 
@@ -27,8 +29,27 @@ func (h *SubjectHandler) GetAllSubjects(c *gin.Context) {
 		return
 	}
 
+	h.getAllSubjects(c, param)
+}
+
+func (h *SubjectHandler) GetPublicSubjects(c *gin.Context) {
+	var param models.SubjectsQueryParam
+	if err := c.BindQuery(&param); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	param.PublicOnly = true
+	h.getAllSubjects(c, param)
+}
+
+func (h *SubjectHandler) getAllSubjects(c *gin.Context, param models.SubjectsQueryParam) {
 	subjects, err := h.subjectService.GetAllSubjects(param)
 	if err != nil {
+		if body, ok := pagination.BadRequest(err); ok {
+			c.JSON(http.StatusBadRequest, body)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -37,6 +58,14 @@ func (h *SubjectHandler) GetAllSubjects(c *gin.Context) {
 }
 
 func (h *SubjectHandler) GetSubjectByID(c *gin.Context) {
+	h.getSubjectByID(c, h.subjectService.GetSubjectByID)
+}
+
+func (h *SubjectHandler) GetPublicSubjectByID(c *gin.Context) {
+	h.getSubjectByID(c, h.subjectService.GetPublicSubjectByID)
+}
+
+func (h *SubjectHandler) getSubjectByID(c *gin.Context, get func(id int) (*models.Subjects, error)) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
@@ -44,7 +73,7 @@ func (h *SubjectHandler) GetSubjectByID(c *gin.Context) {
 		return
 	}
 
-	subject, err := h.subjectService.GetSubjectByID(id)
+	subject, err := get(id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "subject not found"})
@@ -71,7 +100,11 @@ func (h *SubjectHandler) CreateSubject(c *gin.Context) {
 
 	createdSubject, err := h.subjectService.CreateSubject(req, userID, ip, userAgent)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, credits.ErrInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -100,6 +133,8 @@ func (h *SubjectHandler) UpdateSubject(c *gin.Context) {
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "subject not found"})
+		} else if errors.Is(err, credits.ErrInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
@@ -133,3 +168,17 @@ func (h *SubjectHandler) DeleteSubject(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "subject deleted successfully"})
 }
+
+func (h *SubjectHandler) GetCourseCredits(c *gin.Context) {
+	totals, err := h.subjectService.GetCourseCredits(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, totals)
+}
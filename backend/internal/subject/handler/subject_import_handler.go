@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"cpsu/internal/subject/models"
+	"cpsu/internal/subject/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportSubjects รับไฟล์ XLSX หลาย sheet ในฟิลด์ file หรือไฟล์ csv ในฟิลด์ subjects, description และ clo
+// ส่งได้หลายไฟล์ต่อฟิลด์ ถ้า ?dry_run=true จะคืนรายการที่จะเปลี่ยนโดยไม่บันทึก
+// ไฟล์ที่มีข้อผิดพลาดรายแถวจะได้ 422 พร้อมผลการตรวจ
+// ต้องมีทั้ง subject:create และ subject:update เพราะแถวที่มีอยู่แล้วจะถูกแก้ทับ
+func (h *SubjectHandler) ImportSubjects(c *gin.Context) {
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+			return
+		}
+		dryRun = parsed
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	var files []models.ImportFile
+	fields := append([]string{"file"}, models.ImportTables...)
+	for _, field := range fields {
+		table := field
+		if field == "file" {
+			table = ""
+		}
+		for _, header := range form.File[field] {
+			opened, err := header.Open()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			defer opened.Close()
+			files = append(files, models.ImportFile{Table: table, Name: header.Filename, Reader: opened})
+		}
+	}
+	if len(files) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	result, err := h.subjectService.ImportSubjects(
+		files,
+		dryRun,
+		c.GetInt("user_id"),
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+	)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidImportFile) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
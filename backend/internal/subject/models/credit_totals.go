@@ -0,0 +1,33 @@
+package models
+
+// CourseCredits คือผลรวมหน่วยกิตของหลักสูตร วิชาที่มี * (non_credit) ไม่นับใน credits
+type CourseCredits struct {
+	CourseID   string            `json:"course_id"`
+	Categories []CategoryCredits `json:"categories"`
+	Plans      []PlanCredits     `json:"plans"`
+}
+
+// CategoryCredits รวมหน่วยกิตของวิชาในหมวดและกลุ่มย่อยตามโครงสร้างหลักสูตร
+type CategoryCredits struct {
+	Category   string `json:"category"`
+	NameTH     string `json:"name_th"`
+	NameEN     string `json:"name_en"`
+	MinCredits int    `json:"min_credits"`
+	Credits    int    `json:"credits"`
+	Subjects   int    `json:"subjects"`
+}
+
+// PlanCredits แยกตามแผนการเรียน เพราะแต่ละแผนลงวิชาในภาคการศึกษาต่างกัน
+type PlanCredits struct {
+	PlanType  string            `json:"plan_type"`
+	Credits   int               `json:"credits"`
+	Semesters []SemesterCredits `json:"semesters"`
+}
+
+type SemesterCredits struct {
+	Semester string `json:"semester"`
+	Credits  int    `json:"credits"`
+	// NonCredits คือหน่วยกิตของวิชาที่ไม่นับหน่วยกิตรวมในภาคการศึกษานั้น
+	NonCredits int `json:"non_credits"`
+	Subjects   int `json:"subjects"`
+}
@@ -0,0 +1,92 @@
+package models
+
+// ขอบเขตของเกณฑ์เกรดเฉลี่ย
+const (
+	// GPAScopeOverall คือเกรดเฉลี่ยสะสมทุกวิชา
+	GPAScopeOverall = "overall"
+	// GPAScopeSpecific คือหมวดวิชาเฉพาะ ได้แก่ วิชาแกนและวิชาเฉพาะด้าน
+	GPAScopeSpecific = "specific"
+	// GPAScopeMajor คือวิชาเฉพาะด้าน ทั้งบังคับและเลือก
+	GPAScopeMajor = "major"
+)
+
+type GraduationAuditRequest struct {
+	// PlanType คือแผนการเรียนของนักศึกษา เช่น โครงงานวิจัย หรือ สหกิจศึกษา ถ้าไม่ระบุจะตรวจรวมทุกแผน
+	PlanType  string             `json:"plan_type"`
+	Completed []CompletedSubject `json:"completed" binding:"max=300,dive"`
+}
+
+// CompletedSubject คือวิชาที่เรียนแล้ว credits ใช้กับวิชานอกหลักสูตรซึ่งนับเป็นวิชาเลือกเสรี
+type CompletedSubject struct {
+	SubjectID string `json:"subject_id" binding:"required"`
+	Grade     string `json:"grade" binding:"required"`
+	Credits   *int   `json:"credits" binding:"omitempty,min=0,max=30"`
+}
+
+// PlanSemester คือภาคการศึกษาที่รายวิชาอยู่ในแผนการเรียนหนึ่ง
+type PlanSemester struct {
+	SubjectID string
+	PlanType  string
+	Semester  string
+}
+
+// AuditSubject คือรายวิชาของหลักสูตรพร้อมหมวดในโครงสร้างหลักสูตร category ว่างเมื่อไม่ได้อยู่ในโครงสร้าง
+type AuditSubject struct {
+	SubjectID   string `json:"subject_id"`
+	ThaiSubject string `json:"thai_subject"`
+	EngSubject  string `json:"eng_subject"`
+	Credits     string `json:"credits"`
+	CreditValue int    `json:"-"`
+	NonCredit   bool   `json:"-"`
+	Category    string `json:"-"`
+}
+
+// GraduationRequirements คือข้อความเกณฑ์จากตาราง courses
+type GraduationRequirements struct {
+	Credits       string
+	GraduationReq string
+}
+
+type GraduationAudit struct {
+	CourseID        string          `json:"course_id"`
+	RequiredCredits int             `json:"required_credits"`
+	EarnedCredits   int             `json:"earned_credits"`
+	MissingCredits  int             `json:"missing_credits"`
+	GPA             *float64        `json:"gpa"`
+	Categories      []CategoryAudit `json:"categories"`
+	GPARules        []GPARuleAudit  `json:"gpa_rules"`
+	// GraduationReq คือข้อความเกณฑ์เต็ม สำหรับเงื่อนไขที่ตรวจอัตโนมัติไม่ได้
+	GraduationReq string            `json:"graduation_req"`
+	Eligible      []EligibleSubject `json:"eligible"`
+	// Unrecognized คือรหัสวิชานอกหลักสูตรที่ไม่ได้ระบุหน่วยกิตจึงไม่ถูกนับ
+	Unrecognized []string `json:"unrecognized"`
+	Complete     bool     `json:"complete"`
+}
+
+// CategoryAudit หน่วยกิตที่ได้และที่ยังขาดในแต่ละหมวด remaining คือวิชาบังคับที่ยังไม่ผ่าน
+type CategoryAudit struct {
+	Category       string         `json:"category"`
+	NameTH         string         `json:"name_th"`
+	NameEN         string         `json:"name_en"`
+	MinCredits     int            `json:"min_credits"`
+	EarnedCredits  int            `json:"earned_credits"`
+	MissingCredits int            `json:"missing_credits"`
+	Completed      []string       `json:"completed"`
+	Remaining      []AuditSubject `json:"remaining"`
+}
+
+type GPARuleAudit struct {
+	Scope  string   `json:"scope"`
+	MinGPA float64  `json:"min_gpa"`
+	GPA    *float64 `json:"gpa"`
+	Met    bool     `json:"met"`
+	Text   string   `json:"text"`
+}
+
+// EligibleSubject คือวิชาที่ยังไม่ได้เรียนและผ่านวิชาบังคับก่อนครบแล้ว
+// take_with คือวิชาที่ต้องเรียนพร้อมกันถ้ายังไม่เคยเรียน
+type EligibleSubject struct {
+	AuditSubject
+	HasPrerequisites bool     `json:"has_prerequisites"`
+	TakeWith         []string `json:"take_with"`
+}
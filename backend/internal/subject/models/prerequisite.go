@@ -0,0 +1,82 @@
+package models
+
+const (
+	PrerequisiteKindPrerequisite = "prerequisite"
+	// PrerequisiteKindCorequisite คือวิชาที่เรียนก่อนหรือเรียนพร้อมกันได้
+	PrerequisiteKindCorequisite = "corequisite"
+
+	PrerequisiteSourceParsed = "parsed"
+	PrerequisiteSourceManual = "manual"
+)
+
+// PrerequisiteEdge คือเส้นจากวิชาที่ต้องผ่าน (from) ไปยังวิชาที่มีเงื่อนไข (to)
+// เส้นของวิชาเดียวกันที่ group เท่ากันคือทางเลือก "หรือ" ส่วน group ต่างกันต้องผ่านทุกกลุ่ม
+type PrerequisiteEdge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Kind   string `json:"kind"`
+	Group  int    `json:"group"`
+	Source string `json:"source"`
+}
+
+// PrerequisiteNode คือรายวิชาในกราฟ in_course เป็น false เมื่อเป็นวิชาที่อ้างถึงแต่ไม่อยู่ในหลักสูตรนี้
+// level คือความลึกจากวิชาที่ไม่มีเงื่อนไข ใช้จัดวางกราฟเป็นชั้น
+type PrerequisiteNode struct {
+	SubjectID   string `json:"subject_id"`
+	ThaiSubject string `json:"thai_subject"`
+	EngSubject  string `json:"eng_subject"`
+	InCourse    bool   `json:"in_course"`
+	Level       int    `json:"level"`
+}
+
+type PrerequisiteGraph struct {
+	CourseID string             `json:"course_id"`
+	Nodes    []PrerequisiteNode `json:"nodes"`
+	Edges    []PrerequisiteEdge `json:"edges"`
+	// Cycles คือวงของวิชาบังคับก่อนที่ทำให้ลงทะเบียนไม่ได้ ไม่นับวิชาที่เรียนพร้อมกันได้
+	Cycles [][]string `json:"cycles"`
+}
+
+type PrerequisiteOption struct {
+	SubjectID string `json:"subject_id" binding:"required"`
+	Kind      string `json:"kind"`
+}
+
+// PrerequisiteRequest ต้องผ่านทุกกลุ่มใน groups โดยแต่ละกลุ่มผ่านวิชาใดวิชาหนึ่งก็พอ
+// groups ว่างคือล้างเงื่อนไขของวิชา
+type PrerequisiteRequest struct {
+	Groups [][]PrerequisiteOption `json:"groups"`
+}
+
+type SubjectUnlock struct {
+	SubjectID   string `json:"subject_id"`
+	ThaiSubject string `json:"thai_subject"`
+	EngSubject  string `json:"eng_subject"`
+	Kind        string `json:"kind"`
+	// Depth 1 คือปลดล็อกโดยตรง มากกว่านั้นคือผ่านวิชาอื่นต่อกัน
+	Depth int `json:"depth"`
+	// Alternative เป็น true เมื่อมีวิชาอื่นในกลุ่ม "หรือ" เดียวกันที่ใช้แทนได้
+	Alternative bool `json:"alternative"`
+}
+
+type SubjectUnlocks struct {
+	SubjectID string          `json:"subject_id"`
+	Unlocks   []SubjectUnlock `json:"unlocks"`
+}
+
+// PrerequisiteText คือข้อความ compulsory_subject เดิมของวิชา
+type PrerequisiteText struct {
+	SubjectID string `json:"subject_id"`
+	Text      string `json:"text"`
+}
+
+type PrerequisiteParseResult struct {
+	DryRun bool `json:"dry_run"`
+	// Parsed คือจำนวนวิชาที่อ่านเงื่อนไขจากข้อความได้
+	Parsed int                `json:"parsed"`
+	Edges  []PrerequisiteEdge `json:"edges"`
+	// Skipped คือวิชาที่แก้เงื่อนไขเองแล้ว จึงไม่เขียนทับด้วยผลจากข้อความ
+	Skipped  []string           `json:"skipped"`
+	Unparsed []PrerequisiteText `json:"unparsed"`
+	Cycles   [][]string         `json:"cycles"`
+}
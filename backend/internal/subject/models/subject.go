@@ -1,5 +1,10 @@
 package models
 
+import (
+	"cpsu/internal/credits"
+	"cpsu/internal/pagination"
+)
+
 type Subjects struct {
 	ID                int     `json:"id"`
 	SubjectID         string  `json:"subject_id"`
@@ -17,17 +22,27 @@ type Subjects struct {
 	DescriptionEng    *string `json:"description_eng,omitempty"`
 	CloID             *string `json:"clo_id,omitempty"`
 	CLO               *string `json:"clo,omitempty"`
+
+	// หน่วยกิตที่แยกจาก credits แล้ว ชั่วโมงเป็น null เมื่อไม่ได้ระบุ และ non_credit คือวิชาที่มี * ไม่นับหน่วยกิตรวม
+	CreditValue    int  `json:"credit_value"`
+	LectureHours   *int `json:"lecture_hours"`
+	LabHours       *int `json:"lab_hours"`
+	SelfStudyHours *int `json:"self_study_hours"`
+	NonCredit      bool `json:"non_credit"`
 }
 
 type SubjectsQueryParam struct {
+	pagination.Params
 	Search    string `form:"search"`
-	Limit     int    `form:"limit"`
 	SubjectID string `form:"subject_id"`
 	CourseID  string `form:"course_id"`
 	PlanType  string `form:"plan_type"`
 	Semester  string `form:"semester"`
 	Sort      string `form:"sort"`
 	Order     string `form:"order"`
+
+	// PublicOnly ตั้งโดย handler ของหน้าเว็บสาธารณะ
+	PublicOnly bool `form:"-"`
 }
 
 type SubjectsRequest struct {
@@ -45,4 +60,7 @@ type SubjectsRequest struct {
 	DescriptionEng    *string `json:"description_eng,omitempty"`
 	CloID             *string `json:"clo_id,omitempty"`
 	CLO               *string `json:"clo,omitempty"`
+
+	// ParsedCredits ตั้งโดย service หลังตรวจ credits แล้ว
+	ParsedCredits credits.Credits `json:"-"`
 }
@@ -0,0 +1,100 @@
+package models
+
+import "io"
+
+// ตารางที่นำเข้าได้ ชื่อตรงกับตารางในฐานข้อมูลและไฟล์ csv ที่ใช้ตอนสร้างฐานข้อมูล
+const (
+	ImportTableSubjects    = "subjects"
+	ImportTableDescription = "description"
+	ImportTableCLO         = "clo"
+)
+
+// ImportTables เรียงตามลำดับที่บันทึก description และ clo ต้องมีก่อนรายวิชาที่อ้างถึง
+var ImportTables = []string{ImportTableDescription, ImportTableCLO, ImportTableSubjects}
+
+// ImportFile คือไฟล์ที่อัปโหลดหนึ่งไฟล์ table ว่างคือไฟล์ XLSX ที่แยกตารางตามชื่อ sheet
+type ImportFile struct {
+	Table  string
+	Name   string
+	Reader io.Reader
+}
+
+type ImportDescription struct {
+	DescriptionID   string
+	DescriptionThai *string
+	DescriptionEng  *string
+}
+
+type ImportCLO struct {
+	CLOID string
+	CLO   *string
+}
+
+// ImportSubjectUpdate คือรายวิชาเดิมที่จะถูกแก้ตามแถวในไฟล์
+type ImportSubjectUpdate struct {
+	ID      int
+	Subject SubjectsRequest
+}
+
+// ImportPrerequisites คือเส้นวิชาบังคับก่อนที่แยกจากข้อความใหม่ของรายวิชาหนึ่ง
+type ImportPrerequisites struct {
+	CourseID  string
+	SubjectID string
+	Edges     []PrerequisiteEdge
+}
+
+// SubjectImportPlan คือทุกอย่างที่จะบันทึกใน transaction เดียว
+type SubjectImportPlan struct {
+	Descriptions  []ImportDescription
+	CLOs          []ImportCLO
+	Creates       []SubjectsRequest
+	Updates       []ImportSubjectUpdate
+	Prerequisites []ImportPrerequisites
+}
+
+// ExistingSubject คือรายวิชาในฐานข้อมูลที่ใช้เทียบกับแถวในไฟล์
+type ExistingSubject struct {
+	ID int
+	SubjectsRequest
+}
+
+type ImportRowError struct {
+	File    string `json:"file"`
+	Table   string `json:"table"`
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportFieldChange struct {
+	Field string  `json:"field"`
+	Old   *string `json:"old"`
+	New   *string `json:"new"`
+}
+
+// ImportChange คือแถวที่จะเพิ่มหรือแก้ แถวที่ไม่เปลี่ยนนับไว้ใน summary อย่างเดียว
+// id คือ id ของรายวิชาเดิมที่ถูกแก้
+type ImportChange struct {
+	Table  string              `json:"table"`
+	File   string              `json:"file"`
+	Row    int                 `json:"row"`
+	Action string              `json:"action"`
+	Key    string              `json:"key"`
+	ID     *int                `json:"id,omitempty"`
+	Fields []ImportFieldChange `json:"fields"`
+}
+
+type ImportSummary struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+}
+
+// SubjectImportResult ถ้ามี errors จะไม่บันทึกอะไรเลย
+// การนำเข้าเพิ่มหรือแก้อย่างเดียว ข้อมูลเดิมที่ไม่อยู่ในไฟล์ยังอยู่
+type SubjectImportResult struct {
+	DryRun  bool                     `json:"dry_run"`
+	Errors  []ImportRowError         `json:"errors"`
+	Summary map[string]ImportSummary `json:"summary"`
+	Changes []ImportChange           `json:"changes"`
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"cpsu/internal/subject/models"
+)
+
+// GetCategoryCredits รวมหน่วยกิตของวิชาในแต่ละหมวด รวมถึงวิชาในกลุ่มย่อย
+// รหัสวิชาเดียวกันหลายแผนการเรียนนับครั้งเดียว
+func (r *subjectRepository) GetCategoryCredits(courseID string) ([]models.CategoryCredits, error) {
+	rows, err := r.db.Query(`
+		WITH RECURSIVE tree AS (
+			SELECT node_id, node_id AS root_id
+			FROM curriculum_nodes
+			WHERE course_id = $1 AND parent_id IS NULL
+			UNION ALL
+			SELECT n.node_id, t.root_id
+			FROM curriculum_nodes n
+			JOIN tree t ON n.parent_id = t.node_id
+		), subject_credits AS (
+			SELECT DISTINCT ON (subject_id) subject_id, credit_value, non_credit
+			FROM subjects
+			WHERE course_id = $1
+			ORDER BY subject_id, id
+		)
+		SELECT r.category, r.name_th, r.name_en, r.min_credits,
+			COALESCE(SUM(CASE WHEN sc.non_credit THEN 0 ELSE sc.credit_value END), 0),
+			COUNT(sc.subject_id)
+		FROM curriculum_nodes r
+		LEFT JOIN tree t ON t.root_id = r.node_id
+		LEFT JOIN curriculum_node_subjects ns ON ns.node_id = t.node_id
+		LEFT JOIN subject_credits sc ON sc.subject_id = ns.subject_id
+		WHERE r.course_id = $1 AND r.parent_id IS NULL
+		GROUP BY r.node_id
+		ORDER BY r.sort_order, r.node_id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []models.CategoryCredits{}
+	for rows.Next() {
+		var c models.CategoryCredits
+		if err := rows.Scan(&c.Category, &c.NameTH, &c.NameEN, &c.MinCredits, &c.Credits, &c.Subjects); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// GetPlanCredits รวมหน่วยกิตตามแผนการเรียนและภาคการศึกษา เรียงตามปีและภาคที่อยู่ในชื่อ
+// เช่น "ปีที่ 1 ภาคการศึกษาที่ 2"
+func (r *subjectRepository) GetPlanCredits(courseID string) ([]models.PlanCredits, error) {
+	rows, err := r.db.Query(`
+		SELECT plan_type, semester,
+			SUM(CASE WHEN non_credit THEN 0 ELSE credit_value END),
+			SUM(CASE WHEN non_credit THEN credit_value ELSE 0 END),
+			COUNT(*)
+		FROM (
+			SELECT plan_type, REGEXP_REPLACE(BTRIM(semester), '\s+', ' ', 'g') AS semester, credit_value, non_credit
+			FROM subjects
+			WHERE course_id = $1
+		) s
+		GROUP BY plan_type, semester
+		ORDER BY plan_type,
+			(REGEXP_MATCH(semester, '(\d+)\D+(\d+)'))[1]::INT NULLS LAST,
+			(REGEXP_MATCH(semester, '(\d+)\D+(\d+)'))[2]::INT NULLS LAST,
+			semester
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plans := []models.PlanCredits{}
+	for rows.Next() {
+		var (
+			planType string
+			semester models.SemesterCredits
+		)
+		if err := rows.Scan(&planType, &semester.Semester, &semester.Credits, &semester.NonCredits, &semester.Subjects); err != nil {
+			return nil, err
+		}
+		if len(plans) == 0 || plans[len(plans)-1].PlanType != planType {
+			plans = append(plans, models.PlanCredits{PlanType: planType, Semesters: []models.SemesterCredits{}})
+		}
+		plan := &plans[len(plans)-1]
+		plan.Credits += semester.Credits
+		plan.Semesters = append(plan.Semesters, semester)
+	}
+	return plans, rows.Err()
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"cpsu/internal/subject/models"
+)
+
+// GetGraduationRequirements คืน sql.ErrNoRows เมื่อไม่มีหลักสูตรนี้
+func (r *subjectRepository) GetGraduationRequirements(courseID string) (*models.GraduationRequirements, error) {
+	var req models.GraduationRequirements
+	err := r.db.QueryRow(`
+		SELECT credits, graduation_req FROM courses WHERE course_id = $1
+	`, courseID).Scan(&req.Credits, &req.GraduationReq)
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// GetAuditSubjects คืนรายวิชาของหลักสูตรพร้อมหมวดระดับบนสุดที่วิชานั้นอยู่ในโครงสร้างหลักสูตร
+func (r *subjectRepository) GetAuditSubjects(courseID string) ([]models.AuditSubject, error) {
+	rows, err := r.db.Query(`
+		WITH RECURSIVE tree AS (
+			SELECT node_id, node_id AS root_id
+			FROM curriculum_nodes
+			WHERE course_id = $1 AND parent_id IS NULL
+			UNION ALL
+			SELECT n.node_id, t.root_id
+			FROM curriculum_nodes n
+			JOIN tree t ON n.parent_id = t.node_id
+		), placed AS (
+			SELECT DISTINCT ON (ns.subject_id) ns.subject_id, root.category
+			FROM tree t
+			JOIN curriculum_node_subjects ns ON ns.node_id = t.node_id
+			JOIN curriculum_nodes root ON root.node_id = t.root_id
+			ORDER BY ns.subject_id, root.sort_order
+		)
+		SELECT DISTINCT ON (s.subject_id) s.subject_id, s.thai_subject, COALESCE(s.eng_subject, ''),
+			s.credits, s.credit_value, s.non_credit, COALESCE(p.category, '')
+		FROM subjects s
+		LEFT JOIN placed p ON p.subject_id = s.subject_id
+		WHERE s.course_id = $1
+		ORDER BY s.subject_id, s.id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subjects := []models.AuditSubject{}
+	for rows.Next() {
+		var s models.AuditSubject
+		if err := rows.Scan(&s.SubjectID, &s.ThaiSubject, &s.EngSubject, &s.Credits, &s.CreditValue, &s.NonCredit, &s.Category); err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, s)
+	}
+	return subjects, rows.Err()
+}
+
+// GetPlanSemesters คืนแผนและภาคการศึกษาของทุกแถวรายวิชาในหลักสูตร
+func (r *subjectRepository) GetPlanSemesters(courseID string) ([]models.PlanSemester, error) {
+	rows, err := r.db.Query(`
+		SELECT subject_id, plan_type, semester
+		FROM subjects
+		WHERE course_id = $1
+		ORDER BY id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	placements := []models.PlanSemester{}
+	for rows.Next() {
+		var p models.PlanSemester
+		if err := rows.Scan(&p.SubjectID, &p.PlanType, &p.Semester); err != nil {
+			return nil, err
+		}
+		placements = append(placements, p)
+	}
+	return placements, rows.Err()
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"database/sql"
+
+	"cpsu/internal/subject/models"
+)
+
+// CourseExists คืน sql.ErrNoRows เมื่อไม่มีหลักสูตรนี้
+func (r *subjectRepository) CourseExists(courseID string) error {
+	var exists int
+	return r.db.QueryRow("SELECT 1 FROM courses WHERE course_id = $1", courseID).Scan(&exists)
+}
+
+// GetPrerequisiteNodes คืนรายวิชาของหลักสูตร รหัสเดียวกันหลายแผนการเรียนจะใช้แถวแรก
+func (r *subjectRepository) GetPrerequisiteNodes(courseID string) ([]models.PrerequisiteNode, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT ON (subject_id) subject_id, thai_subject, COALESCE(eng_subject, '')
+		FROM subjects
+		WHERE course_id = $1
+		ORDER BY subject_id, id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := []models.PrerequisiteNode{}
+	for rows.Next() {
+		node := models.PrerequisiteNode{InCourse: true}
+		if err := rows.Scan(&node.SubjectID, &node.ThaiSubject, &node.EngSubject); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, rows.Err()
+}
+
+func (r *subjectRepository) GetPrerequisiteEdges(courseID string) ([]models.PrerequisiteEdge, error) {
+	rows, err := r.db.Query(`
+		SELECT requires_id, subject_id, kind, group_no, source
+		FROM subject_prerequisites
+		WHERE course_id = $1
+		ORDER BY subject_id, group_no, prerequisite_id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := []models.PrerequisiteEdge{}
+	for rows.Next() {
+		var e models.PrerequisiteEdge
+		if err := rows.Scan(&e.From, &e.To, &e.Kind, &e.Group, &e.Source); err != nil {
+			return nil, err
+		}
+		edges = append(edges, e)
+	}
+	return edges, rows.Err()
+}
+
+// GetPrerequisiteTexts คืนข้อความ compulsory_subject ที่ไม่ว่างของแต่ละรหัสวิชา
+func (r *subjectRepository) GetPrerequisiteTexts(courseID string) ([]models.PrerequisiteText, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT ON (subject_id) subject_id, compulsory_subject
+		FROM subjects
+		WHERE course_id = $1 AND TRIM(COALESCE(compulsory_subject, '')) <> ''
+		ORDER BY subject_id, id
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	texts := []models.PrerequisiteText{}
+	for rows.Next() {
+		var t models.PrerequisiteText
+		if err := rows.Scan(&t.SubjectID, &t.Text); err != nil {
+			return nil, err
+		}
+		texts = append(texts, t)
+	}
+	return texts, rows.Err()
+}
+
+// ReplaceSubjectPrerequisites แทนที่เงื่อนไขทั้งหมดของวิชาเดียว ไม่ว่าจะมาจากข้อความหรือแก้เอง
+func (r *subjectRepository) ReplaceSubjectPrerequisites(courseID string, subjectID string, edges []models.PrerequisiteEdge) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		"DELETE FROM subject_prerequisites WHERE course_id = $1 AND subject_id = $2",
+		courseID, subjectID,
+	); err != nil {
+		return err
+	}
+	if err := insertPrerequisites(tx, courseID, edges); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReplaceParsedPrerequisites แทนที่เฉพาะเส้นที่ได้จากข้อความ เส้นที่แก้เองยังอยู่
+func (r *subjectRepository) ReplaceParsedPrerequisites(courseID string, edges []models.PrerequisiteEdge) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		"DELETE FROM subject_prerequisites WHERE course_id = $1 AND source = $2",
+		courseID, models.PrerequisiteSourceParsed,
+	); err != nil {
+		return err
+	}
+	if err := insertPrerequisites(tx, courseID, edges); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// syncParsedPrerequisites แทนที่เงื่อนไขของวิชาด้วยเส้นที่แปลงจากข้อความ ถ้าวิชานั้นไม่ได้แก้เงื่อนไขเองไว้
+func syncParsedPrerequisites(tx *sql.Tx, courseID string, subjectID string, edges []models.PrerequisiteEdge) error {
+	var manual bool
+	err := tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM subject_prerequisites
+			WHERE course_id = $1 AND subject_id = $2 AND source = $3
+		)
+	`, courseID, subjectID, models.PrerequisiteSourceManual).Scan(&manual)
+	if err != nil || manual {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		"DELETE FROM subject_prerequisites WHERE course_id = $1 AND subject_id = $2",
+		courseID, subjectID,
+	); err != nil {
+		return err
+	}
+	return insertPrerequisites(tx, courseID, edges)
+}
+
+// removeUnusedParsedPrerequisites ลบเส้นที่แปลงจากข้อความของรหัสที่ไม่มีแถวไหนในหลักสูตรใช้แล้ว
+func removeUnusedParsedPrerequisites(tx *sql.Tx, courseID string, subjectID string) error {
+	_, err := tx.Exec(`
+		DELETE FROM subject_prerequisites p
+		WHERE p.course_id = $1 AND p.subject_id = $2 AND p.source = $3
+			AND NOT EXISTS (SELECT 1 FROM subjects s WHERE s.course_id = $1 AND s.subject_id = $2)
+	`, courseID, subjectID, models.PrerequisiteSourceParsed)
+	return err
+}
+
+func insertPrerequisites(tx *sql.Tx, courseID string, edges []models.PrerequisiteEdge) error {
+	for _, e := range edges {
+		_, err := tx.Exec(`
+			INSERT INTO subject_prerequisites (course_id, subject_id, requires_id, kind, group_no, source)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, courseID, e.To, e.From, e.Kind, e.Group, e.Source)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"database/sql"
+
+	"cpsu/internal/subject/models"
+
+	"github.com/lib/pq"
+)
+
+// แยก CLO รายข้อจากข้อความของ clo แบบเดียวกับตอนสร้างฐานข้อมูล รหัสที่ซ้ำในข้อความเดียวกันใช้บรรทัดแรก
+const parsedCLOItems = `
+	SELECT DISTINCT ON (code) code, description, ord
+	FROM (
+		SELECT REGEXP_REPLACE(m[1], '\s+', '', 'g') AS code,
+			REGEXP_REPLACE(m[2], '^\s+|\s+$', '', 'g') AS description,
+			l.ord
+		FROM REGEXP_SPLIT_TO_TABLE(COALESCE($2::text, ''), '\n') WITH ORDINALITY AS l(line, ord)
+		CROSS JOIN LATERAL REGEXP_MATCH(l.line, '^\s*(CLO\s*\d+(?:\.\d+)?)[[:space:]:.)-]*(.*)$') AS m
+		WHERE m IS NOT NULL
+	) parsed
+	ORDER BY code, ord
+`
+
+// ExistingCourses คืนรหัสหลักสูตรใน courseIDs ที่มีอยู่จริง
+func (r *subjectRepository) ExistingCourses(courseIDs []string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	rows, err := r.db.Query("SELECT course_id FROM courses WHERE course_id = ANY($1)", pq.Array(courseIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		existing[id] = true
+	}
+	return existing, rows.Err()
+}
+
+// GetExistingSubjects คืนรายวิชาทั้งหมดของหลักสูตรที่ระบุ เรียงตาม id
+func (r *subjectRepository) GetExistingSubjects(courseIDs []string) ([]models.ExistingSubject, error) {
+	rows, err := r.db.Query(`
+		SELECT id, subject_id, course_id, plan_type, semester, thai_subject, eng_subject,
+			credits, compulsory_subject, condition, description_id, clo_id
+		FROM subjects
+		WHERE course_id = ANY($1)
+		ORDER BY id
+	`, pq.Array(courseIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subjects := []models.ExistingSubject{}
+	for rows.Next() {
+		var s models.ExistingSubject
+		if err := rows.Scan(
+			&s.ID, &s.SubjectID, &s.CourseID, &s.PlanType, &s.Semester, &s.ThaiSubject, &s.EngSubject,
+			&s.Credits, &s.CompulsorySubject, &s.Condition, &s.DescriptionID, &s.CloID,
+		); err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, s)
+	}
+	return subjects, rows.Err()
+}
+
+func (r *subjectRepository) GetDescriptions(ids []string) (map[string]models.ImportDescription, error) {
+	descriptions := map[string]models.ImportDescription{}
+	rows, err := r.db.Query(`
+		SELECT description_id, description_thai, description_eng
+		FROM description
+		WHERE description_id = ANY($1)
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d models.ImportDescription
+		if err := rows.Scan(&d.DescriptionID, &d.DescriptionThai, &d.DescriptionEng); err != nil {
+			return nil, err
+		}
+		descriptions[d.DescriptionID] = d
+	}
+	return descriptions, rows.Err()
+}
+
+func (r *subjectRepository) GetCLOTexts(ids []string) (map[string]models.ImportCLO, error) {
+	clos := map[string]models.ImportCLO{}
+	rows, err := r.db.Query("SELECT clo_id, clo FROM clo WHERE clo_id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.ImportCLO
+		if err := rows.Scan(&c.CLOID, &c.CLO); err != nil {
+			return nil, err
+		}
+		clos[c.CLOID] = c
+	}
+	return clos, rows.Err()
+}
+
+// ApplySubjectImport บันทึกผลการนำเข้าทั้งหมดใน transaction เดียว ถ้าขั้นใดผิดพลาดจะไม่มีอะไรถูกบันทึก
+func (r *subjectRepository) ApplySubjectImport(plan models.SubjectImportPlan) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, d := range plan.Descriptions {
+		_, err := tx.Exec(`
+			INSERT INTO description (description_id, description_thai, description_eng)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (description_id) DO UPDATE
+			SET description_thai = EXCLUDED.description_thai, description_eng = EXCLUDED.description_eng
+		`, d.DescriptionID, d.DescriptionThai, d.DescriptionEng)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, c := range plan.CLOs {
+		_, err := tx.Exec(`
+			INSERT INTO clo (clo_id, clo)
+			VALUES ($1, $2)
+			ON CONFLICT (clo_id) DO UPDATE SET clo = EXCLUDED.clo
+		`, c.CLOID, c.CLO)
+		if err != nil {
+			return err
+		}
+		if err := syncCLOItems(tx, c); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range plan.Creates {
+		_, err := tx.Exec(`
+			INSERT INTO subjects (
+				subject_id, course_id, plan_type, semester, thai_subject, eng_subject,
+				credits, compulsory_subject, condition, description_id, clo_id,
+				credit_value, lecture_hours, lab_hours, self_study_hours, non_credit
+			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)
+		`,
+			s.SubjectID, s.CourseID, s.PlanType, s.Semester,
+			s.ThaiSubject, s.EngSubject, s.Credits, s.CompulsorySubject,
+			s.Condition, s.DescriptionID, s.CloID,
+			s.ParsedCredits.Value, s.ParsedCredits.LectureHours, s.ParsedCredits.LabHours,
+			s.ParsedCredits.SelfStudyHours, s.ParsedCredits.NonCredit,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, u := range plan.Updates {
+		s := u.Subject
+		_, err := tx.Exec(`
+			UPDATE subjects
+			SET thai_subject=$1, eng_subject=$2, credits=$3, compulsory_subject=$4, condition=$5,
+				description_id=$6, clo_id=$7, credit_value=$8, lecture_hours=$9, lab_hours=$10,
+				self_study_hours=$11, non_credit=$12
+			WHERE id=$13
+		`,
+			s.ThaiSubject, s.EngSubject, s.Credits, s.CompulsorySubject, s.Condition,
+			s.DescriptionID, s.CloID, s.ParsedCredits.Value, s.ParsedCredits.LectureHours, s.ParsedCredits.LabHours,
+			s.ParsedCredits.SelfStudyHours, s.ParsedCredits.NonCredit, u.ID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, p := range plan.Prerequisites {
+		if _, err := tx.Exec(
+			"DELETE FROM subject_prerequisites WHERE course_id = $1 AND subject_id = $2",
+			p.CourseID, p.SubjectID,
+		); err != nil {
+			return err
+		}
+		if err := insertPrerequisites(tx, p.CourseID, p.Edges); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// syncCLOItems ทำให้ clo_items ตรงกับข้อความใหม่ รหัสเดิมคง clo_item_id ไว้เพื่อไม่ให้ mapping กับ PLO หาย
+func syncCLOItems(tx *sql.Tx, c models.ImportCLO) error {
+	_, err := tx.Exec(`
+		INSERT INTO clo_items (clo_id, code, description, sort_order)
+		SELECT $1, code, description, ord FROM (`+parsedCLOItems+`) items
+		ON CONFLICT (clo_id, code) DO UPDATE
+		SET description = EXCLUDED.description, sort_order = EXCLUDED.sort_order
+	`, c.CLOID, c.CLO)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM clo_items
+		WHERE clo_id = $1 AND code NOT IN (SELECT code FROM (`+parsedCLOItems+`) items)
+	`, c.CLOID, c.CLO)
+	return err
+}
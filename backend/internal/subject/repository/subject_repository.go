@@ -3,17 +3,42 @@ package repository
 import (
 	"database/sql"
 	"strconv"
-	"strings"
 
+	coursemodels "cpsu/internal/course/models"
+	"cpsu/internal/pagination"
 	"cpsu/internal/subject/models"
+	"cpsu/internal/textsearch"
+
+	"github.com/lib/pq"
 )
 
 type SubjectRepository interface {
-	GetAllSubjects(param models.SubjectsQueryParam) ([]models.Subjects, error)
+	GetAllSubjects(param models.SubjectsQueryParam) (*pagination.Page[models.Subjects], error)
 	GetSubjectByID(id int) (*models.Subjects, error)
-	CreateSubject(req models.SubjectsRequest) (*models.Subjects, error)
-	UpdateSubject(id int, req models.SubjectsRequest) (*models.Subjects, error)
+	GetPublicSubjectByID(id int) (*models.Subjects, error)
+	CreateSubject(req models.SubjectsRequest, prerequisites []models.PrerequisiteEdge) (*models.Subjects, error)
+	UpdateSubject(id int, req models.SubjectsRequest, prerequisites []models.PrerequisiteEdge) (*models.Subjects, error)
 	DeleteSubject(id int) error
+
+	CourseExists(courseID string) error
+	GetPrerequisiteNodes(courseID string) ([]models.PrerequisiteNode, error)
+	GetPrerequisiteEdges(courseID string) ([]models.PrerequisiteEdge, error)
+	GetPrerequisiteTexts(courseID string) ([]models.PrerequisiteText, error)
+	ReplaceSubjectPrerequisites(courseID string, subjectID string, edges []models.PrerequisiteEdge) error
+	ReplaceParsedPrerequisites(courseID string, edges []models.PrerequisiteEdge) error
+
+	GetCategoryCredits(courseID string) ([]models.CategoryCredits, error)
+	GetPlanCredits(courseID string) ([]models.PlanCredits, error)
+
+	GetGraduationRequirements(courseID string) (*models.GraduationRequirements, error)
+	GetAuditSubjects(courseID string) ([]models.AuditSubject, error)
+	GetPlanSemesters(courseID string) ([]models.PlanSemester, error)
+
+	ExistingCourses(courseIDs []string) (map[string]bool, error)
+	GetExistingSubjects(courseIDs []string) ([]models.ExistingSubject, error)
+	GetDescriptions(ids []string) (map[string]models.ImportDescription, error)
+	GetCLOTexts(ids []string) (map[string]models.ImportCLO, error)
+	ApplySubjectImport(plan models.SubjectImportPlan) error
 }
 
 type subjectRepository struct {
@@ -24,19 +49,22 @@ func NewSubjectRepository(db *sql.DB) SubjectRepository {
 	return &subjectRepository{db: db}
 }
 
-func (r *subjectRepository) GetAllSubjects(param models.SubjectsQueryParam) ([]models.Subjects, error) {
-	query := `
-		SELECT 
-			s.id, s.subject_id, c.course_id, c.thai_course,s.plan_type, 
-			s.semester, s.thai_subject, s.eng_subject, s.credits, 
-			s.compulsory_subject, s.condition, d.description_id, 
-			d.description_thai, d.description_eng, cl.clo_id, cl.clo
-		FROM subjects s
-		LEFT JOIN courses c ON s.course_id = c.course_id
-		LEFT JOIN description d ON s.description_id = d.description_id
-		LEFT JOIN clo cl ON s.clo_id = cl.clo_id
-	`
+var subjectSort = pagination.Sorter{
+	Columns: map[string]string{
+		"id":           "s.id",
+		"subject_id":   "s.subject_id",
+		"course_id":    "s.course_id",
+		"plan_type":    "s.plan_type",
+		"semester":     "s.semester",
+		"thai_subject": "s.thai_subject",
+		"eng_subject":  "s.eng_subject",
+		"credits":      "s.credit_value",
+	},
+	Default: "id",
+	Key:     "s.id",
+}
 
+func (r *subjectRepository) GetAllSubjects(param models.SubjectsQueryParam) (*pagination.Page[models.Subjects], error) {
 	conditions := []string{}
 	args := []interface{}{}
 	argIndex := 1
@@ -65,56 +93,73 @@ func (r *subjectRepository) GetAllSubjects(param models.SubjectsQueryParam) ([]m
 		argIndex++
 	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
-	}
-
-	sort := "s.id"
-	if param.Sort != "" {
-		sort = "s." + param.Sort
-	}
-
-	order := "ASC"
-	if strings.ToUpper(param.Order) == "DESC" {
-		order = "DESC"
+	if param.PublicOnly {
+		conditions = append(conditions, "c.status = ANY($"+strconv.Itoa(argIndex)+")")
+		args = append(args, pq.Array(coursemodels.PublicStatuses))
+		argIndex++
 	}
 
-	query += " ORDER BY " + sort + " " + order
-
-	if param.Limit > 0 {
-		query += " LIMIT " + strconv.Itoa(param.Limit)
+	if terms := textsearch.Terms(param.Search); len(terms) > 0 {
+		condition, searchArgs, next := textsearch.Condition([]string{"s.subject_id", "s.thai_subject", "s.eng_subject"}, terms, argIndex)
+		conditions = append(conditions, condition)
+		args = append(args, searchArgs...)
+		argIndex = next
 	}
 
-	rows, err := r.db.Query(query, args...)
+	orderBy, err := subjectSort.Parse(param.Sort, param.Order)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var subjects []models.Subjects
-	for rows.Next() {
+	stmt := pagination.Statement{
+		Columns: `
+			s.id, s.subject_id, c.course_id, c.thai_course,s.plan_type, 
+			s.semester, s.thai_subject, s.eng_subject, s.credits, 
+			s.credit_value, s.lecture_hours, s.lab_hours, s.self_study_hours, s.non_credit,
+			s.compulsory_subject, s.condition, d.description_id, 
+			d.description_thai, d.description_eng, cl.clo_id, cl.clo
+		`,
+		From: `
+			FROM subjects s
+			LEFT JOIN courses c ON s.course_id = c.course_id
+			LEFT JOIN description d ON s.description_id = d.description_id
+			LEFT JOIN clo cl ON s.clo_id = cl.clo_id
+		`,
+		Conditions: conditions,
+		Args:       args,
+		OrderBy:    orderBy,
+	}
+
+	return pagination.Fetch(r.db, stmt, param.Params, func(row pagination.Scanner) (models.Subjects, error) {
 		var subject models.Subjects
-		err := rows.Scan(
+		err := row.Scan(
 			&subject.ID, &subject.SubjectID, &subject.CourseID, &subject.ThaiCourse,
 			&subject.PlanType, &subject.Semester, &subject.ThaiSubject,
-			&subject.EngSubject, &subject.Credits, &subject.CompulsorySubject,
+			&subject.EngSubject, &subject.Credits,
+			&subject.CreditValue, &subject.LectureHours, &subject.LabHours, &subject.SelfStudyHours, &subject.NonCredit,
+			&subject.CompulsorySubject,
 			&subject.Condition, &subject.DescriptionID, &subject.DescriptionThai,
 			&subject.DescriptionEng, &subject.CloID, &subject.CLO,
 		)
-		if err != nil {
-			return nil, err
-		}
-		subjects = append(subjects, subject)
-	}
-
-	return subjects, nil
+		return subject, err
+	})
 }
 
 func (r *subjectRepository) GetSubjectByID(id int) (*models.Subjects, error) {
+	return r.getSubjectByID(id, false)
+}
+
+// GetPublicSubjectByID คืน sql.ErrNoRows เมื่อหลักสูตรของรายวิชายังไม่เผยแพร่
+func (r *subjectRepository) GetPublicSubjectByID(id int) (*models.Subjects, error) {
+	return r.getSubjectByID(id, true)
+}
+
+func (r *subjectRepository) getSubjectByID(id int, publicOnly bool) (*models.Subjects, error) {
 	query := `
 		SELECT 
 			s.id, s.subject_id, c.course_id, c.thai_course,s.plan_type, 
 			s.semester, s.thai_subject, s.eng_subject, s.credits, 
+			s.credit_value, s.lecture_hours, s.lab_hours, s.self_study_hours, s.non_credit,
 			s.compulsory_subject, s.condition, d.description_id, 
 			d.description_thai, d.description_eng, cl.clo_id, cl.clo
 		FROM subjects s
@@ -123,14 +168,21 @@ func (r *subjectRepository) GetSubjectByID(id int) (*models.Subjects, error) {
 		LEFT JOIN clo cl ON s.clo_id = cl.clo_id
 		WHERE s.id = $1
 	`
+	args := []interface{}{id}
+	if publicOnly {
+		query += " AND c.status = ANY($2)"
+		args = append(args, pq.Array(coursemodels.PublicStatuses))
+	}
 
-	row := r.db.QueryRow(query, id)
+	row := r.db.QueryRow(query, args...)
 
 	var subject models.Subjects
 	err := row.Scan(
 		&subject.ID, &subject.SubjectID, &subject.CourseID, &subject.ThaiCourse,
 		&subject.PlanType, &subject.Semester, &subject.ThaiSubject,
-		&subject.EngSubject, &subject.Credits, &subject.CompulsorySubject,
+		&subject.EngSubject, &subject.Credits,
+		&subject.CreditValue, &subject.LectureHours, &subject.LabHours, &subject.SelfStudyHours, &subject.NonCredit,
+		&subject.CompulsorySubject,
 		&subject.Condition, &subject.DescriptionID, &subject.DescriptionThai,
 		&subject.DescriptionEng, &subject.CloID, &subject.CLO,
 	)
@@ -144,7 +196,8 @@ func (r *subjectRepository) GetSubjectByID(id int) (*models.Subjects, error) {
 	return &subject, nil
 }
 
-func (r *subjectRepository) CreateSubject(req models.SubjectsRequest) (*models.Subjects, error) {
+// CreateSubject บันทึกเงื่อนไขที่แปลงจากข้อความใน transaction เดียวกับรายวิชา
+func (r *subjectRepository) CreateSubject(req models.SubjectsRequest, prerequisites []models.PrerequisiteEdge) (*models.Subjects, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
 		return nil, err
@@ -181,18 +234,25 @@ func (r *subjectRepository) CreateSubject(req models.SubjectsRequest) (*models.S
 	err = tx.QueryRow(`
 		INSERT INTO subjects (
 			subject_id, course_id, plan_type, semester, thai_subject, eng_subject,
-			credits, compulsory_subject, condition, description_id, clo_id
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+			credits, compulsory_subject, condition, description_id, clo_id,
+			credit_value, lecture_hours, lab_hours, self_study_hours, non_credit
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)
 		RETURNING id
 	`,
 		req.SubjectID, req.CourseID, req.PlanType, req.Semester,
 		req.ThaiSubject, req.EngSubject, req.Credits, req.CompulsorySubject,
 		req.Condition, req.DescriptionID, req.CloID,
+		req.ParsedCredits.Value, req.ParsedCredits.LectureHours, req.ParsedCredits.LabHours,
+		req.ParsedCredits.SelfStudyHours, req.ParsedCredits.NonCredit,
 	).Scan(&subject.ID)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = syncParsedPrerequisites(tx, req.CourseID, req.SubjectID, prerequisites); err != nil {
+		return nil, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -200,13 +260,23 @@ func (r *subjectRepository) CreateSubject(req models.SubjectsRequest) (*models.S
 	return r.GetSubjectByID(subject.ID)
 }
 
-func (r *subjectRepository) UpdateSubject(id int, req models.SubjectsRequest) (*models.Subjects, error) {
+// UpdateSubject บันทึกเงื่อนไขที่แปลงจากข้อความใน transaction เดียวกับรายวิชา
+// ถ้าเปลี่ยนรหัสหรือหลักสูตร เส้นที่แปลงไว้ของรหัสเดิมจะถูกลบเมื่อไม่มีแถวอื่นใช้รหัสนั้นแล้ว
+func (r *subjectRepository) UpdateSubject(id int, req models.SubjectsRequest, prerequisites []models.PrerequisiteEdge) (*models.Subjects, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
+	var previousSubjectID, previousCourseID string
+	err = tx.QueryRow(
+		"SELECT subject_id, course_id FROM subjects WHERE id = $1 FOR UPDATE", id,
+	).Scan(&previousSubjectID, &previousCourseID)
+	if err != nil {
+		return nil, err
+	}
+
 	if req.DescriptionThai != nil || req.DescriptionEng != nil {
 		_, err = tx.Exec(`
 			INSERT INTO description (description_id, description_thai, description_eng)
@@ -237,16 +307,28 @@ func (r *subjectRepository) UpdateSubject(id int, req models.SubjectsRequest) (*
 		UPDATE subjects
 		SET subject_id=$1, course_id=$2, plan_type=$3, semester=$4, 
 		    thai_subject=$5, eng_subject=$6, credits=$7, compulsory_subject=$8, 
-		    condition=$9, description_id=$10, clo_id=$11
-		WHERE id=$12
+		    condition=$9, description_id=$10, clo_id=$11,
+		    credit_value=$12, lecture_hours=$13, lab_hours=$14, self_study_hours=$15, non_credit=$16
+		WHERE id=$17
 	`, req.SubjectID, req.CourseID, req.PlanType, req.Semester,
 		req.ThaiSubject, req.EngSubject, req.Credits, req.CompulsorySubject,
-		req.Condition, req.DescriptionID, req.CloID, id,
+		req.Condition, req.DescriptionID, req.CloID,
+		req.ParsedCredits.Value, req.ParsedCredits.LectureHours, req.ParsedCredits.LabHours,
+		req.ParsedCredits.SelfStudyHours, req.ParsedCredits.NonCredit, id,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	if previousSubjectID != req.SubjectID || previousCourseID != req.CourseID {
+		if err = removeUnusedParsedPrerequisites(tx, previousCourseID, previousSubjectID); err != nil {
+			return nil, err
+		}
+	}
+	if err = syncParsedPrerequisites(tx, req.CourseID, req.SubjectID, prerequisites); err != nil {
+		return nil, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -254,17 +336,57 @@ func (r *subjectRepository) UpdateSubject(id int, req models.SubjectsRequest) (*
 	return r.GetSubjectByID(id)
 }
 
+// DeleteSubject ลบรายวิชา และถ้าไม่มีแถวอื่นในหลักสูตรใช้รหัสเดียวกันแล้ว จะลบตารางที่อ้างถึงรหัสวิชาด้วย
+// ตารางเหล่านี้อ้างอิงด้วยรหัสวิชาจึงไม่มี foreign key มาลบให้
 func (r *subjectRepository) DeleteSubject(id int) error {
-	result, err := r.db.Exec("DELETE FROM subjects WHERE id = $1", id)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var subjectID, courseID string
+	err = tx.QueryRow(
+		"DELETE FROM subjects WHERE id = $1 RETURNING subject_id, course_id", id,
+	).Scan(&subjectID, &courseID)
 	if err != nil {
 		return err
 	}
-	rowsAffected, err := result.RowsAffected()
+
+	var used bool
+	err = tx.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM subjects WHERE course_id = $1 AND subject_id = $2)",
+		courseID, subjectID,
+	).Scan(&used)
 	if err != nil {
 		return err
 	}
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
+	if !used {
+		if err = removeSubjectReferences(tx, courseID, subjectID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// removeSubjectReferences ลบเส้นเงื่อนไขทั้งสองทิศ วิชาในโครงสร้างหลักสูตรและช่องในแผนการเรียนของหลักสูตร
+// ส่วนอาชีพใช้ร่วมกันหลายหลักสูตร จึงลบเมื่อไม่มีหลักสูตรใดมีรหัสนี้แล้ว
+func removeSubjectReferences(tx *sql.Tx, courseID string, subjectID string) error {
+	queries := []string{
+		`DELETE FROM subject_prerequisites
+		WHERE course_id = $1 AND (subject_id = $2 OR requires_id = $2)`,
+		`DELETE FROM curriculum_node_subjects
+		WHERE subject_id = $2 AND node_id IN (SELECT node_id FROM curriculum_nodes WHERE course_id = $1)`,
+		`DELETE FROM study_plan_subjects
+		WHERE subject_id = $2 AND plan_id IN (SELECT plan_id FROM study_plans WHERE course_id = $1)`,
+		`DELETE FROM career_subjects
+		WHERE subject_id = $2 AND NOT EXISTS (SELECT 1 FROM subjects WHERE subject_id = $2 AND course_id <> $1)`,
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query, courseID, subjectID); err != nil {
+			return err
+		}
 	}
 	return nil
 }
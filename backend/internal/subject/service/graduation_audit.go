@@ -0,0 +1,306 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"cpsu/internal/credits"
+	"cpsu/internal/subject/models"
+)
+
+var (
+	ErrInvalidGrade = errors.New("invalid grade")
+	ErrUnknownPlan  = errors.New("plan_type not found in course")
+)
+
+// หมวดในโครงสร้างหลักสูตรที่ใช้ในการตรวจ ตรงกับหมวดของ course_structure
+const (
+	auditCategoryCore          = "core"
+	auditCategoryMajorRequired = "major_required"
+	auditCategoryMajorElective = "major_elective"
+	auditCategoryFreeElective  = "free_elective"
+)
+
+// ค่าระดับคะแนนที่นำมาคิดเกรดเฉลี่ย
+var gradePoints = map[string]float64{
+	"A": 4, "B+": 3.5, "B": 3, "C+": 2.5, "C": 2, "D+": 1.5, "D": 1, "F": 0,
+}
+
+// ผลการเรียนที่ไม่คิดเกรดเฉลี่ย S และ P นับว่าผ่าน
+var ungradedPass = map[string]bool{
+	"S": true, "P": true, "U": false, "W": false, "I": false, "IP": false,
+}
+
+// วิชาที่มีผลต่อเกรดเฉลี่ยแต่ละขอบเขต
+var gpaScopeCategories = map[string][]string{
+	models.GPAScopeSpecific: {auditCategoryCore, auditCategoryMajorRequired, auditCategoryMajorElective},
+	models.GPAScopeMajor:    {auditCategoryMajorRequired, auditCategoryMajorElective},
+}
+
+type gpaSum struct {
+	points  float64
+	credits int
+}
+
+func (g gpaSum) value() *float64 {
+	if g.credits == 0 {
+		return nil
+	}
+	gpa := math.Round(g.points/float64(g.credits)*100) / 100
+	return &gpa
+}
+
+// GetGraduationAudit ตรวจว่านักศึกษาเหลือเงื่อนไขใดบ้างจากวิชาที่เรียนแล้ว โดยไม่บันทึกข้อมูลใด
+//
+// วิชาที่เรียนซ้ำนับหน่วยกิตครั้งเดียวแต่นำเกรดทุกครั้งมาคิดเกรดเฉลี่ย วิชาที่ไม่อยู่ในโครงสร้างหลักสูตร
+// และหน่วยกิตที่เกินในหมวดวิชาเฉพาะด้านเลือกนับเป็นวิชาเลือกเสรี
+func (s *subjectService) GetGraduationAudit(courseID string, req models.GraduationAuditRequest) (*models.GraduationAudit, error) {
+	requirements, err := s.repo.GetGraduationRequirements(courseID)
+	if err != nil {
+		return nil, err
+	}
+	subjects, err := s.repo.GetAuditSubjects(courseID)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.repo.GetCategoryCredits(courseID)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := s.repo.GetPrerequisiteEdges(courseID)
+	if err != nil {
+		return nil, err
+	}
+	planSubjects := subjects
+	if planType := strings.TrimSpace(req.PlanType); planType != "" {
+		placements, err := s.repo.GetPlanSemesters(courseID)
+		if err != nil {
+			return nil, err
+		}
+		planSubjects, err = subjectsInPlan(subjects, placements, planType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	byCode := map[string]models.AuditSubject{}
+	for _, subject := range subjects {
+		byCode[subject.SubjectID] = subject
+	}
+
+	audit := &models.GraduationAudit{
+		CourseID:      courseID,
+		Categories:    []models.CategoryAudit{},
+		GraduationReq: requirements.GraduationReq,
+		Eligible:      []models.EligibleSubject{},
+		Unrecognized:  []string{},
+	}
+
+	passed := map[string]bool{}
+	earned := map[string]int{}
+	completed := map[string][]string{}
+	overall := gpaSum{}
+	byCategory := map[string]gpaSum{}
+
+	for _, entry := range req.Completed {
+		code := strings.ToUpper(strings.TrimSpace(entry.SubjectID))
+		grade := strings.ToUpper(strings.TrimSpace(entry.Grade))
+
+		points, graded := gradePoints[grade]
+		pass, ungraded := ungradedPass[grade]
+		if !graded && !ungraded {
+			return nil, fmt.Errorf("%w %q for %s", ErrInvalidGrade, entry.Grade, code)
+		}
+		if graded {
+			pass = grade != "F"
+		}
+
+		subject, inCourse := byCode[code]
+		counted := 0
+		switch {
+		case inCourse && !subject.NonCredit:
+			counted = subject.CreditValue
+		case !inCourse && entry.Credits != nil:
+			counted = *entry.Credits
+		case !inCourse:
+			if !containsString(audit.Unrecognized, code) {
+				audit.Unrecognized = append(audit.Unrecognized, code)
+			}
+			continue
+		}
+		category := subject.Category
+		if category == "" {
+			category = auditCategoryFreeElective
+		}
+
+		if graded && counted > 0 {
+			overall.points += points * float64(counted)
+			overall.credits += counted
+			sum := byCategory[category]
+			sum.points += points * float64(counted)
+			sum.credits += counted
+			byCategory[category] = sum
+		}
+
+		if pass && !passed[code] {
+			passed[code] = true
+			earned[category] += counted
+			completed[category] = append(completed[category], code)
+			audit.EarnedCredits += counted
+		}
+	}
+
+	// หน่วยกิตที่เกินในวิชาเฉพาะด้านเลือกนับเป็นวิชาเลือกเสรี
+	for _, c := range categories {
+		if c.Category == auditCategoryMajorElective && earned[c.Category] > c.MinCredits {
+			earned[auditCategoryFreeElective] += earned[c.Category] - c.MinCredits
+			earned[c.Category] = c.MinCredits
+		}
+	}
+
+	categoryMin := 0
+	audit.Complete = true
+	for _, c := range categories {
+		category := models.CategoryAudit{
+			Category:      c.Category,
+			NameTH:        c.NameTH,
+			NameEN:        c.NameEN,
+			MinCredits:    c.MinCredits,
+			EarnedCredits: earned[c.Category],
+			Completed:     completed[c.Category],
+			Remaining:     []models.AuditSubject{},
+		}
+		if category.Completed == nil {
+			category.Completed = []string{}
+		}
+		if category.EarnedCredits < category.MinCredits {
+			category.MissingCredits = category.MinCredits - category.EarnedCredits
+		}
+		if c.Category == auditCategoryCore || c.Category == auditCategoryMajorRequired {
+			for _, subject := range planSubjects {
+				if subject.Category == c.Category && !passed[subject.SubjectID] {
+					category.Remaining = append(category.Remaining, subject)
+				}
+			}
+		}
+		if category.MissingCredits > 0 || len(category.Remaining) > 0 {
+			audit.Complete = false
+		}
+		categoryMin += c.MinCredits
+		audit.Categories = append(audit.Categories, category)
+	}
+
+	audit.RequiredCredits = credits.ParseTotal(requirements.Credits)
+	if categoryMin > audit.RequiredCredits {
+		audit.RequiredCredits = categoryMin
+	}
+	if audit.EarnedCredits < audit.RequiredCredits {
+		audit.MissingCredits = audit.RequiredCredits - audit.EarnedCredits
+		audit.Complete = false
+	}
+
+	audit.GPA = overall.value()
+	audit.GPARules = ParseGPARules(requirements.GraduationReq)
+	for i := range audit.GPARules {
+		rule := &audit.GPARules[i]
+		sum := overall
+		if scope, ok := gpaScopeCategories[rule.Scope]; ok {
+			sum = gpaSum{}
+			for _, category := range scope {
+				sum.points += byCategory[category].points
+				sum.credits += byCategory[category].credits
+			}
+		}
+		rule.GPA = sum.value()
+		rule.Met = rule.GPA != nil && *rule.GPA >= rule.MinGPA
+		if !rule.Met {
+			audit.Complete = false
+		}
+	}
+
+	audit.Eligible = eligibleSubjects(planSubjects, edges, passed)
+	return audit, nil
+}
+
+// subjectsInPlan คัดรายวิชาของแผนที่เลือก แผนรองอย่างสหกิจศึกษามักระบุเฉพาะภาคการศึกษาที่ต่างจากแผนหลัก
+// จึงนับวิชาที่อยู่ในแผนนั้น และวิชาของแผนอื่นที่อยู่ในภาคการศึกษาที่แผนนั้นไม่ได้ระบุ
+func subjectsInPlan(subjects []models.AuditSubject, placements []models.PlanSemester, planType string) ([]models.AuditSubject, error) {
+	planSemesters := map[string]bool{}
+	for _, p := range placements {
+		if p.PlanType == planType {
+			planSemesters[p.Semester] = true
+		}
+	}
+	if len(planSemesters) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPlan, planType)
+	}
+
+	inPlan := map[string]bool{}
+	for _, p := range placements {
+		if p.PlanType == planType || !planSemesters[p.Semester] {
+			inPlan[p.SubjectID] = true
+		}
+	}
+
+	filtered := []models.AuditSubject{}
+	for _, subject := range subjects {
+		if inPlan[subject.SubjectID] {
+			filtered = append(filtered, subject)
+		}
+	}
+	return filtered, nil
+}
+
+// eligibleSubjects คือวิชาที่ยังไม่ผ่านและผ่านวิชาบังคับก่อนครบทุกกลุ่มแล้ว
+// กลุ่มที่เป็นวิชาเรียนพร้อมกันไม่ตัดสิทธิ์ แต่บอกไว้ใน take_with
+func eligibleSubjects(subjects []models.AuditSubject, edges []models.PrerequisiteEdge, passed map[string]bool) []models.EligibleSubject {
+	groups := map[string][]models.PrerequisiteEdge{}
+	var order []string
+	for _, e := range edges {
+		key := groupKey(e)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], e)
+	}
+
+	eligible := []models.EligibleSubject{}
+	for _, subject := range subjects {
+		if passed[subject.SubjectID] {
+			continue
+		}
+		item := models.EligibleSubject{AuditSubject: subject, TakeWith: []string{}}
+		ok := true
+		for _, key := range order {
+			group := groups[key]
+			if group[0].To != subject.SubjectID {
+				continue
+			}
+			item.HasPrerequisites = true
+
+			satisfied := false
+			for _, e := range group {
+				if passed[e.From] {
+					satisfied = true
+					break
+				}
+			}
+			if satisfied {
+				continue
+			}
+			if group[0].Kind != models.PrerequisiteKindCorequisite {
+				ok = false
+				break
+			}
+			for _, e := range group {
+				item.TakeWith = append(item.TakeWith, e.From)
+			}
+		}
+		if ok {
+			eligible = append(eligible, item)
+		}
+	}
+	return eligible
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cpsu/internal/seedtest"
+	"cpsu/internal/subject/models"
+	"cpsu/internal/subject/repository"
+)
+
+func TestParseGPARules(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"empty", "", []string{}},
+		{"no gpa", "สอบผ่านการสอบปากเปล่าขั้นสุดท้าย", []string{}},
+		{"overall", "เกรดเฉลี่ยไม่ต่ำกว่า 2.00", []string{"overall 2"}},
+		{"cumulative", "เกรดเฉลี่ยสะสมไม่น้อยกว่า 2.50", []string{"overall 2.5"}},
+		{"specific", "เกรดเฉลี่ยวิชาเฉพาะไม่ต่ำกว่า 2.00", []string{"specific 2"}},
+		{"major", "เกรดเฉลี่ยสะสมของทุกรายวิชาในวิชาเฉพาะด้านในหมวดวิชาเฉพาะไม่น้อยกว่า 2.25", []string{"major 2.25"}},
+		{"integer", "เกรดเฉลี่ยไม่ต่ำกว่า 2", []string{"overall 2"}},
+		{"space before number", "เกรดเฉลี่ยไม่ต่ำกว่า  3.00", []string{"overall 3"}},
+		{
+			"several scopes",
+			"เกรดเฉลี่ยไม่ต่ำกว่า 2.00 เกรดเฉลี่ยวิชาเฉพาะไม่ต่ำกว่า 2.50",
+			[]string{"overall 2", "specific 2.5"},
+		},
+		{"first rule of a scope", "เกรดเฉลี่ยไม่ต่ำกว่า 2.00 และเกรดเฉลี่ยไม่ต่ำกว่า 3.00", []string{"overall 2"}},
+		{"above 4", "เกรดเฉลี่ยไม่ต่ำกว่า 5.00", []string{}},
+		{"zero", "เกรดเฉลี่ยไม่ต่ำกว่า 0", []string{}},
+		{"number between", "เกรดเฉลี่ย 8 ภาคการศึกษาไม่ต่ำกว่า 2.00", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, rule := range ParseGPARules(tt.text) {
+				got = append(got, fmt.Sprintf("%s %g", rule.Scope, rule.MinGPA))
+				if !strings.HasPrefix(rule.Text, "เกรดเฉลี่ย") {
+					t.Errorf("rule text = %q, want the matched sentence", rule.Text)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseGPARules(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGPARulesSeed(t *testing.T) {
+	want := map[string][]string{
+		"BSCS65": {"overall 2", "specific 2"},
+		"BSIT65": {"overall 2", "specific 2"},
+		"BSDS65": {"overall 2", "major 2"},
+		"MSIT66": {},
+		"DSIT66": {},
+	}
+
+	for _, row := range seedtest.CourseRows(t) {
+		got := []string{}
+		for _, rule := range ParseGPARules(row["graduation_req"]) {
+			got = append(got, fmt.Sprintf("%s %g", rule.Scope, rule.MinGPA))
+		}
+
+		if expected, ok := want[row["course_id"]]; ok {
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("%s: ParseGPARules = %v, want %v", row["course_id"], got, expected)
+			}
+			continue
+		}
+		if strings.Contains(row["graduation_req"], "เกรดเฉลี่ย") && len(got) == 0 {
+			t.Errorf("%s: no gpa rule in %q", row["course_id"], row["graduation_req"])
+		}
+	}
+}
+
+func TestSubjectsInPlan(t *testing.T) {
+	subjects := []models.AuditSubject{{SubjectID: "A"}, {SubjectID: "B"}, {SubjectID: "C"}, {SubjectID: "D"}, {SubjectID: "E"}}
+	// แผนสหกิจศึกษาระบุเฉพาะภาค 4/2 ส่วนภาคอื่นใช้วิชาของแผนโครงงาน
+	placements := []models.PlanSemester{
+		{SubjectID: "A", PlanType: "โครงงาน", Semester: "1/1"},
+		{SubjectID: "B", PlanType: "โครงงาน", Semester: "4/2"},
+		{SubjectID: "C", PlanType: "สหกิจศึกษา", Semester: "4/2"},
+		{SubjectID: "D", PlanType: "โครงงาน", Semester: "4/1"},
+	}
+
+	tests := []struct {
+		planType string
+		want     []string
+		wantErr  error
+	}{
+		{"โครงงาน", []string{"A", "B", "D"}, nil},
+		{"สหกิจศึกษา", []string{"A", "C", "D"}, nil},
+		{"วิจัย", nil, ErrUnknownPlan},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.planType, func(t *testing.T) {
+			filtered, err := subjectsInPlan(subjects, placements, tt.planType)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("subjectsInPlan error = %v, want %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, subject := range filtered {
+				got = append(got, subject.SubjectID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("subjectsInPlan(%s) = %v, want %v", tt.planType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEligibleSubjects(t *testing.T) {
+	subjects := []models.AuditSubject{{SubjectID: "S1"}, {SubjectID: "S2"}, {SubjectID: "S3"}, {SubjectID: "S4"}, {SubjectID: "S5"}, {SubjectID: "S6"}}
+	prerequisite := func(from, to string, group int) models.PrerequisiteEdge {
+		return models.PrerequisiteEdge{From: from, To: to, Kind: models.PrerequisiteKindPrerequisite, Group: group}
+	}
+	edges := []models.PrerequisiteEdge{
+		prerequisite("S1", "S2", 1),
+		// S3 ต้องผ่าน S1 หรือ S2 อย่างใดอย่างหนึ่ง
+		prerequisite("S1", "S3", 1),
+		prerequisite("S2", "S3", 1),
+		// S4 ต้องผ่านทั้ง S1 และ S2
+		prerequisite("S1", "S4", 1),
+		prerequisite("S2", "S4", 2),
+		// S5 เรียนพร้อม S6 ได้
+		{From: "S6", To: "S5", Kind: models.PrerequisiteKindCorequisite, Group: 1},
+	}
+
+	// ผลลัพธ์ย่อเป็นรหัสวิชา ? คือมีวิชาบังคับก่อน และวงเล็บคือ take_with
+	tests := []struct {
+		name   string
+		passed []string
+		want   []string
+	}{
+		{"nothing passed", nil, []string{"S1", "S5?(S6)", "S6"}},
+		{"one alternative", []string{"S1"}, []string{"S2?", "S3?", "S5?(S6)", "S6"}},
+		{"every group", []string{"S1", "S2"}, []string{"S3?", "S4?", "S5?(S6)", "S6"}},
+		{"other alternative", []string{"S2"}, []string{"S1", "S3?", "S5?(S6)", "S6"}},
+		{"corequisite passed", []string{"S6"}, []string{"S1", "S5?"}},
+		{"everything passed", []string{"S1", "S2", "S3", "S4", "S5", "S6"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passed := map[string]bool{}
+			for _, code := range tt.passed {
+				passed[code] = true
+			}
+
+			got := []string{}
+			for _, item := range eligibleSubjects(subjects, edges, passed) {
+				id := item.SubjectID
+				if item.HasPrerequisites {
+					id += "?"
+				}
+				if len(item.TakeWith) > 0 {
+					id += "(" + strings.Join(item.TakeWith, ",") + ")"
+				}
+				got = append(got, id)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("eligibleSubjects(%v) = %v, want %v", tt.passed, got, tt.want)
+			}
+		})
+	}
+}
+
+// auditRepo คืนหลักสูตรขนาดเล็กชุดเดียว เมธอดอื่นของ SubjectRepository ไม่ได้ใช้ในเทสต์นี้
+type auditRepo struct {
+	repository.SubjectRepository
+}
+
+func (auditRepo) GetGraduationRequirements(courseID string) (*models.GraduationRequirements, error) {
+	return &models.GraduationRequirements{
+		Credits:       "จำนวนไม่น้อยกว่า 18 หน่วยกิต",
+		GraduationReq: "เกรดเฉลี่ยไม่ต่ำกว่า 2.00 เกรดเฉลี่ยวิชาเฉพาะไม่ต่ำกว่า 2.00",
+	}, nil
+}
+
+func (auditRepo) GetAuditSubjects(courseID string) ([]models.AuditSubject, error) {
+	return []models.AuditSubject{
+		{SubjectID: "C1", CreditValue: 3, Category: auditCategoryCore},
+		{SubjectID: "R1", CreditValue: 3, Category: auditCategoryMajorRequired},
+		{SubjectID: "E1", CreditValue: 3, Category: auditCategoryMajorElective},
+		{SubjectID: "E2", CreditValue: 3, Category: auditCategoryMajorElective},
+		{SubjectID: "G1", CreditValue: 3},
+		{SubjectID: "N1", CreditValue: 1, NonCredit: true, Category: auditCategoryCore},
+	}, nil
+}
+
+func (auditRepo) GetCategoryCredits(courseID string) ([]models.CategoryCredits, error) {
+	return []models.CategoryCredits{
+		{Category: auditCategoryCore, MinCredits: 3},
+		{Category: auditCategoryMajorRequired, MinCredits: 3},
+		{Category: auditCategoryMajorElective, MinCredits: 3},
+		{Category: auditCategoryFreeElective, MinCredits: 6},
+	}, nil
+}
+
+func (auditRepo) GetPrerequisiteEdges(courseID string) ([]models.PrerequisiteEdge, error) {
+	return nil, nil
+}
+
+func TestGetGraduationAudit(t *testing.T) {
+	svc := NewSubjectService(auditRepo{}, nil)
+	done := func(code, grade string) models.CompletedSubject {
+		return models.CompletedSubject{SubjectID: code, Grade: grade}
+	}
+	outside := func(code, grade string, credits int) models.CompletedSubject {
+		return models.CompletedSubject{SubjectID: code, Grade: grade, Credits: &credits}
+	}
+
+	tests := []struct {
+		name         string
+		completed    []models.CompletedSubject
+		wantEarned   map[string]int
+		wantTotal    int
+		wantGPA      string
+		wantComplete bool
+		wantErr      error
+	}{
+		{
+			"retake after fail counts credits once and both grades",
+			[]models.CompletedSubject{done("C1", "F"), done("c1 ", "b")},
+			map[string]int{auditCategoryCore: 3},
+			3, "1.50", false, nil,
+		},
+		{
+			"retake after pass",
+			[]models.CompletedSubject{done("C1", "A"), done("C1", "B")},
+			map[string]int{auditCategoryCore: 3},
+			3, "3.50", false, nil,
+		},
+		{
+			"major elective overflow becomes free elective",
+			[]models.CompletedSubject{done("E1", "A"), done("E2", "B")},
+			map[string]int{auditCategoryMajorElective: 3, auditCategoryFreeElective: 3},
+			6, "3.50", false, nil,
+		},
+		{
+			"subject outside structure and outside course",
+			[]models.CompletedSubject{done("G1", "C"), outside("X1", "A", 2), done("X2", "A")},
+			map[string]int{auditCategoryFreeElective: 5},
+			5, "2.80", false, nil,
+		},
+		{
+			"ungraded pass and non-credit subject",
+			[]models.CompletedSubject{done("R1", "S"), done("N1", "A")},
+			map[string]int{auditCategoryMajorRequired: 3},
+			3, "none", false, nil,
+		},
+		{
+			"complete",
+			[]models.CompletedSubject{
+				done("C1", "B"), done("R1", "B"), done("E1", "A"), done("E2", "A"),
+				done("G1", "C"), outside("X1", "B", 3), done("N1", "S"),
+			},
+			map[string]int{auditCategoryCore: 3, auditCategoryMajorRequired: 3, auditCategoryMajorElective: 3, auditCategoryFreeElective: 9},
+			18, "3.17", true, nil,
+		},
+		{"invalid grade", []models.CompletedSubject{done("C1", "E")}, nil, 0, "", false, ErrInvalidGrade},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit, err := svc.GetGraduationAudit("BSCS65", models.GraduationAuditRequest{Completed: tt.completed})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetGraduationAudit error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			earned := map[string]int{}
+			for _, c := range audit.Categories {
+				if c.EarnedCredits > 0 {
+					earned[c.Category] = c.EarnedCredits
+				}
+			}
+			if !reflect.DeepEqual(earned, tt.wantEarned) {
+				t.Errorf("earned by category = %v, want %v", earned, tt.wantEarned)
+			}
+
+			gpa := "none"
+			if audit.GPA != nil {
+				gpa = fmt.Sprintf("%.2f", *audit.GPA)
+			}
+			if audit.EarnedCredits != tt.wantTotal || gpa != tt.wantGPA || audit.Complete != tt.wantComplete {
+				t.Errorf("earned %d gpa %s complete %v, want %d %s %v",
+					audit.EarnedCredits, gpa, audit.Complete, tt.wantTotal, tt.wantGPA, tt.wantComplete)
+			}
+		})
+	}
+}
+
+func TestGetGraduationAuditUnrecognized(t *testing.T) {
+	audit, err := NewSubjectService(auditRepo{}, nil).GetGraduationAudit("BSCS65", models.GraduationAuditRequest{
+		Completed: []models.CompletedSubject{{SubjectID: "X2", Grade: "A"}, {SubjectID: "x2", Grade: "B"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(audit.Unrecognized, []string{"X2"}) || audit.EarnedCredits != 0 || audit.GPA != nil {
+		t.Errorf("audit = %v %d %v, want [X2] 0 <nil>", audit.Unrecognized, audit.EarnedCredits, audit.GPA)
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cpsu/internal/subject/models"
+)
+
+// เกณฑ์เกรดเฉลี่ยในข้อความ graduation_req เช่น "เกรดเฉลี่ยวิชาเฉพาะไม่ต่ำกว่า 2.00"
+// ข้อความระหว่าง "เกรดเฉลี่ย" กับ "ไม่ต่ำกว่า" ใช้บอกขอบเขตของวิชาที่นำมาคิด
+var gpaRulePattern = regexp.MustCompile(`เกรดเฉลี่ย([^\d]{0,80}?)(?:ไม่ต่ำกว่า|ไม่น้อยกว่า)\s*(\d+(?:\.\d+)?)`)
+
+// ParseGPARules อ่านเกณฑ์เกรดเฉลี่ยจากข้อความ ขอบเขตเดียวกันใช้เกณฑ์แรกที่พบ
+// ข้อความที่อ่านไม่ได้จะได้ slice ว่าง
+func ParseGPARules(text string) []models.GPARuleAudit {
+	rules := []models.GPARuleAudit{}
+	seen := map[string]bool{}
+	for _, m := range gpaRulePattern.FindAllStringSubmatch(text, -1) {
+		min, err := strconv.ParseFloat(m[2], 64)
+		if err != nil || min <= 0 || min > 4 {
+			continue
+		}
+
+		scope := models.GPAScopeOverall
+		switch {
+		case strings.Contains(m[1], "เฉพาะด้าน"):
+			scope = models.GPAScopeMajor
+		case strings.Contains(m[1], "วิชาเฉพาะ"):
+			scope = models.GPAScopeSpecific
+		}
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+
+		rules = append(rules, models.GPARuleAudit{
+			Scope:  scope,
+			MinGPA: min,
+			Text:   strings.TrimSpace(m[0]),
+		})
+	}
+	return rules
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"sort"
+
+	"cpsu/internal/subject/models"
+)
+
+// findPrerequisiteCycles หาวงของวิชาบังคับก่อน แต่ละวงเริ่มและจบที่วิชาเดียวกัน
+// วิชาที่เรียนพร้อมกันได้ไม่นับ เพราะสองวิชาที่เป็น corequisite กันเองยังลงทะเบียนพร้อมกันได้
+func findPrerequisiteCycles(edges []models.PrerequisiteEdge) [][]string {
+	adjacency := map[string][]string{}
+	for _, e := range edges {
+		if e.Kind == models.PrerequisiteKindPrerequisite {
+			adjacency[e.From] = append(adjacency[e.From], e.To)
+		}
+	}
+	var starts []string
+	for from, tos := range adjacency {
+		sort.Strings(tos)
+		starts = append(starts, from)
+	}
+	sort.Strings(starts)
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	var stack []string
+	cycles := [][]string{}
+	seen := map[string]bool{}
+
+	var visit func(node string)
+	visit = func(node string) {
+		state[node] = visiting
+		stack = append(stack, node)
+		for _, next := range adjacency[node] {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case visiting:
+				start := len(stack) - 1
+				for stack[start] != next {
+					start--
+				}
+				cycle := append(append([]string{}, stack[start:]...), next)
+				if key := cycleKey(cycle); !seen[key] {
+					seen[key] = true
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[node] = done
+	}
+	for _, node := range starts {
+		if state[node] == unvisited {
+			visit(node)
+		}
+	}
+	return cycles
+}
+
+// cycleKey หมุนวงให้เริ่มที่รหัสน้อยสุด เพื่อไม่นับวงเดียวกันซ้ำ
+func cycleKey(cycle []string) string {
+	nodes := cycle[:len(cycle)-1]
+	min := 0
+	for i, n := range nodes {
+		if n < nodes[min] {
+			min = i
+		}
+	}
+	key := ""
+	for i := range nodes {
+		key += nodes[(min+i)%len(nodes)] + ">"
+	}
+	return key
+}
+
+// prerequisiteLevels คือเส้นทางยาวสุดจากวิชาที่ไม่มีเงื่อนไข วิชาที่เรียนพร้อมกันได้อยู่ชั้นเดียวกัน
+// เส้นที่ย้อนกลับเข้าวงจะถูกข้ามเพื่อให้ทุกวิชายังมีชั้น
+func prerequisiteLevels(edges []models.PrerequisiteEdge) map[string]int {
+	incoming := map[string][]models.PrerequisiteEdge{}
+	for _, e := range edges {
+		incoming[e.To] = append(incoming[e.To], e)
+	}
+
+	levels := map[string]int{}
+	resolving := map[string]bool{}
+	var level func(node string) int
+	level = func(node string) int {
+		if l, ok := levels[node]; ok {
+			return l
+		}
+		if resolving[node] {
+			return 0
+		}
+		resolving[node] = true
+		l := 0
+		for _, e := range incoming[node] {
+			candidate := level(e.From)
+			if e.Kind == models.PrerequisiteKindPrerequisite {
+				candidate++
+			}
+			if candidate > l {
+				l = candidate
+			}
+		}
+		resolving[node] = false
+		levels[node] = l
+		return l
+	}
+	for _, e := range edges {
+		level(e.From)
+		level(e.To)
+	}
+	return levels
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+
+	"cpsu/internal/subject/models"
+)
+
+// รหัสวิชาในข้อความเดิมเขียนได้ทั้ง "517 111", "517111" และ "SU201" ถ้ามี * นำหน้าคือวิชาที่เรียนพร้อมกันได้
+var subjectCodePattern = regexp.MustCompile(`(\*?)\s*\b((?i:[A-Z]{2,4}) ?\d{3}|\d{3} ?\d{3})\b`)
+
+// ข้อความ "หรือ" แบ่งทางเลือก ส่วน (1) (2) ที่มีหลายวิชาในทางเลือกเดียวคือต้องผ่านทุกวิชา
+const (
+	alternativeSeparator = "หรือ"
+	concurrentMarker     = "พร้อมกัน"
+
+	// maxPrerequisiteClauses กันข้อความที่มีทางเลือกซ้อนกันมากเกินกว่าจะแปลงได้อย่างมีความหมาย
+	maxPrerequisiteClauses = 64
+)
+
+// ParsePrerequisiteText แปลงข้อความ compulsory_subject เป็นกลุ่มเงื่อนไข ต้องผ่านทุกกลุ่ม กลุ่มละหนึ่งวิชา
+// ok เป็น false เมื่อข้อความไม่ว่างแต่อ่านรหัสวิชาไม่ได้ ข้อความว่างได้ groups ว่างและ ok เป็น true
+func ParsePrerequisiteText(text string) ([][]models.PrerequisiteOption, bool) {
+	if strings.TrimSpace(text) == "" {
+		return nil, true
+	}
+
+	concurrent := strings.Contains(text, concurrentMarker)
+	starred := strings.Contains(text, "*")
+
+	var alternatives [][]models.PrerequisiteOption
+	for _, part := range strings.Split(text, alternativeSeparator) {
+		var options []models.PrerequisiteOption
+		for _, m := range subjectCodePattern.FindAllStringSubmatch(part, -1) {
+			option := models.PrerequisiteOption{
+				SubjectID: strings.ToUpper(strings.ReplaceAll(m[2], " ", "")),
+				Kind:      models.PrerequisiteKindPrerequisite,
+			}
+			if concurrent && (m[1] == "*" || !starred) {
+				option.Kind = models.PrerequisiteKindCorequisite
+			}
+			options = appendOption(options, option)
+		}
+		if len(options) > 0 {
+			alternatives = append(alternatives, options)
+		}
+	}
+	if len(alternatives) == 0 {
+		return nil, false
+	}
+
+	// ทางเลือกคือ OR ของ AND แปลงเป็น AND ของ OR โดยกระจายทีละทางเลือก
+	clauses := [][]models.PrerequisiteOption{nil}
+	for _, alternative := range alternatives {
+		var next [][]models.PrerequisiteOption
+		for _, clause := range clauses {
+			for _, option := range alternative {
+				next = append(next, appendOption(append([]models.PrerequisiteOption(nil), clause...), option))
+			}
+		}
+		if len(next) > maxPrerequisiteClauses {
+			return nil, false
+		}
+		clauses = next
+	}
+
+	return absorbClauses(clauses), true
+}
+
+func appendOption(options []models.PrerequisiteOption, option models.PrerequisiteOption) []models.PrerequisiteOption {
+	for _, o := range options {
+		if o.SubjectID == option.SubjectID {
+			return options
+		}
+	}
+	return append(options, option)
+}
+
+// absorbClauses ตัดกลุ่มที่มีกลุ่มอื่นเป็นส่วนย่อย เช่น (A) กับ (A หรือ C) เหลือแค่ (A)
+func absorbClauses(clauses [][]models.PrerequisiteOption) [][]models.PrerequisiteOption {
+	subset := func(a, b []models.PrerequisiteOption) bool {
+		for _, x := range a {
+			found := false
+			for _, y := range b {
+				if x.SubjectID == y.SubjectID {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+
+	var result [][]models.PrerequisiteOption
+	for i, clause := range clauses {
+		absorbed := false
+		for j, other := range clauses {
+			if i != j && subset(other, clause) && (len(other) < len(clause) || j < i) {
+				absorbed = true
+				break
+			}
+		}
+		if !absorbed {
+			result = append(result, clause)
+		}
+	}
+	return result
+}
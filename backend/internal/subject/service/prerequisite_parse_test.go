@@ -0,0 +1,136 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"cpsu/internal/seedtest"
+	"cpsu/internal/subject/models"
+)
+
+// clauseIDs ย่อผลลัพธ์เป็นรหัสวิชา วิชาที่เรียนพร้อมกันได้มี * ต่อท้าย
+func clauseIDs(groups [][]models.PrerequisiteOption) [][]string {
+	if groups == nil {
+		return nil
+	}
+	result := [][]string{}
+	for _, group := range groups {
+		ids := []string{}
+		for _, option := range group {
+			id := option.SubjectID
+			if option.Kind == models.PrerequisiteKindCorequisite {
+				id += "*"
+			}
+			ids = append(ids, id)
+		}
+		result = append(result, ids)
+	}
+	return result
+}
+
+func TestParsePrerequisiteText(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		want   [][]string
+		wantOK bool
+	}{
+		{"empty", "", nil, true},
+		{"blank", "  \t", nil, true},
+		{"no subject code", "ความเห็นชอบของภาควิชา", nil, false},
+		{
+			"corequisite marker",
+			"* 511 100 ความรู้พื้นฐานสำหรับแคลคูลัส  * อาจเรียนพร้อมกันได้",
+			[][]string{{"511100*"}}, true,
+		},
+		{"letter code", "SU201 ภาษาอังกฤษในยุคดิจิทัล", [][]string{{"SU201"}}, true},
+		{"lowercase letter code", "su201 ภาษาอังกฤษในยุคดิจิทัล", [][]string{{"SU201"}}, true},
+		{"code without space", "517111 การเขียนโปรแกรมคอมพิวเตอร์", [][]string{{"517111"}}, true},
+		{
+			"two alternatives",
+			"517 111 การเขียนโปรแกรมคอมพิวเตอร์สำหรับนักวิทยาการข้อมูล หรือ\t517 121 ทักษะการเขียนโปรแกรมคอมพิวเตอร์ 1",
+			[][]string{{"517111", "517121"}}, true,
+		},
+		{
+			"three alternatives",
+			"511 101 แคลคูลัส 1 หรือ 511 108 แคลคูลัสสำหรับนักวิทยาศาสตร์คณนา 1 หรือ 511 110 แคลคูลัสสำหรับการวิเคราะห์ข้อมูล 1",
+			[][]string{{"511101", "511108", "511110"}}, true,
+		},
+		{
+			"numbered groups",
+			"(1) 517 261 หลักการระบบฐานข้อมูลและการออกแบบ หรือ (2) 520 221 ระบบฐานข้อมูล 520 223 ภาษาเอสคิวแอลเบื้องต้น",
+			[][]string{{"517261", "520221"}, {"517261", "520223"}}, true,
+		},
+		{
+			"numbered groups sharing a subject",
+			"(1) 517 122 ทักษะการเขียนโปรแกรมคอมพิวเตอร์ 2 517 242 การพัฒนาโปรแกรมประยุกต์บนเว็บ หรือ (2)  517 122 ทักษะการเขียนโปรแกรมคอมพิวเตอร์ 2 520 112 เว็บเทคโนโลยี",
+			[][]string{{"517122"}, {"517242", "520112"}}, true,
+		},
+		{
+			"all required",
+			"520 393 การเตรียมโครงงานวิจัย 520 394 การเตรียมความพร้อมสหกิจศึกษา",
+			[][]string{{"520393"}, {"520394"}}, true,
+		},
+		{
+			"all required on separate lines",
+			"517 111 การเขียนโปรแกรมคอมพิวเตอร์สำหรับนักวิทยาการข้อมูล\n515 273 สถิติสำหรับวิทยาการข้อมูล",
+			[][]string{{"517111"}, {"515273"}}, true,
+		},
+		{
+			"concurrent without marker",
+			"517 111 การเขียนโปรแกรม 515 273 สถิติ อาจเรียนพร้อมกันได้",
+			[][]string{{"517111*"}, {"515273*"}}, true,
+		},
+		{
+			"marker on one subject",
+			"517 111 การเขียนโปรแกรม *515 273 สถิติ * อาจเรียนพร้อมกันได้",
+			[][]string{{"517111"}, {"515273*"}}, true,
+		},
+		{
+			"star without concurrent text",
+			"* 511 100 ความรู้พื้นฐานสำหรับแคลคูลัส",
+			[][]string{{"511100"}}, true,
+		},
+		{"duplicate code", "517 111 การเขียนโปรแกรม 517111", [][]string{{"517111"}}, true},
+		{
+			"too many clauses",
+			strings.Repeat("511 101 แคลคูลัส 511 102 แคลคูลัส 2 หรือ ", 6) + "511 101 แคลคูลัส 511 103 แคลคูลัส 3",
+			nil, false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, ok := ParsePrerequisiteText(tt.text)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if got := clauseIDs(groups); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groups = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// ข้อความ compulsory_subject ทุกแถวในไฟล์ seed ต้องแปลงได้
+func TestParsePrerequisiteTextSeedRows(t *testing.T) {
+	for _, row := range seedtest.SubjectRows(t) {
+		text := row["compulsory_subject"]
+		groups, ok := ParsePrerequisiteText(text)
+		if !ok {
+			t.Errorf("%s %s: cannot parse %q", row["file"], row["subject_id"], text)
+			continue
+		}
+		if strings.TrimSpace(text) != "" && len(groups) == 0 {
+			t.Errorf("%s %s: %q has no groups", row["file"], row["subject_id"], text)
+		}
+		for _, group := range groups {
+			for _, option := range group {
+				if option.SubjectID == row["subject_id"] {
+					t.Errorf("%s %s: requires itself", row["file"], row["subject_id"])
+				}
+			}
+		}
+	}
+}
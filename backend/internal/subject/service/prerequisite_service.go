@@ -0,0 +1,335 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cpsu/internal/subject/models"
+)
+
+var (
+	ErrSubjectNotInCourse      = errors.New("subject not found in course")
+	ErrSelfPrerequisite        = errors.New("subject cannot require itself")
+	ErrInvalidPrerequisiteKind = errors.New("invalid prerequisite kind")
+	ErrUnknownPrerequisite     = errors.New("prerequisite subject not found in course")
+)
+
+// PrerequisiteCycleError คือเงื่อนไขที่ทำให้เกิดวงของวิชาบังคับก่อน Cycle เริ่มและจบที่วิชาเดียวกัน
+type PrerequisiteCycleError struct {
+	Cycle []string
+}
+
+func (e *PrerequisiteCycleError) Error() string {
+	return "prerequisite cycle: " + strings.Join(e.Cycle, " -> ")
+}
+
+func (s *subjectService) GetPrerequisiteGraph(courseID string) (*models.PrerequisiteGraph, error) {
+	nodes, edges, err := s.loadPrerequisites(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes = withExternalNodes(nodes, edges)
+	levels := prerequisiteLevels(edges)
+	for i := range nodes {
+		nodes[i].Level = levels[nodes[i].SubjectID]
+	}
+
+	return &models.PrerequisiteGraph{
+		CourseID: courseID,
+		Nodes:    nodes,
+		Edges:    edges,
+		Cycles:   findPrerequisiteCycles(edges),
+	}, nil
+}
+
+// GetSubjectUnlocks คืนวิชาที่เปิดให้ลงทะเบียนได้เมื่อผ่านวิชานี้ ทั้งโดยตรงและต่อเนื่อง
+func (s *subjectService) GetSubjectUnlocks(courseID string, subjectID string) (*models.SubjectUnlocks, error) {
+	nodes, edges, err := s.loadPrerequisites(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	subjectID = strings.ToUpper(subjectID)
+	nodes = withExternalNodes(nodes, edges)
+	names := map[string]models.PrerequisiteNode{}
+	for _, n := range nodes {
+		names[n.SubjectID] = n
+	}
+	if _, ok := names[subjectID]; !ok {
+		return nil, ErrSubjectNotInCourse
+	}
+
+	groupSize := map[string]int{}
+	outgoing := map[string][]models.PrerequisiteEdge{}
+	for _, e := range edges {
+		groupSize[groupKey(e)]++
+		outgoing[e.From] = append(outgoing[e.From], e)
+	}
+
+	result := &models.SubjectUnlocks{SubjectID: subjectID, Unlocks: []models.SubjectUnlock{}}
+	seen := map[string]bool{subjectID: true}
+	queue := []string{subjectID}
+	for depth := 1; len(queue) > 0; depth++ {
+		var next []string
+		for _, from := range queue {
+			for _, e := range outgoing[from] {
+				if seen[e.To] {
+					continue
+				}
+				seen[e.To] = true
+				next = append(next, e.To)
+				node := names[e.To]
+				result.Unlocks = append(result.Unlocks, models.SubjectUnlock{
+					SubjectID:   e.To,
+					ThaiSubject: node.ThaiSubject,
+					EngSubject:  node.EngSubject,
+					Kind:        e.Kind,
+					Depth:       depth,
+					Alternative: groupSize[groupKey(e)] > 1,
+				})
+			}
+		}
+		queue = next
+	}
+
+	return result, nil
+}
+
+// SetPrerequisites แทนที่เงื่อนไขของวิชาด้วยค่าที่แก้เอง ข้อความเดิมจะไม่ถูกนำมาแปลงทับอีก
+func (s *subjectService) SetPrerequisites(courseID string, subjectID string, req models.PrerequisiteRequest, userID int, ip string, userAgent string) ([]models.PrerequisiteEdge, error) {
+	nodes, edges, err := s.loadPrerequisites(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	subjectID = strings.ToUpper(subjectID)
+	inCourse := map[string]bool{}
+	for _, n := range nodes {
+		inCourse[n.SubjectID] = true
+	}
+	if !inCourse[subjectID] {
+		return nil, ErrSubjectNotInCourse
+	}
+
+	subjectEdges := []models.PrerequisiteEdge{}
+	group := 0
+	for _, options := range req.Groups {
+		var added []string
+		for _, option := range options {
+			code := strings.ToUpper(strings.TrimSpace(option.SubjectID))
+			kind := option.Kind
+			if kind == "" {
+				kind = models.PrerequisiteKindPrerequisite
+			}
+			switch {
+			case kind != models.PrerequisiteKindPrerequisite && kind != models.PrerequisiteKindCorequisite:
+				return nil, ErrInvalidPrerequisiteKind
+			case code == subjectID:
+				return nil, ErrSelfPrerequisite
+			case !inCourse[code]:
+				return nil, ErrUnknownPrerequisite
+			}
+			if containsString(added, code) {
+				continue
+			}
+			if len(added) == 0 {
+				group++
+			}
+			added = append(added, code)
+			subjectEdges = append(subjectEdges, models.PrerequisiteEdge{
+				From:   code,
+				To:     subjectID,
+				Kind:   kind,
+				Group:  group,
+				Source: models.PrerequisiteSourceManual,
+			})
+		}
+	}
+
+	proposed := append([]models.PrerequisiteEdge{}, subjectEdges...)
+	for _, e := range edges {
+		if e.To != subjectID {
+			proposed = append(proposed, e)
+		}
+	}
+	for _, cycle := range findPrerequisiteCycles(proposed) {
+		if containsString(cycle, subjectID) {
+			return nil, &PrerequisiteCycleError{Cycle: cycle}
+		}
+	}
+
+	if err := s.repo.ReplaceSubjectPrerequisites(courseID, subjectID, subjectEdges); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "update", "subject_prerequisites", courseID+"/"+subjectID,
+		map[string]interface{}{
+			"groups": len(req.Groups),
+			"edges":  len(subjectEdges),
+		},
+		ip, userAgent,
+	)
+
+	return subjectEdges, nil
+}
+
+// ParsePrerequisites แปลงข้อความ compulsory_subject ของทั้งหลักสูตรเป็นกราฟ
+// วิชาที่มีเงื่อนไขแก้เองแล้วจะถูกข้าม เพื่อไม่ให้ข้อความเดิมเขียนทับ
+func (s *subjectService) ParsePrerequisites(courseID string, dryRun bool, userID int, ip string, userAgent string) (*models.PrerequisiteParseResult, error) {
+	if err := s.repo.CourseExists(courseID); err != nil {
+		return nil, err
+	}
+	texts, err := s.repo.GetPrerequisiteTexts(courseID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.GetPrerequisiteEdges(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	manual := map[string]bool{}
+	var kept []models.PrerequisiteEdge
+	for _, e := range existing {
+		if e.Source == models.PrerequisiteSourceManual {
+			manual[e.To] = true
+			kept = append(kept, e)
+		}
+	}
+
+	result := &models.PrerequisiteParseResult{
+		DryRun:   dryRun,
+		Edges:    []models.PrerequisiteEdge{},
+		Skipped:  []string{},
+		Unparsed: []models.PrerequisiteText{},
+	}
+	for _, t := range texts {
+		if manual[t.SubjectID] {
+			result.Skipped = append(result.Skipped, t.SubjectID)
+			continue
+		}
+		parsed, ok := parsedEdges(t.SubjectID, t.Text)
+		if !ok {
+			result.Unparsed = append(result.Unparsed, t)
+			continue
+		}
+		result.Parsed++
+		result.Edges = append(result.Edges, parsed...)
+	}
+	result.Cycles = findPrerequisiteCycles(append(kept, result.Edges...))
+
+	if dryRun {
+		return result, nil
+	}
+
+	if err := s.repo.ReplaceParsedPrerequisites(courseID, result.Edges); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID, "import", "subject_prerequisites", courseID,
+		map[string]interface{}{
+			"parsed":   result.Parsed,
+			"edges":    len(result.Edges),
+			"unparsed": len(result.Unparsed),
+			"cycles":   len(result.Cycles),
+		},
+		ip, userAgent,
+	)
+
+	return result, nil
+}
+
+// subjectPrerequisites แปลงข้อความเงื่อนไขของวิชาที่กำลังบันทึก ข้อความที่อ่านไม่ได้จะไม่มีเส้น
+func subjectPrerequisites(subject models.SubjectsRequest) []models.PrerequisiteEdge {
+	if subject.CompulsorySubject == nil {
+		return nil
+	}
+	edges, _ := parsedEdges(subject.SubjectID, *subject.CompulsorySubject)
+	return edges
+}
+
+func (s *subjectService) loadPrerequisites(courseID string) ([]models.PrerequisiteNode, []models.PrerequisiteEdge, error) {
+	if err := s.repo.CourseExists(courseID); err != nil {
+		return nil, nil, err
+	}
+	nodes, err := s.repo.GetPrerequisiteNodes(courseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	edges, err := s.repo.GetPrerequisiteEdges(courseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nodes, edges, nil
+}
+
+func parsedEdges(subjectID string, text string) ([]models.PrerequisiteEdge, bool) {
+	groups, ok := ParsePrerequisiteText(text)
+	if !ok {
+		return nil, false
+	}
+
+	var edges []models.PrerequisiteEdge
+	group := 0
+	for _, options := range groups {
+		added := false
+		for _, option := range options {
+			// ข้อความบางวิชาอ้างรหัสตัวเอง ไม่นับเป็นเงื่อนไข
+			if option.SubjectID == subjectID {
+				continue
+			}
+			if !added {
+				group++
+				added = true
+			}
+			edges = append(edges, models.PrerequisiteEdge{
+				From:   option.SubjectID,
+				To:     subjectID,
+				Kind:   option.Kind,
+				Group:  group,
+				Source: models.PrerequisiteSourceParsed,
+			})
+		}
+	}
+	return edges, true
+}
+
+// withExternalNodes เพิ่มวิชาที่ถูกอ้างถึงแต่ไม่อยู่ในหลักสูตร เพื่อให้ทุกเส้นมีปลายทาง
+func withExternalNodes(nodes []models.PrerequisiteNode, edges []models.PrerequisiteEdge) []models.PrerequisiteNode {
+	known := map[string]bool{}
+	for _, n := range nodes {
+		known[n.SubjectID] = true
+	}
+	var external []string
+	for _, e := range edges {
+		for _, code := range []string{e.From, e.To} {
+			if !known[code] {
+				known[code] = true
+				external = append(external, code)
+			}
+		}
+	}
+	sort.Strings(external)
+	for _, code := range external {
+		nodes = append(nodes, models.PrerequisiteNode{SubjectID: code})
+	}
+	return nodes
+}
+
+func groupKey(e models.PrerequisiteEdge) string {
+	return e.To + "#" + strconv.Itoa(e.Group)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,422 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cpsu/internal/credits"
+	"cpsu/internal/subject/models"
+)
+
+const (
+	importActionCreate = "create"
+	importActionUpdate = "update"
+)
+
+// importSubjectRow คือรายวิชาหนึ่งแถวที่ตรวจแล้ว
+type importSubjectRow struct {
+	record  importRecord
+	subject models.SubjectsRequest
+}
+
+// ImportSubjects นำเข้ารายวิชา คำอธิบายรายวิชาและ CLO จากไฟล์ csv แยกตารางหรือ XLSX หลาย sheet
+//
+// รายวิชาเทียบกับของเดิมด้วยหลักสูตร แผนการเรียน ภาคการศึกษาและรหัสวิชา ถ้าซ้ำกันหลายแถว เช่น ช่องวิชาเลือก
+// จะจับคู่ตามลำดับ ส่วน description และ clo เทียบด้วยรหัส ถ้ามีข้อผิดพลาดแม้แถวเดียวหรือเป็น dryRun
+// จะคืนผลการตรวจโดยไม่บันทึก
+func (s *subjectService) ImportSubjects(files []models.ImportFile, dryRun bool, userID int, ip string, userAgent string) (*models.SubjectImportResult, error) {
+	tables, err := readImportFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.SubjectImportResult{
+		DryRun:  dryRun,
+		Errors:  []models.ImportRowError{},
+		Summary: map[string]models.ImportSummary{},
+		Changes: []models.ImportChange{},
+	}
+	for _, table := range models.ImportTables {
+		result.Summary[table] = models.ImportSummary{}
+	}
+
+	records := map[string][]importRecord{}
+	for _, t := range tables {
+		parsed, errs := parseImportTable(t)
+		records[t.table] = append(records[t.table], parsed...)
+		result.Errors = append(result.Errors, errs...)
+	}
+
+	rowErr := func(table string, r importRecord, column, message string) {
+		result.Errors = append(result.Errors, models.ImportRowError{
+			File: r.file, Table: table, Row: r.row, Column: column, Message: message,
+		})
+	}
+
+	descriptions := map[string]importRecord{}
+	for _, r := range records[models.ImportTableDescription] {
+		id := r.value("description_id")
+		if first, dup := descriptions[id]; dup {
+			rowErr(models.ImportTableDescription, r, "description_id",
+				fmt.Sprintf("description_id %s already in %s row %d", id, first.file, first.row))
+			continue
+		}
+		descriptions[id] = r
+	}
+	clos := map[string]importRecord{}
+	for _, r := range records[models.ImportTableCLO] {
+		id := r.value("clo_id")
+		if first, dup := clos[id]; dup {
+			rowErr(models.ImportTableCLO, r, "clo_id",
+				fmt.Sprintf("clo_id %s already in %s row %d", id, first.file, first.row))
+			continue
+		}
+		clos[id] = r
+	}
+
+	var subjects []importSubjectRow
+	courseIDs := map[string]bool{}
+	descriptionIDs := keysOf(descriptions)
+	cloIDs := keysOf(clos)
+	for _, r := range records[models.ImportTableSubjects] {
+		subject := models.SubjectsRequest{
+			SubjectID:         r.value("subject_id"),
+			CourseID:          r.value("course_id"),
+			PlanType:          r.value("plan_type"),
+			Semester:          r.value("semester"),
+			ThaiSubject:       r.value("thai_subject"),
+			EngSubject:        r.optional("eng_subject"),
+			Credits:           r.value("credits"),
+			CompulsorySubject: r.optional("compulsory_subject"),
+			Condition:         r.optional("condition"),
+			DescriptionID:     r.optional("description_id"),
+			CloID:             r.optional("clo_id"),
+		}
+		if err := parseSubjectCredits(&subject); err != nil {
+			rowErr(models.ImportTableSubjects, r, "credits", err.Error())
+			continue
+		}
+		courseIDs[subject.CourseID] = true
+		if subject.DescriptionID != nil {
+			descriptionIDs = append(descriptionIDs, *subject.DescriptionID)
+		}
+		if subject.CloID != nil {
+			cloIDs = append(cloIDs, *subject.CloID)
+		}
+		subjects = append(subjects, importSubjectRow{record: r, subject: subject})
+	}
+
+	courses, err := s.repo.ExistingCourses(keysOf(courseIDs))
+	if err != nil {
+		return nil, err
+	}
+	existingDescriptions, err := s.repo.GetDescriptions(descriptionIDs)
+	if err != nil {
+		return nil, err
+	}
+	existingCLOs, err := s.repo.GetCLOTexts(cloIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range subjects {
+		subject := row.subject
+		if !courses[subject.CourseID] {
+			rowErr(models.ImportTableSubjects, row.record, "course_id", fmt.Sprintf("unknown course %s", subject.CourseID))
+		}
+		if id := subject.DescriptionID; id != nil {
+			if _, inFile := descriptions[*id]; !inFile {
+				if _, exists := existingDescriptions[*id]; !exists {
+					rowErr(models.ImportTableSubjects, row.record, "description_id", fmt.Sprintf("unknown description %s", *id))
+				}
+			}
+		}
+		if id := subject.CloID; id != nil {
+			if _, inFile := clos[*id]; !inFile {
+				if _, exists := existingCLOs[*id]; !exists {
+					rowErr(models.ImportTableSubjects, row.record, "clo_id", fmt.Sprintf("unknown clo %s", *id))
+				}
+			}
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		sort.SliceStable(result.Errors, func(i, j int) bool {
+			a, b := result.Errors[i], result.Errors[j]
+			if a.File != b.File {
+				return a.File < b.File
+			}
+			return a.Row < b.Row
+		})
+		return result, nil
+	}
+
+	var plan models.SubjectImportPlan
+
+	for _, id := range sortedKeys(descriptions) {
+		r := descriptions[id]
+		d := models.ImportDescription{
+			DescriptionID:   id,
+			DescriptionThai: r.optional("description_thai"),
+			DescriptionEng:  r.optional("description_eng"),
+		}
+		old, exists := existingDescriptions[id]
+		if exists {
+			d.DescriptionThai = keepMissing(r, "description_thai", d.DescriptionThai, old.DescriptionThai)
+			d.DescriptionEng = keepMissing(r, "description_eng", d.DescriptionEng, old.DescriptionEng)
+		}
+		fields := diffFields(exists, []fieldPair{
+			{"description_thai", old.DescriptionThai, d.DescriptionThai},
+			{"description_eng", old.DescriptionEng, d.DescriptionEng},
+		})
+		if recordChange(result, models.ImportTableDescription, r, id, nil, exists, fields) {
+			plan.Descriptions = append(plan.Descriptions, d)
+		}
+	}
+
+	for _, id := range sortedKeys(clos) {
+		r := clos[id]
+		c := models.ImportCLO{CLOID: id, CLO: r.optional("clo")}
+		old, exists := existingCLOs[id]
+		if exists {
+			c.CLO = keepMissing(r, "clo", c.CLO, old.CLO)
+		}
+		fields := diffFields(exists, []fieldPair{{"clo", old.CLO, c.CLO}})
+		if recordChange(result, models.ImportTableCLO, r, id, nil, exists, fields) {
+			plan.CLOs = append(plan.CLOs, c)
+		}
+	}
+
+	if len(subjects) > 0 {
+		if err := s.planSubjects(result, &plan, subjects, keysOf(courseIDs)); err != nil {
+			return nil, err
+		}
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	if err := s.repo.ApplySubjectImport(plan); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditRepo.LogAudit(
+		userID,
+		"import",
+		"subject",
+		strings.Join(sortedKeys(courseIDs), ","),
+		map[string]interface{}{
+			"summary": result.Summary,
+		},
+		ip,
+		userAgent,
+	)
+
+	return result, nil
+}
+
+// planSubjects จับคู่รายวิชาในไฟล์กับของเดิม แล้วเตรียมเส้นวิชาบังคับก่อนของรายวิชาที่ข้อความเปลี่ยน
+func (s *subjectService) planSubjects(result *models.SubjectImportResult, plan *models.SubjectImportPlan, rows []importSubjectRow, courseIDs []string) error {
+	existing, err := s.repo.GetExistingSubjects(courseIDs)
+	if err != nil {
+		return err
+	}
+	byKey := map[string][]models.ExistingSubject{}
+	for _, e := range existing {
+		key := subjectImportKey(e.SubjectsRequest)
+		byKey[key] = append(byKey[key], e)
+	}
+
+	used := map[string]int{}
+	prerequisiteText := map[[2]string]*string{}
+	for _, row := range rows {
+		subject := row.subject
+		key := subjectImportKey(subject)
+		n := used[key]
+		used[key]++
+
+		if n >= len(byKey[key]) {
+			fields := diffFields(false, subjectFieldPairs(models.SubjectsRequest{}, subject))
+			recordChange(result, models.ImportTableSubjects, row.record, key, nil, false, fields)
+			plan.Creates = append(plan.Creates, subject)
+			if subject.CompulsorySubject != nil {
+				prerequisiteText[[2]string{subject.CourseID, subject.SubjectID}] = subject.CompulsorySubject
+			}
+			continue
+		}
+
+		old := byKey[key][n]
+		r := row.record
+		subject.EngSubject = keepMissing(r, "eng_subject", subject.EngSubject, old.EngSubject)
+		subject.CompulsorySubject = keepMissing(r, "compulsory_subject", subject.CompulsorySubject, old.CompulsorySubject)
+		subject.Condition = keepMissing(r, "condition", subject.Condition, old.Condition)
+		subject.DescriptionID = keepMissing(r, "description_id", subject.DescriptionID, old.DescriptionID)
+		subject.CloID = keepMissing(r, "clo_id", subject.CloID, old.CloID)
+
+		fields := diffFields(true, subjectFieldPairs(old.SubjectsRequest, subject))
+		id := old.ID
+		if recordChange(result, models.ImportTableSubjects, r, key, &id, true, fields) {
+			plan.Updates = append(plan.Updates, models.ImportSubjectUpdate{ID: old.ID, Subject: subject})
+			if !sameText(old.CompulsorySubject, subject.CompulsorySubject) {
+				prerequisiteText[[2]string{subject.CourseID, subject.SubjectID}] = subject.CompulsorySubject
+			}
+		}
+	}
+
+	// เส้นที่แก้เองไว้แล้วไม่ถูกแทนที่ เหมือนตอนแก้รายวิชาทีละวิชา
+	manual := map[string]map[string]bool{}
+	for target := range prerequisiteText {
+		courseID := target[0]
+		if _, loaded := manual[courseID]; loaded {
+			continue
+		}
+		edges, err := s.repo.GetPrerequisiteEdges(courseID)
+		if err != nil {
+			return err
+		}
+		manual[courseID] = map[string]bool{}
+		for _, e := range edges {
+			if e.Source == models.PrerequisiteSourceManual {
+				manual[courseID][e.To] = true
+			}
+		}
+	}
+	targets := make([][2]string, 0, len(prerequisiteText))
+	for target := range prerequisiteText {
+		targets = append(targets, target)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i][0] != targets[j][0] {
+			return targets[i][0] < targets[j][0]
+		}
+		return targets[i][1] < targets[j][1]
+	})
+	for _, target := range targets {
+		if manual[target[0]][target[1]] {
+			continue
+		}
+		var edges []models.PrerequisiteEdge
+		if text := prerequisiteText[target]; text != nil {
+			edges, _ = parsedEdges(target[1], *text)
+		}
+		plan.Prerequisites = append(plan.Prerequisites, models.ImportPrerequisites{
+			CourseID:  target[0],
+			SubjectID: target[1],
+			Edges:     edges,
+		})
+	}
+	return nil
+}
+
+type fieldPair struct {
+	field string
+	old   *string
+	new   *string
+}
+
+func subjectFieldPairs(old models.SubjectsRequest, subject models.SubjectsRequest) []fieldPair {
+	// หน่วยกิตเดิมอาจเขียนต่างรูปแบบกัน เทียบหลังจัดรูปแบบแล้ว
+	oldCredits := old.Credits
+	if parsed, err := credits.Parse(oldCredits); err == nil {
+		oldCredits = parsed.String()
+	}
+	return []fieldPair{
+		{"thai_subject", textPtr(old.ThaiSubject), textPtr(subject.ThaiSubject)},
+		{"eng_subject", old.EngSubject, subject.EngSubject},
+		{"credits", textPtr(oldCredits), textPtr(subject.Credits)},
+		{"compulsory_subject", old.CompulsorySubject, subject.CompulsorySubject},
+		{"condition", old.Condition, subject.Condition},
+		{"description_id", old.DescriptionID, subject.DescriptionID},
+		{"clo_id", old.CloID, subject.CloID},
+	}
+}
+
+// diffFields คืนช่องที่เปลี่ยน ถ้าเป็นแถวใหม่คืนทุกช่องที่มีค่า
+func diffFields(exists bool, pairs []fieldPair) []models.ImportFieldChange {
+	fields := []models.ImportFieldChange{}
+	for _, p := range pairs {
+		if !exists {
+			if p.new != nil && *p.new != "" {
+				fields = append(fields, models.ImportFieldChange{Field: p.field, New: p.new})
+			}
+			continue
+		}
+		if !sameText(p.old, p.new) {
+			fields = append(fields, models.ImportFieldChange{Field: p.field, Old: p.old, New: p.new})
+		}
+	}
+	return fields
+}
+
+// recordChange นับผลลง summary และเพิ่มรายการใน changes คืน true เมื่อแถวนี้ต้องบันทึก
+func recordChange(result *models.SubjectImportResult, table string, r importRecord, key string, id *int, exists bool, fields []models.ImportFieldChange) bool {
+	summary := result.Summary[table]
+	defer func() { result.Summary[table] = summary }()
+
+	action := importActionCreate
+	if exists {
+		if len(fields) == 0 {
+			summary.Unchanged++
+			return false
+		}
+		action = importActionUpdate
+		summary.Updated++
+	} else {
+		summary.Created++
+	}
+
+	result.Changes = append(result.Changes, models.ImportChange{
+		Table:  table,
+		File:   r.file,
+		Row:    r.row,
+		Action: action,
+		Key:    key,
+		ID:     id,
+		Fields: fields,
+	})
+	return true
+}
+
+// keepMissing ใช้ค่าเดิมเมื่อไฟล์ไม่มีคอลัมน์นั้นเลย ส่วนช่องว่างในคอลัมน์ที่มีคือล้างค่า
+func keepMissing(r importRecord, column string, value *string, old *string) *string {
+	if r.present[column] {
+		return value
+	}
+	return old
+}
+
+func subjectImportKey(s models.SubjectsRequest) string {
+	return strings.Join([]string{s.CourseID, s.PlanType, s.Semester, s.SubjectID}, " / ")
+}
+
+// sameText ถือว่า null กับข้อความว่างเท่ากัน และไม่สนช่องว่างหัวท้ายที่ติดมากับ csv
+func sameText(a, b *string) bool {
+	var x, y string
+	if a != nil {
+		x = strings.TrimSpace(*a)
+	}
+	if b != nil {
+		y = strings.TrimSpace(*b)
+	}
+	return x == y
+}
+
+func textPtr(s string) *string {
+	return &s
+}
+
+func keysOf[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := keysOf(m)
+	sort.Strings(keys)
+	return keys
+}
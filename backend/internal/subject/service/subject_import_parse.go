@@ -0,0 +1,221 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"cpsu/internal/subject/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var ErrInvalidImportFile = errors.New("invalid import file")
+
+// คอลัมน์ของแต่ละตารางตามไฟล์ csv ที่ใช้ตอนสร้างฐานข้อมูล
+var importColumns = map[string][]string{
+	models.ImportTableSubjects: {
+		"subject_id", "course_id", "plan_type", "semester", "thai_subject", "eng_subject",
+		"credits", "compulsory_subject", "condition", "description_id", "clo_id",
+	},
+	models.ImportTableDescription: {"description_id", "description_thai", "description_eng"},
+	models.ImportTableCLO:         {"clo_id", "clo"},
+}
+
+var requiredImportColumns = map[string][]string{
+	models.ImportTableSubjects:    {"subject_id", "course_id", "plan_type", "semester", "thai_subject", "credits"},
+	models.ImportTableDescription: {"description_id"},
+	models.ImportTableCLO:         {"clo_id"},
+}
+
+// ความยาวสูงสุดตามชนิดคอลัมน์ในฐานข้อมูล นับเป็นตัวอักษร
+var importColumnLimits = map[string]int{
+	"subject_id":         10,
+	"course_id":          10,
+	"plan_type":          50,
+	"semester":           50,
+	"thai_subject":       100,
+	"eng_subject":        100,
+	"credits":            50,
+	"compulsory_subject": 255,
+	"condition":          255,
+	"description_id":     6,
+	"clo_id":             6,
+}
+
+// importTable คือข้อมูลหนึ่งตารางจากไฟล์ csv หรือ sheet หนึ่งของไฟล์ XLSX
+type importTable struct {
+	table string
+	file  string
+	rows  [][]string
+}
+
+// importRecord คือหนึ่งแถวข้อมูล present บอกว่าไฟล์มีคอลัมน์นั้นหรือไม่
+type importRecord struct {
+	file    string
+	row     int
+	values  map[string]string
+	present map[string]bool
+}
+
+func (r importRecord) value(column string) string {
+	return r.values[column]
+}
+
+// optional คืน nil เมื่อช่องว่าง
+func (r importRecord) optional(column string) *string {
+	if v := r.values[column]; v != "" {
+		return &v
+	}
+	return nil
+}
+
+// readImportFiles อ่านไฟล์ที่อัปโหลด ไฟล์ XLSX ใช้ชื่อ sheet บอกตาราง ส่วนไฟล์ csv ใช้ชื่อฟิลด์ที่อัปโหลด
+func readImportFiles(files []models.ImportFile) ([]importTable, error) {
+	var tables []importTable
+	for _, f := range files {
+		if f.Table != "" {
+			reader := csv.NewReader(f.Reader)
+			reader.FieldsPerRecord = -1
+			rows, err := reader.ReadAll()
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidImportFile, f.Name, err)
+			}
+			if len(rows) > 0 && len(rows[0]) > 0 {
+				rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+			}
+			tables = append(tables, importTable{table: f.Table, file: f.Name, rows: rows})
+			continue
+		}
+
+		book, err := excelize.OpenReader(f.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidImportFile, f.Name, err)
+		}
+		found := false
+		for _, sheet := range book.GetSheetList() {
+			table := importTableForSheet(sheet)
+			if table == "" {
+				continue
+			}
+			rows, err := book.GetRows(sheet)
+			if err != nil {
+				book.Close()
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidImportFile, f.Name, err)
+			}
+			tables = append(tables, importTable{table: table, file: f.Name + " [" + sheet + "]", rows: rows})
+			found = true
+		}
+		book.Close()
+		if !found {
+			return nil, fmt.Errorf("%w: %s has no subjects, description or clo sheet", ErrInvalidImportFile, f.Name)
+		}
+	}
+	return tables, nil
+}
+
+// importTableForSheet รับชื่อ sheet เช่น subjects, BS_subjects, DSIT66_description หรือ clo
+func importTableForSheet(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.Contains(name, "subject"):
+		return models.ImportTableSubjects
+	case strings.Contains(name, "description"):
+		return models.ImportTableDescription
+	case strings.Contains(name, "clo"):
+		return models.ImportTableCLO
+	}
+	return ""
+}
+
+// parseImportTable แปลงแถวเป็น record แถวแรกที่ไม่ว่างคือหัวตาราง
+func parseImportTable(t importTable) ([]importRecord, []models.ImportRowError) {
+	var errs []models.ImportRowError
+	rowErr := func(row int, column, message string) {
+		errs = append(errs, models.ImportRowError{File: t.file, Table: t.table, Row: row, Column: column, Message: message})
+	}
+
+	headerRow := -1
+	for i, row := range t.rows {
+		if !blankImportRow(row) {
+			headerRow = i
+			break
+		}
+	}
+	if headerRow < 0 {
+		rowErr(1, "", "file has no rows")
+		return nil, errs
+	}
+
+	known := map[string]bool{}
+	for _, column := range importColumns[t.table] {
+		known[column] = true
+	}
+	columns := map[string]int{}
+	for i, cell := range t.rows[headerRow] {
+		name := strings.ToLower(strings.TrimSpace(cell))
+		if _, seen := columns[name]; known[name] && !seen {
+			columns[name] = i
+		}
+	}
+	for _, column := range requiredImportColumns[t.table] {
+		if _, ok := columns[column]; !ok {
+			rowErr(headerRow+1, column, "missing column "+column)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	present := map[string]bool{}
+	for column := range columns {
+		present[column] = true
+	}
+	required := map[string]bool{}
+	for _, column := range requiredImportColumns[t.table] {
+		required[column] = true
+	}
+
+	var records []importRecord
+	for i := headerRow + 1; i < len(t.rows); i++ {
+		row := t.rows[i]
+		if blankImportRow(row) {
+			continue
+		}
+
+		record := importRecord{file: t.file, row: i + 1, values: map[string]string{}, present: present}
+		valid := true
+		for column, index := range columns {
+			value := ""
+			if index < len(row) {
+				value = strings.TrimSpace(row[index])
+			}
+			if required[column] && value == "" {
+				rowErr(i+1, column, column+" is required")
+				valid = false
+				continue
+			}
+			if limit, ok := importColumnLimits[column]; ok && utf8.RuneCountInString(value) > limit {
+				rowErr(i+1, column, fmt.Sprintf("%s is longer than %d characters", column, limit))
+				valid = false
+				continue
+			}
+			record.values[column] = value
+		}
+		if valid {
+			records = append(records, record)
+		}
+	}
+	return records, errs
+}
+
+func blankImportRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cpsu/internal/seedtest"
+	"cpsu/internal/subject/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestImportTableForSheet(t *testing.T) {
+	tests := []struct {
+		sheet string
+		want  string
+	}{
+		{"subjects", models.ImportTableSubjects},
+		{"BS_subjects", models.ImportTableSubjects},
+		{"DSIT66_Subjects", models.ImportTableSubjects},
+		{"DSIT66_description", models.ImportTableDescription},
+		{"CLO", models.ImportTableCLO},
+		{"MSIT66_clo", models.ImportTableCLO},
+		{"Sheet1", ""},
+	}
+
+	for _, tt := range tests {
+		if got := importTableForSheet(tt.sheet); got != tt.want {
+			t.Errorf("importTableForSheet(%q) = %q, want %q", tt.sheet, got, tt.want)
+		}
+	}
+}
+
+func TestParseImportTable(t *testing.T) {
+	header := []string{"subject_id", "course_id", "plan_type", "semester", "thai_subject", "credits"}
+
+	tests := []struct {
+		name    string
+		rows    [][]string
+		want    []map[string]string
+		wantErr []string
+	}{
+		{
+			name: "header mapping",
+			rows: [][]string{
+				{" Credits ", "SUBJECT_ID", "course_id", "plan_type", "semester", "thai_subject", "unknown"},
+				{"3(3-0-6)", "520111", "BSCS65", "แผน ก", "1/1", "การเขียนโปรแกรม", "x"},
+			},
+			want: []map[string]string{{
+				"subject_id": "520111", "course_id": "BSCS65", "plan_type": "แผน ก",
+				"semester": "1/1", "thai_subject": "การเขียนโปรแกรม", "credits": "3(3-0-6)",
+			}},
+		},
+		{
+			name: "blank rows before header and between rows",
+			rows: [][]string{
+				{"", " "},
+				header,
+				{},
+				{" 520111 ", "BSCS65", "แผน ก", "1/1", "การเขียนโปรแกรม", "3(3-0-6)"},
+			},
+			want: []map[string]string{{
+				"subject_id": "520111", "course_id": "BSCS65", "plan_type": "แผน ก",
+				"semester": "1/1", "thai_subject": "การเขียนโปรแกรม", "credits": "3(3-0-6)",
+			}},
+		},
+		{
+			name: "first duplicate column wins",
+			rows: [][]string{
+				append(header, "subject_id"),
+				{"520111", "BSCS65", "แผน ก", "1/1", "การเขียนโปรแกรม", "3(3-0-6)", "999999"},
+			},
+			want: []map[string]string{{
+				"subject_id": "520111", "course_id": "BSCS65", "plan_type": "แผน ก",
+				"semester": "1/1", "thai_subject": "การเขียนโปรแกรม", "credits": "3(3-0-6)",
+			}},
+		},
+		{
+			name:    "missing column",
+			rows:    [][]string{{"subject_id", "course_id", "plan_type", "semester", "credits"}},
+			wantErr: []string{"1 thai_subject missing column thai_subject"},
+		},
+		{
+			name:    "no rows",
+			rows:    [][]string{{""}},
+			wantErr: []string{"1  file has no rows"},
+		},
+		{
+			name: "required value and length limit",
+			rows: [][]string{
+				header,
+				{"520111", "BSCS65", "แผน ก", "1/1", "", "3(3-0-6)"},
+				{"52011100000", "BSCS65", "แผน ก", "1/1", "การเขียนโปรแกรม", "3(3-0-6)"},
+				{"520112", "BSCS65", "แผน ก", "1/1", "โครงสร้างข้อมูล"},
+			},
+			wantErr: []string{
+				"2 thai_subject thai_subject is required",
+				"3 subject_id subject_id is longer than 10 characters",
+				"4 credits credits is required",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, errs := parseImportTable(importTable{table: models.ImportTableSubjects, file: "subjects.csv", rows: tt.rows})
+
+			var gotErr []string
+			for _, e := range errs {
+				gotErr = append(gotErr, fmt.Sprintf("%d %s %s", e.Row, e.Column, e.Message))
+			}
+			if strings.Join(gotErr, "\n") != strings.Join(tt.wantErr, "\n") {
+				t.Errorf("errors = %q, want %q", gotErr, tt.wantErr)
+			}
+
+			if len(records) != len(tt.want) {
+				t.Fatalf("got %d records, want %d", len(records), len(tt.want))
+			}
+			for i, record := range records {
+				if len(record.values) != len(tt.want[i]) {
+					t.Errorf("record %d = %v, want %v", i, record.values, tt.want[i])
+				}
+				for column, value := range tt.want[i] {
+					if record.value(column) != value || !record.present[column] {
+						t.Errorf("record %d %s = %q, want %q", i, column, record.value(column), value)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestImportRecordOptional(t *testing.T) {
+	record := importRecord{values: map[string]string{"condition": "", "clo_id": "1"}}
+	if got := record.optional("condition"); got != nil {
+		t.Errorf("optional(condition) = %q, want nil", *got)
+	}
+	if got := record.optional("clo_id"); got == nil || *got != "1" {
+		t.Errorf("optional(clo_id) = %v, want 1", got)
+	}
+}
+
+func TestReadImportFilesCSV(t *testing.T) {
+	tables, err := readImportFiles([]models.ImportFile{{
+		Table:  models.ImportTableCLO,
+		Name:   "clo.csv",
+		Reader: strings.NewReader("\ufeffclo_id,clo\n1,\"อธิบาย, วิเคราะห์\"\n"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 || tables[0].table != models.ImportTableCLO || tables[0].rows[0][0] != "clo_id" {
+		t.Fatalf("tables = %+v", tables)
+	}
+	if got := tables[0].rows[1][1]; got != "อธิบาย, วิเคราะห์" {
+		t.Errorf("clo = %q", got)
+	}
+
+	_, err = readImportFiles([]models.ImportFile{{
+		Table:  models.ImportTableCLO,
+		Name:   "clo.csv",
+		Reader: strings.NewReader("clo_id,clo\n1,\"unterminated\n"),
+	}})
+	if !errors.Is(err, ErrInvalidImportFile) {
+		t.Errorf("err = %v, want ErrInvalidImportFile", err)
+	}
+}
+
+func TestReadImportFilesXLSX(t *testing.T) {
+	book := excelize.NewFile()
+	book.SetSheetName("Sheet1", "BS_subjects")
+	book.SetSheetRow("BS_subjects", "A1", &[]string{"subject_id", "credits"})
+	book.SetSheetRow("BS_subjects", "A2", &[]string{"520111", "3(3-0-6)"})
+	book.NewSheet("BS_description")
+	book.SetSheetRow("BS_description", "A1", &[]string{"description_id"})
+	book.NewSheet("notes")
+	var buf bytes.Buffer
+	if err := book.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	tables, err := readImportFiles([]models.ImportFile{{Name: "BS.xlsx", Reader: &buf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("got %d tables, want 2", len(tables))
+	}
+	if tables[0].table != models.ImportTableSubjects || tables[0].file != "BS.xlsx [BS_subjects]" || tables[0].rows[1][1] != "3(3-0-6)" {
+		t.Errorf("subjects table = %+v", tables[0])
+	}
+	if tables[1].table != models.ImportTableDescription {
+		t.Errorf("description table = %+v", tables[1])
+	}
+
+	empty := excelize.NewFile()
+	buf.Reset()
+	if err := empty.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readImportFiles([]models.ImportFile{{Name: "empty.xlsx", Reader: &buf}}); !errors.Is(err, ErrInvalidImportFile) {
+		t.Errorf("err = %v, want ErrInvalidImportFile", err)
+	}
+	if _, err := readImportFiles([]models.ImportFile{{Name: "bad.xlsx", Reader: strings.NewReader("not a workbook")}}); !errors.Is(err, ErrInvalidImportFile) {
+		t.Errorf("err = %v, want ErrInvalidImportFile", err)
+	}
+}
+
+// ไฟล์ seed ทุกไฟล์ต้องอ่านได้ครบทุกแถวโดยไม่มี error
+func TestReadImportFilesSeed(t *testing.T) {
+	subjectRows := map[string]int{}
+	for _, row := range seedtest.SubjectRows(t) {
+		subjectRows[row["file"]]++
+	}
+
+	for _, path := range seedtest.Files(t, "*.csv") {
+		name := filepath.Base(path)
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tables, err := readImportFiles([]models.ImportFile{{
+			Table:  importTableForSheet(strings.TrimSuffix(name, ".csv")),
+			Name:   name,
+			Reader: f,
+		}})
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		records, errs := parseImportTable(tables[0])
+		for _, e := range errs {
+			t.Errorf("%s row %d %s: %s", name, e.Row, e.Column, e.Message)
+		}
+		if len(records) == 0 {
+			t.Errorf("%s has no records", name)
+		}
+		if tables[0].table == models.ImportTableSubjects && len(records) != subjectRows[name] {
+			t.Errorf("%s: got %d records, want %d", name, len(records), subjectRows[name])
+		}
+	}
+}
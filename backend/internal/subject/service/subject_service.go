@@ -1,6 +1,8 @@
 package service
 
 import (
+	"cpsu/internal/credits"
+	"cpsu/internal/pagination"
 	"cpsu/internal/subject/models"
 	"cpsu/internal/subject/repository"
 	"strconv"
@@ -9,11 +11,22 @@ import (
 )
 
 type SubjectService interface {
-	GetAllSubjects(param models.SubjectsQueryParam) ([]models.Subjects, error)
+	GetAllSubjects(param models.SubjectsQueryParam) (*pagination.Page[models.Subjects], error)
 	GetSubjectByID(id int) (*models.Subjects, error)
+	GetPublicSubjectByID(id int) (*models.Subjects, error)
 	CreateSubject(req models.SubjectsRequest, userID int, ip string, userAgent string) (*models.Subjects, error)
 	UpdateSubject(id int, req models.SubjectsRequest, userID int, ip string, userAgent string) (*models.Subjects, error)
 	DeleteSubject(id int, userID int, ip string, userAgent string) error
+
+	GetPrerequisiteGraph(courseID string) (*models.PrerequisiteGraph, error)
+	GetSubjectUnlocks(courseID string, subjectID string) (*models.SubjectUnlocks, error)
+	SetPrerequisites(courseID string, subjectID string, req models.PrerequisiteRequest, userID int, ip string, userAgent string) ([]models.PrerequisiteEdge, error)
+	ParsePrerequisites(courseID string, dryRun bool, userID int, ip string, userAgent string) (*models.PrerequisiteParseResult, error)
+
+	GetCourseCredits(courseID string) (*models.CourseCredits, error)
+	GetGraduationAudit(courseID string, req models.GraduationAuditRequest) (*models.GraduationAudit, error)
+
+	ImportSubjects(files []models.ImportFile, dryRun bool, userID int, ip string, userAgent string) (*models.SubjectImportResult, error)
 }
 
 type subjectService struct {
@@ -31,7 +44,7 @@ func NewSubjectService(
 	}
 }
 
-func (s *subjectService) GetAllSubjects(param models.SubjectsQueryParam) ([]models.Subjects, error) {
+func (s *subjectService) GetAllSubjects(param models.SubjectsQueryParam) (*pagination.Page[models.Subjects], error) {
 	return s.repo.GetAllSubjects(param)
 }
 
@@ -39,8 +52,16 @@ func (s *subjectService) GetSubjectByID(id int) (*models.Subjects, error) {
 	return s.repo.GetSubjectByID(id)
 }
 
+func (s *subjectService) GetPublicSubjectByID(id int) (*models.Subjects, error) {
+	return s.repo.GetPublicSubjectByID(id)
+}
+
 func (s *subjectService) CreateSubject(subject models.SubjectsRequest, userID int, ip string, userAgent string) (*models.Subjects, error) {
-	created, err := s.repo.CreateSubject(subject)
+	if err := parseSubjectCredits(&subject); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.CreateSubject(subject, subjectPrerequisites(subject))
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +78,11 @@ func (s *subjectService) CreateSubject(subject models.SubjectsRequest, userID in
 }
 
 func (s *subjectService) UpdateSubject(id int, subject models.SubjectsRequest, userID int, ip string, userAgent string) (*models.Subjects, error) {
-	updated, err := s.repo.UpdateSubject(id, subject)
+	if err := parseSubjectCredits(&subject); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.UpdateSubject(id, subject, subjectPrerequisites(subject))
 	if err != nil {
 		return nil, err
 	}
@@ -87,3 +112,29 @@ func (s *subjectService) DeleteSubject(id int, userID int, ip string, userAgent
 
 	return nil
 }
+
+func (s *subjectService) GetCourseCredits(courseID string) (*models.CourseCredits, error) {
+	if err := s.repo.CourseExists(courseID); err != nil {
+		return nil, err
+	}
+	categories, err := s.repo.GetCategoryCredits(courseID)
+	if err != nil {
+		return nil, err
+	}
+	plans, err := s.repo.GetPlanCredits(courseID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.CourseCredits{CourseID: courseID, Categories: categories, Plans: plans}, nil
+}
+
+// parseSubjectCredits ตรวจหน่วยกิตแล้วเก็บ credits ในรูปแบบมาตรฐาน เช่น " 3 (2-2-5)" เป็น "3(2-2-5)"
+func parseSubjectCredits(subject *models.SubjectsRequest) error {
+	parsed, err := credits.Parse(subject.Credits)
+	if err != nil {
+		return err
+	}
+	subject.ParsedCredits = parsed
+	subject.Credits = parsed.String()
+	return nil
+}
@@ -0,0 +1,256 @@
+package textsearch
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxTerms คือจำนวนคำค้นสูงสุดที่นำไปสร้างเงื่อนไข
+	MaxTerms = 8
+	// MaxQueryLength คือความยาวคำค้นสูงสุด (นับเป็นตัวอักษร)
+	MaxQueryLength = 200
+)
+
+// ภาษาไทยไม่เว้นวรรคระหว่างคำ จึงค้นแบบ substring ของแต่ละคำค้นแทนการตัดคำ
+// (ฐานข้อมูลมี index แบบ pg_trgm ช่วยให้ ILIKE '%...%' ไม่ต้อง scan ทั้งตาราง)
+var normalizer = strings.NewReplacer(
+	// อักขระความกว้างศูนย์ที่มักติดมากับข้อความภาษาไทยที่คัดลอกมาจากเว็บหรือเอกสาร
+	"\u200b", "",
+	"\u200c", "",
+	"\u200d", "",
+	"\ufeff", "",
+	// นิคหิต + สระอา ที่พิมพ์แทนสระอำ
+	"\u0e4d\u0e32", "\u0e33",
+)
+
+// Normalize แปลงข้อความให้อยู่ในรูปเดียวกันก่อนเปรียบเทียบ
+func Normalize(s string) string {
+	return strings.ToLower(normalizer.Replace(s))
+}
+
+// Terms แยกคำค้นด้วยช่องว่าง ตัดคำซ้ำ และจำกัดจำนวนคำ
+func Terms(query string) []string {
+	runes := []rune(strings.TrimSpace(query))
+	if len(runes) > MaxQueryLength {
+		runes = runes[:MaxQueryLength]
+	}
+
+	var terms []string
+	seen := map[string]bool{}
+	for _, term := range strings.Fields(Normalize(string(runes))) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+		if len(terms) == MaxTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// LikePattern คืน pattern สำหรับ ILIKE ที่หา term ในตำแหน่งใดก็ได้ โดย escape อักขระพิเศษของ LIKE
+func LikePattern(term string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+	return "%" + escaped + "%"
+}
+
+// Condition สร้างเงื่อนไขที่ทุก term ต้องพบในคอลัมน์ใดคอลัมน์หนึ่ง
+// คืนเงื่อนไข, args และ argIndex ถัดไป
+func Condition(columns []string, terms []string, argIndex int) (string, []interface{}, int) {
+	var (
+		parts []string
+		args  []interface{}
+	)
+
+	for _, term := range terms {
+		placeholder := "$" + strconv.Itoa(argIndex)
+		matches := make([]string, len(columns))
+		for i, column := range columns {
+			matches[i] = column + " ILIKE " + placeholder
+		}
+		parts = append(parts, "("+strings.Join(matches, " OR ")+")")
+		args = append(args, LikePattern(term))
+		argIndex++
+	}
+
+	return strings.Join(parts, " AND "), args, argIndex
+}
+
+// Field คือข้อความหนึ่งส่วนของผลลัพธ์ พร้อมน้ำหนักที่ใช้คิดคะแนน
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Score ให้คะแนนความเกี่ยวข้องจากจำนวนครั้งที่พบคำค้นในแต่ละ field
+// พบที่ต้นข้อความ พบทั้งวลี หรือข้อความตรงกับคำค้นทั้งหมด ได้คะแนนเพิ่ม
+func Score(fields []Field, terms []string) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	phrase := strings.Join(terms, " ")
+
+	var score float64
+	for _, field := range fields {
+		text := strings.Join(strings.Fields(Normalize(field.Text)), " ")
+		if text == "" {
+			continue
+		}
+
+		for _, term := range terms {
+			count := strings.Count(text, term)
+			if count == 0 {
+				continue
+			}
+			score += field.Weight * (1 + math.Log(float64(count)))
+			if strings.HasPrefix(text, term) {
+				score += field.Weight * 0.5
+			}
+		}
+
+		if len(terms) > 1 && strings.Contains(text, phrase) {
+			score += field.Weight
+		}
+		if text == phrase {
+			score += field.Weight * 2
+		}
+	}
+
+	return math.Round(score*1000) / 1000
+}
+
+type span struct {
+	start, end int
+}
+
+// Highlight ตัดข้อความรอบตำแหน่งแรกที่พบคำค้นให้ยาวไม่เกิน width ตัวอักษร
+// และครอบคำที่พบด้วย <mark> ข้อความส่วนอื่นถูก escape เป็น HTML แล้ว
+// ทำงานเป็นหน่วย rune เพื่อไม่ตัดกลางตัวอักษรภาษาไทย
+func Highlight(text string, terms []string, width int) string {
+	runes := []rune(strings.Join(strings.Fields(normalizer.Replace(text)), " "))
+	if len(runes) == 0 {
+		return ""
+	}
+
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	spans := findSpans(lower, terms)
+
+	start := 0
+	if len(spans) > 0 {
+		// ให้คำที่พบอยู่ราวหนึ่งในสามของ snippet
+		start = spans[0].start - width/3
+		if start < 0 {
+			start = 0
+		}
+	}
+	end := start + width
+	if end > len(runes) {
+		end = len(runes)
+		if start = end - width; start < 0 {
+			start = 0
+		}
+	}
+	// สระบน สระล่าง และวรรณยุกต์เป็น combining mark ต้องอยู่ติดกับพยัญชนะตัวหน้าเสมอ
+	for start > 0 && unicode.Is(unicode.Mn, runes[start]) {
+		start--
+	}
+	for end < len(runes) && unicode.Is(unicode.Mn, runes[end]) {
+		end++
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+
+	pos := start
+	for _, s := range spans {
+		if s.end <= start || s.start >= end {
+			continue
+		}
+		from, to := max(s.start, start), min(s.end, end)
+		b.WriteString(html.EscapeString(string(runes[pos:from])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[from:to])))
+		b.WriteString("</mark>")
+		pos = to
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// Contains บอกว่าพบคำค้นอย่างน้อยหนึ่งคำในข้อความหรือไม่
+func Contains(text string, terms []string) bool {
+	text = Normalize(text)
+	for _, term := range terms {
+		if strings.Contains(text, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// findSpans หาตำแหน่งของทุก term แล้วรวมช่วงที่ซ้อนกัน
+// ช่วงที่พบจะขยายให้ครอบ combining mark ที่ติดกับพยัญชนะ เช่นหา "ก" ใน "กิน" จะได้ "กิ"
+func findSpans(text []rune, terms []string) []span {
+	var spans []span
+	for _, term := range terms {
+		needle := []rune(term)
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(text); i++ {
+			if equalRunes(text[i:i+len(needle)], needle) {
+				start, end := i, i+len(needle)
+				for start > 0 && unicode.Is(unicode.Mn, text[start]) {
+					start--
+				}
+				for end < len(text) && unicode.Is(unicode.Mn, text[end]) {
+					end++
+				}
+				spans = append(spans, span{start, end})
+				i = end - 1
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	merged := []span{spans[0]}
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s.start <= last.end {
+			last.end = max(last.end, s.end)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+func equalRunes(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,203 @@
+package textsearch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"empty", "", nil},
+		{"blank", " \t ", nil},
+		{"single", "ข่าว", []string{"ข่าว"}},
+		{"lowercase and split", "  Data   SCIENCE ", []string{"data", "science"}},
+		{"duplicates", "AI ai Ai ML", []string{"ai", "ml"}},
+		{"zero width space", "วิทยา\u200bการ", []string{"วิทยาการ"}},
+		{"nikhahit and sara aa", "ค\u0e4d\u0e32นวณ", []string{"คำนวณ"}},
+		{"limited terms", "a b c d e f g h i j", []string{"a", "b", "c", "d", "e", "f", "g", "h"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Terms(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Terms(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTermsMaxQueryLength(t *testing.T) {
+	// ตัดตามจำนวนตัวอักษร ไม่ใช่ byte จึงไม่ได้ UTF-8 ที่ขาดครึ่ง
+	query := strings.Repeat("ก", MaxQueryLength+50)
+	terms := Terms(query)
+	if len(terms) != 1 || terms[0] != strings.Repeat("ก", MaxQueryLength) {
+		t.Errorf("Terms(%d runes) = %d runes, want %d", MaxQueryLength+50, len([]rune(strings.Join(terms, ""))), MaxQueryLength)
+	}
+}
+
+func TestLikePattern(t *testing.T) {
+	tests := []struct {
+		term string
+		want string
+	}{
+		{"ข่าว", "%ข่าว%"},
+		{"100%", `%100\%%`},
+		{"file_name", `%file\_name%`},
+		{`c:\temp`, `%c:\\temp%`},
+		{`\%_`, `%\\\%\_%`},
+	}
+
+	for _, tt := range tests {
+		if got := LikePattern(tt.term); got != tt.want {
+			t.Errorf("LikePattern(%q) = %q, want %q", tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestCondition(t *testing.T) {
+	tests := []struct {
+		name     string
+		columns  []string
+		terms    []string
+		argIndex int
+		want     string
+		wantArgs []interface{}
+		wantNext int
+	}{
+		{
+			name:     "no terms",
+			columns:  []string{"n.title"},
+			argIndex: 3,
+			want:     "",
+			wantNext: 3,
+		},
+		{
+			name:     "one term many columns",
+			columns:  []string{"n.title", "n.content"},
+			terms:    []string{"ai"},
+			argIndex: 1,
+			want:     "(n.title ILIKE $1 OR n.content ILIKE $1)",
+			wantArgs: []interface{}{"%ai%"},
+			wantNext: 2,
+		},
+		{
+			name:     "every term must match",
+			columns:  []string{"s.thai_subject", "s.subject_id"},
+			terms:    []string{"50%", "a_b"},
+			argIndex: 4,
+			want:     "(s.thai_subject ILIKE $4 OR s.subject_id ILIKE $4) AND (s.thai_subject ILIKE $5 OR s.subject_id ILIKE $5)",
+			wantArgs: []interface{}{`%50\%%`, `%a\_b%`},
+			wantNext: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, next := Condition(tt.columns, tt.terms, tt.argIndex)
+			if got != tt.want || !reflect.DeepEqual(args, tt.wantArgs) || next != tt.wantNext {
+				t.Errorf("Condition = %q, %v, %d; want %q, %v, %d", got, args, next, tt.want, tt.wantArgs, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	title := func(s string) []Field {
+		return []Field{{Text: s, Weight: 3}, {Text: "เนื้อหาข่าวทั่วไป", Weight: 1}}
+	}
+
+	tests := []struct {
+		name   string
+		higher []Field
+		lower  []Field
+		terms  []string
+	}{
+		{"title beats body", title("ประกาศทุน"), []Field{{Text: "อื่น ๆ", Weight: 3}, {Text: "ประกาศทุน", Weight: 1}}, []string{"ทุน"}},
+		{"more occurrences", title("ทุน ทุน ทุน"), title("ทุน การศึกษา"), []string{"ทุน"}},
+		{"prefix", title("ทุนการศึกษา"), title("ข่าวทุน"), []string{"ทุน"}},
+		{"phrase", title("data science club"), title("science of data"), []string{"data", "science"}},
+		{"exact", title("data science"), title("data science club"), []string{"data", "science"}},
+		{"case and zero width", title("Data\u200bScience"), title("data"), []string{"datascience"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			high, low := Score(tt.higher, tt.terms), Score(tt.lower, tt.terms)
+			if high <= low {
+				t.Errorf("Score = %v, want more than %v", high, low)
+			}
+		})
+	}
+
+	if got := Score(title("ประกาศทุน"), nil); got != 0 {
+		t.Errorf("Score without terms = %v, want 0", got)
+	}
+	if got := Score(title("ประกาศทุน"), []string{"ai"}); got != 0 {
+		t.Errorf("Score without match = %v, want 0", got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		width int
+		want  string
+	}{
+		{"no match", "ข่าวประชาสัมพันธ์", []string{"ai"}, 50, "ข่าวประชาสัมพันธ์"},
+		{"match", "ประกาศทุนการศึกษา", []string{"ทุน"}, 50, "ประกาศ<mark>ทุน</mark>การศึกษา"},
+		{"case insensitive keeps original case", "Intro to AI", []string{"ai"}, 50, "Intro to <mark>AI</mark>"},
+		{"every occurrence", "ai and AI", []string{"ai"}, 50, "<mark>ai</mark> and <mark>AI</mark>"},
+		{"overlapping terms merge", "database", []string{"data", "tab"}, 50, "<mark>datab</mark>ase"},
+		{"whitespace collapsed", "a \n\t b", []string{"b"}, 50, "a <mark>b</mark>"},
+		{
+			"html outside mark",
+			`<script>alert("x")</script> ai`, []string{"ai"}, 100,
+			"&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; <mark>ai</mark>",
+		},
+		{"html inside mark", "use <b> & <i>", []string{"<b>", "&"}, 50, "use <mark>&lt;b&gt;</mark> <mark>&amp;</mark> &lt;i&gt;"},
+		{"thai combining vowel stays with consonant", "กินข้าว", []string{"ก"}, 50, "<mark>กิ</mark>นข้าว"},
+		{"thai tone mark stays with consonant", "ข้าวต้ม", []string{"ข"}, 50, "<mark>ข้</mark>าวต้ม"},
+		{"thai stacked marks", "ที่นี่", []string{"ท"}, 50, "<mark>ที่</mark>นี่"},
+		{"term starting with a mark", "กินข้าว", []string{"ิน"}, 50, "<mark>กิน</mark>ข้าว"},
+		{"term ending before a mark", "สวัสดี", []string{"สวัสด"}, 50, "<mark>สวัสดี</mark>"},
+		{"snippet without match", "0123456789abcdefghij", []string{"k"}, 5, "01234…"},
+		{"snippet around match", "0123456789abcdefghij", []string{"c"}, 6, "…ab<mark>c</mark>def…"},
+		{"snippet at end", "0123456789abcdefghij", []string{"j"}, 6, "…efghi<mark>j</mark>"},
+		{"snippet does not cut thai marks", "นี่คือข้อความทดสอบ", []string{"ข้อ"}, 4, "…อ<mark>ข้อ</mark>…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.text, tt.terms, tt.width); got != tt.want {
+				t.Errorf("Highlight(%q, %q, %d) =\n  %s\nwant\n  %s", tt.text, tt.terms, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContains(t *testing.T) {
+	tests := []struct {
+		text  string
+		terms []string
+		want  bool
+	}{
+		{"Data Science", []string{"science"}, true},
+		{"คณิตศาสตร์", []string{"ฟิสิกส์", "ศาสตร์"}, true},
+		{"ค\u0e4d\u0e32นวณ", []string{"คำนวณ"}, true},
+		{"คณิตศาสตร์", []string{"ฟิสิกส์"}, false},
+		{"คณิตศาสตร์", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := Contains(tt.text, tt.terms); got != tt.want {
+			t.Errorf("Contains(%q, %q) = %v, want %v", tt.text, tt.terms, got, tt.want)
+		}
+	}
+}
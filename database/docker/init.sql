@@ -2,35 +2,81 @@
 
 CREATE TABLE IF NOT EXISTS news_types (
     type_id SERIAL PRIMARY KEY,
-    type_name VARCHAR(50) NOT NULL
+    type_name VARCHAR(50) NOT NULL,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    sort_order INT NOT NULL DEFAULT 0
 );
 
 CREATE TABLE IF NOT EXISTS news (
     news_id SERIAL PRIMARY KEY,
     title VARCHAR(255) NOT NULL,
     content TEXT NOT NULL,
+    -- คำแปลภาษาอังกฤษ ค่าว่างคือยังไม่ได้แปล หน้าเว็บจะแสดงภาษาไทยแทน
+    title_en VARCHAR(255) NOT NULL DEFAULT '',
+    content_en TEXT NOT NULL DEFAULT '',
     type_id INT NOT NULL,
     detail_url TEXT NULL,
     cover_image TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'in_review', 'scheduled', 'published', 'archived')),
+    -- เก็บแบบมีเขตเวลาเพื่อเทียบกับ NOW() ได้ถูกต้องไม่ว่า client จะส่งเวลาเขตใดมา
+    publish_at TIMESTAMPTZ NULL,
+    unpublish_at TIMESTAMPTZ NULL,
+    -- ปักหมุดไว้บนสุดของรายการข่าว และข่าวเด่นใน carousel หน้าแรก
+    -- slot น้อยแสดงก่อน NULL คือไม่ได้ตั้ง ช่วงเวลาที่เป็น NULL คือไม่จำกัด
+    -- ช่วงเวลาเก็บแบบมีเขตเวลาเช่นเดียวกับ publish_at
+    pin_slot INT NULL CHECK (pin_slot > 0),
+    pin_start_at TIMESTAMPTZ NULL,
+    pin_end_at TIMESTAMPTZ NULL,
+    feature_slot INT NULL CHECK (feature_slot > 0),
+    feature_start_at TIMESTAMPTZ NULL,
+    feature_end_at TIMESTAMPTZ NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (type_id) REFERENCES news_types(type_id) ON DELETE CASCADE
+    FOREIGN KEY (type_id) REFERENCES news_types(type_id) ON DELETE RESTRICT,
+    CHECK (pin_end_at IS NULL OR pin_start_at IS NULL OR pin_end_at > pin_start_at),
+    CHECK (feature_end_at IS NULL OR feature_start_at IS NULL OR feature_end_at > feature_start_at)
 );
 
+CREATE INDEX IF NOT EXISTS idx_news_status_publish_at ON news(status, publish_at);
+CREATE INDEX IF NOT EXISTS idx_news_pin_slot ON news(pin_slot) WHERE pin_slot IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_news_feature_slot ON news(feature_slot) WHERE feature_slot IS NOT NULL;
+
 CREATE TABLE IF NOT EXISTS news_images (
     image_id SERIAL PRIMARY KEY,
     news_id INT NOT NULL,
     file_image TEXT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    caption TEXT NOT NULL DEFAULT '',
+    alt_text TEXT NOT NULL DEFAULT '',
     FOREIGN KEY (news_id) REFERENCES news(news_id) ON DELETE CASCADE
 );
 
+CREATE INDEX IF NOT EXISTS idx_news_images_position ON news_images(news_id, position);
+
+CREATE TABLE IF NOT EXISTS news_tags (
+    tag_id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    slug VARCHAR(100) NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS news_tag_map (
+    news_id INT NOT NULL,
+    tag_id INT NOT NULL,
+    PRIMARY KEY (news_id, tag_id),
+    FOREIGN KEY (news_id) REFERENCES news(news_id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES news_tags(tag_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_news_tag_map_tag ON news_tag_map(tag_id);
+
 -- insert news
 
-INSERT INTO news_types(type_name) VALUES
-('ข่าวประชาสัมพันธ์'),
-('ทุนการศึกษา'),
-('รางวัลที่ได้รับ'),
-('กิจกรรมของภาควิชา');
+INSERT INTO news_types(type_name,slug,sort_order) VALUES
+('ข่าวประชาสัมพันธ์','announcement',1),
+('ทุนการศึกษา','scholarship',2),
+('รางวัลที่ได้รับ','award',3),
+('กิจกรรมของภาควิชา','activity',4);
 
 INSERT INTO news(title,content,type_id,detail_url,cover_image) VALUES
 ('คู่มือแนะนำนักศึกษาใหม่ ปีการศึกษา 2568',
@@ -39,9 +85,12 @@ INSERT INTO news(title,content,type_id,detail_url,cover_image) VALUES
     'https://drive.google.com/file/d/1FnJRketlluku27HQqs-mVPnxMJ5wFxqZ/view?usp=sharing&fbclid=IwZXh0bgNhZW0CMTAAAR3M6TbS4tN1DUSa-z2NaM1ekAOELZp7TnsIhJWC6g_dvfz-sD_b0La0S7U_aem_K5_Zhi2eLKhIpJxaeszOlQ',
     'http://localhost:9000/images/news/manual.jpg');
 
-INSERT INTO news_images(news_id,file_image) VALUES
+-- ข่าวตัวอย่างให้แสดงหน้าเว็บทันที
+UPDATE news SET status = 'published', publish_at = created_at;
+
+INSERT INTO news_images(news_id,file_image,position) VALUES
 ((SELECT news_id FROM news WHERE title = 'คู่มือแนะนำนักศึกษาใหม่ ปีการศึกษา 2568' LIMIT 1),
-    'http://localhost:9000/images/news/manual.jpg');
+    'http://localhost:9000/images/news/manual.jpg', 1);
 
 -- create courses
 
@@ -73,7 +122,10 @@ CREATE TABLE IF NOT EXISTS courses (
     career_paths_id INT NOT NULL,
     plo_id INT NOT NULL,
     detail_url TEXT NOT NULL,
-    status VARCHAR(25) NOT NULL,
+    status VARCHAR(25) NOT NULL DEFAULT 'draft',
+    next_status VARCHAR(25) NULL CHECK (next_status IN ('draft', 'active', 'closed', 'retired')),
+    next_status_at DATE NULL,
+    CHECK ((next_status IS NULL) = (next_status_at IS NULL)),
     FOREIGN KEY (career_paths_id) REFERENCES career_paths(career_paths_id) ON DELETE CASCADE,
     FOREIGN KEY (plo_id) REFERENCES plo(plo_id) ON DELETE CASCADE
 );
@@ -95,6 +147,49 @@ WITH (FORMAT csv, HEADER true, ENCODING 'UTF8');
 SELECT setval('career_paths_career_paths_id_seq', (SELECT MAX(career_paths_id) FROM career_paths));
 SELECT setval('plo_plo_id_seq', (SELECT MAX(plo_id) FROM plo));
 
+-- สถานะหลักสูตร draft = ฉบับร่าง, active = เปิดรับ, closed = ปิดรับนักศึกษาใหม่, retired = เลิกใช้
+-- ข้อมูลเดิมเป็นข้อความอิสระ "แสดง" คือหลักสูตรที่เปิดใช้ ค่าอื่นถือเป็นฉบับร่าง
+-- next_status จะมีผลเมื่อถึงวันที่ next_status_at
+
+UPDATE courses SET status = CASE
+    WHEN status IN ('draft', 'active', 'closed', 'retired') THEN status
+    WHEN status = 'แสดง' THEN 'active'
+    ELSE 'draft'
+END;
+
+ALTER TABLE courses ADD CONSTRAINT courses_status_check CHECK (status IN ('draft', 'active', 'closed', 'retired'));
+
+CREATE INDEX IF NOT EXISTS idx_courses_next_status_at ON courses(next_status_at) WHERE next_status_at IS NOT NULL;
+
+-- รุ่นของหลักสูตร course_id คือหลักสูตรใหม่ที่ใช้แทน replaces_id
+-- หลักสูตรหนึ่งแทนได้หลักสูตรเดียวและถูกแทนได้ครั้งเดียว จึงเป็นสายเส้นเดียว
+
+CREATE TABLE IF NOT EXISTS course_lineage (
+    course_id VARCHAR(10) PRIMARY KEY,
+    replaces_id VARCHAR(10) NOT NULL UNIQUE,
+    note TEXT NOT NULL DEFAULT '',
+    CHECK (course_id <> replaces_id),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (replaces_id) REFERENCES courses(course_id) ON DELETE CASCADE
+);
+
+-- รายวิชาเทียบเท่าระหว่างหลักสูตร วิชาเดิมหนึ่งวิชาเทียบได้หลายวิชาใหม่และกลับกัน
+
+CREATE TABLE IF NOT EXISTS subject_equivalences (
+    equivalence_id SERIAL PRIMARY KEY,
+    from_course_id VARCHAR(10) NOT NULL,
+    from_subject_id VARCHAR(10) NOT NULL,
+    to_course_id VARCHAR(10) NOT NULL,
+    to_subject_id VARCHAR(10) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    UNIQUE (from_course_id, from_subject_id, to_course_id, to_subject_id),
+    CHECK (from_course_id <> to_course_id),
+    FOREIGN KEY (from_course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (to_course_id) REFERENCES courses(course_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_subject_equivalences_to ON subject_equivalences(to_course_id, from_course_id);
+
 -- create course structure
 
 CREATE TABLE IF NOT EXISTS course_structure (
@@ -104,6 +199,32 @@ CREATE TABLE IF NOT EXISTS course_structure (
     FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE
 );
 
+-- โครงสร้างหลักสูตรแบบต้นไม้ หมวดวิชา (parent_id เป็น NULL) -> กลุ่มวิชา -> รายวิชา
+-- รายวิชาอ้างด้วยรหัสวิชา เพราะ subjects มีรหัสเดียวกันหลายแถวตามแผนการเรียน
+
+CREATE TABLE IF NOT EXISTS curriculum_nodes (
+    node_id SERIAL PRIMARY KEY,
+    course_id VARCHAR(10) NOT NULL,
+    parent_id INT NULL,
+    category VARCHAR(30) NOT NULL CHECK (category IN ('general_education', 'core', 'major_required', 'major_elective', 'free_elective')),
+    name_th VARCHAR(255) NOT NULL,
+    name_en VARCHAR(255) NOT NULL DEFAULT '',
+    min_credits INT NOT NULL DEFAULT 0 CHECK (min_credits >= 0),
+    sort_order INT NOT NULL DEFAULT 0,
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES curriculum_nodes(node_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_curriculum_nodes_course ON curriculum_nodes(course_id, sort_order);
+
+CREATE TABLE IF NOT EXISTS curriculum_node_subjects (
+    node_id INT NOT NULL,
+    subject_id VARCHAR(10) NOT NULL,
+    sort_order INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (node_id, subject_id),
+    FOREIGN KEY (node_id) REFERENCES curriculum_nodes(node_id) ON DELETE CASCADE
+);
+
 -- create roadmap
 
 CREATE TABLE IF NOT EXISTS roadmap (
@@ -143,6 +264,11 @@ CREATE TABLE IF NOT EXISTS subjects (
     thai_subject VARCHAR(100) NOT NULL,
     eng_subject VARCHAR(100) NULL,
     credits VARCHAR(50) NOT NULL,
+    credit_value INT NOT NULL DEFAULT 0 CHECK (credit_value >= 0),
+    lecture_hours INT NULL CHECK (lecture_hours >= 0),
+    lab_hours INT NULL CHECK (lab_hours >= 0),
+    self_study_hours INT NULL CHECK (self_study_hours >= 0),
+    non_credit BOOLEAN NOT NULL DEFAULT false,
     compulsory_subject VARCHAR(255) NULL,
     condition VARCHAR(255) NULL,
     description_id VARCHAR(6) NULL,
@@ -152,6 +278,24 @@ CREATE TABLE IF NOT EXISTS subjects (
     FOREIGN KEY (clo_id) REFERENCES clo(clo_id) ON DELETE CASCADE
 );
 
+-- กราฟวิชาบังคับก่อนภายในหลักสูตร อ้างด้วยรหัสวิชาเหมือน curriculum_node_subjects
+-- เส้นของวิชาเดียวกันที่ group_no เท่ากันคือทางเลือก "หรือ" ส่วน group_no ต่างกันต้องผ่านทุกกลุ่ม
+
+CREATE TABLE IF NOT EXISTS subject_prerequisites (
+    prerequisite_id SERIAL PRIMARY KEY,
+    course_id VARCHAR(10) NOT NULL,
+    subject_id VARCHAR(10) NOT NULL,
+    requires_id VARCHAR(10) NOT NULL,
+    kind VARCHAR(20) NOT NULL DEFAULT 'prerequisite' CHECK (kind IN ('prerequisite', 'corequisite')),
+    group_no INT NOT NULL DEFAULT 1,
+    source VARCHAR(10) NOT NULL DEFAULT 'manual' CHECK (source IN ('parsed', 'manual')),
+    UNIQUE (course_id, subject_id, group_no, requires_id),
+    CHECK (subject_id <> requires_id),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_subject_prerequisites_requires ON subject_prerequisites(course_id, requires_id);
+
 -- insert subject
 
 COPY description(description_id,description_thai,description_eng)
@@ -190,8 +334,177 @@ COPY subjects(subject_id,course_id,plan_type,semester,thai_subject,eng_subject,c
 FROM '/docker-entrypoint-initdb.d/csv/subject/DSIT66_subjects.csv'
 WITH (FORMAT csv, HEADER true, ENCODING 'UTF8');
 
+-- แยกหน่วยกิตจากข้อความ เช่น "3(2-2-5)" หรือ "1*(0-2-1)" เป็นตัวเลข และตัดช่องว่างหัวท้ายที่ติดมากับ csv
+
+UPDATE subjects SET credits = REGEXP_REPLACE(BTRIM(credits, E' \t\r\n'), '\s+', ' ', 'g');
+
+UPDATE subjects SET
+    credit_value = (REGEXP_MATCH(credits, '^(\d+)'))[1]::INT,
+    non_credit = credits ~ '^\d+\s*\*',
+    lecture_hours = (REGEXP_MATCH(credits, '\((\d+)-(\d+)-(\d+)\)'))[1]::INT,
+    lab_hours = (REGEXP_MATCH(credits, '\((\d+)-(\d+)-(\d+)\)'))[2]::INT,
+    self_study_hours = (REGEXP_MATCH(credits, '\((\d+)-(\d+)-(\d+)\)'))[3]::INT;
+
+-- แผนการศึกษาแบบข้อมูล แผน -> ปี/ภาคการศึกษา -> รายวิชาเรียงตาม position
+-- subject_id เป็น NULL คือช่องวิชาเลือกที่ระบุแค่ label และหน่วยกิต
+
+CREATE TABLE IF NOT EXISTS study_plans (
+    plan_id SERIAL PRIMARY KEY,
+    course_id VARCHAR(10) NOT NULL,
+    plan_type VARCHAR(50) NOT NULL,
+    name_en VARCHAR(100) NOT NULL DEFAULT '',
+    max_semester_credits INT NOT NULL DEFAULT 22 CHECK (max_semester_credits > 0),
+    max_summer_credits INT NOT NULL DEFAULT 9 CHECK (max_summer_credits >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (course_id, plan_type),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS study_plan_subjects (
+    plan_id INT NOT NULL,
+    year INT NOT NULL CHECK (year BETWEEN 1 AND 8),
+    semester INT NOT NULL CHECK (semester BETWEEN 1 AND 3),
+    position INT NOT NULL,
+    subject_id VARCHAR(10) NULL,
+    label VARCHAR(255) NOT NULL DEFAULT '',
+    credit_value INT NOT NULL DEFAULT 0 CHECK (credit_value >= 0),
+    PRIMARY KEY (plan_id, year, semester, position),
+    CHECK (subject_id IS NOT NULL OR label <> ''),
+    FOREIGN KEY (plan_id) REFERENCES study_plans(plan_id) ON DELETE CASCADE
+);
+
+-- สร้างแผนเริ่มต้นจากแผนการเรียนและภาคการศึกษาของรายวิชา รหัส "------" คือช่องวิชาเลือก
+
+INSERT INTO study_plans (course_id, plan_type)
+SELECT DISTINCT course_id, plan_type FROM subjects;
+
+INSERT INTO study_plan_subjects (plan_id, year, semester, position, subject_id, label, credit_value)
+SELECT p.plan_id, t.year, t.semester,
+    ROW_NUMBER() OVER (PARTITION BY p.plan_id, t.year, t.semester ORDER BY s.id),
+    CASE WHEN s.subject_id ~ '^-+$' THEN NULL ELSE s.subject_id END,
+    CASE WHEN s.subject_id ~ '^-+$' THEN BTRIM(s.thai_subject) ELSE '' END,
+    CASE WHEN s.non_credit THEN 0 ELSE s.credit_value END
+FROM subjects s
+JOIN study_plans p ON p.course_id = s.course_id AND p.plan_type = s.plan_type
+CROSS JOIN LATERAL (
+    SELECT (m)[1]::INT AS year, (m)[2]::INT AS semester
+    FROM REGEXP_MATCH(s.semester, '(\d+)\D+(\d+)') AS m
+) t
+WHERE t.year IS NOT NULL;
+
 SELECT setval('subjects_id_seq', (SELECT MAX(id) FROM subjects));
 
+-- PLO และ CLO รายข้อ แยกจากข้อความหลายบรรทัดใน plo และ clo ซึ่งยังเก็บไว้ให้ API เดิม
+-- plo_items เป็นของหลักสูตร ส่วน clo_items เป็นของ clo ที่รายวิชาอ้างผ่าน subjects.clo_id
+
+CREATE TABLE IF NOT EXISTS plo_items (
+    plo_item_id SERIAL PRIMARY KEY,
+    course_id VARCHAR(10) NOT NULL,
+    code VARCHAR(20) NOT NULL,
+    description TEXT NOT NULL,
+    sort_order INT NOT NULL DEFAULT 0,
+    UNIQUE (course_id, code),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS clo_items (
+    clo_item_id SERIAL PRIMARY KEY,
+    clo_id VARCHAR(6) NOT NULL,
+    code VARCHAR(20) NOT NULL,
+    description TEXT NOT NULL,
+    sort_order INT NOT NULL DEFAULT 0,
+    UNIQUE (clo_id, code),
+    FOREIGN KEY (clo_id) REFERENCES clo(clo_id) ON DELETE CASCADE
+);
+
+-- ระดับที่ CLO ส่งเสริม PLO: I = Introduce, R = Reinforce, M = Master
+
+CREATE TABLE IF NOT EXISTS clo_plo_map (
+    clo_item_id INT NOT NULL,
+    plo_item_id INT NOT NULL,
+    level CHAR(1) NOT NULL CHECK (level IN ('I', 'R', 'M')),
+    PRIMARY KEY (clo_item_id, plo_item_id),
+    FOREIGN KEY (clo_item_id) REFERENCES clo_items(clo_item_id) ON DELETE CASCADE,
+    FOREIGN KEY (plo_item_id) REFERENCES plo_items(plo_item_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_clo_plo_map_plo ON clo_plo_map(plo_item_id);
+
+-- แต่ละบรรทัดขึ้นต้นด้วยรหัส เช่น "PLO1        อธิบาย..." รหัสที่ซ้ำในข้อความเดียวกันใช้บรรทัดแรก
+
+INSERT INTO plo_items (course_id, code, description, sort_order)
+SELECT c.course_id, REGEXP_REPLACE(m[1], '\s+', '', 'g'), REGEXP_REPLACE(m[2], '^\s+|\s+$', '', 'g'), l.ord
+FROM courses c
+JOIN plo p ON p.plo_id = c.plo_id
+CROSS JOIN LATERAL REGEXP_SPLIT_TO_TABLE(p.plo, '\n') WITH ORDINALITY AS l(line, ord)
+CROSS JOIN LATERAL REGEXP_MATCH(l.line, '^\s*(PLO\s*\d+(?:\.\d+)?)[[:space:]:.)-]*(.*)$') AS m
+WHERE m IS NOT NULL
+ORDER BY c.course_id, l.ord
+ON CONFLICT (course_id, code) DO NOTHING;
+
+INSERT INTO clo_items (clo_id, code, description, sort_order)
+SELECT c.clo_id, REGEXP_REPLACE(m[1], '\s+', '', 'g'), REGEXP_REPLACE(m[2], '^\s+|\s+$', '', 'g'), l.ord
+FROM clo c
+CROSS JOIN LATERAL REGEXP_SPLIT_TO_TABLE(c.clo, '\n') WITH ORDINALITY AS l(line, ord)
+CROSS JOIN LATERAL REGEXP_MATCH(l.line, '^\s*(CLO\s*\d+(?:\.\d+)?)[[:space:]:.)-]*(.*)$') AS m
+WHERE m IS NOT NULL
+ORDER BY c.clo_id, l.ord
+ON CONFLICT (clo_id, code) DO NOTHING;
+
+-- อาชีพรายตัว แยกจากข้อความรวมใน career_paths ซึ่งยังเก็บไว้ให้ API เดิม
+-- หลักสูตรหนึ่งนำไปสู่ได้หลายอาชีพ และอาชีพเดียวกันมาจากได้หลายหลักสูตร
+
+CREATE TABLE IF NOT EXISTS careers (
+    career_id SERIAL PRIMARY KEY,
+    title VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    salary_min INT,
+    salary_max INT,
+    CHECK (salary_min IS NULL OR salary_min >= 0),
+    CHECK (salary_min IS NULL OR salary_max IS NULL OR salary_min <= salary_max)
+);
+
+CREATE TABLE IF NOT EXISTS course_careers (
+    course_id VARCHAR(10) NOT NULL,
+    career_id INT NOT NULL,
+    sort_order INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (course_id, career_id),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (career_id) REFERENCES careers(career_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_course_careers_career ON course_careers(career_id);
+
+-- รายวิชาที่เกี่ยวข้องกับอาชีพ อ้างด้วยรหัสวิชาเพราะรหัสเดียวกันอยู่ได้หลายหลักสูตร
+
+CREATE TABLE IF NOT EXISTS career_subjects (
+    career_id INT NOT NULL,
+    subject_id VARCHAR(10) NOT NULL,
+    sort_order INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (career_id, subject_id),
+    FOREIGN KEY (career_id) REFERENCES careers(career_id) ON DELETE CASCADE
+);
+
+-- แต่ละบรรทัดเป็น "1) ชื่ออาชีพ" อาชีพที่ชื่อตรงกันในหลายหลักสูตรใช้แถวเดียวกัน
+
+INSERT INTO careers (title)
+SELECT DISTINCT LEFT(m[1], 255)
+FROM career_paths cp
+CROSS JOIN LATERAL REGEXP_SPLIT_TO_TABLE(cp.career_paths, '\n') AS l(line)
+CROSS JOIN LATERAL REGEXP_MATCH(l.line, '^\s*(?:\d+[).]\s*)?(\S.*\S|\S)\s*$') AS m
+WHERE m IS NOT NULL
+ON CONFLICT (title) DO NOTHING;
+
+INSERT INTO course_careers (course_id, career_id, sort_order)
+SELECT c.course_id, k.career_id, MIN(l.ord)
+FROM courses c
+JOIN career_paths cp ON cp.career_paths_id = c.career_paths_id
+CROSS JOIN LATERAL REGEXP_SPLIT_TO_TABLE(cp.career_paths, '\n') WITH ORDINALITY AS l(line, ord)
+CROSS JOIN LATERAL REGEXP_MATCH(l.line, '^\s*(?:\d+[).]\s*)?(\S.*\S|\S)\s*$') AS m
+JOIN careers k ON k.title = LEFT(m[1], 255)
+WHERE m IS NOT NULL
+GROUP BY c.course_id, k.career_id;
+
 -- create personnel
 
 CREATE TABLE IF NOT EXISTS department_position (
@@ -222,6 +535,22 @@ CREATE TABLE IF NOT EXISTS personnels (
     FOREIGN KEY (academic_position_id) REFERENCES academic_position(academic_position_id) ON DELETE CASCADE
 );
 
+-- สถานะบุคลากร (active, study_leave, retired, former) พร้อมวันที่มีผล
+-- ถ้าไม่มีแถวที่มีผลอยู่ ณ วันปัจจุบัน ถือว่าเป็น active
+CREATE TABLE IF NOT EXISTS personnel_status (
+    status_id SERIAL PRIMARY KEY,
+    personnel_id INT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('active', 'study_leave', 'retired', 'former')),
+    effective_from DATE NOT NULL DEFAULT CURRENT_DATE,
+    effective_to DATE NULL,
+    note TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (personnel_id) REFERENCES personnels(personnel_id) ON DELETE CASCADE,
+    CHECK (effective_to IS NULL OR effective_to >= effective_from)
+);
+
+CREATE INDEX idx_personnel_status_personnel ON personnel_status(personnel_id, effective_from);
+
 -- insert personnel
 
 INSERT INTO department_position(department_position_name) VALUES
@@ -259,7 +588,7 @@ CREATE TABLE IF NOT EXISTS research (
     doi TEXT,
     cited INT DEFAULT 0,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (personnel_id) REFERENCES personnels(personnel_id) ON DELETE CASCADE
+    FOREIGN KEY (personnel_id) REFERENCES personnels(personnel_id) ON DELETE RESTRICT
 );
 
 CREATE TABLE IF NOT EXISTS research_authors (
@@ -277,6 +606,8 @@ CREATE TABLE IF NOT EXISTS admission (
     admission_id SERIAL PRIMARY KEY,
     round TEXT NOT NULL,
     detail TEXT NOT NULL,
+    round_en TEXT NOT NULL DEFAULT '',
+    detail_en TEXT NOT NULL DEFAULT '',
     file_image TEXT NOT NULL
 );
 
@@ -286,6 +617,8 @@ CREATE TABLE IF NOT EXISTS calendar (
     calendar_id SERIAL PRIMARY KEY,
     title TEXT NOT NULL,  
     detail TEXT NOT NULL,
+    title_en TEXT NOT NULL DEFAULT '',
+    detail_en TEXT NOT NULL DEFAULT '',
     start_date DATE NOT NULL,
     end_date DATE NOT NULL
 );
@@ -310,6 +643,8 @@ CREATE TABLE IF NOT EXISTS document (
     title TEXT NOT NULL,
     description TEXT,
     file TEXT NOT NULL,
+    -- private คือเอกสารภายในที่เก็บใน bucket ที่ไม่เปิดสาธารณะ ดาวน์โหลดได้เฉพาะผู้มีสิทธิ์
+    visibility VARCHAR(10) NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'private')),
     FOREIGN KEY (type_id) REFERENCES document_types(type_id) ON DELETE CASCADE
 );
 
@@ -568,6 +903,14 @@ INSERT INTO permissions (name, description, resource, action) VALUES
 ('news:create', 'Can create new news', 'news', 'create'),
 ('news:update', 'Can update news', 'news', 'update'),
 ('news:delete', 'Can delete news', 'news', 'delete'),
+('news:publish', 'Can publish news', 'news', 'publish'),
+('news:feature', 'Can pin and feature news on the homepage', 'news', 'feature'),
+('news_types:create', 'Can create news types', 'news_types', 'create'),
+('news_types:update', 'Can update and reorder news types', 'news_types', 'update'),
+('news_types:delete', 'Can delete news types', 'news_types', 'delete'),
+('news_tags:create', 'Can create news tags', 'news_tags', 'create'),
+('news_tags:update', 'Can update news tags', 'news_tags', 'update'),
+('news_tags:delete', 'Can delete news tags', 'news_tags', 'delete'),
 
 -- courses 
 ('courses:read', 'Can view courses', 'courses', 'read'),
@@ -575,6 +918,9 @@ INSERT INTO permissions (name, description, resource, action) VALUES
 ('courses:create', 'Can create new courses', 'courses', 'create'),
 ('courses:update', 'Can update courses', 'courses', 'update'),
 ('courses:delete', 'Can delete courses', 'courses', 'delete'),
+('careers:create', 'Can create new careers', 'careers', 'create'),
+('careers:update', 'Can update careers', 'careers', 'update'),
+('careers:delete', 'Can delete careers', 'careers', 'delete'),
 
 -- course_structure 
 ('course_structure:read', 'Can view course_structure', 'course_structure', 'read'),
@@ -587,6 +933,7 @@ INSERT INTO permissions (name, description, resource, action) VALUES
 ('roadmap:read', 'Can view roadmap', 'roadmap', 'read'),
 ('roadmap:read_id', 'Can view roadmap id', 'roadmap', 'read'),
 ('roadmap:create', 'Can create new roadmap', 'roadmap', 'create'),
+('roadmap:update', 'Can update roadmap', 'roadmap', 'update'),
 ('roadmap:delete', 'Can delete roadmap', 'roadmap', 'delete'),
 
 -- subject 
@@ -628,6 +975,7 @@ INSERT INTO permissions (name, description, resource, action) VALUES
 ('document:create', 'Can create new document', 'document', 'create'),
 ('document:update', 'Can update document', 'document', 'update'),
 ('document:delete', 'Can delete document', 'document', 'delete'),
+('document:read_private', 'Can download private document', 'document', 'read'),
 
 -- users
 ('users:read', 'Can view users', 'users', 'read'),
@@ -664,23 +1012,26 @@ SELECT
     (SELECT role_id FROM roles WHERE name = 'admin'),
     permission_id FROM permissions
 WHERE name IN (
-    'news:read', 'news:read_id', 'news:create', 'news:update', 'news:delete',
+    'news:read', 'news:read_id', 'news:create', 'news:update', 'news:delete', 'news:publish', 'news:feature',
+    'news_types:create', 'news_types:update', 'news_types:delete',
+    'news_tags:create', 'news_tags:update', 'news_tags:delete',
     'courses:read', 'courses:read_id', 'courses:create', 'courses:update', 'courses:delete',
+    'careers:create', 'careers:update', 'careers:delete',
     'course_structure:read', 'course_structure:read_id', 'course_structure:create', 'course_structure:update', 'course_structure:delete',
-    'roadmap:read', 'roadmap:read_id', 'roadmap:create', 'roadmap:delete',
+    'roadmap:read', 'roadmap:read_id', 'roadmap:create', 'roadmap:update', 'roadmap:delete',
     'subject:read', 'subject:read_id', 'subject:create', 'subject:update', 'subject:delete',
     'personnel:read', 'personnel:read_id', 'personnel:create', 'personnel:update', 'your_personnel:update', 'personnel:delete',
     'scopus:read', 'research:read',
     'admission:read', 'admission:read_id', 'admission:create', 'admission:update', 'admission:delete',
-    'calendar:read', 'calendar:read_id', 'calendar:create', 'calendar:update', 'calendar:delete'
-    'document:read', 'document:read_id', 'document:create', 'document:update', 'document:delete'
+    'calendar:read', 'calendar:read_id', 'calendar:create', 'calendar:update', 'calendar:delete',
+    'document:read', 'document:read_id', 'document:create', 'document:update', 'document:delete', 'document:read_private'
 );
 
--- Teacher your_personnel:update
+-- Teacher your_personnel:update, document:read_private
 INSERT INTO role_permissions (role_id, permission_id)
 SELECT
     (SELECT role_id FROM roles WHERE name = 'teacher'),
-    permission_id FROM permissions WHERE name = 'your_personnel:update';
+    permission_id FROM permissions WHERE name IN ('your_personnel:update', 'document:read_private');
 
 -- Refresh Tokens (สำหรับ JWT refresh)
 CREATE TABLE refresh_tokens (
@@ -714,6 +1065,54 @@ CREATE INDEX idx_audit_logs_user ON audit_logs(user_id);
 CREATE INDEX idx_audit_logs_action ON audit_logs(action);
 CREATE INDEX idx_audit_logs_created ON audit_logs(created_at);
 
+-- News Revisions (ประวัติการแก้ไขข่าว แก้ไขไม่ได้)
+CREATE TABLE news_revisions (
+    revision_id SERIAL PRIMARY KEY,
+    news_id INTEGER NOT NULL REFERENCES news(news_id) ON DELETE CASCADE,
+    revision_no INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    title_en VARCHAR(255) NOT NULL DEFAULT '',
+    content_en TEXT NOT NULL DEFAULT '',
+    type_id INTEGER NOT NULL,
+    detail_url TEXT NULL,
+    cover_image TEXT NOT NULL,
+    images TEXT[] NOT NULL DEFAULT '{}',
+    author_id INTEGER NULL REFERENCES users(user_id) ON DELETE SET NULL,
+    rollback_of INTEGER NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (news_id, revision_no)
+);
+
+-- revision แรกของข่าวตัวอย่าง
+INSERT INTO news_revisions (news_id, revision_no, title, content, title_en, content_en, type_id, detail_url, cover_image, images, created_at)
+SELECT
+    n.news_id, 1, n.title, n.content, n.title_en, n.content_en, n.type_id, n.detail_url, n.cover_image,
+    ARRAY(SELECT ni.file_image FROM news_images ni WHERE ni.news_id = n.news_id ORDER BY ni.position, ni.image_id),
+    n.created_at
+FROM news n;
+
+-- SEARCH
+
+-- ภาษาไทยไม่เว้นวรรคระหว่างคำ /api/v1/search จึงค้นแบบ ILIKE '%คำค้น%'
+-- index แบบ trigram ช่วยให้ค้น substring ได้โดยไม่ต้อง scan ทั้งตาราง
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_news_title_trgm ON news USING gin (title gin_trgm_ops);
+CREATE INDEX idx_news_content_trgm ON news USING gin (content gin_trgm_ops);
+CREATE INDEX idx_courses_thai_course_trgm ON courses USING gin (thai_course gin_trgm_ops);
+CREATE INDEX idx_courses_eng_course_trgm ON courses USING gin (eng_course gin_trgm_ops);
+CREATE INDEX idx_subjects_thai_subject_trgm ON subjects USING gin (thai_subject gin_trgm_ops);
+CREATE INDEX idx_subjects_eng_subject_trgm ON subjects USING gin (eng_subject gin_trgm_ops);
+CREATE INDEX idx_description_thai_trgm ON description USING gin (description_thai gin_trgm_ops);
+CREATE INDEX idx_description_eng_trgm ON description USING gin (description_eng gin_trgm_ops);
+CREATE INDEX idx_personnels_thai_name_trgm ON personnels USING gin (thai_name gin_trgm_ops);
+CREATE INDEX idx_personnels_eng_name_trgm ON personnels USING gin (eng_name gin_trgm_ops);
+CREATE INDEX idx_personnels_related_fields_trgm ON personnels USING gin (related_fields gin_trgm_ops);
+CREATE INDEX idx_research_title_trgm ON research USING gin (title gin_trgm_ops);
+CREATE INDEX idx_document_title_trgm ON document USING gin (title gin_trgm_ops);
+CREATE INDEX idx_document_description_trgm ON document USING gin (description gin_trgm_ops);
+
 -- TRIGGER
 
 CREATE OR REPLACE FUNCTION update_modified_column()